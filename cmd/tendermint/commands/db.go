@@ -0,0 +1,271 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	dbm "github.com/tendermint/tm-db"
+
+	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/libs/compress"
+	"github.com/tendermint/tendermint/internal/libs/tempfile"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/store"
+	tmos "github.com/tendermint/tendermint/libs/os"
+	"github.com/tendermint/tendermint/scripts/dbmigrate"
+)
+
+// nodeDBContexts are the IDs of all databases a node opens via its
+// DBProvider, ordered to put the (presumably) biggest ones first.
+var nodeDBContexts = []string{
+	"blockstore",
+	"state",
+	"tx_index",
+	"indexer",
+	"peerstore",
+	"evidence",
+	"light",
+}
+
+// migrateProgressInterval is the minimum interval between progress reports.
+const migrateProgressInterval = 10 * time.Second
+
+// MakeDBCommand constructs a command to manage the node's databases.
+func MakeDBCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Manage the node's databases",
+	}
+	cmd.AddCommand(
+		makeDBMigrateCommand(),
+		makeDBRecompressCommand(),
+	)
+	return cmd
+}
+
+func makeDBMigrateCommand() *cobra.Command {
+	var (
+		from, to, targetDir string
+		batchSize           int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Copy the node's databases to another database backend",
+		Long: `Copy all databases of a stopped node (blockstore, state, tx_index, peerstore,
+evidence and light) from one database backend to another, so that the node can
+switch backends without resyncing.
+
+The databases are copied to the target directory, which defaults to the
+database directory suffixed with the target backend. Once all databases have
+been copied, their key counts and checksums are verified against the source
+databases. An interrupted migration resumes where it left off when the command
+is run again.
+
+Once the migration has completed, set db-backend and db-dir in config.toml to
+the target backend and directory.`,
+		Example: "tendermint db migrate --from goleveldb --to rocksdb",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if targetDir == "" {
+				targetDir = config.DBDir() + "-" + to
+			}
+			if err := migrateDBs(cmd.Context(), config, dbm.BackendType(from), dbm.BackendType(to),
+				targetDir, batchSize); err != nil {
+				return err
+			}
+
+			logger.Info("completed database migration successfully",
+				"db-backend", to, "db-dir", targetDir)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", string(dbm.GoLevelDBBackend), "database backend to migrate from")
+	cmd.Flags().StringVar(&to, "to", "", "database backend to migrate to")
+	cmd.Flags().StringVar(&targetDir, "target-dir", "",
+		"directory to write the migrated databases to (default: the database directory suffixed with the backend)")
+	cmd.Flags().IntVar(&batchSize, "batch-size", dbmigrate.DefaultBatchSize, "number of keys written per batch")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func makeDBRecompressCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "recompress",
+		Short: "Rewrite stored blocks and ABCI responses with the configured compression",
+		Long: `Rewrite the block parts in the blockstore database and the ABCI responses in
+the state database of a stopped node with the compression configured in the
+[storage] section of config.toml, e.g. to compress data written before
+compression was enabled, or to switch to another algorithm.
+
+Data that is already stored with the configured algorithm is skipped, and data
+is rewritten in small batches, so the command can be interrupted at any time
+and resumes where it left off when it is run again. Setting compression to
+"none" decompresses all data.`,
+		Example: "tendermint db recompress",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			compressor, err := compress.NewCompressor(config.Storage.Compression, config.Storage.CompressionLevel)
+			if err != nil {
+				return err
+			}
+			return recompressDBs(cmd.Context(), config, compressor)
+		},
+	}
+}
+
+// recompressDBs rewrites the block parts and ABCI responses in the configured
+// database directory with the compressor.
+func recompressDBs(ctx context.Context, conf *cfg.Config, compressor *compress.Compressor) error {
+	dbType := dbm.BackendType(conf.DBBackend)
+
+	blockStoreDB, err := dbm.NewDB("blockstore", dbType, conf.DBDir())
+	if err != nil {
+		return err
+	}
+	defer blockStoreDB.Close()
+	logger.Info("recompressing block parts", "compression", compressor.Algorithm().String())
+	parts, err := store.NewBlockStore(blockStoreDB, store.WithCompressor(compressor)).RecompressBlockParts(ctx)
+	if err != nil {
+		return fmt.Errorf("recompressing block parts: %w", err)
+	}
+	logger.Info("recompressed block parts", "parts", parts)
+
+	stateDB, err := dbm.NewDB("state", dbType, conf.DBDir())
+	if err != nil {
+		return err
+	}
+	defer stateDB.Close()
+	logger.Info("recompressing ABCI responses", "compression", compressor.Algorithm().String())
+	responses, err := sm.RecompressABCIResponses(ctx, stateDB, compressor)
+	if err != nil {
+		return fmt.Errorf("recompressing ABCI responses: %w", err)
+	}
+	logger.Info("recompressed ABCI responses", "responses", responses)
+	return nil
+}
+
+// migrateDBs copies all existing node databases in the configured database
+// directory from one backend to the other, writing them to targetDir.
+func migrateDBs(
+	ctx context.Context,
+	conf *cfg.Config,
+	from, to dbm.BackendType,
+	targetDir string,
+	batchSize int,
+) error {
+	sourceDir := conf.DBDir()
+	if filepath.Clean(sourceDir) == filepath.Clean(targetDir) {
+		return errors.New("target directory must differ from the database directory")
+	}
+	if to == dbm.MemDBBackend {
+		return fmt.Errorf("can't migrate to %s", to)
+	}
+	if err := tmos.EnsureDir(targetDir, 0700); err != nil {
+		return err
+	}
+
+	for idx, id := range nodeDBContexts {
+		if !dbExists(from, id, sourceDir) {
+			logger.Info("skipping database which doesn't exist", "db", id)
+			continue
+		}
+		logger.Info("beginning database migration",
+			"db", id,
+			"num", idx+1,
+			"total", len(nodeDBContexts),
+		)
+		if err := migrateDB(ctx, id, from, sourceDir, to, targetDir, batchSize); err != nil {
+			return fmt.Errorf("migrating database %q: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// migrateDB copies and verifies a single database, resuming from and
+// recording its progress in a file in the target directory.
+func migrateDB(
+	ctx context.Context,
+	id string,
+	from dbm.BackendType, sourceDir string,
+	to dbm.BackendType, targetDir string,
+	batchSize int,
+) error {
+	src, err := dbm.NewDB(id, from, sourceDir)
+	if err != nil {
+		return fmt.Errorf("opening source database: %w", err)
+	}
+	defer src.Close()
+	dst, err := dbm.NewDB(id, to, targetDir)
+	if err != nil {
+		return fmt.Errorf("opening target database: %w", err)
+	}
+	defer dst.Close()
+
+	progressFile := filepath.Join(targetDir, "migrate-"+id+".json")
+	progress, err := loadMigrateProgress(progressFile)
+	if err != nil {
+		return err
+	}
+	if progress.Keys > 0 && !progress.Done {
+		logger.Info("resuming database migration", "db", id, "keys", progress.Keys)
+	}
+
+	lastReport := time.Now()
+	progress, err = dbmigrate.Migrate(ctx, src, dst, progress, batchSize, func(p dbmigrate.Progress) error {
+		if time.Since(lastReport) >= migrateProgressInterval {
+			logger.Info("migrating database", "db", id, "keys", p.Keys)
+			lastReport = time.Now()
+		}
+		return saveMigrateProgress(progressFile, p)
+	})
+	if err != nil {
+		return err
+	}
+	if err := saveMigrateProgress(progressFile, progress); err != nil {
+		return err
+	}
+
+	logger.Info("verifying database", "db", id, "keys", progress.Keys)
+	return dbmigrate.Verify(ctx, src, dst)
+}
+
+func loadMigrateProgress(path string) (dbmigrate.Progress, error) {
+	var progress dbmigrate.Progress
+	bz, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return progress, nil
+	case err != nil:
+		return progress, err
+	}
+	if err := json.Unmarshal(bz, &progress); err != nil {
+		return progress, fmt.Errorf("invalid migration progress file %s: %w", path, err)
+	}
+	return progress, nil
+}
+
+func saveMigrateProgress(path string, progress dbmigrate.Progress) error {
+	bz, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	return tempfile.WriteFileAtomic(path, bz, 0600)
+}
+
+// dbExists returns true if a database with the given name exists in dir, so
+// that the migration doesn't create empty databases which the node never
+// opened.
+func dbExists(backend dbm.BackendType, name, dir string) bool {
+	path := filepath.Join(dir, name+".db")
+	if backend == dbm.BadgerDBBackend {
+		path = filepath.Join(dir, name)
+	}
+	return tmos.FileExists(path)
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	tmcfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/scripts/dbmigrate"
+)
+
+func TestMigrateDBs(t *testing.T) {
+	ctx := context.Background()
+	conf := tmcfg.TestConfig().SetRoot(t.TempDir())
+	targetDir := filepath.Join(t.TempDir(), "data-migrated")
+
+	for _, id := range []string{"blockstore", "state"} {
+		db, err := dbm.NewDB(id, dbm.GoLevelDBBackend, conf.DBDir())
+		require.NoError(t, err)
+		for i := 0; i < 25; i++ {
+			require.NoError(t, db.Set([]byte(fmt.Sprintf("%s%d", id, i)), []byte{byte(i)}))
+		}
+		require.NoError(t, db.Close())
+	}
+
+	require.Error(t, migrateDBs(ctx, conf, dbm.GoLevelDBBackend, dbm.GoLevelDBBackend, conf.DBDir(), 10))
+	require.NoError(t, migrateDBs(ctx, conf, dbm.GoLevelDBBackend, dbm.GoLevelDBBackend, targetDir, 10))
+
+	for _, id := range []string{"blockstore", "state"} {
+		progress, err := loadMigrateProgress(filepath.Join(targetDir, "migrate-"+id+".json"))
+		require.NoError(t, err)
+		require.True(t, progress.Done)
+		require.EqualValues(t, 25, progress.Keys)
+
+		src, err := dbm.NewDB(id, dbm.GoLevelDBBackend, conf.DBDir())
+		require.NoError(t, err)
+		dst, err := dbm.NewDB(id, dbm.GoLevelDBBackend, targetDir)
+		require.NoError(t, err)
+		require.NoError(t, dbmigrate.Verify(ctx, src, dst))
+		require.NoError(t, src.Close())
+		require.NoError(t, dst.Close())
+	}
+
+	// Databases the node never opened are not created.
+	require.False(t, dbExists(dbm.GoLevelDBBackend, "light", targetDir))
+
+	// Running the migration again only verifies the databases.
+	require.NoError(t, migrateDBs(ctx, conf, dbm.GoLevelDBBackend, dbm.GoLevelDBBackend, targetDir, 10))
+}
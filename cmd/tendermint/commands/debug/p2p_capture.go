@@ -0,0 +1,187 @@
+package debug
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/blocksync"
+	"github.com/tendermint/tendermint/internal/consensus"
+	"github.com/tendermint/tendermint/internal/evidence"
+	"github.com/tendermint/tendermint/internal/mempool"
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/p2p/pex"
+	"github.com/tendermint/tendermint/internal/statesync"
+	"github.com/tendermint/tendermint/libs/cli"
+	"github.com/tendermint/tendermint/types"
+)
+
+var (
+	capturePeers    []string
+	captureChannels []string
+
+	flagCapturePeer    = "peer"
+	flagCaptureChannel = "channel"
+)
+
+var p2pCaptureCmd = &cobra.Command{
+	Use:   "p2p-capture [capture-file]",
+	Short: "Decode a p2p message capture into JSON",
+	Long: `Decode a p2p message capture, as written by a node with p2p.capture-file
+set, into JSON. Each captured message is printed as a single line of JSON
+containing the capture time, peer ID, channel ID, direction ("in" or "out")
+and the message decoded using the message type registered for its channel.
+
+If no capture file is given, the capture file configured for the node is used.
+
+Example:
+$ tendermint debug p2p-capture /path/to/p2p-capture --channel 0x20`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: p2pCaptureCmdHandler,
+}
+
+func init() {
+	p2pCaptureCmd.Flags().StringSliceVar(
+		&capturePeers,
+		flagCapturePeer,
+		nil,
+		"only show messages sent to or received from these peer IDs",
+	)
+	p2pCaptureCmd.Flags().StringSliceVar(
+		&captureChannels,
+		flagCaptureChannel,
+		nil,
+		"only show messages on these channel IDs (e.g. 0x20)",
+	)
+}
+
+// capturedMessage is the JSON representation of a decoded capture record.
+type capturedMessage struct {
+	Time        time.Time       `json:"time"`
+	Peer        types.NodeID    `json:"peer"`
+	Channel     p2p.ChannelID   `json:"channel"`
+	Direction   string          `json:"direction"`
+	MessageType string          `json:"message_type,omitempty"`
+	Message     json.RawMessage `json:"message,omitempty"`
+	Raw         []byte          `json:"raw,omitempty"`
+	Error       string          `json:"error,omitempty"`
+}
+
+func p2pCaptureCmdHandler(cmd *cobra.Command, args []string) error {
+	var path string
+	if len(args) > 0 {
+		path = args[0]
+	} else {
+		conf := config.DefaultConfig()
+		if err := viper.Unmarshal(conf); err != nil {
+			return err
+		}
+		conf = conf.SetRoot(viper.GetString(cli.HomeFlag))
+		if path = conf.P2P.CaptureFilePath(); path == "" {
+			return fmt.Errorf("no capture file given, and p2p.capture-file is not set")
+		}
+	}
+
+	peers := make(map[types.NodeID]bool, len(capturePeers))
+	for _, s := range capturePeers {
+		peerID, err := types.NewNodeID(s)
+		if err != nil {
+			return fmt.Errorf("invalid peer ID %q: %w", s, err)
+		}
+		peers[peerID] = true
+	}
+	channels := make(map[p2p.ChannelID]bool, len(captureChannels))
+	for _, s := range captureChannels {
+		chID, err := strconv.ParseUint(s, 0, 16)
+		if err != nil {
+			return fmt.Errorf("invalid channel ID %q: %w", s, err)
+		}
+		channels[p2p.ChannelID(chID)] = true
+	}
+
+	messageTypes := channelMessageTypes()
+	marshaler := jsonpb.Marshaler{OrigName: true}
+
+	out := bufio.NewWriter(cmd.OutOrStdout())
+	defer out.Flush()
+	enc := json.NewEncoder(out)
+
+	return p2p.ReadCapture(path, func(record p2p.CaptureRecord) error {
+		if len(peers) > 0 && !peers[record.Peer] {
+			return nil
+		}
+		if len(channels) > 0 && !channels[record.Channel] {
+			return nil
+		}
+
+		msg := capturedMessage{
+			Time:      record.Time,
+			Peer:      record.Peer,
+			Channel:   record.Channel,
+			Direction: record.Direction,
+		}
+		if err := decodeCapturedMessage(&msg, record, messageTypes, marshaler); err != nil {
+			msg.Raw = record.Message
+			msg.Error = err.Error()
+		}
+		return enc.Encode(msg)
+	})
+}
+
+// decodeCapturedMessage decodes the message of a capture record into msg.
+func decodeCapturedMessage(
+	msg *capturedMessage,
+	record p2p.CaptureRecord,
+	messageTypes map[p2p.ChannelID]proto.Message,
+	marshaler jsonpb.Marshaler,
+) error {
+	messageType, ok := messageTypes[record.Channel]
+	if !ok {
+		return fmt.Errorf("unknown channel %#x", record.Channel)
+	}
+	pb := proto.Clone(messageType)
+	if err := proto.Unmarshal(record.Message, pb); err != nil {
+		return fmt.Errorf("failed to decode message: %w", err)
+	}
+	if wrapper, ok := pb.(p2p.Wrapper); ok {
+		inner, err := wrapper.Unwrap()
+		if err != nil {
+			return fmt.Errorf("failed to unwrap message: %w", err)
+		}
+		pb = inner
+	}
+	bz, err := marshaler.MarshalToString(pb)
+	if err != nil {
+		return fmt.Errorf("failed to encode message as JSON: %w", err)
+	}
+	msg.MessageType = proto.MessageName(pb)
+	msg.Message = json.RawMessage(bz)
+	return nil
+}
+
+// channelMessageTypes returns the message types of all channels registered by
+// the node's reactors, by channel ID.
+func channelMessageTypes() map[p2p.ChannelID]proto.Message {
+	chDescs := []*p2p.ChannelDescriptor{
+		blocksync.GetChannelDescriptor(),
+		evidence.GetChannelDescriptor(),
+		mempool.GetChannelDescriptor(config.DefaultMempoolConfig()),
+		pex.ChannelDescriptor(),
+	}
+	chDescs = append(chDescs, consensus.GetChannelDescriptors()...)
+	chDescs = append(chDescs, statesync.GetChannelDescriptors()...)
+
+	messageTypes := make(map[p2p.ChannelID]proto.Message, len(chDescs))
+	for _, chDesc := range chDescs {
+		messageTypes[chDesc.ID] = chDesc.MessageType
+	}
+	return messageTypes
+}
@@ -0,0 +1,69 @@
+package debug
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/tendermint/tendermint/config"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/store"
+	"github.com/tendermint/tendermint/libs/cli"
+)
+
+var verifyStoreCmd = &cobra.Command{
+	Use:   "verify-store",
+	Short: "Verify the integrity of the block and state stores",
+	Long: `Verify the integrity of the block and state stores of a stopped node.
+
+Every block from the base to the height of the block store is checked: its
+parts must reassemble to the block hash in its metadata, its LastBlockID must
+link to the previous block, its commit must verify against the validator set
+from the state store, and the stored ABCI responses must hash to the
+LastResultsHash of the next block. Every broken height is reported, and the
+command fails if any problems were found.
+
+The node must not be running while the stores are verified.`,
+	Args: cobra.NoArgs,
+	RunE: verifyStoreCmdHandler,
+}
+
+func verifyStoreCmdHandler(cmd *cobra.Command, args []string) error {
+	conf := config.DefaultConfig()
+	if err := viper.Unmarshal(conf); err != nil {
+		return err
+	}
+	conf = conf.SetRoot(viper.GetString(cli.HomeFlag))
+
+	blockStoreDB, err := config.DefaultDBProvider(&config.DBContext{ID: "blockstore", Config: conf})
+	if err != nil {
+		return err
+	}
+	defer blockStoreDB.Close()
+	stateDB, err := config.DefaultDBProvider(&config.DBContext{ID: "state", Config: conf})
+	if err != nil {
+		return err
+	}
+	defer stateDB.Close()
+
+	blockStore := store.NewBlockStore(blockStoreDB)
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "verifying blocks %d to %d\n", blockStore.Base(), blockStore.Height())
+
+	broken := map[int64]bool{}
+	err = sm.VerifyStore(cmd.Context(), blockStore, stateDB, func(err sm.StoreError) {
+		broken[err.Height] = true
+		fmt.Fprintln(out, err)
+	})
+	if err != nil {
+		return err
+	}
+	if len(broken) > 0 {
+		return fmt.Errorf("found problems at %d heights", len(broken))
+	}
+
+	fmt.Fprintln(out, "no problems found")
+	return nil
+}
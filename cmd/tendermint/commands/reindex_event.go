@@ -1,20 +1,31 @@
 package commands
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
 	dbm "github.com/tendermint/tm-db"
 
 	abcitypes "github.com/tendermint/tendermint/abci/types"
 	tmcfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/libs/compress"
 	"github.com/tendermint/tendermint/internal/libs/progressbar"
+	"github.com/tendermint/tendermint/internal/libs/tempfile"
 	"github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/file"
 	"github.com/tendermint/tendermint/internal/state/indexer/sink/kv"
 	"github.com/tendermint/tendermint/internal/state/indexer/sink/psql"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/sqlite"
 	"github.com/tendermint/tendermint/internal/store"
 	"github.com/tendermint/tendermint/rpc/coretypes"
 	"github.com/tendermint/tendermint/types"
@@ -32,15 +43,24 @@ var ReIndexEventCmd = &cobra.Command{
 reindex-event is an offline tooling to re-index block and tx events to the eventsinks,
 you can run this command when the event store backend dropped/disconnected or you want to 
 replace the backend. The default start-height is 0, meaning the tooling will start 
-reindex from the base block height(inclusive); and the default end-height is 0, meaning 
+reindex from the base block height(inclusive), or resume after the checkpoint
+if all the event sinks keep one (such as the file sink); and the default end-height is 0, meaning 
 the tooling will reindex until the latest block height(inclusive). User can omit
-either or both arguments.
+either or both arguments. Ranges of blocks are re-indexed concurrently by
+--workers workers, while the sinks which must index blocks in order (such as the
+file sink) still receive them in order. The progress is checkpointed in the data
+directory, so an interrupted run resumes after the checkpoint when it is run
+again with the same heights, --rebuild flag and event sinks. With --rebuild, the indexed events from the start height
+are deleted before re-indexing them, for example to apply a change to the
+tx-index.include-keys or tx-index.exclude-keys settings.
 	`,
 	Example: `
 	tendermint reindex-event
 	tendermint reindex-event --start-height 2
 	tendermint reindex-event --end-height 10
 	tendermint reindex-event --start-height 2 --end-height 10
+	tendermint reindex-event --rebuild
+	tendermint reindex-event --workers 8
 	`,
 	Run: func(cmd *cobra.Command, args []string) {
 		bs, ss, err := loadStateAndBlockStore(config)
@@ -49,18 +69,53 @@ either or both arguments.
 			return
 		}
 
-		if err := checkValidHeight(bs); err != nil {
+		es, err := loadEventSinks(config)
+		if err != nil {
 			fmt.Println(reindexFailed, err)
 			return
 		}
 
-		es, err := loadEventSinks(config)
+		checkpointPath := filepath.Join(config.DBDir(), reindexCheckpointFile)
+		cp, err := loadReindexCheckpoint(checkpointPath)
 		if err != nil {
 			fmt.Println(reindexFailed, err)
 			return
 		}
 
-		if err = eventReIndex(cmd, es, bs, ss); err != nil {
+		// An interrupted run is resumed only with the same options, and an
+		// interrupted rebuild without deleting the events again.
+		run := newReindexCheckpoint(es)
+		resumed := false
+		var h int64
+		if startHeight == 0 && !rebuild {
+			h = resumeHeight(es)
+		}
+		if cp.resumes(run) && cp.Height+1 > h {
+			h = cp.Height + 1
+			resumed = true
+		}
+		if h > 0 {
+			if h > bs.Height() || (endHeight > 0 && h > endHeight) {
+				fmt.Println("the event sinks are up to date")
+				return
+			}
+			startHeight = h
+			fmt.Printf("resume from the checkpoint at height %d \n", h)
+		}
+
+		if err := checkValidHeight(bs); err != nil {
+			fmt.Println(reindexFailed, err)
+			return
+		}
+
+		if rebuild && !resumed {
+			if err := deleteEvents(es, bs); err != nil {
+				fmt.Println(reindexFailed, err)
+				return
+			}
+		}
+
+		if err = eventReIndex(cmd, es, bs, ss, run, checkpointPath); err != nil {
 			fmt.Println(reindexFailed, err)
 			return
 		}
@@ -72,11 +127,17 @@ either or both arguments.
 var (
 	startHeight int64
 	endHeight   int64
+	rebuild     bool
+	workers     int
 )
 
 func init() {
 	ReIndexEventCmd.Flags().Int64Var(&startHeight, "start-height", 0, "the block height would like to start for re-index")
 	ReIndexEventCmd.Flags().Int64Var(&endHeight, "end-height", 0, "the block height would like to finish for re-index")
+	ReIndexEventCmd.Flags().BoolVar(&rebuild, "rebuild", false,
+		"delete the indexed events from the start height before re-indexing them up to the latest height")
+	ReIndexEventCmd.Flags().IntVar(&workers, "workers", runtime.NumCPU(),
+		"the number of workers loading and indexing ranges of blocks concurrently")
 }
 
 func loadEventSinks(cfg *tmcfg.Config) ([]indexer.EventSink, error) {
@@ -101,7 +162,8 @@ func loadEventSinks(cfg *tmcfg.Config) ([]indexer.EventSink, error) {
 			if err != nil {
 				return nil, err
 			}
-			eventSinks = append(eventSinks, kv.NewEventSink(store))
+			filter := indexer.NewAttributeFilter(cfg.TxIndex.IncludeKeys, cfg.TxIndex.ExcludeKeys)
+			eventSinks = append(eventSinks, kv.NewEventSink(store, filter))
 		case string(indexer.PSQL):
 			conn := cfg.TxIndex.PsqlConn
 			if conn == "" {
@@ -112,6 +174,18 @@ func loadEventSinks(cfg *tmcfg.Config) ([]indexer.EventSink, error) {
 				return nil, err
 			}
 			eventSinks = append(eventSinks, es)
+		case string(indexer.SQLITE):
+			es, err := sqlite.NewEventSink(cfg.TxIndex.SqliteFile(), chainID)
+			if err != nil {
+				return nil, err
+			}
+			eventSinks = append(eventSinks, es)
+		case string(indexer.FILE):
+			es, err := file.NewEventSink(cfg.TxIndex.FileDir())
+			if err != nil {
+				return nil, err
+			}
+			eventSinks = append(eventSinks, es)
 		default:
 			return nil, errors.New("unsupported event sink type")
 		}
@@ -129,87 +203,341 @@ func loadEventSinks(cfg *tmcfg.Config) ([]indexer.EventSink, error) {
 	return eventSinks, nil
 }
 
+// deleteEvents deletes the events from the start height in all event sinks, so
+// that they are rebuilt from scratch by eventReIndex. Since the events are
+// deleted up to the latest height, the end height must be the latest height.
+func deleteEvents(es []indexer.EventSink, bs state.BlockStore) error {
+	if endHeight != bs.Height() {
+		return fmt.Errorf("rebuilding re-indexes the events up to the latest height %d, not %d",
+			bs.Height(), endHeight)
+	}
+	for _, sink := range es {
+		if _, ok := sink.(indexer.EventSinkDeleter); !ok {
+			return fmt.Errorf("event sink %s does not support deleting events", sink.Type())
+		}
+	}
+	for _, sink := range es {
+		if err := sink.(indexer.EventSinkDeleter).DeleteFrom(startHeight); err != nil {
+			return fmt.Errorf("deleting events of event sink %s: %w", sink.Type(), err)
+		}
+	}
+	return nil
+}
+
+// resumeHeight returns the height following the lowest checkpoint of the
+// event sinks, or 0 if a sink doesn't keep a checkpoint or hasn't written any
+// block yet.
+func resumeHeight(es []indexer.EventSink) int64 {
+	var height int64
+	for _, sink := range es {
+		fs, ok := sink.(*file.EventSink)
+		if !ok {
+			return 0
+		}
+		cp := fs.Checkpoint()
+		if cp.Height == 0 {
+			return 0
+		}
+		if height == 0 || cp.Height+1 < height {
+			height = cp.Height + 1
+		}
+	}
+	return height
+}
+
 func loadStateAndBlockStore(cfg *tmcfg.Config) (*store.BlockStore, state.Store, error) {
 	dbType := dbm.BackendType(cfg.DBBackend)
 
+	compressor, err := compress.NewCompressor(cfg.Storage.Compression, cfg.Storage.CompressionLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Get BlockStore
 	blockStoreDB, err := dbm.NewDB("blockstore", dbType, cfg.DBDir())
 	if err != nil {
 		return nil, nil, err
 	}
-	blockStore := store.NewBlockStore(blockStoreDB)
+	blockStore := store.NewBlockStore(blockStoreDB, store.WithCompressor(compressor))
 
 	// Get StateStore
 	stateDB, err := dbm.NewDB("state", dbType, cfg.DBDir())
 	if err != nil {
 		return nil, nil, err
 	}
-	stateStore := state.NewStore(stateDB)
+	stateStore := state.NewStore(stateDB, state.StoreWithCompressor(compressor))
 
 	return blockStore, stateStore, nil
 }
 
-func eventReIndex(cmd *cobra.Command, es []indexer.EventSink, bs state.BlockStore, ss state.Store) error {
+// reindexCheckpointFile is the name of the file in the data directory
+// recording the progress of the re-indexing.
+const reindexCheckpointFile = "reindex_event.json"
+
+// reindexRangeSize is the number of blocks re-indexed by a worker at a time.
+const reindexRangeSize = 100
+
+// reindexCheckpoint records the progress of an interrupted re-indexing, along
+// with the options of the run, which a run must share to resume it.
+type reindexCheckpoint struct {
+	// Height is the height up to which all blocks have been re-indexed.
+	Height int64 `json:"height"`
+	// Start and End are the heights requested for the run, or 0 if they
+	// weren't given.
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+	// Rebuild reports whether the events were deleted before re-indexing.
+	Rebuild bool `json:"rebuild"`
+	// Sinks are the sorted types of the event sinks.
+	Sinks []string `json:"sinks"`
+}
+
+// newReindexCheckpoint returns the checkpoint of a run with the requested
+// heights and options into the event sinks, before any block is re-indexed.
+func newReindexCheckpoint(es []indexer.EventSink) *reindexCheckpoint {
+	sinks := make([]string, 0, len(es))
+	for _, sink := range es {
+		sinks = append(sinks, string(sink.Type()))
+	}
+	sort.Strings(sinks)
+	return &reindexCheckpoint{Start: startHeight, End: endHeight, Rebuild: rebuild, Sinks: sinks}
+}
+
+// resumes reports whether the run can resume after the checkpoint, that is
+// whether the checkpoint was written by a run with the same options.
+func (cp *reindexCheckpoint) resumes(run *reindexCheckpoint) bool {
+	if cp == nil || cp.Start != run.Start || cp.End != run.End || cp.Rebuild != run.Rebuild ||
+		len(cp.Sinks) != len(run.Sinks) {
+		return false
+	}
+	for i := range cp.Sinks {
+		if cp.Sinks[i] != run.Sinks[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// loadReindexCheckpoint loads the checkpoint from the given file. It returns
+// nil if the file doesn't exist.
+func loadReindexCheckpoint(path string) (*reindexCheckpoint, error) {
+	bz, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	cp := &reindexCheckpoint{}
+	if err := json.Unmarshal(bz, cp); err != nil {
+		return nil, fmt.Errorf("decoding re-index checkpoint %s: %w", path, err)
+	}
+	return cp, nil
+}
+
+// reindexBlock is a block loaded from the stores, along with its results.
+type reindexBlock struct {
+	header types.EventDataNewBlockHeader
+	txs    []*abcitypes.TxResult
+}
+
+// reindexJob is a range of blocks re-indexed by a worker.
+type reindexJob struct {
+	from, to int64
+	// blocks are the loaded blocks, kept for the sinks indexing in order.
+	blocks []reindexBlock
+	err    error
+}
+
+// eventReIndex re-indexes the blocks from startHeight to endHeight in the
+// event sinks. The blocks are split into ranges of reindexRangeSize blocks,
+// which the workers load and index concurrently. The sinks which must index
+// the blocks in order are fed the ranges in order once they are loaded. Each
+// time all the blocks up to a height are indexed, the height is recorded in
+// the checkpoint of the run, which is written to checkpointPath unless it is
+// empty; the checkpoint is removed once all the blocks are indexed.
+func eventReIndex(cmd *cobra.Command, es []indexer.EventSink, bs state.BlockStore, ss state.Store,
+	run *reindexCheckpoint, checkpointPath string) error {
+	var ordered, unordered []indexer.EventSink
+	for _, sink := range es {
+		if indexer.IndexesInOrder(sink) {
+			ordered = append(ordered, sink)
+		} else {
+			unordered = append(unordered, sink)
+		}
+	}
+	n := workers
+	if n < 1 {
+		n = 1
+	}
+
+	ctx, cancel := context.WithCancel(cmd.Context())
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	// Ranges are dispatched at most 2n ranges ahead of the first range which
+	// isn't indexed yet, bounding the blocks kept for the ordered sinks.
+	slots := make(chan struct{}, 2*n)
+	jobs := make(chan *reindexJob)
+	results := make(chan *reindexJob)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		for from := startHeight; from <= endHeight; from += reindexRangeSize {
+			to := from + reindexRangeSize - 1
+			if to > endHeight {
+				to = endHeight
+			}
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- &reindexJob{from: from, to: to}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				job.err = reindexRange(ctx, job, unordered, len(ordered) > 0, bs, ss)
+				select {
+				case results <- job:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
 
 	var bar progressbar.Bar
 	bar.NewOption(startHeight-1, endHeight)
 
-	fmt.Println("start re-indexing events:")
+	fmt.Printf("start re-indexing events with %d workers:\n", n)
 	defer bar.Finish()
-	for i := startHeight; i <= endHeight; i++ {
+	pending := make(map[int64]*reindexJob)
+	for next := startHeight; next <= endHeight; {
 		select {
-		case <-cmd.Context().Done():
-			return fmt.Errorf("event re-index terminated at height %d: %w", i, cmd.Context().Err())
-		default:
-			b := bs.LoadBlock(i)
-			if b == nil {
-				return fmt.Errorf("not able to load block at height %d from the blockstore", i)
+		case job := <-results:
+			if job.err != nil {
+				return job.err
 			}
+			pending[job.from] = job
+		case <-ctx.Done():
+			return fmt.Errorf("event re-index terminated at height %d: %w", next, ctx.Err())
+		}
 
-			r, err := ss.LoadABCIResponses(i)
-			if err != nil {
-				return fmt.Errorf("not able to load ABCI Response at height %d from the statestore", i)
+		for job, ok := pending[next]; ok; job, ok = pending[next] {
+			delete(pending, next)
+			for _, b := range job.blocks {
+				if err := indexBlock(ordered, b); err != nil {
+					return err
+				}
 			}
-
-			e := types.EventDataNewBlockHeader{
-				Header:           b.Header,
-				NumTxs:           int64(len(b.Txs)),
-				ResultBeginBlock: *r.BeginBlock,
-				ResultEndBlock:   *r.EndBlock,
+			if checkpointPath != "" {
+				cp := *run
+				cp.Height = job.to
+				bz, err := json.Marshal(cp)
+				if err != nil {
+					return err
+				}
+				if err := tempfile.WriteFileAtomic(checkpointPath, bz, 0600); err != nil {
+					return fmt.Errorf("writing re-index checkpoint: %w", err)
+				}
 			}
+			next = job.to + 1
+			<-slots
+			bar.Play(job.to)
+		}
+	}
 
-			var batch *indexer.Batch
-			if e.NumTxs > 0 {
-				batch = indexer.NewBatch(e.NumTxs)
+	if checkpointPath != "" {
+		if err := os.Remove(checkpointPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing re-index checkpoint: %w", err)
+		}
+	}
+	return nil
+}
 
-				for i, tx := range b.Data.Txs {
-					tr := abcitypes.TxResult{
-						Height: b.Height,
-						Index:  uint32(i),
-						Tx:     tx,
-						Result: *(r.DeliverTxs[i]),
-					}
+// reindexRange loads the blocks of the job and indexes them in the given
+// sinks. If keep is true, the loaded blocks are kept in the job.
+func reindexRange(ctx context.Context, job *reindexJob, sinks []indexer.EventSink, keep bool,
+	bs state.BlockStore, ss state.Store) error {
+	for i := job.from; i <= job.to; i++ {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("event re-index terminated at height %d: %w", i, err)
+		}
+		b, err := loadBlock(bs, ss, i)
+		if err != nil {
+			return err
+		}
+		if err := indexBlock(sinks, b); err != nil {
+			return err
+		}
+		if keep {
+			job.blocks = append(job.blocks, b)
+		}
+	}
+	return nil
+}
 
-					_ = batch.Add(&tr)
-				}
-			}
+// loadBlock loads the block at the given height and its results from the
+// stores.
+func loadBlock(bs state.BlockStore, ss state.Store, height int64) (reindexBlock, error) {
+	b := bs.LoadBlock(height)
+	if b == nil {
+		return reindexBlock{}, fmt.Errorf("not able to load block at height %d from the blockstore", height)
+	}
 
-			for _, sink := range es {
-				if err := sink.IndexBlockEvents(e); err != nil {
-					return fmt.Errorf("block event re-index at height %d failed: %w", i, err)
-				}
+	r, err := ss.LoadABCIResponses(height)
+	if err != nil {
+		return reindexBlock{}, fmt.Errorf("not able to load ABCI Response at height %d from the statestore", height)
+	}
 
-				if batch != nil {
-					if err := sink.IndexTxEvents(batch.Ops); err != nil {
-						return fmt.Errorf("tx event re-index at height %d failed: %w", i, err)
-					}
-				}
+	rb := reindexBlock{
+		header: types.EventDataNewBlockHeader{
+			Header:           b.Header,
+			NumTxs:           int64(len(b.Txs)),
+			ResultBeginBlock: *r.BeginBlock,
+			ResultEndBlock:   *r.EndBlock,
+		},
+	}
+	if len(b.Txs) > 0 {
+		batch := indexer.NewBatch(rb.header.NumTxs)
+		for i, tx := range b.Data.Txs {
+			tr := abcitypes.TxResult{
+				Height: b.Height,
+				Index:  uint32(i),
+				Tx:     tx,
+				Result: *(r.DeliverTxs[i]),
 			}
-		}
 
-		bar.Play(i)
+			_ = batch.Add(&tr)
+		}
+		rb.txs = batch.Ops
 	}
+	return rb, nil
+}
 
+// indexBlock indexes the block events and the tx events of the block in the
+// given sinks.
+func indexBlock(sinks []indexer.EventSink, b reindexBlock) error {
+	height := b.header.Header.Height
+	for _, sink := range sinks {
+		if err := indexer.IndexBlock(sink, b.header, b.txs); err != nil {
+			return fmt.Errorf("event re-index at height %d failed: %w", height, err)
+		}
+	}
 	return nil
 }
 
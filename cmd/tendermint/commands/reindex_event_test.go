@@ -3,15 +3,20 @@ package commands
 import (
 	"context"
 	"errors"
+	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
 
 	abcitypes "github.com/tendermint/tendermint/abci/types"
 	tmcfg "github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/file"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/kv"
 	"github.com/tendermint/tendermint/internal/state/mocks"
 	prototmstate "github.com/tendermint/tendermint/proto/tendermint/state"
 	"github.com/tendermint/tendermint/types"
@@ -90,6 +95,8 @@ func TestLoadEventSink(t *testing.T) {
 		{[]string{"PSQL"}, "", true},         // true because empty connect url
 		{[]string{"PSQL"}, "wrongUrl", true}, // true because wrong connect url
 		// skip to test PSQL connect with correct url
+		{[]string{"SQLITE"}, "", false},
+		{[]string{"FILE"}, "", false},
 		{[]string{"UnsupportedSinkType"}, "wrongUrl", true},
 	}
 
@@ -97,6 +104,7 @@ func TestLoadEventSink(t *testing.T) {
 		cfg := tmcfg.TestConfig()
 		cfg.TxIndex.Indexer = tc.sinks
 		cfg.TxIndex.PsqlConn = tc.connURL
+		cfg.TxIndex.RootDir = t.TempDir()
 		_, err := loadEventSinks(cfg)
 		if tc.loadErr {
 			require.Error(t, err)
@@ -106,6 +114,25 @@ func TestLoadEventSink(t *testing.T) {
 	}
 }
 
+func TestResumeHeight(t *testing.T) {
+	newFileSink := func(height int64) indexer.EventSink {
+		es, err := file.NewEventSink(t.TempDir())
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = es.Stop() })
+		if height > 0 {
+			require.NoError(t, es.IndexBlockEvents(types.EventDataNewBlockHeader{Header: types.Header{Height: height}}))
+		}
+		return es
+	}
+
+	require.EqualValues(t, 0, resumeHeight(nil))
+	require.EqualValues(t, 0, resumeHeight([]indexer.EventSink{newFileSink(0)}))
+	require.EqualValues(t, 6, resumeHeight([]indexer.EventSink{newFileSink(5)}))
+	require.EqualValues(t, 4, resumeHeight([]indexer.EventSink{newFileSink(5), newFileSink(3)}))
+	// Sinks without a checkpoint are re-indexed from the start.
+	require.EqualValues(t, 0, resumeHeight([]indexer.EventSink{newFileSink(5), &mocks.EventSink{}}))
+}
+
 func TestLoadBlockStore(t *testing.T) {
 	bs, ss, err := loadStateAndBlockStore(tmcfg.TestConfig())
 	require.NoError(t, err)
@@ -161,7 +188,8 @@ func TestReIndexEvent(t *testing.T) {
 		startHeight = tc.startHeight
 		endHeight = tc.endHeight
 
-		err := eventReIndex(setupReIndexEventCmd(), []indexer.EventSink{mockEventSink}, mockBlockStore, mockStateStore)
+		err := eventReIndex(setupReIndexEventCmd(), []indexer.EventSink{mockEventSink}, mockBlockStore, mockStateStore,
+			newReindexCheckpoint([]indexer.EventSink{mockEventSink}), "")
 		if tc.reIndexErr {
 			require.Error(t, err)
 		} else {
@@ -169,3 +197,101 @@ func TestReIndexEvent(t *testing.T) {
 		}
 	}
 }
+
+func TestReIndexEventParallel(t *testing.T) {
+	const latest int64 = 250
+	mockBlockStore := &mocks.BlockStore{}
+	mockStateStore := &mocks.Store{}
+	mockBlockStore.
+		On("LoadBlock", mock.AnythingOfType("int64")).Return(func(h int64) *types.Block {
+		return &types.Block{
+			Header: types.Header{Height: h},
+			Data:   types.Data{Txs: types.Txs{types.Tx(fmt.Sprintf("tx%d", h))}},
+		}
+	})
+	abciResp := &prototmstate.ABCIResponses{
+		DeliverTxs: []*abcitypes.ResponseDeliverTx{{}},
+		EndBlock:   &abcitypes.ResponseEndBlock{},
+		BeginBlock: &abcitypes.ResponseBeginBlock{},
+	}
+	mockStateStore.
+		On("LoadABCIResponses", int64(180)).Return(nil, errors.New("")).Once().
+		On("LoadABCIResponses", mock.AnythingOfType("int64")).Return(abciResp, nil)
+
+	kvSink := kv.NewEventSink(dbm.NewMemDB(), nil)
+	fileSink, err := file.NewEventSink(t.TempDir())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = fileSink.Stop() })
+	sinks := []indexer.EventSink{kvSink, fileSink}
+	checkpointPath := filepath.Join(t.TempDir(), reindexCheckpointFile)
+	defer func(w int) { workers = w }(workers)
+
+	// The re-indexing fails at height 180. A single worker indexes the ranges
+	// in turn, so the checkpoint records the first range.
+	workers = 1
+	startHeight, endHeight, rebuild = 0, latest, false
+	run := newReindexCheckpoint(sinks)
+	startHeight = 1
+	require.Error(t, eventReIndex(setupReIndexEventCmd(), sinks, mockBlockStore, mockStateStore, run, checkpointPath))
+	cp, err := loadReindexCheckpoint(checkpointPath)
+	require.NoError(t, err)
+	require.Equal(t, &reindexCheckpoint{
+		Height: reindexRangeSize, End: latest, Sinks: []string{"file", "kv"},
+	}, cp)
+	require.Equal(t, int64(reindexRangeSize), fileSink.Checkpoint().Height)
+
+	// The checkpoint is only resumed by a run with the same options.
+	startHeight = 0
+	require.True(t, cp.resumes(newReindexCheckpoint(sinks)))
+	require.False(t, cp.resumes(newReindexCheckpoint([]indexer.EventSink{kvSink})))
+	endHeight = latest - 1
+	require.False(t, cp.resumes(newReindexCheckpoint(sinks)))
+	startHeight, endHeight = 1, latest
+	require.False(t, cp.resumes(newReindexCheckpoint(sinks)))
+	startHeight, rebuild = 0, true
+	require.False(t, cp.resumes(newReindexCheckpoint(sinks)))
+	rebuild = false
+
+	// The re-indexing resumes after the checkpoint with several workers, and
+	// completes.
+	workers = 4
+	startHeight = cp.Height + 1
+	require.NoError(t, eventReIndex(setupReIndexEventCmd(), sinks, mockBlockStore, mockStateStore, run, checkpointPath))
+	for h := int64(1); h <= latest; h++ {
+		ok, err := kvSink.HasBlock(h)
+		require.NoError(t, err)
+		require.True(t, ok, h)
+	}
+	require.Equal(t, latest, fileSink.Checkpoint().Height)
+	cp, err = loadReindexCheckpoint(checkpointPath)
+	require.NoError(t, err)
+	require.Nil(t, cp)
+}
+
+func TestDeleteEvents(t *testing.T) {
+	mockBlockStore := &mocks.BlockStore{}
+	mockBlockStore.On("Height").Return(height)
+
+	es := kv.NewEventSink(dbm.NewMemDB(), nil)
+	for h := base; h <= height; h++ {
+		require.NoError(t, es.IndexBlockEvents(types.EventDataNewBlockHeader{Header: types.Header{Height: h}}))
+	}
+
+	// Events can only be deleted up to the latest height.
+	startHeight, endHeight = base+1, height-1
+	require.Error(t, deleteEvents([]indexer.EventSink{es}, mockBlockStore))
+
+	// All sinks must support deleting events.
+	startHeight, endHeight = base+1, height
+	mockEventSink := &mocks.EventSink{}
+	mockEventSink.On("Type").Return(indexer.PSQL)
+	require.Error(t, deleteEvents([]indexer.EventSink{es, mockEventSink}, mockBlockStore))
+
+	require.NoError(t, deleteEvents([]indexer.EventSink{es}, mockBlockStore))
+	ok, err := es.HasBlock(base)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = es.HasBlock(base + 1)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
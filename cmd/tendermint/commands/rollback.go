@@ -9,9 +9,16 @@ import (
 	"github.com/tendermint/tendermint/internal/state"
 )
 
+var (
+	rollbackHeight       int64
+	rollbackDeleteBlocks bool
+	rollbackDeleteEvents bool
+	rollbackDryRun       bool
+)
+
 var RollbackStateCmd = &cobra.Command{
 	Use:   "rollback",
-	Short: "rollback tendermint state by one height",
+	Short: "rollback tendermint state by one or more heights",
 	Long: `
 A state rollback is performed to recover from an incorrect application state transition,
 when Tendermint has persisted an incorrect app hash and is thus unable to make
@@ -19,18 +26,46 @@ progress. Rollback overwrites a state at height n with the state at height n - 1
 The application should also roll back to height n - 1. No blocks are removed, so upon
 restarting Tendermint the transactions in block n will be re-executed against the
 application.
+
+With --height, the state is rolled back to the state after the block at the given
+height, which must still be present in the block store, and the application should
+roll back to the same height. The blocks above the height are re-executed upon
+restarting Tendermint, unless they are deleted with --delete-blocks, in which case
+they are fetched from peers again. The events of the blocks above the height can be
+deleted from the configured event sinks with --delete-events. Use --dry-run to print
+what would change without changing anything.
 `,
+	Example: `
+	tendermint rollback
+	tendermint rollback --height 100 --dry-run
+	tendermint rollback --height 100 --delete-blocks --delete-events
+	`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		height, hash, err := RollbackState(config)
-		if err != nil {
-			return fmt.Errorf("failed to rollback state: %w", err)
+		if rollbackHeight == 0 && !rollbackDeleteBlocks && !rollbackDeleteEvents && !rollbackDryRun {
+			height, hash, err := RollbackState(config)
+			if err != nil {
+				return fmt.Errorf("failed to rollback state: %w", err)
+			}
+
+			fmt.Printf("Rolled back state to height %d and hash %v", height, hash)
+			return nil
 		}
 
-		fmt.Printf("Rolled back state to height %d and hash %v", height, hash)
-		return nil
+		return rollbackStateTo(config, rollbackHeight, rollbackDeleteBlocks, rollbackDeleteEvents, rollbackDryRun)
 	},
 }
 
+func init() {
+	RollbackStateCmd.Flags().Int64Var(&rollbackHeight, "height", 0,
+		"the height to roll back to (default: one below the latest height)")
+	RollbackStateCmd.Flags().BoolVar(&rollbackDeleteBlocks, "delete-blocks", false,
+		"delete the blocks above the height from the block store")
+	RollbackStateCmd.Flags().BoolVar(&rollbackDeleteEvents, "delete-events", false,
+		"delete the events of the blocks above the height from the event sinks")
+	RollbackStateCmd.Flags().BoolVar(&rollbackDryRun, "dry-run", false,
+		"print what would change without changing anything")
+}
+
 // RollbackState takes the state at the current height n and overwrites it with the state
 // at height n - 1. Note state here refers to tendermint state not application state.
 // Returns the latest state height and app hash alongside an error if there was one.
@@ -44,3 +79,51 @@ func RollbackState(config *cfg.Config) (int64, []byte, error) {
 	// rollback the last state
 	return state.Rollback(blockStore, stateStore)
 }
+
+// rollbackStateTo rolls the state back to the given height, defaulting to one
+// below the latest height, and prints the changes.
+func rollbackStateTo(config *cfg.Config, height int64, deleteBlocks, deleteEvents, dryRun bool) error {
+	blockStore, stateStore, err := loadStateAndBlockStore(config)
+	if err != nil {
+		return err
+	}
+	currentState, err := stateStore.Load()
+	if err != nil {
+		return err
+	}
+	if height == 0 {
+		height = currentState.LastBlockHeight - 1
+	}
+
+	opts := state.RollbackOptions{DeleteBlocks: deleteBlocks, DryRun: dryRun}
+	if deleteEvents {
+		if opts.EventSinks, err = loadEventSinks(config); err != nil {
+			return err
+		}
+	}
+
+	storeHeight := blockStore.Height()
+	rolledBackState, err := state.RollbackTo(blockStore, stateStore, height, opts)
+	if err != nil {
+		return fmt.Errorf("failed to rollback state: %w", err)
+	}
+
+	rolledBack, deleted := "Rolled back", "Deleted"
+	if dryRun {
+		rolledBack, deleted = "Would roll back", "Would delete"
+	}
+	fmt.Printf("%s state from height %d and hash %X to height %d and hash %X\n",
+		rolledBack, currentState.LastBlockHeight, currentState.AppHash,
+		rolledBackState.LastBlockHeight, rolledBackState.AppHash)
+	if storeHeight > height {
+		if deleteBlocks {
+			fmt.Printf("%s blocks %d to %d from the block store\n", deleted, height+1, storeHeight)
+		} else {
+			fmt.Printf("Blocks %d to %d will be re-executed upon restart\n", height+1, storeHeight)
+		}
+	}
+	for _, sink := range opts.EventSinks {
+		fmt.Printf("%s events above height %d from the %s event sink\n", deleted, height, sink.Type())
+	}
+	return nil
+}
@@ -7,11 +7,14 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	tmjson "github.com/tendermint/tendermint/libs/json"
 	"github.com/tendermint/tendermint/libs/log"
 	tmos "github.com/tendermint/tendermint/libs/os"
+	tmstrings "github.com/tendermint/tendermint/libs/strings"
 	"github.com/tendermint/tendermint/types"
 )
 
@@ -69,6 +72,8 @@ type Config struct {
 	StateSync       *StateSyncConfig       `mapstructure:"statesync"`
 	Consensus       *ConsensusConfig       `mapstructure:"consensus"`
 	TxIndex         *TxIndexConfig         `mapstructure:"tx-index"`
+	Pruning         *PruningConfig         `mapstructure:"pruning"`
+	Storage         *StorageConfig         `mapstructure:"storage"`
 	Instrumentation *InstrumentationConfig `mapstructure:"instrumentation"`
 	PrivValidator   *PrivValidatorConfig   `mapstructure:"priv-validator"`
 }
@@ -83,6 +88,8 @@ func DefaultConfig() *Config {
 		StateSync:       DefaultStateSyncConfig(),
 		Consensus:       DefaultConsensusConfig(),
 		TxIndex:         DefaultTxIndexConfig(),
+		Pruning:         DefaultPruningConfig(),
+		Storage:         DefaultStorageConfig(),
 		Instrumentation: DefaultInstrumentationConfig(),
 		PrivValidator:   DefaultPrivValidatorConfig(),
 	}
@@ -105,6 +112,8 @@ func TestConfig() *Config {
 		StateSync:       TestStateSyncConfig(),
 		Consensus:       TestConsensusConfig(),
 		TxIndex:         TestTxIndexConfig(),
+		Pruning:         TestPruningConfig(),
+		Storage:         TestStorageConfig(),
 		Instrumentation: TestInstrumentationConfig(),
 		PrivValidator:   DefaultPrivValidatorConfig(),
 	}
@@ -118,6 +127,7 @@ func (cfg *Config) SetRoot(root string) *Config {
 	cfg.Mempool.RootDir = root
 	cfg.Consensus.RootDir = root
 	cfg.PrivValidator.RootDir = root
+	cfg.TxIndex.RootDir = root
 	return cfg
 }
 
@@ -130,6 +140,9 @@ func (cfg *Config) ValidateBasic() error {
 	if err := cfg.RPC.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [rpc] section: %w", err)
 	}
+	if err := cfg.P2P.validateCapture(); err != nil {
+		return fmt.Errorf("error in [p2p] section: %w", err)
+	}
 	if err := cfg.Mempool.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [mempool] section: %w", err)
 	}
@@ -139,6 +152,15 @@ func (cfg *Config) ValidateBasic() error {
 	if err := cfg.Consensus.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [consensus] section: %w", err)
 	}
+	if err := cfg.TxIndex.ValidateBasic(); err != nil {
+		return fmt.Errorf("error in [tx-index] section: %w", err)
+	}
+	if err := cfg.Pruning.ValidateBasic(); err != nil {
+		return fmt.Errorf("error in [pruning] section: %w", err)
+	}
+	if err := cfg.Storage.ValidateBasic(); err != nil {
+		return fmt.Errorf("error in [storage] section: %w", err)
+	}
 	if err := cfg.Instrumentation.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [instrumentation] section: %w", err)
 	}
@@ -424,6 +446,15 @@ type RPCConfig struct {
 	// Activate unsafe RPC commands like /dial-persistent-peers and /unsafe-flush-mempool
 	Unsafe bool `mapstructure:"unsafe"`
 
+	// TCP or UNIX socket address for the gRPC server to listen on, which
+	// exposes the same methods as the JSON-RPC server except the unsafe ones.
+	// The gRPC server is disabled if empty.
+	GRPCListenAddress string `mapstructure:"grpc-laddr"`
+
+	// Maximum number of simultaneous connections to the gRPC server.
+	// 0 - unlimited.
+	GRPCMaxOpenConnections int `mapstructure:"grpc-max-open-connections"`
+
 	// Maximum number of simultaneous connections (including WebSocket).
 	// If you want to accept a larger number than the default, make sure
 	// you increase your OS limits.
@@ -442,6 +473,35 @@ type RPCConfig struct {
 	// to the estimated maximum number of broadcast_tx_commit calls per block.
 	MaxSubscriptionsPerClient int `mapstructure:"max-subscriptions-per-client"`
 
+	// Maximum number of requests in a JSON-RPC batch request.
+	// 0 - unlimited.
+	MaxBatchSize int `mapstructure:"max-batch-size"`
+
+	// Number of units each client (identified by its name if it authenticated,
+	// otherwise by its IP address) may spend per second. Each request costs
+	// the units of its route in RateLimitCosts, or 1.
+	// 0 - unlimited.
+	RateLimit float64 `mapstructure:"rate-limit"`
+
+	// Maximum number of units a client may spend at once.
+	RateLimitBurst int `mapstructure:"rate-limit-burst"`
+
+	// Cost of the routes which cost more than 1 unit.
+	RateLimitCosts map[string]int `mapstructure:"rate-limit-costs"`
+
+	// Additional cost of a JSON-RPC batch request, on top of the costs of
+	// its requests.
+	RateLimitBatchCost int `mapstructure:"rate-limit-batch-cost"`
+
+	// How long the events published by the node are kept in the event log
+	// read by the events method. 0 disables the event log.
+	EventLogWindowSize time.Duration `mapstructure:"event-log-window-size"`
+
+	// Maximum number of events in the event log, after which the oldest events
+	// are removed before the end of the window.
+	// 0 - unlimited.
+	EventLogMaxItems int `mapstructure:"event-log-max-items"`
+
 	// How long to wait for a tx to be committed during /broadcast_tx_commit
 	// WARNING: Using a value larger than 10s will result in increasing the
 	// global HTTP write timeout, which applies to all connections and endpoints.
@@ -472,6 +532,20 @@ type RPCConfig struct {
 	// Otherwise, HTTP server is run.
 	TLSKeyFile string `mapstructure:"tls-key-file"`
 
+	// The path to a file containing the certificates of the CAs used to verify
+	// the certificates of TLS clients, which authenticates the clients with
+	// a verified certificate, named "cert:<common name>". Requires
+	// tls-cert-file and tls-key-file.
+	// Might be either absolute path or path related to tendermint's config directory.
+	TLSClientCAFile string `mapstructure:"tls-client-ca-file"`
+
+	// The path to a JSON file with the bearer tokens of the RPC clients, and
+	// the names of the clients allowed to call each route. The unsafe routes
+	// may only be called by the clients in their own list. If set, or if
+	// tls-client-ca-file is set, clients must authenticate.
+	// Might be either absolute path or path related to tendermint's config directory.
+	AuthFile string `mapstructure:"auth-file"`
+
 	// pprof listen address (https://golang.org/pkg/net/http/pprof)
 	PprofListenAddress string `mapstructure:"pprof-laddr"`
 }
@@ -487,10 +561,28 @@ func DefaultRPCConfig() *RPCConfig {
 		Unsafe:             false,
 		MaxOpenConnections: 900,
 
+		GRPCListenAddress:      "",
+		GRPCMaxOpenConnections: 900,
+
 		MaxSubscriptionClients:    100,
 		MaxSubscriptionsPerClient: 5,
 		TimeoutBroadcastTxCommit:  10 * time.Second,
 
+		MaxBatchSize:   0,
+		RateLimit:      0,
+		RateLimitBurst: 100,
+		RateLimitCosts: map[string]int{
+			"tx_search":     10,
+			"block_search":  10,
+			"blockchain":    5,
+			"block_results": 5,
+			"subscribe":     5,
+		},
+		RateLimitBatchCost: 5,
+
+		EventLogWindowSize: 30 * time.Second,
+		EventLogMaxItems:   10000,
+
 		MaxBodyBytes:   int64(1000000), // 1MB
 		MaxHeaderBytes: 1 << 20,        // same as the net/http default
 
@@ -513,12 +605,43 @@ func (cfg *RPCConfig) ValidateBasic() error {
 	if cfg.MaxOpenConnections < 0 {
 		return errors.New("max-open-connections can't be negative")
 	}
+	if cfg.GRPCMaxOpenConnections < 0 {
+		return errors.New("grpc-max-open-connections can't be negative")
+	}
 	if cfg.MaxSubscriptionClients < 0 {
 		return errors.New("max-subscription-clients can't be negative")
 	}
 	if cfg.MaxSubscriptionsPerClient < 0 {
 		return errors.New("max-subscriptions-per-client can't be negative")
 	}
+	if cfg.MaxBatchSize < 0 {
+		return errors.New("max-batch-size can't be negative")
+	}
+	if cfg.RateLimit < 0 {
+		return errors.New("rate-limit can't be negative")
+	}
+	if cfg.RateLimitBurst < 0 {
+		return errors.New("rate-limit-burst can't be negative")
+	}
+	if cfg.RateLimitBatchCost < 0 {
+		return errors.New("rate-limit-batch-cost can't be negative")
+	}
+	if cfg.IsRateLimitEnabled() {
+		if cfg.RateLimitBurst == 0 {
+			return errors.New("rate-limit-burst must be positive if rate-limit is set")
+		}
+		for route, cost := range cfg.RateLimitCosts {
+			if cost <= 0 || cost > cfg.RateLimitBurst {
+				return fmt.Errorf("rate-limit-costs of %s must be in [1, rate-limit-burst], got %d", route, cost)
+			}
+		}
+	}
+	if cfg.EventLogWindowSize < 0 {
+		return errors.New("event-log-window-size can't be negative")
+	}
+	if cfg.EventLogMaxItems < 0 {
+		return errors.New("event-log-max-items can't be negative")
+	}
 	if cfg.TimeoutBroadcastTxCommit < 0 {
 		return errors.New("timeout-broadcast-tx-commit can't be negative")
 	}
@@ -528,6 +651,9 @@ func (cfg *RPCConfig) ValidateBasic() error {
 	if cfg.MaxHeaderBytes < 0 {
 		return errors.New("max-header-bytes can't be negative")
 	}
+	if cfg.TLSClientCAFile != "" && !cfg.IsTLSEnabled() {
+		return errors.New("tls-client-ca-file requires tls-cert-file and tls-key-file")
+	}
 	return nil
 }
 
@@ -556,6 +682,33 @@ func (cfg RPCConfig) IsTLSEnabled() bool {
 	return cfg.TLSCertFile != "" && cfg.TLSKeyFile != ""
 }
 
+func (cfg RPCConfig) ClientCAFile() string {
+	path := cfg.TLSClientCAFile
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return rootify(filepath.Join(defaultConfigDir, path), cfg.RootDir)
+}
+
+func (cfg RPCConfig) AuthFilePath() string {
+	path := cfg.AuthFile
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return rootify(filepath.Join(defaultConfigDir, path), cfg.RootDir)
+}
+
+// IsRateLimitEnabled returns true if the rate of the requests of RPC clients
+// is limited.
+func (cfg RPCConfig) IsRateLimitEnabled() bool {
+	return cfg.RateLimit > 0
+}
+
+// IsAuthEnabled returns true if RPC clients must authenticate.
+func (cfg RPCConfig) IsAuthEnabled() bool {
+	return cfg.AuthFile != "" || cfg.TLSClientCAFile != ""
+}
+
 //-----------------------------------------------------------------------------
 // P2PConfig
 
@@ -629,6 +782,19 @@ type P2PConfig struct { //nolint: maligned
 	// layer uses. Options are: "fifo" and "priority",
 	// with the default being "priority".
 	QueueType string `mapstructure:"queue-type"`
+
+	// Path of a file to capture all inbound and outbound peer messages to,
+	// for debugging. The file is rotated once it grows too large. Empty
+	// disables message capture.
+	CaptureFile string `mapstructure:"capture-file"`
+
+	// Comma separated list of peer IDs to restrict message capture to. If
+	// empty, messages of all peers are captured.
+	CapturePeers string `mapstructure:"capture-peers"`
+
+	// Comma separated list of channel IDs to restrict message capture to,
+	// e.g. "0x20,0x21". If empty, messages on all channels are captured.
+	CaptureChannels string `mapstructure:"capture-channels"`
 }
 
 // DefaultP2PConfig returns a default configuration for the peer-to-peer layer
@@ -672,7 +838,45 @@ func (cfg *P2PConfig) ValidateBasic() error {
 	if cfg.RecvRate < 0 {
 		return errors.New("recv-rate can't be negative")
 	}
-	return nil
+	return cfg.validateCapture()
+}
+
+// validateCapture checks the message capture options.
+func (cfg *P2PConfig) validateCapture() error {
+	_, _, err := cfg.CaptureFilters()
+	return err
+}
+
+// CaptureFilePath returns the full path of the message capture file, or an
+// empty string if message capture is disabled.
+func (cfg *P2PConfig) CaptureFilePath() string {
+	if cfg.CaptureFile == "" {
+		return ""
+	}
+	return rootify(cfg.CaptureFile, cfg.RootDir)
+}
+
+// CaptureFilters parses the peer IDs and channel IDs to restrict message
+// capture to.
+func (cfg *P2PConfig) CaptureFilters() ([]types.NodeID, []uint16, error) {
+	var peers []types.NodeID
+	for _, s := range tmstrings.SplitAndTrimEmpty(cfg.CapturePeers, ",", " ") {
+		peerID, err := types.NewNodeID(s)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid capture-peers entry %q: %w", s, err)
+		}
+		peers = append(peers, peerID)
+	}
+
+	var channels []uint16
+	for _, s := range tmstrings.SplitAndTrimEmpty(cfg.CaptureChannels, ",", " ") {
+		chID, err := strconv.ParseUint(s, 0, 16)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid capture-channels entry %q: %w", s, err)
+		}
+		channels = append(channels, uint16(chID))
+	}
+	return peers, channels, nil
 }
 
 // TestP2PConfig returns a configuration for testing the peer-to-peer layer
@@ -1082,6 +1286,8 @@ func (cfg *ConsensusConfig) ValidateBasic() error {
 // TxIndexConfig defines the configuration for the transaction indexer,
 // including composite keys to index.
 type TxIndexConfig struct {
+	RootDir string `mapstructure:"home"`
+
 	// The backend database list to back the indexer.
 	// If list contains `null`, meaning no indexer service will be used.
 	//
@@ -1090,18 +1296,68 @@ type TxIndexConfig struct {
 	//   2) "kv" (default) - the simplest possible indexer,
 	//      backed by key-value storage (defaults to levelDB; see DBBackend).
 	//   3) "psql" - the indexer services backed by PostgreSQL.
+	//   4) "sqlite" - the indexer services backed by an embedded SQLite
+	//      database, which external tools can read while the node runs.
+	//   5) "file" - writes blocks and transaction results to rotating
+	//      NDJSON files, for consumption by external data pipelines.
 	Indexer []string `mapstructure:"indexer"`
 
 	// The PostgreSQL connection configuration, the connection format:
 	// postgresql://<user>:<password>@<host>:<port>/<db>?<opts>
 	PsqlConn string `mapstructure:"psql-conn"`
+
+	// The path of the SQLite database file used by the "sqlite" indexer,
+	// relative to the home directory if not absolute.
+	SqlitePath string `mapstructure:"sqlite-path"`
+
+	// The directory of the NDJSON files written by the "file" indexer,
+	// relative to the home directory if not absolute.
+	FilePath string `mapstructure:"file-path"`
+
+	// The composite keys (e.g. "transfer.recipient") of the event attributes
+	// indexed by the "kv" indexer, for both transaction and block events. If
+	// empty, all attributes the application marks for indexing are indexed.
+	IncludeKeys []string `mapstructure:"include-keys"`
+
+	// The composite keys of the event attributes the "kv" indexer doesn't
+	// index, even if they are marked for indexing and included above.
+	ExcludeKeys []string `mapstructure:"exclude-keys"`
 }
 
 // DefaultTxIndexConfig returns a default configuration for the transaction indexer.
 func DefaultTxIndexConfig() *TxIndexConfig {
 	return &TxIndexConfig{
-		Indexer: []string{"kv"},
+		Indexer:    []string{"kv"},
+		SqlitePath: filepath.Join(defaultDataDir, "tx_index.sqlite"),
+		FilePath:   filepath.Join(defaultDataDir, "events"),
+	}
+}
+
+// SqliteFile returns the full path to the SQLite database file of the
+// "sqlite" indexer.
+func (cfg *TxIndexConfig) SqliteFile() string {
+	return rootify(cfg.SqlitePath, cfg.RootDir)
+}
+
+// FileDir returns the full path to the directory of the "file" indexer.
+func (cfg *TxIndexConfig) FileDir() string {
+	return rootify(cfg.FilePath, cfg.RootDir)
+}
+
+// ValidateBasic performs basic validation (checking param bounds, etc.) and
+// returns an error if any check fails.
+func (cfg *TxIndexConfig) ValidateBasic() error {
+	for _, keys := range []struct {
+		name string
+		keys []string
+	}{{"include-keys", cfg.IncludeKeys}, {"exclude-keys", cfg.ExcludeKeys}} {
+		for _, key := range keys.keys {
+			if i := strings.Index(key, "."); i <= 0 || i == len(key)-1 {
+				return fmt.Errorf("%s: %q is not a composite key of the form type.key", keys.name, key)
+			}
+		}
 	}
+	return nil
 }
 
 // TestTxIndexConfig returns a default configuration for the transaction indexer.
@@ -1109,6 +1365,112 @@ func TestTxIndexConfig() *TxIndexConfig {
 	return DefaultTxIndexConfig()
 }
 
+//-----------------------------------------------------------------------------
+// PruningConfig
+
+// PruningConfig defines the operator's pruning policy for blocks, states and
+// indexed events. The node prunes up to the more conservative (i.e. lower) of
+// the retain height requested by the application and the retain height implied
+// by this policy.
+type PruningConfig struct {
+	// Archive disables all pruning, including pruning requested by the
+	// application via the ResponseCommit.RetainHeight field.
+	Archive bool `mapstructure:"archive"`
+
+	// KeepBlocks is the number of most recent blocks to keep. 0 disables
+	// pruning by height.
+	KeepBlocks uint64 `mapstructure:"keep-blocks"`
+
+	// KeepTime is the minimum age of blocks to keep. 0 disables pruning by age.
+	KeepTime time.Duration `mapstructure:"keep-time"`
+
+	// Interval is the interval at which the pruning service runs.
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// DefaultPruningConfig returns a default configuration for pruning, which
+// leaves pruning entirely up to the application.
+func DefaultPruningConfig() *PruningConfig {
+	return &PruningConfig{
+		Interval: 10 * time.Minute,
+	}
+}
+
+// TestPruningConfig returns a configuration for pruning used in tests.
+func TestPruningConfig() *PruningConfig {
+	cfg := DefaultPruningConfig()
+	cfg.Interval = time.Second
+	return cfg
+}
+
+// Enabled returns true if the operator has configured a pruning policy, i.e.
+// if the pruning service should be run.
+func (cfg *PruningConfig) Enabled() bool {
+	return cfg.Archive || cfg.KeepBlocks > 0 || cfg.KeepTime > 0
+}
+
+// ValidateBasic performs basic validation (checking param bounds, etc.) and
+// returns an error if any check fails.
+func (cfg *PruningConfig) ValidateBasic() error {
+	if cfg.KeepTime < 0 {
+		return errors.New("keep-time can't be negative")
+	}
+	if cfg.Interval <= 0 {
+		return errors.New("interval must be positive")
+	}
+	if cfg.Archive && (cfg.KeepBlocks > 0 || cfg.KeepTime > 0) {
+		return errors.New("keep-blocks and keep-time can't be set in archive mode")
+	}
+	return nil
+}
+
+//-----------------------------------------------------------------------------
+// StorageConfig
+
+// StorageConfig defines how the node stores data in its databases.
+type StorageConfig struct {
+	// Compression is the algorithm used to compress new block parts and ABCI
+	// responses: "none", "snappy" or "gzip". Existing data remains readable
+	// when the algorithm is changed, and can be rewritten with the new
+	// algorithm by "tendermint db recompress".
+	Compression string `mapstructure:"compression"`
+
+	// CompressionLevel is the gzip compression level, from 1 (fastest) to 9
+	// (best compression). 0 selects the default level.
+	CompressionLevel int `mapstructure:"compression-level"`
+}
+
+// DefaultStorageConfig returns a default configuration for storage, which
+// stores data uncompressed.
+func DefaultStorageConfig() *StorageConfig {
+	return &StorageConfig{
+		Compression: "none",
+	}
+}
+
+// TestStorageConfig returns a configuration for storage used in tests.
+func TestStorageConfig() *StorageConfig {
+	return DefaultStorageConfig()
+}
+
+// ValidateBasic performs basic validation (checking param bounds, etc.) and
+// returns an error if any check fails.
+func (cfg *StorageConfig) ValidateBasic() error {
+	switch cfg.Compression {
+	case "", "none", "snappy":
+		if cfg.CompressionLevel != 0 {
+			return fmt.Errorf("compression-level is not supported for compression %q", cfg.Compression)
+		}
+	case "gzip":
+		if cfg.CompressionLevel < 0 || cfg.CompressionLevel > 9 {
+			return errors.New("compression-level must be between 0 and 9")
+		}
+	default:
+		return fmt.Errorf("unknown compression %q (expected none, snappy or gzip)", cfg.Compression)
+	}
+	return nil
+}
+
 //-----------------------------------------------------------------------------
 // InstrumentationConfig
 
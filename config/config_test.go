@@ -50,6 +50,12 @@ func TestTLSConfiguration(t *testing.T) {
 	assert.Equal("/abs/path/to/file.crt", cfg.RPC.CertFile())
 	cfg.RPC.TLSKeyFile = "/abs/path/to/file.key"
 	assert.Equal("/abs/path/to/file.key", cfg.RPC.KeyFile())
+
+	cfg.RPC.TLSClientCAFile = "ca.crt"
+	assert.Equal("/home/user/config/ca.crt", cfg.RPC.ClientCAFile())
+	cfg.RPC.AuthFile = "/abs/path/to/auth.json"
+	assert.Equal("/abs/path/to/auth.json", cfg.RPC.AuthFilePath())
+	assert.True(cfg.RPC.IsAuthEnabled())
 }
 
 func TestBaseConfigValidateBasic(t *testing.T) {
@@ -67,8 +73,14 @@ func TestRPCConfigValidateBasic(t *testing.T) {
 
 	fieldsToTest := []string{
 		"MaxOpenConnections",
+		"GRPCMaxOpenConnections",
 		"MaxSubscriptionClients",
 		"MaxSubscriptionsPerClient",
+		"MaxBatchSize",
+		"RateLimitBurst",
+		"RateLimitBatchCost",
+		"EventLogWindowSize",
+		"EventLogMaxItems",
 		"TimeoutBroadcastTxCommit",
 		"MaxBodyBytes",
 		"MaxHeaderBytes",
@@ -79,6 +91,21 @@ func TestRPCConfigValidateBasic(t *testing.T) {
 		assert.Error(t, cfg.ValidateBasic())
 		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetInt(0)
 	}
+
+	cfg.RateLimit = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.RateLimit = 10
+	cfg.RateLimitBurst = 5 // less than the cost of tx_search
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.RateLimitBurst = 100
+	assert.NoError(t, cfg.ValidateBasic())
+
+	// client certificates require TLS
+	cfg.TLSClientCAFile = "ca.crt"
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.TLSCertFile = "file.crt"
+	cfg.TLSKeyFile = "file.key"
+	assert.NoError(t, cfg.ValidateBasic())
 }
 
 func TestMempoolConfigValidateBasic(t *testing.T) {
@@ -172,3 +199,92 @@ func TestP2PConfigValidateBasic(t *testing.T) {
 		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetInt(0)
 	}
 }
+
+func TestP2PConfigCaptureFilters(t *testing.T) {
+	cfg := TestP2PConfig()
+	cfg.CapturePeers = "0123456789abcdef0123456789abcdef01234567, fedcba9876543210fedcba9876543210fedcba98"
+	cfg.CaptureChannels = "0x20, 33"
+	assert.NoError(t, cfg.ValidateBasic())
+
+	peers, channels, err := cfg.CaptureFilters()
+	assert.NoError(t, err)
+	assert.Len(t, peers, 2)
+	assert.Equal(t, []uint16{0x20, 0x21}, channels)
+
+	cfg.CaptureChannels = "0x10000"
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg.CaptureChannels = ""
+	cfg.CapturePeers = "foo"
+	assert.Error(t, cfg.ValidateBasic())
+}
+
+func TestPruningConfigValidateBasic(t *testing.T) {
+	cfg := TestPruningConfig()
+	assert.NoError(t, cfg.ValidateBasic())
+	assert.False(t, cfg.Enabled())
+
+	cfg.KeepBlocks = 100
+	cfg.KeepTime = time.Hour
+	assert.NoError(t, cfg.ValidateBasic())
+	assert.True(t, cfg.Enabled())
+
+	cfg.Archive = true
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestPruningConfig()
+	cfg.KeepTime = -time.Hour
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestPruningConfig()
+	cfg.Interval = 0
+	assert.Error(t, cfg.ValidateBasic())
+}
+
+func TestStorageConfigValidateBasic(t *testing.T) {
+	cfg := TestStorageConfig()
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.CompressionLevel = 5
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg.Compression = "gzip"
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.CompressionLevel = 10
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestStorageConfig()
+	cfg.Compression = "snappy"
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.Compression = "zip"
+	assert.Error(t, cfg.ValidateBasic())
+}
+
+func TestTxIndexConfigValidateBasic(t *testing.T) {
+	cfg := TestTxIndexConfig()
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.IncludeKeys = []string{"transfer.recipient"}
+	cfg.ExcludeKeys = []string{"transfer.amount"}
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.IncludeKeys = []string{"transfer"}
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg.IncludeKeys = nil
+	cfg.ExcludeKeys = []string{"transfer."}
+	assert.Error(t, cfg.ValidateBasic())
+}
+
+func TestConfigValidateBasicP2PCapture(t *testing.T) {
+	cfg := TestConfig()
+	cfg.P2P.CaptureChannels = "foo"
+	assert.Error(t, cfg.ValidateBasic())
+
+	// The other p2p options are not validated with the node config.
+	cfg.P2P.CaptureChannels = ""
+	cfg.P2P.SendRate = -1
+	assert.NoError(t, cfg.ValidateBasic())
+}
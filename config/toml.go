@@ -121,6 +121,9 @@ mode = "{{ .BaseConfig.Mode }}"
 # * badgerdb (uses github.com/dgraph-io/badger)
 #   - EXPERIMENTAL
 #   - use badgerdb build tag (go build -tags badgerdb)
+#
+# To switch the backend of an existing node, copy its databases to the new
+# backend with "tendermint db migrate".
 db-backend = "{{ .BaseConfig.DBBackend }}"
 
 # Database directory
@@ -201,6 +204,16 @@ cors-allowed-headers = [{{ range .RPC.CORSAllowedHeaders }}{{ printf "%q, " . }}
 # Activate unsafe RPC commands like /dial-seeds and /unsafe-flush-mempool
 unsafe = {{ .RPC.Unsafe }}
 
+# TCP or UNIX socket address for the gRPC server to listen on, which exposes
+# the same methods as the JSON-RPC server except the unsafe ones.
+# The gRPC server uses TLS if tls-cert-file and tls-key-file are set.
+# Default value '' disables the gRPC server.
+grpc-laddr = "{{ .RPC.GRPCListenAddress }}"
+
+# Maximum number of simultaneous connections to the gRPC server.
+# 0 - unlimited.
+grpc-max-open-connections = {{ .RPC.GRPCMaxOpenConnections }}
+
 # Maximum number of simultaneous connections (including WebSocket).
 # If you want to accept a larger number than the default, make sure
 # you increase your OS limits.
@@ -219,6 +232,39 @@ max-subscription-clients = {{ .RPC.MaxSubscriptionClients }}
 # to the estimated maximum number of broadcast_tx_commit calls per block.
 max-subscriptions-per-client = {{ .RPC.MaxSubscriptionsPerClient }}
 
+# Maximum number of requests in a JSON-RPC batch request.
+# 0 - unlimited.
+max-batch-size = {{ .RPC.MaxBatchSize }}
+
+# Number of units each client may spend per second. Clients are identified by
+# their name if they authenticate, and by their IP address otherwise. Each
+# request costs the units of its route in rate-limit-costs, or 1, and requests
+# over the limit are rejected with HTTP status 429 (or the gRPC code
+# RESOURCE_EXHAUSTED).
+# 0 - unlimited.
+rate-limit = {{ .RPC.RateLimit }}
+
+# Maximum number of units a client may spend at once.
+rate-limit-burst = {{ .RPC.RateLimitBurst }}
+
+# Cost of the routes which cost more than 1 unit.
+rate-limit-costs = { {{- $sep := " " }}{{ range $route, $cost := .RPC.RateLimitCosts }}{{ $sep }}{{ $route }} = {{ $cost }}{{ $sep = ", " }}{{ end }} }
+
+# Additional cost of a JSON-RPC batch request, on top of the costs of its
+# requests.
+rate-limit-batch-cost = {{ .RPC.RateLimitBatchCost }}
+
+# How long the events published by the node are kept in the event log read
+# by the events method, which lets clients read the events following a cursor
+# over HTTP or websocket without missing events between requests.
+# 0 disables the event log.
+event-log-window-size = "{{ .RPC.EventLogWindowSize }}"
+
+# Maximum number of events in the event log, after which the oldest events are
+# removed before the end of the window.
+# 0 - unlimited.
+event-log-max-items = {{ .RPC.EventLogMaxItems }}
+
 # How long to wait for a tx to be committed during /broadcast_tx_commit.
 # WARNING: Using a value larger than 10s will result in increasing the
 # global HTTP write timeout, which applies to all connections and endpoints.
@@ -246,6 +292,29 @@ tls-cert-file = "{{ .RPC.TLSCertFile }}"
 # Otherwise, HTTP server is run.
 tls-key-file = "{{ .RPC.TLSKeyFile }}"
 
+# The path to a file containing the certificates of the CAs used to verify the
+# certificates of TLS clients. Clients with a verified certificate are
+# authenticated, and named "cert:" followed by the common name of their
+# certificate, e.g. "cert:ops".
+# Might be either absolute path or path related to Tendermint's config directory.
+# NOTE: requires tls-cert-file and tls-key-file.
+tls-client-ca-file = "{{ .RPC.TLSClientCAFile }}"
+
+# The path to a JSON file with the bearer tokens of the RPC clients, and the
+# names of the clients allowed to call each route, e.g.
+#   {
+#     "keys": {"ops": "<token>"},
+#     "routes": {"unsafe_flush_mempool": ["ops"], "broadcast_tx_commit": ["ops"]}
+#   }
+# Clients send their token in the "Authorization: Bearer <token>" header.
+# Routes without a list may be called by all authenticated clients, unless the
+# "*" list is set. The unsafe routes (unsafe_* and dial_*) may only be called
+# by the clients in their own list. If auth-file or tls-client-ca-file is set,
+# the RPC and gRPC servers reject the requests of clients which do not
+# authenticate.
+# Might be either absolute path or path related to Tendermint's config directory.
+auth-file = "{{ .RPC.AuthFile }}"
+
 # pprof listen address (https://golang.org/pkg/net/http/pprof)
 pprof-laddr = "{{ .RPC.PprofListenAddress }}"
 
@@ -321,6 +390,18 @@ send-rate = {{ .P2P.SendRate }}
 # TODO: Remove once MConnConnection is removed.
 recv-rate = {{ .P2P.RecvRate }}
 
+# Path of a file to capture all inbound and outbound peer messages to, for
+# debugging. The file is rotated once it grows too large. Captures can be
+# decoded with "tendermint debug p2p-capture". Empty disables message capture.
+capture-file = "{{ .P2P.CaptureFile }}"
+
+# Comma separated list of peer IDs to restrict message capture to.
+capture-peers = "{{ .P2P.CapturePeers }}"
+
+# Comma separated list of channel IDs to restrict message capture to,
+# e.g. "0x20,0x21".
+capture-channels = "{{ .P2P.CaptureChannels }}"
+
 
 #######################################################
 ###          Mempool Configuration Option          ###
@@ -471,13 +552,75 @@ peer-query-maj23-sleep-duration = "{{ .Consensus.PeerQueryMaj23SleepDuration }}"
 #   1) "null"
 #   2) "kv" (default) - the simplest possible indexer, backed by key-value storage (defaults to levelDB; see DBBackend).
 #   3) "psql" - the indexer services backed by PostgreSQL.
-# When "kv" or "psql" is chosen "tx.height" and "tx.hash" will always be indexed.
+#   4) "sqlite" - the indexer services backed by an embedded SQLite database,
+#      which external tools can open read-only while the node runs.
+#   5) "file" - writes blocks and transaction results as JSON lines to rotating
+#      files, with a checkpoint of the last fully written height.
+# When "kv", "psql", "sqlite" or "file" is chosen "tx.height" and "tx.hash" will always be indexed.
 indexer = [{{ range $i, $e := .TxIndex.Indexer }}{{if $i}}, {{end}}{{ printf "%q" $e}}{{end}}]
 
 # The PostgreSQL connection configuration, the connection format:
 #   postgresql://<user>:<password>@<host>:<port>/<db>?<opts>
 psql-conn = "{{ .TxIndex.PsqlConn }}"
 
+# The path of the SQLite database file used by the "sqlite" indexer.
+# Relative paths are resolved against the home directory.
+sqlite-path = "{{ js .TxIndex.SqlitePath }}"
+
+# The directory of the files written by the "file" indexer.
+# Relative paths are resolved against the home directory.
+file-path = "{{ js .TxIndex.FilePath }}"
+
+# The composite keys (e.g. "transfer.recipient") of the event attributes indexed
+# by the "kv" indexer, for both transaction and block events. Only attributes
+# the application marks for indexing are indexed. If empty, all of them are.
+# After changing the keys, run "tendermint reindex-event --rebuild" to apply
+# the change to the blocks indexed before.
+include-keys = [{{ range $i, $e := .TxIndex.IncludeKeys }}{{if $i}}, {{end}}{{ printf "%q" $e}}{{end}}]
+
+# The composite keys of the event attributes the "kv" indexer doesn't index,
+# even if they are included above.
+exclude-keys = [{{ range $i, $e := .TxIndex.ExcludeKeys }}{{if $i}}, {{end}}{{ printf "%q" $e}}{{end}}]
+
+#######################################################
+###         Pruning Configuration Options           ###
+#######################################################
+[pruning]
+
+# The node prunes blocks, states and indexed events below the more conservative
+# (i.e. lower) of the retain height requested by the application in
+# ResponseCommit and the retain height implied by the options below.
+
+# If true, nothing is ever pruned, even if requested by the application.
+archive = {{ .Pruning.Archive }}
+
+# The number of most recent blocks to keep. 0 disables pruning by height.
+keep-blocks = {{ .Pruning.KeepBlocks }}
+
+# The minimum age of blocks to keep, e.g. "168h". 0 disables pruning by age.
+keep-time = "{{ .Pruning.KeepTime }}"
+
+# The interval at which blocks are pruned.
+interval = "{{ .Pruning.Interval }}"
+
+#######################################################
+###          Storage Configuration Options          ###
+#######################################################
+[storage]
+
+# The algorithm used to compress new block parts and ABCI responses:
+#   1) "none" (default) - data is stored uncompressed.
+#   2) "snappy" - fast compression with a moderate ratio.
+#   3) "gzip" - slower compression with a better ratio, see compression-level.
+# Existing data remains readable when the algorithm is changed. Run
+# "tendermint db recompress" on a stopped node to rewrite it with the
+# configured algorithm.
+compression = "{{ .Storage.Compression }}"
+
+# The gzip compression level, from 1 (fastest) to 9 (best compression).
+# 0 selects the default level.
+compression-level = {{ .Storage.CompressionLevel }}
+
 #######################################################
 ###       Instrumentation Configuration Options     ###
 #######################################################
@@ -0,0 +1,135 @@
+/*
+Package archive exports blocks from a node's block and state stores to a
+portable flat file archive, and imports them into another node's stores.
+
+An archive is a directory containing a manifest and one or more chunk files.
+The manifest, manifest.json, is written once all chunks have been written and
+describes the archive:
+
+	{
+	  "version": 1,
+	  "chain_id": "test-chain",
+	  "start_height": 1,
+	  "end_height": 2500,
+	  "app_hash": "...",
+	  "chunks": [
+	    {"file": "000000000001-000000001000.chunk", "start_height": 1, "end_height": 1000, "checksum": "..."},
+	    ...
+	  ]
+	}
+
+where app_hash is the application hash after executing the last block, and
+checksum is the SHA-256 hash of the chunk file. Each chunk file contains one
+record per height, in ascending order, where each record consists of three
+varint length-delimited Protobuf messages:
+
+	tendermint.types.Block            the block
+	tendermint.types.Commit           the commit for the block
+	tendermint.state.ABCIResponses    the ABCI responses from executing the block
+
+Importing an archive verifies each chunk against its checksum and each block
+and its commit against the validator sets derived from the importing node's
+state, before writing them to its stores. The ABCI responses are used to
+derive the state after each block, without executing the blocks against the
+application: the application replays the imported blocks when the node starts.
+*/
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
+	"github.com/tendermint/tendermint/types"
+)
+
+const (
+	// Version is the version of the archive format.
+	Version = 1
+
+	// ManifestFile is the name of the archive manifest file.
+	ManifestFile = "manifest.json"
+
+	// DefaultChunkSize is the default number of heights per chunk file.
+	DefaultChunkSize = 1000
+
+	// maxMsgSize is the maximum size of a single message in a chunk file.
+	maxMsgSize = types.MaxBlockSizeBytes * 2
+)
+
+// Manifest describes the contents of an archive.
+type Manifest struct {
+	Version     uint32 `json:"version"`
+	ChainID     string `json:"chain_id"`
+	StartHeight int64  `json:"start_height"`
+	EndHeight   int64  `json:"end_height"`
+
+	// AppHash is the application hash after executing the block at EndHeight.
+	AppHash tmbytes.HexBytes `json:"app_hash"`
+
+	Chunks []Chunk `json:"chunks"`
+}
+
+// Chunk describes a chunk file of an archive.
+type Chunk struct {
+	// File is the name of the chunk file, relative to the archive directory.
+	File        string `json:"file"`
+	StartHeight int64  `json:"start_height"`
+	EndHeight   int64  `json:"end_height"`
+
+	// Checksum is the SHA-256 hash of the chunk file.
+	Checksum tmbytes.HexBytes `json:"checksum"`
+}
+
+// ValidateBasic performs basic validation of the manifest.
+func (m *Manifest) ValidateBasic() error {
+	if m.Version != Version {
+		return fmt.Errorf("unsupported archive version %d", m.Version)
+	}
+	if m.ChainID == "" {
+		return fmt.Errorf("missing chain ID")
+	}
+	if m.StartHeight <= 0 || m.EndHeight < m.StartHeight {
+		return fmt.Errorf("invalid height range %d-%d", m.StartHeight, m.EndHeight)
+	}
+
+	next := m.StartHeight
+	for i, chunk := range m.Chunks {
+		if chunk.StartHeight != next || chunk.EndHeight < chunk.StartHeight {
+			return fmt.Errorf("chunk %d has invalid height range %d-%d", i, chunk.StartHeight, chunk.EndHeight)
+		}
+		if chunk.File == "" || filepath.Base(chunk.File) != chunk.File {
+			return fmt.Errorf("chunk %d has invalid file name %q", i, chunk.File)
+		}
+		if len(chunk.Checksum) != 32 {
+			return fmt.Errorf("chunk %d has invalid checksum", i)
+		}
+		next = chunk.EndHeight + 1
+	}
+	if next != m.EndHeight+1 {
+		return fmt.Errorf("chunks do not cover heights %d-%d", m.StartHeight, m.EndHeight)
+	}
+	return nil
+}
+
+// LoadManifest loads and validates the manifest of the archive in dir.
+func LoadManifest(dir string) (*Manifest, error) {
+	bz, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(bz, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if err := manifest.ValidateBasic(); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+func chunkFileName(startHeight, endHeight int64) string {
+	return fmt.Sprintf("%012d-%012d.chunk", startHeight, endHeight)
+}
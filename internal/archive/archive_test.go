@@ -0,0 +1,187 @@
+package archive_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/archive"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/store"
+	"github.com/tendermint/tendermint/internal/test/factory"
+	"github.com/tendermint/tendermint/libs/log"
+	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// makeChain builds a chain of the given height in new stores, deriving each
+// state from synthetic ABCI responses.
+func makeChain(t *testing.T, height int64) (*types.GenesisDoc, *store.BlockStore, sm.Store) {
+	t.Helper()
+
+	genDoc, privVals := factory.RandGenesisDoc(config.TestConfig(), 3, false, 10)
+	state, err := sm.MakeGenesisState(genDoc)
+	require.NoError(t, err)
+	stateStore := sm.NewStore(dbm.NewMemDB())
+	require.NoError(t, stateStore.Save(state))
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+
+	lastCommit := types.NewCommit(0, 0, types.BlockID{}, nil)
+	for h := int64(1); h <= height; h++ {
+		proposerAddr := state.Validators.GetProposer().Address
+		block, partSet := state.MakeBlock(h, factory.MakeTenTxs(h), lastCommit, nil, proposerAddr)
+		blockID := types.BlockID{Hash: block.Hash(), PartSetHeader: partSet.Header()}
+
+		abciResponses := &tmstate.ABCIResponses{
+			BeginBlock: &abci.ResponseBeginBlock{},
+			EndBlock:   &abci.ResponseEndBlock{},
+		}
+		for range block.Txs {
+			abciResponses.DeliverTxs = append(abciResponses.DeliverTxs, &abci.ResponseDeliverTx{Data: []byte{byte(h)}})
+		}
+		state, err = sm.UpdateStateWithResponses(state, blockID, block, abciResponses)
+		require.NoError(t, err)
+		state.AppHash = []byte{byte(h)}
+		require.NoError(t, stateStore.SaveABCIResponses(h, abciResponses))
+		require.NoError(t, stateStore.Save(state))
+
+		voteSet := types.NewVoteSet(state.ChainID, h, 0, tmproto.PrecommitType, state.LastValidators)
+		lastCommit, err = factory.MakeCommit(blockID, h, 0, voteSet, privVals, time.Now())
+		require.NoError(t, err)
+		blockStore.SaveBlock(block, partSet, lastCommit)
+	}
+	return genDoc, blockStore, stateStore
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	logger := log.TestingLogger()
+	genDoc, blockStore, stateStore := makeChain(t, 10)
+	dir := t.TempDir()
+
+	_, err := archive.Export(ctx, blockStore, stateStore, dir, 0, 10, 4, logger)
+	require.Error(t, err)
+	manifest, err := archive.Export(ctx, blockStore, stateStore, dir, 1, 10, 4, logger)
+	require.NoError(t, err)
+	require.Len(t, manifest.Chunks, 3)
+	require.EqualValues(t, []byte{10}, manifest.AppHash)
+
+	loaded, err := archive.LoadManifest(dir)
+	require.NoError(t, err)
+	require.Equal(t, manifest, loaded)
+
+	// Exporting into the same directory again fails.
+	_, err = archive.Export(ctx, blockStore, stateStore, dir, 1, 10, 4, logger)
+	require.Error(t, err)
+
+	importBlockStore := store.NewBlockStore(dbm.NewMemDB())
+	importStateStore := sm.NewStore(dbm.NewMemDB())
+	height, err := archive.Import(ctx, dir, importBlockStore, importStateStore, genDoc, logger)
+	require.NoError(t, err)
+	require.EqualValues(t, 10, height)
+
+	expectState, err := stateStore.Load()
+	require.NoError(t, err)
+	state, err := importStateStore.Load()
+	require.NoError(t, err)
+	require.Equal(t, expectState, state)
+
+	require.EqualValues(t, 10, importBlockStore.Height())
+	for h := int64(1); h <= 10; h++ {
+		require.Equal(t, blockStore.LoadBlockMeta(h), importBlockStore.LoadBlockMeta(h))
+		require.Equal(t, blockStore.LoadBlockCommit(h), importBlockStore.LoadBlockCommit(h))
+		abciResponses, err := importStateStore.LoadABCIResponses(h)
+		require.NoError(t, err)
+		expectResponses, err := stateStore.LoadABCIResponses(h)
+		require.NoError(t, err)
+		require.Equal(t, expectResponses, abciResponses)
+		vals, err := importStateStore.LoadValidators(h)
+		require.NoError(t, err)
+		require.Equal(t, state.Validators.Hash(), vals.Hash())
+	}
+
+	// Importing the archive again is a no-op.
+	height, err = archive.Import(ctx, dir, importBlockStore, importStateStore, genDoc, logger)
+	require.NoError(t, err)
+	require.EqualValues(t, 10, height)
+}
+
+func TestImportResume(t *testing.T) {
+	ctx := context.Background()
+	logger := log.TestingLogger()
+	genDoc, blockStore, stateStore := makeChain(t, 8)
+
+	firstDir, secondDir := t.TempDir(), t.TempDir()
+	_, err := archive.Export(ctx, blockStore, stateStore, firstDir, 1, 5, 2, logger)
+	require.NoError(t, err)
+	_, err = archive.Export(ctx, blockStore, stateStore, secondDir, 3, 8, 2, logger)
+	require.NoError(t, err)
+
+	importBlockStore := store.NewBlockStore(dbm.NewMemDB())
+	importStateStore := sm.NewStore(dbm.NewMemDB())
+
+	// The second archive doesn't start at the initial height.
+	_, err = archive.Import(ctx, secondDir, importBlockStore, importStateStore, genDoc, logger)
+	require.Error(t, err)
+
+	height, err := archive.Import(ctx, firstDir, importBlockStore, importStateStore, genDoc, logger)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, height)
+	state, err := importStateStore.Load()
+	require.NoError(t, err)
+	require.Equal(t, blockStore.LoadBlockMeta(6).Header.AppHash.Bytes(), state.AppHash)
+
+	height, err = archive.Import(ctx, secondDir, importBlockStore, importStateStore, genDoc, logger)
+	require.NoError(t, err)
+	require.EqualValues(t, 8, height)
+	expectState, err := stateStore.Load()
+	require.NoError(t, err)
+	state, err = importStateStore.Load()
+	require.NoError(t, err)
+	require.Equal(t, expectState, state)
+}
+
+func TestImportInvalid(t *testing.T) {
+	ctx := context.Background()
+	logger := log.TestingLogger()
+	genDoc, blockStore, stateStore := makeChain(t, 4)
+	dir := t.TempDir()
+	manifest, err := archive.Export(ctx, blockStore, stateStore, dir, 1, 4, 2, logger)
+	require.NoError(t, err)
+
+	t.Run("other validators", func(t *testing.T) {
+		otherGenDoc, _ := factory.RandGenesisDoc(config.TestConfig(), 3, false, 10)
+		_, err := archive.Import(ctx, dir, store.NewBlockStore(dbm.NewMemDB()), sm.NewStore(dbm.NewMemDB()),
+			otherGenDoc, logger)
+		require.Error(t, err)
+	})
+
+	t.Run("corrupt chunk", func(t *testing.T) {
+		path := filepath.Join(dir, manifest.Chunks[1].File)
+		bz, err := os.ReadFile(path)
+		require.NoError(t, err)
+		bz[len(bz)-1] ^= 0xff
+		require.NoError(t, os.WriteFile(path, bz, 0600))
+
+		importStateStore := sm.NewStore(dbm.NewMemDB())
+		height, err := archive.Import(ctx, dir, store.NewBlockStore(dbm.NewMemDB()), importStateStore,
+			genDoc, logger)
+		require.Error(t, err)
+		require.EqualValues(t, 1, height)
+
+		// The blocks of the first chunk have been imported, but the app hash
+		// after the last one is only known from the next block.
+		state, err := importStateStore.Load()
+		require.NoError(t, err)
+		require.EqualValues(t, 1, state.LastBlockHeight)
+		require.Equal(t, blockStore.LoadBlockMeta(2).Header.AppHash.Bytes(), state.AppHash)
+	})
+}
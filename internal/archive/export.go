@@ -0,0 +1,176 @@
+package archive
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/tendermint/tendermint/internal/libs/protoio"
+	"github.com/tendermint/tendermint/internal/libs/tempfile"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/libs/log"
+	tmos "github.com/tendermint/tendermint/libs/os"
+)
+
+// Export writes the blocks at heights startHeight to endHeight (inclusive),
+// along with their commits and ABCI responses, to an archive in dir, with
+// chunkSize heights per chunk file. The directory must not already contain an
+// archive.
+func Export(
+	ctx context.Context,
+	bs sm.BlockStore,
+	ss sm.Store,
+	dir string,
+	startHeight, endHeight, chunkSize int64,
+	logger log.Logger,
+) (*Manifest, error) {
+	if base, height := bs.Base(), bs.Height(); startHeight < base || endHeight > height || startHeight > endHeight {
+		return nil, fmt.Errorf("invalid height range %d-%d, the block store contains heights %d-%d",
+			startHeight, endHeight, base, height)
+	}
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	if err := tmos.EnsureDir(dir, 0700); err != nil {
+		return nil, err
+	}
+	if tmos.FileExists(filepath.Join(dir, ManifestFile)) {
+		return nil, fmt.Errorf("%s already contains an archive", dir)
+	}
+
+	appHash, err := appHashAfter(bs, ss, endHeight)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{
+		Version:     Version,
+		ChainID:     bs.LoadBlockMeta(startHeight).Header.ChainID,
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+		AppHash:     appHash,
+	}
+
+	for start := startHeight; start <= endHeight; start += chunkSize {
+		end := start + chunkSize - 1
+		if end > endHeight {
+			end = endHeight
+		}
+		chunk, err := exportChunk(ctx, bs, ss, dir, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export heights %d-%d: %w", start, end, err)
+		}
+		manifest.Chunks = append(manifest.Chunks, chunk)
+		logger.Info("exported blocks", "start_height", start, "end_height", end)
+	}
+
+	bz, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := tempfile.WriteFileAtomic(filepath.Join(dir, ManifestFile), bz, 0600); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// appHashAfter returns the application hash after executing the block at the
+// given height, which is either found in the next block's header or, for the
+// latest block, in the state.
+func appHashAfter(bs sm.BlockStore, ss sm.Store, height int64) ([]byte, error) {
+	if height < bs.Height() {
+		meta := bs.LoadBlockMeta(height + 1)
+		if meta == nil {
+			return nil, fmt.Errorf("block meta not found for height %d", height+1)
+		}
+		return meta.Header.AppHash, nil
+	}
+	state, err := ss.Load()
+	if err != nil {
+		return nil, err
+	}
+	if state.LastBlockHeight != height {
+		return nil, fmt.Errorf("state height %d does not match block store height %d, can't determine app hash",
+			state.LastBlockHeight, height)
+	}
+	return state.AppHash, nil
+}
+
+// exportChunk writes the records for the given heights to a chunk file.
+func exportChunk(
+	ctx context.Context,
+	bs sm.BlockStore,
+	ss sm.Store,
+	dir string,
+	startHeight, endHeight int64,
+) (Chunk, error) {
+	chunk := Chunk{
+		File:        chunkFileName(startHeight, endHeight),
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+	}
+	file, err := os.OpenFile(filepath.Join(dir, chunk.File), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return chunk, err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	buf := bufio.NewWriter(io.MultiWriter(file, hasher))
+	writer := protoio.NewDelimitedWriter(buf)
+
+	for height := startHeight; height <= endHeight; height++ {
+		if err := ctx.Err(); err != nil {
+			return chunk, err
+		}
+		if err := exportHeight(bs, ss, writer, height); err != nil {
+			return chunk, fmt.Errorf("height %d: %w", height, err)
+		}
+	}
+
+	if err := buf.Flush(); err != nil {
+		return chunk, err
+	}
+	if err := file.Sync(); err != nil {
+		return chunk, err
+	}
+	chunk.Checksum = hasher.Sum(nil)
+	return chunk, file.Close()
+}
+
+// exportHeight writes the record for a single height.
+func exportHeight(bs sm.BlockStore, ss sm.Store, writer protoio.Writer, height int64) error {
+	block := bs.LoadBlock(height)
+	if block == nil {
+		return errors.New("block not found")
+	}
+	commit := bs.LoadBlockCommit(height)
+	if commit == nil && height == bs.Height() {
+		commit = bs.LoadSeenCommit()
+	}
+	if commit == nil || commit.Height != height {
+		return errors.New("commit not found")
+	}
+	abciResponses, err := ss.LoadABCIResponses(height)
+	if err != nil {
+		return err
+	}
+
+	pbb, err := block.ToProto()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.WriteMsg(pbb); err != nil {
+		return err
+	}
+	if _, err := writer.WriteMsg(commit.ToProto()); err != nil {
+		return err
+	}
+	_, err = writer.WriteMsg(abciResponses)
+	return err
+}
@@ -0,0 +1,238 @@
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/tendermint/tendermint/internal/libs/protoio"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/libs/log"
+	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// importer imports records into the block and state stores.
+type importer struct {
+	bs sm.BlockStore
+	ss sm.Store
+
+	// state is the state after the last imported block. Its AppHash is only
+	// known once the next block has been read, so it is saved lazily.
+	state   sm.State
+	pending bool
+
+	// savedHeight is the height of the latest saved state.
+	savedHeight int64
+}
+
+// Import imports the archive in dir into the block and state stores, starting
+// at the height after the latest state, and returns the height of the latest
+// saved state, also if the import fails.
+// If the state store is empty, the import starts from the genesis state, which
+// requires the archive to start at the initial height. Each block and its
+// commit are verified against the validator sets of the state before being
+// written. An interrupted import can be resumed by importing the archive again.
+func Import(
+	ctx context.Context,
+	dir string,
+	bs sm.BlockStore,
+	ss sm.Store,
+	genDoc *types.GenesisDoc,
+	logger log.Logger,
+) (int64, error) {
+	manifest, err := LoadManifest(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	state, err := ss.Load()
+	if err != nil {
+		return 0, err
+	}
+	if state.IsEmpty() {
+		if state, err = sm.MakeGenesisState(genDoc); err != nil {
+			return 0, err
+		}
+		if err := ss.Save(state); err != nil {
+			return 0, err
+		}
+	}
+	if manifest.ChainID != state.ChainID {
+		return 0, fmt.Errorf("archive is for chain %q, but the node is on chain %q", manifest.ChainID, state.ChainID)
+	}
+
+	nextHeight := state.LastBlockHeight + 1
+	if nextHeight == 1 {
+		nextHeight = state.InitialHeight
+	}
+	switch {
+	case nextHeight > manifest.EndHeight:
+		logger.Info("nothing to import", "state_height", state.LastBlockHeight, "end_height", manifest.EndHeight)
+		return state.LastBlockHeight, nil
+	case nextHeight < manifest.StartHeight:
+		return 0, fmt.Errorf("archive starts at height %d, but the node needs height %d next",
+			manifest.StartHeight, nextHeight)
+	}
+	// Blocks are saved before the state, so the block store may be one block
+	// ahead of the state if a previous import was interrupted.
+	if height := bs.Height(); height != 0 && height != nextHeight-1 && height != nextHeight {
+		return 0, fmt.Errorf("block store height %d does not match state height %d",
+			height, state.LastBlockHeight)
+	}
+
+	imp := &importer{bs: bs, ss: ss, state: state, savedHeight: state.LastBlockHeight}
+	for _, chunk := range manifest.Chunks {
+		if chunk.EndHeight < nextHeight {
+			continue
+		}
+		if err := imp.importChunk(ctx, dir, chunk, nextHeight); err != nil {
+			return imp.savedHeight, fmt.Errorf("failed to import %s: %w", chunk.File, err)
+		}
+		logger.Info("imported blocks", "start_height", chunk.StartHeight, "end_height", chunk.EndHeight)
+	}
+
+	err = imp.savePending(manifest.AppHash)
+	return imp.savedHeight, err
+}
+
+// importChunk verifies the checksum of a chunk file, and imports its records
+// from the given height onwards.
+func (imp *importer) importChunk(ctx context.Context, dir string, chunk Chunk, fromHeight int64) error {
+	path := filepath.Join(dir, chunk.File)
+	if err := verifyChecksum(path, chunk.Checksum); err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	reader := protoio.NewDelimitedReader(bufio.NewReader(file), maxMsgSize)
+
+	for height := chunk.StartHeight; height <= chunk.EndHeight; height++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		block, commit, abciResponses, err := readRecord(reader)
+		if err != nil {
+			return fmt.Errorf("height %d: %w", height, err)
+		}
+		if block.Height != height {
+			return fmt.Errorf("expected block at height %d, got %d", height, block.Height)
+		}
+		if height < fromHeight {
+			continue
+		}
+		if err := imp.importBlock(block, commit, abciResponses); err != nil {
+			return fmt.Errorf("height %d: %w", height, err)
+		}
+	}
+	return nil
+}
+
+// importBlock verifies a block and its commit against the current state, and
+// writes it to the stores.
+func (imp *importer) importBlock(
+	block *types.Block,
+	commit *types.Commit,
+	abciResponses *tmstate.ABCIResponses,
+) error {
+	// The app hash after the previous block is only known from this header.
+	// It is verified by the application when replaying the blocks.
+	if err := imp.savePending(block.AppHash); err != nil {
+		return err
+	}
+
+	partSet := block.MakePartSet(types.BlockPartSizeBytes)
+	blockID := types.BlockID{Hash: block.Hash(), PartSetHeader: partSet.Header()}
+	if err := imp.state.Validators.VerifyCommit(imp.state.ChainID, blockID, block.Height, commit); err != nil {
+		return fmt.Errorf("invalid commit: %w", err)
+	}
+	state, err := sm.UpdateStateWithResponses(imp.state, blockID, block, abciResponses)
+	if err != nil {
+		return err
+	}
+
+	if imp.bs.Height() < block.Height {
+		imp.bs.SaveBlock(block, partSet, commit)
+	}
+	if err := imp.ss.SaveABCIResponses(block.Height, abciResponses); err != nil {
+		return err
+	}
+	imp.state = state
+	imp.pending = true
+	return nil
+}
+
+// savePending saves the state after the last imported block, if not yet
+// saved, with the given app hash.
+func (imp *importer) savePending(appHash []byte) error {
+	if !imp.pending {
+		return nil
+	}
+	imp.state.AppHash = appHash
+	if err := imp.ss.Save(imp.state); err != nil {
+		return err
+	}
+	imp.pending = false
+	imp.savedHeight = imp.state.LastBlockHeight
+	return nil
+}
+
+// readRecord reads the record for a single height from a chunk file.
+func readRecord(reader protoio.Reader) (*types.Block, *types.Commit, *tmstate.ABCIResponses, error) {
+	pbb := new(tmproto.Block)
+	if _, err := reader.ReadMsg(pbb); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, nil, nil, fmt.Errorf("failed to read block: %w", err)
+	}
+	block, err := types.BlockFromProto(pbb)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid block: %w", err)
+	}
+
+	pbc := new(tmproto.Commit)
+	if _, err := reader.ReadMsg(pbc); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read commit: %w", err)
+	}
+	commit, err := types.CommitFromProto(pbc)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid commit: %w", err)
+	}
+
+	abciResponses := new(tmstate.ABCIResponses)
+	if _, err := reader.ReadMsg(abciResponses); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read ABCI responses: %w", err)
+	}
+	return block, commit, abciResponses, nil
+}
+
+// verifyChecksum checks that the SHA-256 hash of the file matches the
+// checksum.
+func verifyChecksum(path string, checksum []byte) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return err
+	}
+	if sum := hasher.Sum(nil); !bytes.Equal(sum, checksum) {
+		return fmt.Errorf("checksum %X does not match expected checksum %X", sum, checksum)
+	}
+	return nil
+}
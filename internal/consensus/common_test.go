@@ -290,20 +290,6 @@ func validatePrevote(t *testing.T, cs *State, round int32, privVal *validatorStu
 	}
 }
 
-func validateLastPrecommit(t *testing.T, cs *State, privVal *validatorStub, blockHash []byte) {
-	votes := cs.LastCommit
-	pv, err := privVal.GetPubKey(context.Background())
-	require.NoError(t, err)
-	address := pv.Address()
-	var vote *types.Vote
-	if vote = votes.GetByAddress(address); vote == nil {
-		panic("Failed to find precommit from validator")
-	}
-	if !bytes.Equal(vote.BlockID.Hash, blockHash) {
-		panic(fmt.Sprintf("Expected precommit to be for %X, got %X", blockHash, vote.BlockID.Hash))
-	}
-}
-
 func validatePrecommit(
 	t *testing.T,
 	cs *State,
@@ -574,7 +560,7 @@ func ensureNewTimeout(timeoutCh <-chan tmpubsub.Message, height int64, round int
 		"Timeout expired while waiting for NewTimeout event")
 }
 
-func ensureNewProposal(proposalCh <-chan tmpubsub.Message, height int64, round int32) {
+func ensureNewProposal(proposalCh <-chan tmpubsub.Message, height int64, round int32) types.BlockID {
 	select {
 	case <-time.After(ensureTimeout):
 		panic("Timeout expired while waiting for NewProposal event")
@@ -590,6 +576,7 @@ func ensureNewProposal(proposalCh <-chan tmpubsub.Message, height int64, round i
 		if proposalEvent.Round != round {
 			panic(fmt.Sprintf("expected round %v, got %v", round, proposalEvent.Round))
 		}
+		return proposalEvent.BlockID
 	}
 }
 
@@ -660,16 +647,16 @@ func ensureProposal(proposalCh <-chan tmpubsub.Message, height int64, round int3
 	}
 }
 
-func ensurePrecommit(voteCh <-chan tmpubsub.Message, height int64, round int32) {
-	ensureVote(voteCh, height, round, tmproto.PrecommitType)
+func ensurePrecommit(voteCh <-chan tmpubsub.Message, height int64, round int32) *types.Vote {
+	return ensureVote(voteCh, height, round, tmproto.PrecommitType)
 }
 
-func ensurePrevote(voteCh <-chan tmpubsub.Message, height int64, round int32) {
-	ensureVote(voteCh, height, round, tmproto.PrevoteType)
+func ensurePrevote(voteCh <-chan tmpubsub.Message, height int64, round int32) *types.Vote {
+	return ensureVote(voteCh, height, round, tmproto.PrevoteType)
 }
 
 func ensureVote(voteCh <-chan tmpubsub.Message, height int64, round int32,
-	voteType tmproto.SignedMsgType) {
+	voteType tmproto.SignedMsgType) *types.Vote {
 	select {
 	case <-time.After(ensureTimeout):
 		panic("Timeout expired while waiting for NewVote event")
@@ -689,6 +676,7 @@ func ensureVote(voteCh <-chan tmpubsub.Message, height int64, round int32,
 		if vote.Type != voteType {
 			panic(fmt.Sprintf("expected type %v, got %v", voteType, vote.Type))
 		}
+		return vote
 	}
 }
 
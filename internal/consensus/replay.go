@@ -358,6 +358,14 @@ func (h *Handshaker) ReplayBlocks(
 		}
 	}
 
+	// The store may be several blocks ahead of the state only if the state
+	// was rolled back to its height while keeping the blocks.
+	rollbackHeight, err := h.stateStore.LoadRollbackHeight()
+	if err != nil {
+		return nil, err
+	}
+	rolledBack := rollbackHeight > 0 && rollbackHeight == stateBlockHeight
+
 	// First handle edge cases and constraints on the storeBlockHeight and storeBlockBase.
 	switch {
 	case storeBlockHeight == 0:
@@ -380,13 +388,13 @@ func (h *Handshaker) ReplayBlocks(
 		// the state should never be ahead of the store (this is under tendermint's control)
 		panic(fmt.Sprintf("StateBlockHeight (%d) > StoreBlockHeight (%d)", stateBlockHeight, storeBlockHeight))
 
-	case storeBlockHeight > stateBlockHeight+1:
-		// store should be at most one ahead of the state (this is under tendermint's control)
+	case storeBlockHeight > stateBlockHeight+1 && (!rolledBack || appBlockHeight > stateBlockHeight):
+		// store should be at most one ahead of the state (this is under tendermint's control),
+		// unless the state and the app have both been rolled back while keeping the blocks
 		panic(fmt.Sprintf("StoreBlockHeight (%d) > StateBlockHeight + 1 (%d)", storeBlockHeight, stateBlockHeight+1))
 	}
 
-	var err error
-	// Now either store is equal to state, or one ahead.
+	// Now either store is equal to state, or one ahead, or further ahead after a rollback.
 	// For each, consider all cases of where the app could be, given app <= store
 	if storeBlockHeight == stateBlockHeight {
 		// Tendermint ran Commit and saved the state.
@@ -431,6 +439,27 @@ func (h *Handshaker) ReplayBlocks(
 			return state.AppHash, err
 		}
 
+	} else {
+		// The state and the app were rolled back by several heights while keeping
+		// the blocks, so replay all of them with the real app, updating the state
+		// once the app has caught up with it.
+		if appBlockHeight < stateBlockHeight {
+			if _, err := h.replayBlocks(state, proxyApp, appBlockHeight, stateBlockHeight, false); err != nil {
+				return nil, err
+			}
+		}
+		h.logger.Info("Replay blocks after rollback using real app",
+			"from", stateBlockHeight+1, "to", storeBlockHeight)
+		for height := stateBlockHeight + 1; height <= storeBlockHeight; height++ {
+			state, err = h.replayBlock(state, height, proxyApp.Consensus())
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := h.stateStore.SaveRollbackHeight(0); err != nil {
+			return nil, err
+		}
+		return state.AppHash, nil
 	}
 
 	panic(fmt.Sprintf("uncovered case! appHeight: %d, storeHeight: %d, stateHeight: %d",
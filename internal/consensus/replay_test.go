@@ -614,6 +614,78 @@ func TestHandshakeReplayNone(t *testing.T) {
 	}
 }
 
+// Sync after rolling back the state and the app by several heights, keeping the blocks
+func TestHandshakeReplayAfterRollback(t *testing.T) {
+	const rollbackHeight = 2
+
+	sim := setupSimulator(t)
+	cfg := sim.Config
+
+	for _, appHeight := range []int{0, rollbackHeight} {
+		stateStore := sm.NewStore(dbm.NewMemDB())
+		store := newMockBlockStore(cfg, sim.GenesisState.ConsensusParams)
+		store.chain = append([]*types.Block{}, sim.Chain...)
+		store.commits = sim.Commits
+
+		// build the tendermint state for the whole chain, and the app state up
+		// to the app height
+		state := buildTMStateFromChain(cfg, sim.Mempool, sim.Evpool, stateStore, sim.GenesisState.Copy(),
+			store.chain, appHeight, 0, store)
+		latestAppHash := state.AppHash
+
+		kvstoreApp := kvstore.NewPersistentKVStoreApplication(
+			filepath.Join(cfg.DBDir(), fmt.Sprintf("replay_test_rollback_%d_a_r%d", appHeight, rand.Int())))
+		t.Cleanup(func() { require.NoError(t, kvstoreApp.Close()) })
+		clientCreator := abciclient.NewLocalCreator(kvstoreApp)
+
+		if appHeight > 0 {
+			appStateStore := sm.NewStore(dbm.NewMemDB())
+			require.NoError(t, appStateStore.Save(sim.GenesisState))
+			buildAppStateFromChain(proxy.NewAppConns(clientCreator, proxy.NopMetrics()), appStateStore,
+				sim.Mempool, sim.Evpool, sim.GenesisState, store.chain, appHeight, 0, store)
+		}
+
+		state, err := sm.RollbackTo(store, stateStore, rollbackHeight, sm.RollbackOptions{})
+		require.NoError(t, err)
+		height, err := stateStore.LoadRollbackHeight()
+		require.NoError(t, err)
+		require.EqualValues(t, rollbackHeight, height)
+
+		// now start the app using the handshake - it should replay all blocks
+		genDoc, err := sm.MakeGenesisDocFromFile(cfg.GenesisFile())
+		require.NoError(t, err)
+		handshaker := NewHandshaker(stateStore, state, store, genDoc)
+		proxyApp := proxy.NewAppConns(clientCreator, proxy.NopMetrics())
+		require.NoError(t, proxyApp.Start())
+		t.Cleanup(func() {
+			if err := proxyApp.Stop(); err != nil {
+				t.Error(err)
+			}
+		})
+		if appHeight > 0 {
+			// without the record of the rollback, the store being several
+			// blocks ahead of the state is a corruption
+			require.NoError(t, stateStore.SaveRollbackHeight(0))
+			require.Panics(t, func() { _ = handshaker.Handshake(proxyApp) })
+			require.NoError(t, stateStore.SaveRollbackHeight(rollbackHeight))
+		}
+		require.NoError(t, handshaker.Handshake(proxyApp))
+		height, err = stateStore.LoadRollbackHeight()
+		require.NoError(t, err)
+		require.Zero(t, height)
+
+		res, err := proxyApp.Query().InfoSync(context.Background(), abci.RequestInfo{Version: ""})
+		require.NoError(t, err)
+		require.Equal(t, latestAppHash, res.LastBlockAppHash)
+		require.Equal(t, len(store.chain)-appHeight, handshaker.NBlocks())
+
+		state, err = stateStore.Load()
+		require.NoError(t, err)
+		require.EqualValues(t, len(store.chain), state.LastBlockHeight)
+		require.Equal(t, latestAppHash, state.AppHash)
+	}
+}
+
 // Test mockProxyApp should not panic when app return ABCIResponses with some empty ResponseDeliverTx
 func TestMockProxyApp(t *testing.T) {
 	sim := setupSimulator(t) // setup config and simulator
@@ -1228,6 +1300,13 @@ func (bs *mockBlockStore) PruneBlocks(height int64) (uint64, error) {
 	return pruned, nil
 }
 
+func (bs *mockBlockStore) DeleteBlocksFrom(height int64) (uint64, error) {
+	deleted := uint64(len(bs.chain)) - uint64(height-1)
+	bs.chain = bs.chain[:height-1]
+	bs.commits = bs.commits[:height-1]
+	return deleted, nil
+}
+
 //---------------------------------------
 // Test handshake/init chain
 
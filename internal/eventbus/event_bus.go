@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/eventlog"
 	"github.com/tendermint/tendermint/libs/log"
 	tmpubsub "github.com/tendermint/tendermint/libs/pubsub"
 	"github.com/tendermint/tendermint/libs/service"
@@ -24,6 +25,7 @@ type Subscription interface {
 type EventBus struct {
 	service.BaseService
 	pubsub *tmpubsub.Server
+	log    *eventlog.Log // nil unless set
 }
 
 // NewDefault returns a new event bus with default options.
@@ -39,6 +41,13 @@ func (b *EventBus) SetLogger(l log.Logger) {
 	b.pubsub.SetLogger(l.With("module", "pubsub"))
 }
 
+// SetEventLog sets the log recording the events published on the bus, before
+// they are delivered to the subscribers. It must be called before the bus is
+// started.
+func (b *EventBus) SetEventLog(lg *eventlog.Log) {
+	b.log = lg
+}
+
 func (b *EventBus) OnStart() error {
 	return b.pubsub.Start()
 }
@@ -95,7 +104,7 @@ func (b *EventBus) Publish(eventValue string, eventData types.TMEventData) error
 		},
 	}
 
-	return b.pubsub.PublishWithEvents(ctx, eventData, []abci.Event{event})
+	return b.publish(ctx, eventData, []abci.Event{event})
 }
 
 func (b *EventBus) PublishEventNewBlock(data types.EventDataNewBlock) error {
@@ -106,7 +115,7 @@ func (b *EventBus) PublishEventNewBlock(data types.EventDataNewBlock) error {
 	// add Tendermint-reserved new block event
 	events = append(events, types.EventNewBlock)
 
-	return b.pubsub.PublishWithEvents(ctx, data, events)
+	return b.publish(ctx, data, events)
 }
 
 func (b *EventBus) PublishEventNewBlockHeader(data types.EventDataNewBlockHeader) error {
@@ -117,7 +126,7 @@ func (b *EventBus) PublishEventNewBlockHeader(data types.EventDataNewBlockHeader
 	// add Tendermint-reserved new block header event
 	events = append(events, types.EventNewBlockHeader)
 
-	return b.pubsub.PublishWithEvents(ctx, data, events)
+	return b.publish(ctx, data, events)
 }
 
 func (b *EventBus) PublishEventNewEvidence(evidence types.EventDataNewEvidence) error {
@@ -173,7 +182,7 @@ func (b *EventBus) PublishEventTx(data types.EventDataTx) error {
 		},
 	})
 
-	return b.pubsub.PublishWithEvents(ctx, data, events)
+	return b.publish(ctx, data, events)
 }
 
 func (b *EventBus) PublishEventNewRoundStep(data types.EventDataRoundState) error {
@@ -216,6 +225,14 @@ func (b *EventBus) PublishEventValidatorSetUpdates(data types.EventDataValidator
 	return b.Publish(types.EventValidatorSetUpdatesValue, data)
 }
 
+// publish records the event in the log, if any, and publishes it.
+func (b *EventBus) publish(ctx context.Context, data types.TMEventData, events []abci.Event) error {
+	if b.log != nil {
+		b.log.Add(data, events)
+	}
+	return b.pubsub.PublishWithEvents(ctx, data, events)
+}
+
 //-----------------------------------------------------------------------------
 
 // NopEventBus implements a types.BlockEventPublisher that discards all events.
@@ -0,0 +1,69 @@
+package eventlog
+
+import (
+	"fmt"
+	"time"
+)
+
+// Cursor is the position of an item in the log. Cursors increase with the
+// time the items are added, and are not reused after the node restarts.
+type Cursor struct {
+	Timestamp int64  // Unix time in nanoseconds
+	Sequence  uint16 // disambiguates the items added at the same timestamp
+}
+
+// String returns the encoding of the cursor, which is passed to clients. The
+// encodings of cursors compare in the same order as the cursors.
+func (c Cursor) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%016x-%04x", c.Timestamp, c.Sequence)
+}
+
+// ParseCursor decodes a cursor returned by String. An empty string decodes to
+// the zero cursor.
+func ParseCursor(s string) (Cursor, error) {
+	if s == "" {
+		return Cursor{}, nil
+	}
+	var c Cursor
+	if len(s) != 21 {
+		return Cursor{}, fmt.Errorf("invalid cursor %q", s)
+	}
+	if _, err := fmt.Sscanf(s, "%016x-%04x", &c.Timestamp, &c.Sequence); err != nil || c.Timestamp < 0 {
+		return Cursor{}, fmt.Errorf("invalid cursor %q", s)
+	}
+	return c, nil
+}
+
+// IsZero reports whether c is the zero cursor, which precedes all the items.
+func (c Cursor) IsZero() bool {
+	return c == Cursor{}
+}
+
+// Before reports whether c precedes o.
+func (c Cursor) Before(o Cursor) bool {
+	if c.Timestamp != o.Timestamp {
+		return c.Timestamp < o.Timestamp
+	}
+	return c.Sequence < o.Sequence
+}
+
+// Time returns the time the item at the cursor was added.
+func (c Cursor) Time() time.Time {
+	return time.Unix(0, c.Timestamp)
+}
+
+// next returns the cursor of an item added at time t after the item at c.
+func (c Cursor) next(t time.Time) Cursor {
+	ts := t.UnixNano()
+	switch {
+	case ts > c.Timestamp:
+		return Cursor{Timestamp: ts}
+	case c.Sequence < 1<<16-1:
+		return Cursor{Timestamp: c.Timestamp, Sequence: c.Sequence + 1}
+	default:
+		return Cursor{Timestamp: c.Timestamp + 1}
+	}
+}
@@ -0,0 +1,207 @@
+// Package eventlog implements a bounded log of the events published by the
+// node, which clients read from a cursor so they don't miss events between
+// their requests.
+package eventlog
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// Item is an event recorded in the log.
+type Item struct {
+	Cursor Cursor
+	// Type is the value of the tm.event attribute of the event, if any.
+	Type   string
+	Data   types.TMEventData
+	Events []abci.Event
+}
+
+// Options configures the bounds of a log.
+type Options struct {
+	// WindowSize is the age after which items are removed from the log.
+	WindowSize time.Duration
+	// MaxItems is the maximum number of items in the log, after which the
+	// oldest items are removed (0 - unlimited).
+	MaxItems int
+}
+
+// Log is a log of events bounded by age and number. It is safe for concurrent
+// use.
+type Log struct {
+	windowSize time.Duration
+	maxItems   int
+	now        func() time.Time
+
+	mtx   sync.Mutex
+	items []*Item // in the order of their cursors
+	last  Cursor  // cursor of the last item added, even if removed
+	ready chan struct{}
+}
+
+// New returns an empty log with the options.
+func New(opts Options) (*Log, error) {
+	if opts.WindowSize <= 0 {
+		return nil, errors.New("window size must be positive")
+	}
+	if opts.MaxItems < 0 {
+		return nil, errors.New("max items can't be negative")
+	}
+	return &Log{
+		windowSize: opts.WindowSize,
+		maxItems:   opts.MaxItems,
+		now:        time.Now,
+		ready:      make(chan struct{}),
+	}, nil
+}
+
+// Add records an event in the log, and wakes the readers waiting for it.
+func (lg *Log) Add(data types.TMEventData, events []abci.Event) {
+	lg.mtx.Lock()
+	defer lg.mtx.Unlock()
+
+	now := lg.now()
+	lg.last = lg.last.next(now)
+	lg.items = append(lg.items, &Item{
+		Cursor: lg.last,
+		Type:   eventType(events),
+		Data:   data,
+		Events: events,
+	})
+	lg.prune(now)
+
+	close(lg.ready)
+	lg.ready = make(chan struct{})
+}
+
+// prune removes the items which are too old, or too many.
+func (lg *Log) prune(now time.Time) {
+	oldest := now.Add(-lg.windowSize).UnixNano()
+	n := sort.Search(len(lg.items), func(i int) bool {
+		return lg.items[i].Cursor.Timestamp >= oldest
+	})
+	if lg.maxItems > 0 && len(lg.items)-n > lg.maxItems {
+		n = len(lg.items) - lg.maxItems
+	}
+	for i := 0; i < n; i++ {
+		lg.items[i] = nil // release the item
+	}
+	lg.items = lg.items[n:]
+}
+
+// Info describes the items in a log.
+type Info struct {
+	// Oldest and Newest are the cursors of the oldest and newest items of the
+	// log, or zero if it is empty.
+	Oldest, Newest Cursor
+	// Size is the number of items in the log.
+	Size int
+}
+
+// Info returns the description of the items in the log.
+func (lg *Log) Info() Info {
+	lg.mtx.Lock()
+	defer lg.mtx.Unlock()
+	return lg.info()
+}
+
+func (lg *Log) info() Info {
+	info := Info{Size: len(lg.items)}
+	if len(lg.items) > 0 {
+		info.Oldest = lg.items[0].Cursor
+		info.Newest = lg.items[len(lg.items)-1].Cursor
+	}
+	return info
+}
+
+// Page is the result of a scan of the log.
+type Page struct {
+	// Items are the matching items, in the order of their cursors.
+	Items []*Item
+	// More reports whether the scan stopped at the maximum number of items,
+	// while more items may match.
+	More bool
+	// Next is the cursor to scan from to continue after the page: the cursor
+	// of the last item if More is true, and otherwise the cursor of the
+	// newest item scanned.
+	Next Cursor
+	Info
+}
+
+// Scan returns up to maxItems of the items after the cursor for which match
+// returns true, in the order of their cursors. Scan stops at the first error
+// returned by match.
+func (lg *Log) Scan(after Cursor, maxItems int, match func(*Item) (bool, error)) (Page, error) {
+	lg.mtx.Lock()
+	defer lg.mtx.Unlock()
+	return lg.scan(after, maxItems, match)
+}
+
+func (lg *Log) scan(after Cursor, maxItems int, match func(*Item) (bool, error)) (Page, error) {
+	page := Page{Next: after, Info: lg.info()}
+	i := sort.Search(len(lg.items), func(i int) bool {
+		return after.Before(lg.items[i].Cursor)
+	})
+	for ; i < len(lg.items); i++ {
+		item := lg.items[i]
+		if len(page.Items) == maxItems {
+			page.More = true
+			return page, nil
+		}
+		ok, err := match(item)
+		if err != nil {
+			return Page{}, err
+		}
+		if ok {
+			page.Items = append(page.Items, item)
+		}
+		page.Next = item.Cursor
+	}
+	return page, nil
+}
+
+// WaitScan scans the log like Scan, but if no items match, it waits until
+// items matching are added or the context ends, in which case it returns the
+// empty page and no error.
+func (lg *Log) WaitScan(ctx context.Context, after Cursor, maxItems int, match func(*Item) (bool, error)) (Page, error) {
+	for {
+		lg.mtx.Lock()
+		page, err := lg.scan(after, maxItems, match)
+		ready := lg.ready
+		lg.mtx.Unlock()
+		if err != nil || len(page.Items) > 0 {
+			return page, err
+		}
+
+		select {
+		case <-ready:
+			// Don't scan the items which didn't match again.
+			after = page.Next
+		case <-ctx.Done():
+			return page, nil
+		}
+	}
+}
+
+// eventType returns the value of the tm.event attribute of the events.
+func eventType(events []abci.Event) string {
+	tokens := strings.Split(types.EventTypeKey, ".")
+	for _, event := range events {
+		if event.Type != tokens[0] {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if attr.Key == tokens[1] {
+				return attr.Value
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,184 @@
+package eventlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+func matchAll(*Item) (bool, error) { return true, nil }
+
+func matchType(etype string) func(*Item) (bool, error) {
+	return func(item *Item) (bool, error) { return item.Type == etype, nil }
+}
+
+// newTestLog returns a log whose clock is advanced by a second by each call
+// to Add.
+func newTestLog(t *testing.T, opts Options) (*Log, *time.Time) {
+	t.Helper()
+
+	lg, err := New(opts)
+	require.NoError(t, err)
+	now := time.Unix(1600000000, 0)
+	lg.now = func() time.Time { return now }
+	return lg, &now
+}
+
+func addEvent(lg *Log, now *time.Time, event abci.Event) {
+	lg.Add(types.EventDataString(event.Attributes[0].Value), []abci.Event{event})
+	*now = now.Add(time.Second)
+}
+
+func TestCursor(t *testing.T) {
+	c := Cursor{Timestamp: 1600000000000000000, Sequence: 3}
+	assert.Equal(t, "16345785d8a00000-0003", c.String())
+	parsed, err := ParseCursor(c.String())
+	require.NoError(t, err)
+	assert.Equal(t, c, parsed)
+
+	zero, err := ParseCursor("")
+	require.NoError(t, err)
+	assert.True(t, zero.IsZero())
+	assert.Equal(t, "", zero.String())
+	assert.True(t, zero.Before(c))
+
+	for _, s := range []string{"16345785d8a00000", "16345785d8a00000-003", "x6345785d8a00000-0003"} {
+		_, err := ParseCursor(s)
+		assert.Error(t, err, s)
+	}
+
+	// Cursors increase even if the clock doesn't.
+	t0 := c.Time()
+	next := c.next(t0)
+	assert.Equal(t, Cursor{Timestamp: c.Timestamp, Sequence: 4}, next)
+	assert.True(t, c.Before(next))
+	assert.True(t, c.String() < next.String())
+	next = c.next(t0.Add(-time.Second))
+	assert.True(t, c.Before(next))
+	last := Cursor{Timestamp: c.Timestamp, Sequence: 1<<16 - 1}
+	assert.True(t, last.Before(last.next(t0)))
+}
+
+func TestLogScan(t *testing.T) {
+	lg, now := newTestLog(t, Options{WindowSize: time.Hour})
+
+	page, err := lg.Scan(Cursor{}, 10, matchAll)
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+	assert.True(t, page.Next.IsZero())
+
+	for i := 0; i < 5; i++ {
+		addEvent(lg, now, types.EventNewBlock)
+		addEvent(lg, now, types.EventTx)
+	}
+	info := lg.Info()
+	assert.Equal(t, 10, info.Size)
+
+	// All the items.
+	page, err = lg.Scan(Cursor{}, 20, matchAll)
+	require.NoError(t, err)
+	require.Len(t, page.Items, 10)
+	assert.False(t, page.More)
+	assert.Equal(t, info.Oldest, page.Items[0].Cursor)
+	assert.Equal(t, info.Newest, page.Items[9].Cursor)
+	assert.Equal(t, info.Newest, page.Next)
+	assert.Equal(t, types.EventNewBlockValue, page.Items[0].Type)
+	assert.Equal(t, types.EventTxValue, page.Items[1].Type)
+
+	// Pages of the matching items.
+	page, err = lg.Scan(Cursor{}, 3, matchType(types.EventTxValue))
+	require.NoError(t, err)
+	require.Len(t, page.Items, 3)
+	assert.True(t, page.More)
+	assert.Equal(t, page.Items[2].Cursor, page.Next)
+
+	page, err = lg.Scan(page.Next, 3, matchType(types.EventTxValue))
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	assert.False(t, page.More)
+	assert.Equal(t, info.Newest, page.Next)
+
+	// Errors stop the scan.
+	_, err = lg.Scan(Cursor{}, 3, func(*Item) (bool, error) { return false, errors.New("boom") })
+	assert.Error(t, err)
+}
+
+func TestLogPrune(t *testing.T) {
+	lg, now := newTestLog(t, Options{WindowSize: 5 * time.Second, MaxItems: 3})
+
+	addEvent(lg, now, types.EventNewBlock)
+	first := lg.Info().Newest
+	for i := 0; i < 5; i++ {
+		addEvent(lg, now, types.EventTx)
+	}
+	info := lg.Info()
+	assert.Equal(t, 3, info.Size)
+	assert.True(t, first.Before(info.Oldest))
+
+	// Scanning from a removed item returns the remaining items.
+	page, err := lg.Scan(first, 10, matchAll)
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 3)
+	assert.Equal(t, info.Oldest, page.Oldest)
+
+	// Items older than the window are removed.
+	*now = now.Add(10 * time.Second)
+	addEvent(lg, now, types.EventNewBlock)
+	info = lg.Info()
+	assert.Equal(t, 1, info.Size)
+	assert.Equal(t, info.Oldest, info.Newest)
+
+	_, err = New(Options{})
+	assert.Error(t, err)
+	_, err = New(Options{WindowSize: time.Second, MaxItems: -1})
+	assert.Error(t, err)
+}
+
+func TestLogWaitScan(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lg, err := New(Options{WindowSize: time.Hour})
+	require.NoError(t, err)
+	lg.Add(types.EventDataString("block"), []abci.Event{types.EventNewBlock})
+	after := lg.Info().Newest
+
+	// Items which don't match don't end the wait.
+	done := make(chan Page)
+	go func() {
+		page, err := lg.WaitScan(ctx, after, 10, matchType(types.EventTxValue))
+		assert.NoError(t, err)
+		done <- page
+	}()
+	time.Sleep(10 * time.Millisecond)
+	lg.Add(types.EventDataString("block"), []abci.Event{types.EventNewBlock})
+	select {
+	case <-done:
+		t.Fatal("the wait ended without a matching item")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	lg.Add(types.EventDataString("tx"), []abci.Event{types.EventTx})
+	select {
+	case page := <-done:
+		require.Len(t, page.Items, 1)
+		assert.Equal(t, types.EventDataString("tx"), page.Items[0].Data)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the wait didn't end after a matching item")
+	}
+
+	// The wait ends with the context.
+	waitCtx, waitCancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer waitCancel()
+	page, err := lg.WaitScan(waitCtx, lg.Info().Newest, 10, matchAll)
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+	assert.Equal(t, lg.Info().Newest, page.Next)
+}
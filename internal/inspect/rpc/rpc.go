@@ -51,8 +51,8 @@ func Routes(cfg config.RPCConfig, s state.Store, bs state.BlockStore, es []index
 		"commit":           server.NewRPCFunc(env.Commit, "height", true),
 		"validators":       server.NewRPCFunc(env.Validators, "height,page,per_page", true),
 		"tx":               server.NewRPCFunc(env.Tx, "hash,prove", true),
-		"tx_search":        server.NewRPCFunc(env.TxSearch, "query,prove,page,per_page,order_by", false),
-		"block_search":     server.NewRPCFunc(env.BlockSearch, "query,page,per_page,order_by", false),
+		"tx_search":        server.NewRPCFunc(env.TxSearch, "query,prove,page,per_page,order_by,cursor", false),
+		"block_search":     server.NewRPCFunc(env.BlockSearch, "query,page,per_page,order_by,cursor", false),
 	}
 }
 
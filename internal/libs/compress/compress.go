@@ -0,0 +1,254 @@
+// Package compress implements optional compression of values stored in the
+// node's databases.
+//
+// Compressed values are prefixed with a format marker: a zero byte followed by
+// a byte identifying the algorithm. Values without the marker are stored
+// uncompressed, which includes all values written before compression was
+// enabled. Since the stored values are Protobuf messages, and a Protobuf
+// message can't start with a zero byte (field number 0 is invalid), the
+// marker can't be confused with an uncompressed value.
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// Algorithm identifies a compression algorithm.
+type Algorithm byte
+
+const (
+	// None stores values uncompressed, without a format marker.
+	None Algorithm = iota
+	// Snappy compresses values with Snappy, favoring speed over ratio.
+	Snappy
+	// Gzip compresses values with gzip at a configurable level.
+	Gzip
+)
+
+// marker is the first byte of compressed values.
+const marker byte = 0x00
+
+// recompressBatchSize is the number of values rewritten per batch by
+// Recompress.
+const recompressBatchSize = 1000
+
+var algorithmNames = map[Algorithm]string{
+	None:   "none",
+	Snappy: "snappy",
+	Gzip:   "gzip",
+}
+
+// String returns the name of the algorithm, as used in the configuration.
+func (a Algorithm) String() string {
+	if name, ok := algorithmNames[a]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", byte(a))
+}
+
+// ParseAlgorithm parses the name of an algorithm. The empty string is
+// equivalent to "none".
+func ParseAlgorithm(name string) (Algorithm, error) {
+	if name == "" {
+		return None, nil
+	}
+	for algorithm, n := range algorithmNames {
+		if n == name {
+			return algorithm, nil
+		}
+	}
+	return None, fmt.Errorf("unknown compression algorithm %q (expected none, snappy or gzip)", name)
+}
+
+// ValidateLevel returns an error if the level is invalid for the algorithm.
+// Level 0 selects the algorithm's default level. Only gzip supports levels,
+// from 1 (fastest) to 9 (best compression).
+func ValidateLevel(algorithm Algorithm, level int) error {
+	switch {
+	case level == 0:
+		return nil
+	case algorithm != Gzip:
+		return fmt.Errorf("compression algorithm %v does not support levels", algorithm)
+	case level < gzip.BestSpeed || level > gzip.BestCompression:
+		return fmt.Errorf("gzip compression level must be between %d and %d, got %d",
+			gzip.BestSpeed, gzip.BestCompression, level)
+	}
+	return nil
+}
+
+// Compressor compresses values with a given algorithm and level. A nil
+// Compressor stores values uncompressed.
+type Compressor struct {
+	algorithm Algorithm
+	level     int
+}
+
+// NewCompressor returns a compressor for the named algorithm and level, where
+// level 0 selects the algorithm's default level.
+func NewCompressor(algorithm string, level int) (*Compressor, error) {
+	alg, err := ParseAlgorithm(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateLevel(alg, level); err != nil {
+		return nil, err
+	}
+	if alg == Gzip && level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return &Compressor{algorithm: alg, level: level}, nil
+}
+
+// Algorithm returns the compressor's algorithm.
+func (c *Compressor) Algorithm() Algorithm {
+	if c == nil {
+		return None
+	}
+	return c.algorithm
+}
+
+// Compress compresses a value and prefixes it with the format marker. With
+// algorithm None, the value is returned unchanged.
+func (c *Compressor) Compress(bz []byte) ([]byte, error) {
+	switch c.Algorithm() {
+	case None:
+		return bz, nil
+
+	case Snappy:
+		out := make([]byte, 2, 2+snappy.MaxEncodedLen(len(bz)))
+		out[0], out[1] = marker, byte(Snappy)
+		return append(out, snappy.Encode(nil, bz)...), nil
+
+	case Gzip:
+		var buf bytes.Buffer
+		buf.Write([]byte{marker, byte(Gzip)})
+		w, err := gzip.NewWriterLevel(&buf, c.level)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(bz); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm %v", c.algorithm)
+	}
+}
+
+// AlgorithmOf returns the algorithm a stored value was compressed with, or
+// None if it is uncompressed.
+func AlgorithmOf(bz []byte) Algorithm {
+	if len(bz) < 2 || bz[0] != marker {
+		return None
+	}
+	return Algorithm(bz[1])
+}
+
+// Decompress returns the uncompressed value of a stored value, which may or
+// may not have been compressed.
+func Decompress(bz []byte) ([]byte, error) {
+	if len(bz) == 0 || bz[0] != marker {
+		return bz, nil
+	}
+	if len(bz) < 2 {
+		return nil, errors.New("compressed value is missing the algorithm")
+	}
+
+	switch algorithm := Algorithm(bz[1]); algorithm {
+	case Snappy:
+		return snappy.Decode(nil, bz[2:])
+
+	case Gzip:
+		r, err := gzip.NewReader(bytes.NewReader(bz[2:]))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+
+	default:
+		return nil, fmt.Errorf("unknown compression algorithm %v", algorithm)
+	}
+}
+
+// Recompress rewrites all values in the key range [start, end) of the
+// database with the compressor, skipping values that are already stored with
+// its algorithm, and returns the number of values rewritten. Values are
+// rewritten in batches, so an interrupted run leaves the database consistent
+// and can simply be restarted.
+func Recompress(ctx context.Context, db dbm.DB, start, end []byte, c *Compressor) (uint64, error) {
+	var total uint64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		batch := db.NewBatch()
+		rewritten, next, err := recompressBatch(db, batch, start, end, c)
+		if err == nil {
+			err = batch.WriteSync()
+		}
+		if closeErr := batch.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return total, err
+		}
+
+		total += rewritten
+		if next == nil {
+			return total, nil
+		}
+		start = next
+	}
+}
+
+// recompressBatch adds up to recompressBatchSize rewritten values from the key
+// range [start, end) to the batch, and returns the number of values added and
+// the key to continue from, or nil if the end of the range was reached.
+// Writes are deferred to the batch since not all databases support writes
+// while iterating.
+func recompressBatch(db dbm.DB, batch dbm.Batch, start, end []byte, c *Compressor) (uint64, []byte, error) {
+	iter, err := db.Iterator(start, end)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer iter.Close()
+
+	var rewritten uint64
+	for ; iter.Valid(); iter.Next() {
+		if rewritten == recompressBatchSize {
+			return rewritten, append([]byte{}, iter.Key()...), iter.Error()
+		}
+
+		value := iter.Value()
+		if AlgorithmOf(value) == c.Algorithm() {
+			continue
+		}
+		raw, err := Decompress(value)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to decompress value at key %X: %w", iter.Key(), err)
+		}
+		compressed, err := c.Compress(raw)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to compress value at key %X: %w", iter.Key(), err)
+		}
+		if err := batch.Set(append([]byte{}, iter.Key()...), compressed); err != nil {
+			return 0, nil, err
+		}
+		rewritten++
+	}
+	return rewritten, nil, iter.Error()
+}
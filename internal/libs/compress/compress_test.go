@@ -0,0 +1,131 @@
+package compress
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+)
+
+func TestCompressDecompress(t *testing.T) {
+	value := bytes.Repeat([]byte("\x0a\x05hello"), 100)
+
+	testCases := []struct {
+		algorithm string
+		level     int
+		expect    Algorithm
+	}{
+		{"", 0, None},
+		{"none", 0, None},
+		{"snappy", 0, Snappy},
+		{"gzip", 0, Gzip},
+		{"gzip", 9, Gzip},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(fmt.Sprintf("%s/%d", tc.algorithm, tc.level), func(t *testing.T) {
+			c, err := NewCompressor(tc.algorithm, tc.level)
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, c.Algorithm())
+
+			compressed, err := c.Compress(value)
+			require.NoError(t, err)
+			require.Equal(t, tc.expect, AlgorithmOf(compressed))
+			if tc.expect == None {
+				require.Equal(t, value, compressed)
+			} else {
+				require.Less(t, len(compressed), len(value))
+			}
+
+			decompressed, err := Decompress(compressed)
+			require.NoError(t, err)
+			require.Equal(t, value, decompressed)
+		})
+	}
+
+	// A nil compressor doesn't compress.
+	var c *Compressor
+	compressed, err := c.Compress(value)
+	require.NoError(t, err)
+	require.Equal(t, value, compressed)
+}
+
+func TestNewCompressorInvalid(t *testing.T) {
+	_, err := NewCompressor("zip", 0)
+	require.Error(t, err)
+	_, err = NewCompressor("snappy", 1)
+	require.Error(t, err)
+	_, err = NewCompressor("gzip", 10)
+	require.Error(t, err)
+}
+
+func TestDecompressInvalid(t *testing.T) {
+	_, err := Decompress([]byte{marker})
+	require.Error(t, err)
+	_, err = Decompress([]byte{marker, 0xff, 1, 2, 3})
+	require.Error(t, err)
+	_, err = Decompress([]byte{marker, byte(Gzip), 1, 2, 3})
+	require.Error(t, err)
+}
+
+func TestRecompress(t *testing.T) {
+	ctx := context.Background()
+	db := dbm.NewMemDB()
+	key := func(i int) []byte { return []byte(fmt.Sprintf("key%05d", i)) }
+	value := func(i int) []byte { return bytes.Repeat([]byte{0x0a, byte(i)}, 50) }
+
+	// 2500 uncompressed values, with one value outside the range.
+	const n = 2500
+	for i := 0; i < n; i++ {
+		require.NoError(t, db.Set(key(i), value(i)))
+	}
+	require.NoError(t, db.Set([]byte("other"), value(0)))
+
+	snappyCompressor, err := NewCompressor("snappy", 0)
+	require.NoError(t, err)
+	gzipCompressor, err := NewCompressor("gzip", 0)
+	require.NoError(t, err)
+
+	checkValues := func(algorithm Algorithm) {
+		for i := 0; i < n; i++ {
+			bz, err := db.Get(key(i))
+			require.NoError(t, err)
+			require.Equal(t, algorithm, AlgorithmOf(bz))
+			bz, err = Decompress(bz)
+			require.NoError(t, err)
+			require.Equal(t, value(i), bz)
+		}
+	}
+
+	rewritten, err := Recompress(ctx, db, key(0), key(n), snappyCompressor)
+	require.NoError(t, err)
+	require.EqualValues(t, n, rewritten)
+	checkValues(Snappy)
+
+	bz, err := db.Get([]byte("other"))
+	require.NoError(t, err)
+	require.Equal(t, value(0), bz)
+
+	// Values already stored with the algorithm are skipped.
+	rewritten, err = Recompress(ctx, db, key(0), key(n), snappyCompressor)
+	require.NoError(t, err)
+	require.Zero(t, rewritten)
+
+	rewritten, err = Recompress(ctx, db, key(0), key(n), gzipCompressor)
+	require.NoError(t, err)
+	require.EqualValues(t, n, rewritten)
+	checkValues(Gzip)
+
+	rewritten, err = Recompress(ctx, db, key(0), key(n), nil)
+	require.NoError(t, err)
+	require.EqualValues(t, n, rewritten)
+	checkValues(None)
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = Recompress(cctx, db, key(0), key(n), snappyCompressor)
+	require.ErrorIs(t, err, context.Canceled)
+}
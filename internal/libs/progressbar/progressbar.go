@@ -1,6 +1,9 @@
 package progressbar
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // the progressbar indicates the current status and progress would be desired.
 // ref: https://www.pixelstech.net/article/1596946473-A-simple-example-on-implementing-progress-bar-in-GoLang
@@ -12,6 +15,8 @@ type Bar struct {
 	total   int64  // total value for progress
 	rate    string // the actual progress bar to be printed
 	graph   string // the fill value for progress bar
+
+	startTime time.Time // the time the progress started
 }
 
 func (bar *Bar) NewOption(start, total int64) {
@@ -20,6 +25,7 @@ func (bar *Bar) NewOption(start, total int64) {
 	bar.total = total
 	bar.graph = "█"
 	bar.percent = bar.getPercent()
+	bar.startTime = time.Now()
 }
 
 func (bar *Bar) getPercent() int64 {
@@ -33,7 +39,22 @@ func (bar *Bar) Play(cur int64) {
 	if bar.percent != last && bar.percent%2 == 0 {
 		bar.rate += bar.graph
 	}
-	fmt.Printf("\r[%-50s]%3d%% %8d/%d", bar.rate, bar.percent, bar.cur, bar.total)
+	rate, eta := bar.Throughput()
+	fmt.Printf("\r[%-50s]%3d%% %8d/%d %8.1f/s ETA %-10s",
+		bar.rate, bar.percent, bar.cur, bar.total, rate, eta)
+}
+
+// Throughput returns the progress per second since the start, and the
+// estimated time left to reach the total at that rate.
+func (bar *Bar) Throughput() (float64, time.Duration) {
+	elapsed := time.Since(bar.startTime)
+	done := bar.cur - bar.start
+	if done <= 0 || elapsed <= 0 {
+		return 0, 0
+	}
+	rate := float64(done) / elapsed.Seconds()
+	eta := time.Duration(float64(bar.total-bar.cur) / rate * float64(time.Second))
+	return rate, eta.Round(time.Second)
 }
 
 func (bar *Bar) Finish() {
@@ -39,3 +39,19 @@ func TestProgressBar(t *testing.T) {
 
 	require.Equal(t, rate, bar.rate)
 }
+
+func TestProgressBarThroughput(t *testing.T) {
+	var bar Bar
+	bar.NewOption(0, 100)
+
+	rate, eta := bar.Throughput()
+	require.Zero(t, rate)
+	require.Zero(t, eta)
+
+	bar.startTime = time.Now().Add(-10 * time.Second)
+	bar.cur = 20
+
+	rate, eta = bar.Throughput()
+	require.InDelta(t, 2, rate, 0.1)
+	require.InDelta(t, float64(40*time.Second), float64(eta), float64(2*time.Second))
+}
@@ -0,0 +1,381 @@
+package p2p
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// Address book parameters. These mirror the bucketing scheme used by the legacy
+// PEX address book, which in turn is based on Bitcoin's addrman.
+const (
+	// addrBookBucketSize is the maximum number of addresses in a bucket.
+	addrBookBucketSize = 64
+
+	// addrBookNewBucketCount is the number of buckets in the new table, which
+	// contains addresses learned from peers that we have not connected to yet.
+	addrBookNewBucketCount = 256
+
+	// addrBookNewBucketsPerGroup is the number of new buckets that addresses
+	// from a single source group can be placed in. This bounds the number of
+	// addresses any one source can insert into the new table.
+	addrBookNewBucketsPerGroup = 32
+
+	// addrBookTriedBucketCount is the number of buckets in the tried table,
+	// which contains addresses that we have successfully connected to.
+	addrBookTriedBucketCount = 64
+
+	// addrBookTriedBucketsPerGroup is the number of tried buckets that
+	// addresses from a single address group can be placed in.
+	addrBookTriedBucketsPerGroup = 8
+)
+
+// addressBook keeps track of peer addresses learned via PEX, in order to
+// resist eclipse attacks where a malicious peer floods us with addresses it
+// controls. It does not store any peer information itself, that's done by the
+// peerStore; it only decides which learned addresses to keep, and which
+// ones to dial or advertise.
+//
+// Addresses are placed in one of two tables, each split into fixed-size
+// buckets. The new table contains addresses we have not connected to yet,
+// bucketed by the address group of the source that sent them and the
+// address's own group (i.e. /16 for IPv4), such that a single source group can
+// only ever occupy a small number of buckets. The tried table contains
+// addresses we have successfully connected to, bucketed by address group.
+// When a bucket is full, an existing address is evicted to make room.
+//
+// Bucket placement uses a secret random key, such that an attacker can't
+// predict which addresses end up in the same bucket. When selecting addresses,
+// we first pick a random bucket and then a random address within it, such that
+// flooding a few buckets does not increase the chance of being picked.
+//
+// Addresses configured by the operator (added via PeerManager.Add) are not
+// tracked by the address book, and are never evicted by it.
+//
+// The address book is not thread-safe, it's only used by the PeerManager which
+// handles concurrency control.
+type addressBook struct {
+	key        []byte
+	entries    map[NodeAddress]*addressBookEntry
+	newTable   []map[NodeAddress]*addressBookEntry
+	triedTable []map[NodeAddress]*addressBookEntry
+}
+
+// addressBookEntry is an address tracked by the address book.
+type addressBookEntry struct {
+	Address     NodeAddress
+	SourceGroup string
+	Tried       bool
+	Bucket      int
+	Added       time.Time
+	LastSuccess time.Time
+	Failures    uint32
+}
+
+// newAddressBook creates a new address book, using the given secret key for
+// bucket placement.
+func newAddressBook(key []byte) *addressBook {
+	b := &addressBook{
+		key:        key,
+		entries:    map[NodeAddress]*addressBookEntry{},
+		newTable:   make([]map[NodeAddress]*addressBookEntry, addrBookNewBucketCount),
+		triedTable: make([]map[NodeAddress]*addressBookEntry, addrBookTriedBucketCount),
+	}
+	for i := range b.newTable {
+		b.newTable[i] = map[NodeAddress]*addressBookEntry{}
+	}
+	for i := range b.triedTable {
+		b.triedTable[i] = map[NodeAddress]*addressBookEntry{}
+	}
+	return b
+}
+
+// Has returns true if the address is tracked by the address book.
+func (b *addressBook) Has(address NodeAddress) bool {
+	_, ok := b.entries[address]
+	return ok
+}
+
+// Size returns the number of new and tried addresses in the address book.
+func (b *addressBook) Size() (int, int) {
+	tried := 0
+	for _, entry := range b.entries {
+		if entry.Tried {
+			tried++
+		}
+	}
+	return len(b.entries) - tried, tried
+}
+
+// AddNew adds an address learned from a source in the given address group to
+// the new table, unless it's already known. If the address's bucket is full,
+// the worst address in it is evicted and returned.
+func (b *addressBook) AddNew(address NodeAddress, sourceGroup string) (bool, []NodeAddress) {
+	if b.Has(address) {
+		return false, nil
+	}
+	entry := &addressBookEntry{
+		Address:     address,
+		SourceGroup: sourceGroup,
+		Added:       time.Now().UTC(),
+	}
+	return true, b.placeNew(entry)
+}
+
+// AddTried adds an address that we have successfully connected to directly to
+// the tried table, unless it's already known. This is used to restore the
+// address book from the peer store, where we don't know the address source.
+func (b *addressBook) AddTried(address NodeAddress, lastSuccess time.Time) []NodeAddress {
+	if b.Has(address) {
+		return nil
+	}
+	entry := &addressBookEntry{
+		Address: address,
+		Added:   lastSuccess,
+	}
+	return b.placeTried(entry, lastSuccess)
+}
+
+// MarkGood marks a successful connection to an address, moving it to the tried
+// table. If the tried bucket is full, the oldest tried address is moved back to
+// the new table, possibly evicting a new address which is returned.
+func (b *addressBook) MarkGood(address NodeAddress) []NodeAddress {
+	entry, ok := b.entries[address]
+	if !ok {
+		return nil
+	}
+	now := time.Now().UTC()
+	entry.Failures = 0
+	if entry.Tried {
+		entry.LastSuccess = now
+		return nil
+	}
+	delete(b.newTable[entry.Bucket], address)
+	delete(b.entries, address)
+	return b.placeTried(entry, now)
+}
+
+// MarkFailed marks a failed dial attempt to an address, making it more likely
+// to be evicted from the new table.
+func (b *addressBook) MarkFailed(address NodeAddress) {
+	if entry, ok := b.entries[address]; ok {
+		entry.Failures++
+	}
+}
+
+// Remove removes an address from the address book.
+func (b *addressBook) Remove(address NodeAddress) {
+	entry, ok := b.entries[address]
+	if !ok {
+		return
+	}
+	if entry.Tried {
+		delete(b.triedTable[entry.Bucket], address)
+	} else {
+		delete(b.newTable[entry.Bucket], address)
+	}
+	delete(b.entries, address)
+}
+
+// RemovePeer removes all addresses of a peer from the address book.
+func (b *addressBook) RemovePeer(id types.NodeID) {
+	for address := range b.entries {
+		if address.NodeID == id {
+			b.Remove(address)
+		}
+	}
+}
+
+// Sample returns up to limit addresses from the tried or new table that pass
+// the given filter. Addresses are picked from randomly ordered buckets in a
+// round-robin fashion, such that each bucket is equally likely to contribute
+// regardless of how many addresses it contains.
+func (b *addressBook) Sample(
+	tried bool,
+	limit int,
+	filter func(NodeAddress) bool,
+	r *rand.Rand,
+) []NodeAddress {
+	table := b.newTable
+	if tried {
+		table = b.triedTable
+	}
+
+	candidates := make([][]NodeAddress, 0, len(table))
+	for _, bucket := range table {
+		var eligible []NodeAddress
+		for address := range bucket {
+			if filter == nil || filter(address) {
+				eligible = append(eligible, address)
+			}
+		}
+		if len(eligible) > 0 {
+			// Map iteration order is not uniformly random, so we sort and
+			// shuffle explicitly.
+			sort.Slice(eligible, func(i, j int) bool {
+				return eligible[i].String() < eligible[j].String()
+			})
+			r.Shuffle(len(eligible), func(i, j int) {
+				eligible[i], eligible[j] = eligible[j], eligible[i]
+			})
+			candidates = append(candidates, eligible)
+		}
+	}
+	r.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	addresses := []NodeAddress{}
+	for round := 0; len(addresses) < limit; round++ {
+		found := false
+		for _, bucket := range candidates {
+			if round >= len(bucket) {
+				continue
+			}
+			found = true
+			addresses = append(addresses, bucket[round])
+			if len(addresses) >= limit {
+				break
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	return addresses
+}
+
+// Select picks a single address to dial that passes the given filter. If both
+// tables have eligible addresses, either table is picked with equal
+// probability.
+func (b *addressBook) Select(filter func(NodeAddress) bool, r *rand.Rand) (NodeAddress, bool) {
+	tables := []bool{true, false}
+	if r.Intn(2) == 0 {
+		tables = []bool{false, true}
+	}
+	for _, tried := range tables {
+		if addresses := b.Sample(tried, 1, filter, r); len(addresses) > 0 {
+			return addresses[0], true
+		}
+	}
+	return NodeAddress{}, false
+}
+
+// placeNew places an entry in its new bucket, evicting the worst entry in the
+// bucket if it's full. It returns any evicted addresses.
+func (b *addressBook) placeNew(entry *addressBookEntry) []NodeAddress {
+	var evicted []NodeAddress
+	entry.Tried = false
+	entry.Bucket = b.newBucket(entry.Address, entry.SourceGroup)
+	bucket := b.newTable[entry.Bucket]
+	if len(bucket) >= addrBookBucketSize {
+		worst := b.worstNew(bucket)
+		b.Remove(worst.Address)
+		evicted = append(evicted, worst.Address)
+	}
+	bucket[entry.Address] = entry
+	b.entries[entry.Address] = entry
+	return evicted
+}
+
+// placeTried places an entry in its tried bucket. If the bucket is full, the
+// least recently successful entry is moved back to the new table, which may in
+// turn evict an entry from there. It returns any evicted addresses.
+func (b *addressBook) placeTried(entry *addressBookEntry, lastSuccess time.Time) []NodeAddress {
+	var evicted []NodeAddress
+	entry.Tried = true
+	entry.LastSuccess = lastSuccess
+	entry.Bucket = b.triedBucket(entry.Address)
+	bucket := b.triedTable[entry.Bucket]
+	if len(bucket) >= addrBookBucketSize {
+		var oldest *addressBookEntry
+		for _, e := range bucket {
+			if oldest == nil || e.LastSuccess.Before(oldest.LastSuccess) {
+				oldest = e
+			}
+		}
+		b.Remove(oldest.Address)
+		evicted = append(evicted, b.placeNew(oldest)...)
+	}
+	bucket[entry.Address] = entry
+	b.entries[entry.Address] = entry
+	return evicted
+}
+
+// worstNew returns the entry in a new bucket that should be evicted first,
+// i.e. the one with the most dial failures, or the oldest one if tied.
+func (b *addressBook) worstNew(bucket map[NodeAddress]*addressBookEntry) *addressBookEntry {
+	var worst *addressBookEntry
+	for _, e := range bucket {
+		switch {
+		case worst == nil:
+			worst = e
+		case e.Failures > worst.Failures:
+			worst = e
+		case e.Failures == worst.Failures && e.Added.Before(worst.Added):
+			worst = e
+		}
+	}
+	return worst
+}
+
+// newBucket calculates the new bucket for an address learned from a source in
+// the given group. All addresses in the same group from the same source group
+// map to the same bucket, and a source group maps to at most
+// addrBookNewBucketsPerGroup buckets.
+func (b *addressBook) newBucket(address NodeAddress, sourceGroup string) int {
+	group := addressGroup(address)
+	slot := b.hash(group, sourceGroup) % addrBookNewBucketsPerGroup
+	return int(b.hash(sourceGroup, slot) % addrBookNewBucketCount)
+}
+
+// triedBucket calculates the tried bucket for an address. Addresses in the
+// same group map to at most addrBookTriedBucketsPerGroup buckets.
+func (b *addressBook) triedBucket(address NodeAddress) int {
+	slot := b.hash(address.String()) % addrBookTriedBucketsPerGroup
+	return int(b.hash(addressGroup(address), slot) % addrBookTriedBucketCount)
+}
+
+// hash hashes the given strings and integers together with the secret key.
+func (b *addressBook) hash(parts ...interface{}) uint64 {
+	h := sha256.New()
+	_, _ = h.Write(b.key)
+	for _, part := range parts {
+		switch p := part.(type) {
+		case string:
+			_ = binary.Write(h, binary.BigEndian, uint64(len(p)))
+			_, _ = h.Write([]byte(p))
+		case uint64:
+			_ = binary.Write(h, binary.BigEndian, p)
+		default:
+			panic("unsupported hash part")
+		}
+	}
+	return binary.BigEndian.Uint64(h.Sum(nil))
+}
+
+// addressGroup returns the network group of an address, which is used to
+// limit the influence of any single network over the address book. IPv4
+// addresses are grouped by /16 and IPv6 addresses by /32, while hostnames are
+// grouped by the hostname itself. Addresses that are not routable between
+// hosts (e.g. unspecified or loopback IPs, or non-networked addresses such as
+// those of the memory transport) are grouped by protocol and node ID.
+func addressGroup(address NodeAddress) string {
+	ip := net.ParseIP(address.Hostname)
+	switch {
+	case ip != nil && !ip.IsUnspecified() && !ip.IsLoopback():
+		if ip4 := ip.To4(); ip4 != nil {
+			return ip4.Mask(net.CIDRMask(16, 32)).String() + "/16"
+		}
+		return ip.Mask(net.CIDRMask(32, 128)).String() + "/32"
+	case ip == nil && address.Hostname != "":
+		return address.Hostname
+	default:
+		return string(address.Protocol) + ":" + string(address.NodeID)
+	}
+}
@@ -0,0 +1,159 @@
+package p2p
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// makeAddrBookAddress generates a TCP address for the given IP with a unique
+// node ID.
+func makeAddrBookAddress(t *testing.T, i int, ip string) NodeAddress {
+	id, err := types.NewNodeID(fmt.Sprintf("%040x", i))
+	require.NoError(t, err)
+	return NodeAddress{Protocol: "tcp", NodeID: id, Hostname: ip, Port: 26656}
+}
+
+func TestAddressGroup(t *testing.T) {
+	id := types.NodeID("00112233445566778899aabbccddeeff00112233")
+	testcases := []struct {
+		address NodeAddress
+		expect  string
+	}{
+		{NodeAddress{Protocol: "tcp", NodeID: id, Hostname: "1.2.3.4"}, "1.2.0.0/16"},
+		{NodeAddress{Protocol: "tcp", NodeID: id, Hostname: "1.2.255.255"}, "1.2.0.0/16"},
+		{NodeAddress{Protocol: "tcp", NodeID: id, Hostname: "2001:db8:1::1"}, "2001:db8::/32"},
+		{NodeAddress{Protocol: "tcp", NodeID: id, Hostname: "host.domain"}, "host.domain"},
+		{NodeAddress{Protocol: "tcp", NodeID: id, Hostname: "127.0.0.1"}, "tcp:" + string(id)},
+		{NodeAddress{Protocol: "memory", NodeID: id, Hostname: "0.0.0.0"}, "memory:" + string(id)},
+		{NodeAddress{Protocol: "memory", NodeID: id}, "memory:" + string(id)},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.address.String(), func(t *testing.T) {
+			require.Equal(t, tc.expect, addressGroup(tc.address))
+		})
+	}
+}
+
+func TestAddressBook_AddNew_SameGroup(t *testing.T) {
+	book := newAddressBook([]byte("key"))
+
+	// A single source sending addresses from a single group can only fill a
+	// single bucket.
+	evictions := 0
+	for i := 0; i < 1000; i++ {
+		added, evicted := book.AddNew(makeAddrBookAddress(t, i, fmt.Sprintf("1.2.%v.%v", i/256, i%256)), "9.9.0.0/16")
+		require.True(t, added)
+		evictions += len(evicted)
+	}
+	newCount, triedCount := book.Size()
+	require.Equal(t, addrBookBucketSize, newCount)
+	require.Zero(t, triedCount)
+	require.Equal(t, 1000-addrBookBucketSize, evictions)
+
+	// Adding a known address is a noop.
+	added, evicted := book.AddNew(makeAddrBookAddress(t, 999, "1.2.3.231"), "9.9.0.0/16")
+	require.False(t, added)
+	require.Empty(t, evicted)
+}
+
+func TestAddressBook_AddNew_SourceLimit(t *testing.T) {
+	book := newAddressBook([]byte("key"))
+
+	// A single source sending addresses from many groups can only fill
+	// addrBookNewBucketsPerGroup buckets.
+	for i := 0; i < 20000; i++ {
+		book.AddNew(makeAddrBookAddress(t, i, fmt.Sprintf("%v.%v.1.1", i/256, i%256)), "9.9.0.0/16")
+	}
+	newCount, _ := book.Size()
+	require.LessOrEqual(t, newCount, addrBookNewBucketsPerGroup*addrBookBucketSize)
+
+	// An honest source should still be able to add addresses.
+	added, _ := book.AddNew(makeAddrBookAddress(t, 100000, "8.8.8.8"), "8.8.0.0/16")
+	require.True(t, added)
+}
+
+func TestAddressBook_MarkGood(t *testing.T) {
+	book := newAddressBook([]byte("key"))
+	a := makeAddrBookAddress(t, 1, "1.2.3.4")
+	b := makeAddrBookAddress(t, 2, "5.6.7.8")
+
+	added, _ := book.AddNew(a, "9.9.0.0/16")
+	require.True(t, added)
+	added, _ = book.AddNew(b, "9.9.0.0/16")
+	require.True(t, added)
+
+	// Unknown addresses are ignored.
+	require.Empty(t, book.MarkGood(makeAddrBookAddress(t, 3, "1.1.1.1")))
+
+	require.Empty(t, book.MarkGood(a))
+	newCount, triedCount := book.Size()
+	require.Equal(t, 1, newCount)
+	require.Equal(t, 1, triedCount)
+
+	r := rand.New(rand.NewSource(1)) // nolint:gosec
+	require.Equal(t, []NodeAddress{a}, book.Sample(true, 10, nil, r))
+	require.Equal(t, []NodeAddress{b}, book.Sample(false, 10, nil, r))
+
+	book.RemovePeer(a.NodeID)
+	require.False(t, book.Has(a))
+	require.True(t, book.Has(b))
+}
+
+func TestAddressBook_MarkFailed(t *testing.T) {
+	book := newAddressBook([]byte("key"))
+
+	// Fill a bucket, and mark the most recent address as failed. It should
+	// be evicted first.
+	var failed NodeAddress
+	for i := 0; i < addrBookBucketSize; i++ {
+		failed = makeAddrBookAddress(t, i, fmt.Sprintf("1.2.3.%v", i))
+		added, evicted := book.AddNew(failed, "9.9.0.0/16")
+		require.True(t, added)
+		require.Empty(t, evicted)
+	}
+	book.MarkFailed(failed)
+
+	added, evicted := book.AddNew(makeAddrBookAddress(t, 1000, "1.2.4.1"), "9.9.0.0/16")
+	require.True(t, added)
+	require.Equal(t, []NodeAddress{failed}, evicted)
+}
+
+func TestAddressBook_Sample(t *testing.T) {
+	book := newAddressBook([]byte("key"))
+	r := rand.New(rand.NewSource(1)) // nolint:gosec
+
+	// Flood the address book from one source, and add a single address from
+	// another source. The latter should always be sampled, since sampling picks
+	// addresses across buckets.
+	for i := 0; i < 1000; i++ {
+		book.AddNew(makeAddrBookAddress(t, i, fmt.Sprintf("1.2.%v.%v", i/256, i%256)), "9.9.0.0/16")
+	}
+	honest := makeAddrBookAddress(t, 100000, "8.8.8.8")
+	book.AddNew(honest, "8.8.0.0/16")
+
+	// The honest address is either in the same bucket as the flood, or in its
+	// own bucket, in which case it's always in the first two samples.
+	sample := book.Sample(false, 2, nil, r)
+	require.Len(t, sample, 2)
+	if book.entries[honest].Bucket != book.newBucket(makeAddrBookAddress(t, 0, "1.2.0.0"), "9.9.0.0/16") {
+		require.Contains(t, sample, honest)
+	}
+
+	// Filters should be respected.
+	sample = book.Sample(false, 10, func(address NodeAddress) bool {
+		return address == honest
+	}, r)
+	require.Equal(t, []NodeAddress{honest}, sample)
+
+	address, ok := book.Select(func(address NodeAddress) bool {
+		return address == honest
+	}, r)
+	require.True(t, ok)
+	require.Equal(t, honest, address)
+}
@@ -0,0 +1,200 @@
+package p2p
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/internal/libs/autofile"
+	tmos "github.com/tendermint/tendermint/libs/os"
+	"github.com/tendermint/tendermint/types"
+)
+
+const (
+	// CaptureInbound is the capture direction of messages received from a peer.
+	CaptureInbound = "in"
+	// CaptureOutbound is the capture direction of messages sent to a peer.
+	CaptureOutbound = "out"
+
+	// captureFlushInterval is the interval between flushes of the capture file.
+	captureFlushInterval = time.Second
+
+	// maxCaptureLineSize is the maximum size of a single capture record line.
+	maxCaptureLineSize = 64 * 1024 * 1024
+)
+
+// CaptureOptions specifies options for capturing peer messages to disk.
+type CaptureOptions struct {
+	// Path is the path of the capture file. Once the file grows too large it is
+	// rotated, see autofile.Group.
+	Path string
+
+	// Peers restricts the capture to the given peers. If empty, messages from
+	// and to all peers are captured.
+	Peers []types.NodeID
+
+	// Channels restricts the capture to the given channels. If empty, messages
+	// on all channels are captured.
+	Channels []ChannelID
+}
+
+// CaptureRecord is a single captured message. Records are stored as lines of
+// JSON in the capture file.
+type CaptureRecord struct {
+	Time      time.Time    `json:"time"`
+	Peer      types.NodeID `json:"peer"`
+	Channel   ChannelID    `json:"channel"`
+	Direction string       `json:"direction"`
+	// Message is the protobuf-encoded message, as sent on the wire.
+	Message []byte `json:"message"`
+}
+
+// messageCapture captures peer messages to a rotating file.
+type messageCapture struct {
+	group    *autofile.Group
+	peers    map[types.NodeID]bool
+	channels map[ChannelID]bool
+	doneCh   chan struct{}
+
+	mtx    sync.Mutex
+	closed bool
+}
+
+func newMessageCapture(options CaptureOptions) (*messageCapture, error) {
+	if err := tmos.EnsureDir(filepath.Dir(options.Path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create capture directory: %w", err)
+	}
+	group, err := autofile.OpenGroup(options.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open capture file: %w", err)
+	}
+
+	c := &messageCapture{
+		group:    group,
+		peers:    make(map[types.NodeID]bool, len(options.Peers)),
+		channels: make(map[ChannelID]bool, len(options.Channels)),
+		doneCh:   make(chan struct{}),
+	}
+	for _, peerID := range options.Peers {
+		c.peers[peerID] = true
+	}
+	for _, chID := range options.Channels {
+		c.channels[chID] = true
+	}
+	return c, nil
+}
+
+// start starts the capture file group and a routine that periodically flushes
+// captured messages to disk.
+func (c *messageCapture) start() error {
+	if err := c.group.Start(); err != nil {
+		return err
+	}
+	go func() {
+		ticker := time.NewTicker(captureFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.mtx.Lock()
+				if !c.closed {
+					_ = c.group.FlushAndSync()
+				}
+				c.mtx.Unlock()
+			case <-c.doneCh:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// stop flushes and closes the capture file. Messages captured afterwards are
+// discarded.
+func (c *messageCapture) stop() error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	close(c.doneCh)
+
+	err := c.group.FlushAndSync()
+	if stopErr := c.group.Stop(); stopErr != nil && err == nil {
+		err = stopErr
+	}
+	c.group.Close()
+	return err
+}
+
+// capture writes a message to the capture file, if it matches the filters.
+func (c *messageCapture) capture(direction string, peerID types.NodeID, chID ChannelID, bz []byte) error {
+	if len(c.peers) > 0 && !c.peers[peerID] {
+		return nil
+	}
+	if len(c.channels) > 0 && !c.channels[chID] {
+		return nil
+	}
+	line, err := json.Marshal(CaptureRecord{
+		Time:      time.Now().UTC(),
+		Peer:      peerID,
+		Channel:   chID,
+		Direction: direction,
+		Message:   bz,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.closed {
+		return nil
+	}
+	return c.group.WriteLine(string(line))
+}
+
+// ReadCapture reads the capture file at the given path, including any rotated
+// files, calling fn for each record in the order they were captured.
+func ReadCapture(path string, fn func(CaptureRecord) error) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	group, err := autofile.OpenGroup(path)
+	if err != nil {
+		return err
+	}
+	defer group.Close()
+
+	reader, err := group.NewReader(group.MinIndex())
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxCaptureLineSize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record CaptureRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("invalid capture record: %w", err)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
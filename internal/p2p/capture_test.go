@@ -0,0 +1,56 @@
+package p2p
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestMessageCapture(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture", "p2p-capture")
+	a := types.NodeID(strings.Repeat("a", 40))
+	b := types.NodeID(strings.Repeat("b", 40))
+
+	capture, err := newMessageCapture(CaptureOptions{
+		Path:     path,
+		Peers:    []types.NodeID{a},
+		Channels: []ChannelID{1, 2},
+	})
+	require.NoError(t, err)
+	require.NoError(t, capture.start())
+
+	require.NoError(t, capture.capture(CaptureOutbound, a, 1, []byte("foo")))
+	require.NoError(t, capture.capture(CaptureOutbound, b, 1, []byte("filtered peer")))
+	require.NoError(t, capture.capture(CaptureInbound, a, 3, []byte("filtered channel")))
+	require.NoError(t, capture.capture(CaptureInbound, a, 2, []byte("bar")))
+	require.NoError(t, capture.stop())
+
+	// Messages captured after stopping are discarded.
+	require.NoError(t, capture.capture(CaptureInbound, a, 2, []byte("baz")))
+
+	records := []CaptureRecord{}
+	require.NoError(t, ReadCapture(path, func(record CaptureRecord) error {
+		require.False(t, record.Time.IsZero())
+		records = append(records, record)
+		return nil
+	}))
+	require.Len(t, records, 2)
+
+	require.Equal(t, a, records[0].Peer)
+	require.Equal(t, ChannelID(1), records[0].Channel)
+	require.Equal(t, CaptureOutbound, records[0].Direction)
+	require.Equal(t, []byte("foo"), records[0].Message)
+
+	require.Equal(t, a, records[1].Peer)
+	require.Equal(t, ChannelID(2), records[1].Channel)
+	require.Equal(t, CaptureInbound, records[1].Direction)
+	require.Equal(t, []byte("bar"), records[1].Message)
+
+	require.Error(t, ReadCapture(filepath.Join(t.TempDir(), "missing"), func(CaptureRecord) error {
+		return nil
+	}))
+}
@@ -109,6 +109,9 @@ type MConnection struct {
 
 	chStatsTimer *time.Ticker // update channel stats periodically
 
+	pingSent int64 // unix nanoseconds at which the outstanding ping was sent, atomic.
+	rtt      int64 // smoothed ping round-trip time in nanoseconds, atomic.
+
 	created time.Time // time of creation
 
 	_maxPacketMsgSize int
@@ -361,6 +364,7 @@ FOR_LOOP:
 				break SELECTION
 			}
 			c.sendMonitor.Update(_n)
+			atomic.StoreInt64(&c.pingSent, time.Now().UnixNano())
 			c.Logger.Debug("Starting pong timer", "dur", c.config.PongTimeout)
 			c.pongTimer = time.AfterFunc(c.config.PongTimeout, func() {
 				select {
@@ -534,6 +538,7 @@ FOR_LOOP:
 			}
 		case *tmp2p.Packet_PacketPong:
 			c.Logger.Debug("Receive Pong")
+			c.updateRoundTripTime()
 			select {
 			case c.pongTimeoutCh <- false:
 			default:
@@ -577,6 +582,26 @@ FOR_LOOP:
 	}
 }
 
+// updateRoundTripTime updates the smoothed round-trip time estimate using the
+// outstanding ping, if any. It is only called from recvRoutine.
+func (c *MConnection) updateRoundTripTime() {
+	sent := atomic.SwapInt64(&c.pingSent, 0)
+	if sent == 0 {
+		return
+	}
+	sample := time.Now().UnixNano() - sent
+	if rtt := atomic.LoadInt64(&c.rtt); rtt > 0 {
+		sample = (7*rtt + sample) / 8
+	}
+	atomic.StoreInt64(&c.rtt, sample)
+}
+
+// RoundTripTime returns a smoothed estimate of the round-trip time to the
+// peer, measured using pings. It returns 0 until the first pong is received.
+func (c *MConnection) RoundTripTime() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.rtt))
+}
+
 // not goroutine-safe
 func (c *MConnection) stopPongTimer() {
 	if c.pongTimer != nil {
@@ -336,6 +336,31 @@ func TestMConnectionPingPongs(t *testing.T) {
 	}
 }
 
+func TestMConnectionRoundTripTime(t *testing.T) {
+	server, client := net.Pipe()
+	t.Cleanup(closeAll(t, client, server))
+
+	mconn := createTestMConnection(client)
+	err := mconn.Start()
+	require.Nil(t, err)
+	t.Cleanup(stopAll(t, mconn))
+	require.Zero(t, mconn.RoundTripTime())
+
+	go func() {
+		var pkt tmp2p.Packet
+		_, err := protoio.NewDelimitedReader(server, maxPingPongPacketSize).ReadMsg(&pkt)
+		require.NoError(t, err)
+
+		time.Sleep(20 * time.Millisecond)
+		_, err = protoio.NewDelimitedWriter(server).WriteMsg(mustWrapPacket(&tmp2p.PacketPong{}))
+		require.NoError(t, err)
+	}()
+
+	require.Eventually(t, func() bool {
+		return mconn.RoundTripTime() >= 20*time.Millisecond
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
 func TestMConnectionStopsAndReturnsError(t *testing.T) {
 	server, client := NetPipe()
 	t.Cleanup(closeAll(t, client, server))
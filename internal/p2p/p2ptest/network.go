@@ -32,6 +32,14 @@ type NetworkOptions struct {
 	NumNodes   int
 	BufferSize int
 	NodeOpts   NodeOptions
+
+	// LinkConditions are the default conditions for all links in the
+	// network. They can be changed at runtime via SetLinkConditions.
+	LinkConditions p2p.MemoryLinkConditions
+
+	// Seed seeds the random source used for jitter and message loss. If 0,
+	// the network is seeded randomly.
+	Seed int64
 }
 
 type NodeOptions struct {
@@ -55,6 +63,10 @@ func MakeNetwork(t *testing.T, opts NetworkOptions) *Network {
 		logger:        logger,
 		memoryNetwork: p2p.NewMemoryNetwork(logger, opts.BufferSize),
 	}
+	if opts.Seed != 0 {
+		network.memoryNetwork.SetSeed(opts.Seed)
+	}
+	require.NoError(t, network.memoryNetwork.SetDefaultLinkConditions(opts.LinkConditions))
 
 	for i := 0; i < opts.NumNodes; i++ {
 		node := network.MakeNode(t, opts.NodeOpts)
@@ -159,6 +171,35 @@ func (n *Network) MakeChannelsNoCleanup(
 	return channels
 }
 
+// SetLinkConditions sets the conditions of the links between two nodes, in
+// both directions.
+func (n *Network) SetLinkConditions(
+	t *testing.T,
+	a, b types.NodeID,
+	conditions p2p.MemoryLinkConditions,
+) {
+	require.NoError(t, n.memoryNetwork.SetLinkConditions(a, b, conditions))
+	require.NoError(t, n.memoryNetwork.SetLinkConditions(b, a, conditions))
+}
+
+// SetDefaultLinkConditions sets the conditions of all links that don't have
+// explicit conditions set via SetLinkConditions.
+func (n *Network) SetDefaultLinkConditions(t *testing.T, conditions p2p.MemoryLinkConditions) {
+	require.NoError(t, n.memoryNetwork.SetDefaultLinkConditions(conditions))
+}
+
+// Partition splits the network into the given groups of nodes, which can't
+// communicate with each other until the partition is healed. Nodes not listed
+// in any group form a group of their own.
+func (n *Network) Partition(groups ...[]types.NodeID) {
+	n.memoryNetwork.Partition(groups...)
+}
+
+// Heal removes any network partition.
+func (n *Network) Heal() {
+	n.memoryNetwork.Heal()
+}
+
 // RandomNode returns a random node.
 func (n *Network) RandomNode() *Node {
 	nodes := make([]*Node, 0, len(n.Nodes))
@@ -0,0 +1,136 @@
+package p2p
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// PeerStats contains connection and traffic statistics for a connected peer,
+// as tracked by the Router.
+type PeerStats struct {
+	NodeID types.NodeID
+
+	// Protocol is the transport protocol of the peer connection.
+	Protocol Protocol
+
+	// ConnectedAt is the time at which the peer was connected.
+	ConnectedAt time.Time
+
+	// Latency is an estimate of the round-trip time to the peer, or 0 if the
+	// transport does not provide one (yet).
+	Latency time.Duration
+
+	// QueueDepth is the number of outbound messages waiting to be sent.
+	QueueDepth int
+
+	// Channels contains per-channel traffic statistics, ordered by channel ID.
+	Channels []ChannelStats
+}
+
+// ChannelStats contains traffic statistics for a single channel of a peer.
+type ChannelStats struct {
+	ChannelID        ChannelID
+	MessagesSent     uint64
+	BytesSent        uint64
+	MessagesReceived uint64
+	BytesReceived    uint64
+
+	// MessagesDropped is the number of messages dropped in either direction,
+	// e.g. due to a full send queue or an undecodable inbound message.
+	MessagesDropped uint64
+}
+
+// rttEstimator is implemented by connections that can estimate the round-trip
+// time to the peer.
+type rttEstimator interface {
+	RoundTripTime() time.Duration
+}
+
+// peerTraffic tracks traffic statistics for a connected peer.
+type peerTraffic struct {
+	conn        Connection
+	queue       queue
+	connectedAt time.Time
+
+	mtx      sync.Mutex
+	channels map[ChannelID]*ChannelStats
+}
+
+func newPeerTraffic(conn Connection, queue queue) *peerTraffic {
+	return &peerTraffic{
+		conn:        conn,
+		queue:       queue,
+		connectedAt: time.Now().UTC(),
+		channels:    map[ChannelID]*ChannelStats{},
+	}
+}
+
+// channel returns the stats for the given channel. The caller must hold mtx.
+func (t *peerTraffic) channel(chID ChannelID) *ChannelStats {
+	stats, ok := t.channels[chID]
+	if !ok {
+		stats = &ChannelStats{ChannelID: chID}
+		t.channels[chID] = stats
+	}
+	return stats
+}
+
+// sent records a message sent to the peer.
+func (t *peerTraffic) sent(chID ChannelID, size int) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	stats := t.channel(chID)
+	stats.MessagesSent++
+	stats.BytesSent += uint64(size)
+}
+
+// received records a message received from the peer.
+func (t *peerTraffic) received(chID ChannelID, size int) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	stats := t.channel(chID)
+	stats.MessagesReceived++
+	stats.BytesReceived += uint64(size)
+}
+
+// dropped records a message from the peer that was dropped.
+func (t *peerTraffic) dropped(chID ChannelID) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.channel(chID).MessagesDropped++
+}
+
+// stats returns a snapshot of the peer's statistics.
+func (t *peerTraffic) stats(peerID types.NodeID) PeerStats {
+	queueDropped := t.queue.dropped()
+
+	stats := PeerStats{
+		NodeID:      peerID,
+		Protocol:    t.conn.LocalEndpoint().Protocol,
+		ConnectedAt: t.connectedAt,
+		QueueDepth:  t.queue.len(),
+	}
+	if estimator, ok := t.conn.(rttEstimator); ok {
+		stats.Latency = estimator.RoundTripTime()
+	}
+
+	t.mtx.Lock()
+	for chID := range queueDropped {
+		t.channel(chID)
+	}
+	stats.Channels = make([]ChannelStats, 0, len(t.channels))
+	for chID, chStats := range t.channels {
+		chStats := *chStats
+		chStats.MessagesDropped += queueDropped[chID]
+		stats.Channels = append(stats.Channels, chStats)
+	}
+	t.mtx.Unlock()
+
+	sort.Slice(stats.Channels, func(i, j int) bool {
+		return stats.Channels[i].ChannelID < stats.Channels[j].ChannelID
+	})
+	return stats
+}
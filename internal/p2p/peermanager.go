@@ -14,6 +14,7 @@ import (
 	"github.com/google/orderedcode"
 	dbm "github.com/tendermint/tm-db"
 
+	"github.com/tendermint/tendermint/crypto"
 	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
 	p2pproto "github.com/tendermint/tendermint/proto/tendermint/p2p"
 	"github.com/tendermint/tendermint/types"
@@ -234,6 +235,11 @@ func (o *PeerManagerOptions) optimize() {
 // higher-scored peers. It does not manage actual connections (this is handled
 // by the Router), only the peer lifecycle state.
 //
+// Addresses learned from other peers via AddFromPeer() are tracked in a
+// bucketed addressBook, which limits how many addresses any single source can
+// insert and decides which of them to dial and advertise, such that a single
+// malicious peer can't flood us with its own addresses.
+//
 // For an outbound connection, the flow is as follows:
 // - DialNext: return a peer address to dial, mark peer as dialing.
 // - DialFailed: report a dial failure, unmark as dialing.
@@ -281,6 +287,7 @@ type PeerManager struct {
 
 	mtx           sync.Mutex
 	store         *peerStore
+	addrBook      *addressBook
 	subscriptions map[*PeerUpdates]*PeerUpdates // keyed by struct identity (address)
 	dialing       map[types.NodeID]bool         // peers being dialed (DialNext → Dialed/DialFail)
 	upgrading     map[types.NodeID]types.NodeID // peers claimed for upgrade (DialNext → Dialed/DialFail)
@@ -315,6 +322,7 @@ func NewPeerManager(selfID types.NodeID, peerDB dbm.DB, options PeerManagerOptio
 		closeCh:    make(chan struct{}),
 
 		store:         store,
+		addrBook:      newAddressBook(crypto.CRandBytes(32)),
 		dialing:       map[types.NodeID]bool{},
 		upgrading:     map[types.NodeID]types.NodeID{},
 		connected:     map[types.NodeID]bool{},
@@ -326,6 +334,9 @@ func NewPeerManager(selfID types.NodeID, peerDB dbm.DB, options PeerManagerOptio
 	if err = peerManager.configurePeers(); err != nil {
 		return nil, err
 	}
+	if err = peerManager.loadAddressBook(); err != nil {
+		return nil, err
+	}
 	if err = peerManager.prunePeers(); err != nil {
 		return nil, err
 	}
@@ -357,6 +368,75 @@ func (m *PeerManager) configurePeers() error {
 	return nil
 }
 
+// loadAddressBook populates the address book with the addresses of
+// non-persistent peers in the peer store. Since the address sources aren't
+// persisted, previously dialed addresses are placed in the tried table and
+// others are placed in the new table with an unknown source. Addresses that are
+// later added via Add() are removed from the address book again. The caller
+// must hold the mutex lock.
+func (m *PeerManager) loadAddressBook() error {
+	for _, peer := range m.store.List() {
+		if peer.Persistent {
+			continue
+		}
+		for address, addressInfo := range peer.AddressInfo {
+			var evicted []NodeAddress
+			if !addressInfo.LastDialSuccess.IsZero() {
+				evicted = m.addrBook.AddTried(address, addressInfo.LastDialSuccess)
+			} else {
+				_, evicted = m.addrBook.AddNew(address, "")
+			}
+			if err := m.removeAddresses(evicted); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// removeAddresses removes addresses evicted from the address book from the
+// peer store. Peers without any remaining addresses are deleted, unless they're
+// connected or being dialed. The caller must hold the mutex lock.
+func (m *PeerManager) removeAddresses(addresses []NodeAddress) error {
+	for _, address := range addresses {
+		peer, ok := m.store.Get(address.NodeID)
+		if !ok {
+			continue
+		}
+		delete(peer.AddressInfo, address)
+		if len(peer.AddressInfo) == 0 && !peer.Persistent &&
+			!m.connected[peer.ID] && !m.dialing[peer.ID] {
+			if err := m.store.Delete(peer.ID); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := m.store.Set(peer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sourceGroup returns the address group of a peer that sent us addresses, used
+// for address book bucketing. If we don't know any of the peer's addresses,
+// e.g. for an inbound peer, the peer's node ID is used instead. The caller must
+// hold the mutex lock.
+func (m *PeerManager) sourceGroup(source types.NodeID) string {
+	peer, ok := m.store.Get(source)
+	if !ok || len(peer.AddressInfo) == 0 {
+		return "id:" + string(source)
+	}
+	// Pick the lowest address, to make the group deterministic.
+	var lowest NodeAddress
+	for address := range peer.AddressInfo {
+		if lowest.NodeID == "" || address.String() < lowest.String() {
+			lowest = address
+		}
+	}
+	return addressGroup(lowest)
+}
+
 // configurePeer configures a peer with ephemeral runtime configuration.
 func (m *PeerManager) configurePeer(peer peerInfo) peerInfo {
 	peer.Persistent = m.options.isPersistent(peer.ID)
@@ -392,6 +472,7 @@ func (m *PeerManager) prunePeers() error {
 			if err := m.store.Delete(peerID); err != nil {
 				return err
 			}
+			m.addrBook.RemovePeer(peerID)
 		}
 	}
 	return nil
@@ -400,6 +481,10 @@ func (m *PeerManager) prunePeers() error {
 // Add adds a peer to the manager, given as an address. If the peer already
 // exists, the address is added to it if it isn't already present. This will push
 // low scoring peers out of the address book if it exceeds the maximum size.
+//
+// Addresses added this way are considered trusted (e.g. configured by the
+// operator), and are not subject to the address book limits. Addresses learned
+// from other peers should be added via AddFromPeer() instead.
 func (m *PeerManager) Add(address NodeAddress) (bool, error) {
 	if err := address.Validate(); err != nil {
 		return false, err
@@ -411,6 +496,8 @@ func (m *PeerManager) Add(address NodeAddress) (bool, error) {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
+	m.addrBook.Remove(address)
+
 	peer, ok := m.store.Get(address.NodeID)
 	if !ok {
 		peer = m.newPeerInfo(address.NodeID)
@@ -433,6 +520,58 @@ func (m *PeerManager) Add(address NodeAddress) (bool, error) {
 	return true, nil
 }
 
+// AddFromPeer adds a peer address learned from the given source peer, e.g. via
+// PEX. The address is placed in the address book, which limits the number of
+// addresses any single source can insert, possibly evicting other learned
+// addresses. Returns true if the address was new.
+func (m *PeerManager) AddFromPeer(source types.NodeID, address NodeAddress) (bool, error) {
+	if err := address.Validate(); err != nil {
+		return false, err
+	}
+	if address.NodeID == m.selfID {
+		return false, fmt.Errorf("can't add self (%v) to peer store", m.selfID)
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	peer, ok := m.store.Get(address.NodeID)
+	if !ok {
+		peer = m.newPeerInfo(address.NodeID)
+	}
+	if _, ok = peer.AddressInfo[address]; ok {
+		return false, nil
+	}
+
+	added, evicted := m.addrBook.AddNew(address, m.sourceGroup(source))
+	if !added {
+		return false, nil
+	}
+	peer.AddressInfo[address] = &peerAddressInfo{Address: address}
+	if err := m.store.Set(peer); err != nil {
+		return false, err
+	}
+	if err := m.removeAddresses(evicted); err != nil {
+		return true, err
+	}
+	if err := m.prunePeers(); err != nil {
+		return true, err
+	}
+	m.dialWaker.Wake()
+	return true, nil
+}
+
+// RemoveAddress removes a peer address, e.g. because it has been found to be
+// unreachable. If the peer has no remaining addresses, it is removed entirely,
+// unless it's persistent, connected or being dialed.
+func (m *PeerManager) RemoveAddress(address NodeAddress) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.addrBook.Remove(address)
+	return m.removeAddresses([]NodeAddress{address})
+}
+
 // PeerRatio returns the ratio of peer addresses stored to the maximum size.
 func (m *PeerManager) PeerRatio() float64 {
 	m.mtx.Lock()
@@ -483,6 +622,11 @@ func (m *PeerManager) TryDialNext() (NodeAddress, error) {
 		}
 
 		for _, addressInfo := range peer.AddressInfo {
+			// Learned addresses of unscored peers are drawn from the
+			// address book below.
+			if peer.Score() == 0 && m.addrBook.Has(addressInfo.Address) {
+				continue
+			}
 			if time.Since(addressInfo.LastDialFailure) < m.retryDelay(addressInfo.DialFailures, peer.Persistent) {
 				continue
 			}
@@ -506,6 +650,28 @@ func (m *PeerManager) TryDialNext() (NodeAddress, error) {
 			return addressInfo.Address, nil
 		}
 	}
+
+	// Finally, pick a random learned address from the address book. Since
+	// these peers all have the lowest score, there's no point in upgrading.
+	if m.options.MaxConnected > 0 && len(m.connected) >= int(m.options.MaxConnected) {
+		return NodeAddress{}, nil
+	}
+	address, ok := m.addrBook.Select(func(address NodeAddress) bool {
+		if m.dialing[address.NodeID] || m.connected[address.NodeID] {
+			return false
+		}
+		peer, ok := m.store.peers[address.NodeID]
+		if !ok || peer.Score() > 0 {
+			return false
+		}
+		addressInfo, ok := peer.AddressInfo[address]
+		return ok && time.Since(addressInfo.LastDialFailure) >=
+			m.retryDelay(addressInfo.DialFailures, peer.Persistent)
+	}, m.rand)
+	if ok {
+		m.dialing[address.NodeID] = true
+		return address, nil
+	}
 	return NodeAddress{}, nil
 }
 
@@ -537,6 +703,7 @@ func (m *PeerManager) DialFailed(address NodeAddress) error {
 	if err := m.store.Set(peer); err != nil {
 		return err
 	}
+	m.addrBook.MarkFailed(address)
 
 	// We spawn a goroutine that notifies DialNext() again when the retry
 	// timeout has elapsed, so that we can consider dialing it again. We
@@ -605,6 +772,9 @@ func (m *PeerManager) Dialed(address NodeAddress) error {
 	if err := m.store.Set(peer); err != nil {
 		return err
 	}
+	if err := m.removeAddresses(m.addrBook.MarkGood(address)); err != nil {
+		return err
+	}
 
 	if upgradeFromPeer != "" && m.options.MaxConnected > 0 &&
 		len(m.connected) >= int(m.options.MaxConnected) {
@@ -798,8 +968,9 @@ func (m *PeerManager) Errored(peerID types.NodeID, err error) {
 
 // Advertise returns a list of peer addresses to advertise to a peer.
 //
-// FIXME: This is fairly naïve and only returns the addresses of the
-// highest-ranked peers.
+// Addresses of scored peers and trusted addresses are returned first, in rank
+// order. Any remaining slots are filled with learned addresses from the
+// address book, preferring tried addresses and spread across buckets.
 func (m *PeerManager) Advertise(peerID types.NodeID, limit uint16) []NodeAddress {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
@@ -814,6 +985,9 @@ func (m *PeerManager) Advertise(peerID types.NodeID, limit uint16) []NodeAddress
 			if len(addresses) >= int(limit) {
 				return addresses
 			}
+			if peer.Score() == 0 && m.addrBook.Has(nodeAddr) {
+				continue
+			}
 
 			// only add non-private NodeIDs
 			if _, ok := m.options.PrivatePeers[nodeAddr.NodeID]; !ok {
@@ -822,6 +996,20 @@ func (m *PeerManager) Advertise(peerID types.NodeID, limit uint16) []NodeAddress
 		}
 	}
 
+	for _, tried := range []bool{true, false} {
+		addresses = append(addresses, m.addrBook.Sample(tried, int(limit)-len(addresses),
+			func(address NodeAddress) bool {
+				if address.NodeID == peerID {
+					return false
+				}
+				if _, ok := m.options.PrivatePeers[address.NodeID]; ok {
+					return false
+				}
+				peer, ok := m.store.peers[address.NodeID]
+				return ok && peer.Score() == 0
+			}, m.rand)...)
+	}
+
 	return addresses
 }
 
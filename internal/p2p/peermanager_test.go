@@ -3,6 +3,7 @@ package p2p_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -272,6 +273,64 @@ func TestPeerManager_Add(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestPeerManager_AddFromPeer(t *testing.T) {
+	sourceID := types.NodeID(strings.Repeat("a", 40))
+	source := p2p.NodeAddress{Protocol: "tcp", NodeID: sourceID, Hostname: "9.9.9.9", Port: 26656}
+	trustedID := types.NodeID(strings.Repeat("b", 40))
+	trusted := p2p.NodeAddress{Protocol: "tcp", NodeID: trustedID, Hostname: "1.2.3.4", Port: 26656}
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	defer peerManager.Close()
+
+	added, err := peerManager.Add(source)
+	require.NoError(t, err)
+	require.True(t, added)
+	added, err = peerManager.Add(trusted)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	// Adding ourself or a known address should fail or be a noop.
+	_, err = peerManager.AddFromPeer(sourceID, p2p.NodeAddress{Protocol: "memory", NodeID: selfID})
+	require.Error(t, err)
+	added, err = peerManager.AddFromPeer(sourceID, trusted)
+	require.NoError(t, err)
+	require.False(t, added)
+
+	// The source floods us with addresses in the same /16 as the trusted
+	// address, but only a single bucket's worth should be kept, and the
+	// trusted address should never be evicted.
+	for i := 0; i < 1000; i++ {
+		id, err := types.NewNodeID(fmt.Sprintf("%040x", i))
+		require.NoError(t, err)
+		_, err = peerManager.AddFromPeer(sourceID, p2p.NodeAddress{
+			Protocol: "tcp", NodeID: id, Hostname: fmt.Sprintf("1.2.%v.%v", i/256, i%256), Port: 26656,
+		})
+		require.NoError(t, err)
+	}
+	require.Len(t, peerManager.Peers(), 2+64)
+	require.Equal(t, []p2p.NodeAddress{trusted}, peerManager.Addresses(trustedID))
+
+	// Trusted addresses are dialed before learned ones.
+	dial, err := peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Contains(t, []p2p.NodeAddress{source, trusted}, dial)
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Contains(t, []p2p.NodeAddress{source, trusted}, dial)
+
+	// Then learned addresses are dialed.
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.NotZero(t, dial)
+	require.NotEqual(t, sourceID, dial.NodeID)
+	require.NotEqual(t, trustedID, dial.NodeID)
+	require.NoError(t, peerManager.Dialed(dial))
+
+	// Advertised addresses should include the learned ones.
+	require.Len(t, peerManager.Advertise(sourceID, 100), 1+64)
+}
+
 func TestPeerManager_DialNext(t *testing.T) {
 	a := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("a", 40))}
 
@@ -25,5 +25,11 @@ With each inbound set of addresses, the reactor monitors the amount of new
 addresses to already seen addresses and uses the information to dynamically
 build a picture of the size of the network in order to ascertain how often the
 node needs to search for new peers.
+
+In seed mode (see WithSeedMode), the reactor additionally crawls the addresses
+it knows about by periodically dialing them and verifying that they are
+reachable and compatible with our chain and version, dropping addresses that
+repeatedly fail verification. Only recently verified addresses are handed out
+to requesters.
 */
 package pex
@@ -52,6 +52,16 @@ const (
 	fullCapacityInterval = 10 * time.Minute
 )
 
+// ReactorOption configures the PEX reactor.
+type ReactorOption func(*Reactor)
+
+// WithMinReceiveRequestInterval sets the minimum time a peer must wait
+// between two requests, or the reactor reports it as an error. It defaults to
+// 100ms, and 0 allows any number of requests.
+func WithMinReceiveRequestInterval(interval time.Duration) ReactorOption {
+	return func(r *Reactor) { r.minReceiveInterval = interval }
+}
+
 // TODO: We should decide whether we want channel descriptors to be housed
 // within each reactor (as they are now) or, considering that the reactor doesn't
 // really need to care about the channel descriptors, if they should be housed
@@ -96,8 +106,11 @@ type Reactor struct {
 
 	// lastReceivedRequests keeps track of when peers send a request to prevent
 	// peers from sending requests too often (as defined by
-	// minReceiveRequestInterval).
+	// minReceiveInterval).
 	lastReceivedRequests map[types.NodeID]time.Time
+	// minReceiveInterval is the minimum time a peer must wait between two
+	// requests, minReceiveRequestInterval unless configured otherwise.
+	minReceiveInterval time.Duration
 
 	// the time when another request will be sent
 	nextRequestTime time.Time
@@ -111,6 +124,9 @@ type Reactor struct {
 	// This is multiplied by the minimum duration to calculate how long to wait
 	// between each request.
 	discoveryRatio float32
+
+	// seed is the seed crawler, if running in seed mode.
+	seed *seedCrawler
 }
 
 // NewReactor returns a reference to a new reactor.
@@ -119,6 +135,7 @@ func NewReactor(
 	peerManager *p2p.PeerManager,
 	pexCh *p2p.Channel,
 	peerUpdates *p2p.PeerUpdates,
+	options ...ReactorOption,
 ) *Reactor {
 
 	r := &Reactor{
@@ -129,6 +146,11 @@ func NewReactor(
 		availablePeers:       make(map[types.NodeID]struct{}),
 		requestsSent:         make(map[types.NodeID]struct{}),
 		lastReceivedRequests: make(map[types.NodeID]time.Time),
+		minReceiveInterval:   minReceiveRequestInterval,
+	}
+
+	for _, option := range options {
+		option(r)
 	}
 
 	r.BaseService = *service.NewBaseService(logger, "PEX", r)
@@ -142,6 +164,10 @@ func NewReactor(
 func (r *Reactor) OnStart() error {
 	go r.processPexCh()
 	go r.processPeerUpdates()
+	if r.seed != nil {
+		r.seed.logger = r.Logger.With("module", "seed")
+		go r.seed.run(r.closeCh)
+	}
 	return nil
 }
 
@@ -157,6 +183,9 @@ func (r *Reactor) OnStop() {
 	// panics will occur.
 	<-r.pexCh.Done()
 	<-r.peerUpdates.Done()
+	if r.seed != nil {
+		<-r.seed.doneCh
+	}
 }
 
 // processPexCh implements a blocking event loop where we listen for p2p
@@ -218,9 +247,14 @@ func (r *Reactor) handlePexMessage(envelope p2p.Envelope) error {
 			return err
 		}
 
-		// request peers from the peer manager and parse the NodeAddresses into
-		// URL strings
-		nodeAddresses := r.peerManager.Advertise(envelope.From, maxAddresses)
+		// request peers from the peer manager (or only verified ones if we're
+		// a seed) and parse the NodeAddresses into URL strings
+		var nodeAddresses []p2p.NodeAddress
+		if r.seed != nil {
+			nodeAddresses = r.seed.advertise(envelope.From, maxAddresses)
+		} else {
+			nodeAddresses = r.peerManager.Advertise(envelope.From, maxAddresses)
+		}
 		pexAddresses := make([]protop2p.PexAddress, len(nodeAddresses))
 		for idx, addr := range nodeAddresses {
 			pexAddresses[idx] = protop2p.PexAddress{
@@ -251,7 +285,7 @@ func (r *Reactor) handlePexMessage(envelope p2p.Envelope) error {
 			if err != nil {
 				continue
 			}
-			added, err := r.peerManager.Add(peerAddress)
+			added, err := r.peerManager.AddFromPeer(envelope.From, peerAddress)
 			if err != nil {
 				logger.Error("failed to add PEX address", "address", peerAddress, "err", err)
 			}
@@ -405,9 +439,9 @@ func (r *Reactor) markPeerRequest(peer types.NodeID) error {
 	r.mtx.Lock()
 	defer r.mtx.Unlock()
 	if lastRequestTime, ok := r.lastReceivedRequests[peer]; ok {
-		if time.Now().Before(lastRequestTime.Add(minReceiveRequestInterval)) {
+		if time.Now().Before(lastRequestTime.Add(r.minReceiveInterval)) {
 			return fmt.Errorf("peer sent a request too close after a prior one. Minimum interval: %v",
-				minReceiveRequestInterval)
+				r.minReceiveInterval)
 		}
 	}
 	r.lastReceivedRequests[peer] = time.Now()
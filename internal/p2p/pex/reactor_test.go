@@ -237,6 +237,36 @@ func TestReactorWithNetworkGrowth(t *testing.T) {
 	}
 }
 
+func TestReactorSeedMode(t *testing.T) {
+	testNet := setupNetwork(t, testOptions{
+		MockNodes:  2,
+		TotalNodes: 3,
+		SeedMode:   true,
+	})
+	testNet.connectPeers(t, firstNode, thirdNode)
+
+	// the seed learns about the second node, which is reachable, and a dead
+	// node which is not
+	testNet.addAddresses(t, thirdNode, []int{secondNode})
+	seedManager := testNet.network.Nodes[testNet.nodes[thirdNode]].PeerManager
+	deadAddress := p2p.NodeAddress{Protocol: p2p.MemoryProtocol, NodeID: randomNodeID(t)}
+	added, err := seedManager.Add(deadAddress)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	testNet.start(t)
+
+	// the dead address should be dropped by the crawler
+	require.Eventually(t, func() bool {
+		return len(seedManager.Addresses(deadAddress.NodeID)) == 0
+	}, shortWait, checkFrequency)
+
+	// the seed should only hand out the verified address of the second node
+	testNet.pingAndlistenForNAddresses(t, thirdNode, firstNode, shortWait, 1)
+	testNet.sendRequest(t, firstNode, thirdNode)
+	testNet.listenForResponse(t, thirdNode, firstNode, shortWait, testNet.getAddressesFor([]int{secondNode}))
+}
+
 type singleTestReactor struct {
 	reactor  *pex.Reactor
 	pexInCh  chan p2p.Envelope
@@ -311,6 +341,7 @@ type testOptions struct {
 	BufferSize   int
 	MaxPeers     uint16
 	MaxConnected uint16
+	SeedMode     bool
 }
 
 // setup setups a test suite with a network of nodes. Mocknodes represent the
@@ -358,11 +389,26 @@ func setupNetwork(t *testing.T, opts testOptions) *reactorTestSuite {
 		if idx < opts.MockNodes {
 			rts.mocks = append(rts.mocks, nodeID)
 		} else {
+			var options []pex.ReactorOption
+			if opts.SeedMode {
+				// The seed's addresses are requested repeatedly by the tests.
+				options = append(options, pex.WithMinReceiveRequestInterval(0))
+				options = append(options, pex.WithSeedMode(rts.network.Nodes[nodeID].Router, pex.SeedOptions{
+					CrawlInterval:       100 * time.Millisecond,
+					RecheckInterval:     time.Second,
+					MaxVerifiedAge:      time.Minute,
+					MaxFailures:         2,
+					MaxProbesPerCrawl:   10,
+					MaxConcurrentProbes: 2,
+					ProbeTimeout:        time.Second,
+				}))
+			}
 			rts.reactors[nodeID] = pex.NewReactor(
 				rts.logger.With("nodeID", nodeID),
 				rts.network.Nodes[nodeID].PeerManager,
 				rts.pexChannels[nodeID],
 				rts.peerUpdates[nodeID],
+				options...,
 			)
 		}
 		rts.nodes = append(rts.nodes, nodeID)
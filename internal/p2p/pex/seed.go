@@ -0,0 +1,258 @@
+package pex
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+// PeerProber verifies that a peer address is reachable and compatible with us,
+// returning the peer's node info. It is implemented by p2p.Router.
+type PeerProber interface {
+	ProbePeer(ctx context.Context, address p2p.NodeAddress) (types.NodeInfo, error)
+}
+
+// SeedOptions specifies options for the seed crawler.
+type SeedOptions struct {
+	// CrawlInterval is the interval between crawls of known addresses.
+	CrawlInterval time.Duration
+
+	// RecheckInterval is the minimum time between verifications of the same
+	// address.
+	RecheckInterval time.Duration
+
+	// MaxVerifiedAge is the maximum time since an address was last verified
+	// for it to be handed out to requesters.
+	MaxVerifiedAge time.Duration
+
+	// MaxFailures is the number of consecutive failed verifications after
+	// which an address is dropped.
+	MaxFailures int
+
+	// MaxProbesPerCrawl is the maximum number of addresses verified per crawl.
+	MaxProbesPerCrawl int
+
+	// MaxConcurrentProbes is the maximum number of concurrent verifications.
+	MaxConcurrentProbes int
+
+	// ProbeTimeout is the timeout for verifying a single address.
+	ProbeTimeout time.Duration
+}
+
+// DefaultSeedOptions returns the default seed crawler options.
+func DefaultSeedOptions() SeedOptions {
+	return SeedOptions{
+		CrawlInterval:       30 * time.Second,
+		RecheckInterval:     30 * time.Minute,
+		MaxVerifiedAge:      time.Hour,
+		MaxFailures:         3,
+		MaxProbesPerCrawl:   64,
+		MaxConcurrentProbes: 8,
+		ProbeTimeout:        10 * time.Second,
+	}
+}
+
+// WithSeedMode runs the reactor in seed mode, where it periodically crawls
+// known addresses by dialing them via the given prober, drops addresses that
+// repeatedly fail verification, and only hands out recently verified addresses
+// to requesters.
+func WithSeedMode(prober PeerProber, options SeedOptions) ReactorOption {
+	return func(r *Reactor) {
+		r.seed = &seedCrawler{
+			peerManager: r.peerManager,
+			prober:      prober,
+			options:     options,
+			addresses:   map[p2p.NodeAddress]*seedAddressInfo{},
+			rand:        rand.New(rand.NewSource(time.Now().UnixNano())), // nolint:gosec
+			doneCh:      make(chan struct{}),
+		}
+	}
+}
+
+// seedAddressInfo tracks the verification status of an address.
+type seedAddressInfo struct {
+	lastAttempt  time.Time
+	lastVerified time.Time
+	failures     int
+}
+
+// seedCrawler crawls and verifies known addresses for a seed node.
+type seedCrawler struct {
+	logger      log.Logger
+	peerManager *p2p.PeerManager
+	prober      PeerProber
+	options     SeedOptions
+	doneCh      chan struct{}
+
+	mtx       sync.Mutex
+	addresses map[p2p.NodeAddress]*seedAddressInfo
+	rand      *rand.Rand
+}
+
+// run crawls known addresses every CrawlInterval until closeCh is closed.
+func (s *seedCrawler) run(closeCh <-chan struct{}) {
+	defer close(s.doneCh)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-closeCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			s.crawl(ctx)
+			timer.Reset(s.options.CrawlInterval)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// crawl verifies the known addresses that are due for verification, and
+// blocks until done.
+func (s *seedCrawler) crawl(ctx context.Context) {
+	candidates := s.candidates()
+	s.logger.Debug("crawling peer addresses", "addresses", len(candidates))
+
+	wg := &sync.WaitGroup{}
+	sem := make(chan struct{}, s.options.MaxConcurrentProbes)
+	for _, address := range candidates {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+		wg.Add(1)
+		go func(address p2p.NodeAddress) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			s.verify(ctx, address)
+		}(address)
+	}
+	wg.Wait()
+}
+
+// candidates returns the known addresses that are due for verification, least
+// recently attempted first. It also forgets addresses that are no longer known.
+func (s *seedCrawler) candidates() []p2p.NodeAddress {
+	known := map[p2p.NodeAddress]bool{}
+	for _, peerID := range s.peerManager.Peers() {
+		for _, address := range s.peerManager.Addresses(peerID) {
+			known[address] = true
+		}
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for address := range s.addresses {
+		if !known[address] {
+			delete(s.addresses, address)
+		}
+	}
+
+	candidates := []p2p.NodeAddress{}
+	for address := range known {
+		info, ok := s.addresses[address]
+		if !ok {
+			info = &seedAddressInfo{}
+			s.addresses[address] = info
+		}
+		if time.Since(info.lastAttempt) >= s.options.RecheckInterval || info.failures > 0 {
+			candidates = append(candidates, address)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := s.addresses[candidates[i]], s.addresses[candidates[j]]
+		if !a.lastAttempt.Equal(b.lastAttempt) {
+			return a.lastAttempt.Before(b.lastAttempt)
+		}
+		return candidates[i].String() < candidates[j].String()
+	})
+	if len(candidates) > s.options.MaxProbesPerCrawl {
+		candidates = candidates[:s.options.MaxProbesPerCrawl]
+	}
+	return candidates
+}
+
+// verify probes an address, recording the outcome and dropping the address if
+// it has failed too many times in a row.
+func (s *seedCrawler) verify(ctx context.Context, address p2p.NodeAddress) {
+	probeCtx, cancel := context.WithTimeout(ctx, s.options.ProbeTimeout)
+	defer cancel()
+	_, err := s.prober.ProbePeer(probeCtx, address)
+	if ctx.Err() != nil {
+		return // we're shutting down, don't count this as a failure
+	}
+
+	s.mtx.Lock()
+	info, ok := s.addresses[address]
+	if !ok {
+		info = &seedAddressInfo{}
+		s.addresses[address] = info
+	}
+	info.lastAttempt = time.Now()
+	if err == nil {
+		info.lastVerified = info.lastAttempt
+		info.failures = 0
+		s.mtx.Unlock()
+		s.logger.Debug("verified peer address", "address", address)
+		return
+	}
+	info.failures++
+	drop := info.failures >= s.options.MaxFailures
+	if drop {
+		delete(s.addresses, address)
+	}
+	s.mtx.Unlock()
+
+	s.logger.Debug("failed to verify peer address", "address", address, "err", err)
+	if drop {
+		s.logger.Info("dropping unreachable peer address", "address", address)
+		if err := s.peerManager.RemoveAddress(address); err != nil {
+			s.logger.Error("failed to remove peer address", "address", address, "err", err)
+		}
+	}
+}
+
+// advertise returns up to limit recently verified addresses to hand out to
+// the given peer, in random order.
+func (s *seedCrawler) advertise(peerID types.NodeID, limit uint16) []p2p.NodeAddress {
+	candidates := s.peerManager.Advertise(peerID, math.MaxUint16)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	addresses := make([]p2p.NodeAddress, 0, len(candidates))
+	for _, address := range candidates {
+		info, ok := s.addresses[address]
+		if ok && !info.lastVerified.IsZero() && time.Since(info.lastVerified) <= s.options.MaxVerifiedAge {
+			addresses = append(addresses, address)
+		}
+	}
+	s.rand.Shuffle(len(addresses), func(i, j int) {
+		addresses[i], addresses[j] = addresses[j], addresses[i]
+	})
+	if len(addresses) > int(limit) {
+		addresses = addresses[:limit]
+	}
+	return addresses
+}
@@ -4,6 +4,8 @@ import (
 	"container/heap"
 	"sort"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
@@ -74,6 +76,10 @@ type pqScheduler struct {
 	chDescs      []*ChannelDescriptor
 	capacity     uint
 	chPriorities map[ChannelID]uint
+	pending      int64 // number of envelopes in pq, atomic
+
+	droppedMtx sync.Mutex
+	dropCounts map[ChannelID]uint64
 
 	enqueueCh chan Envelope
 	dequeueCh chan Envelope
@@ -115,6 +121,7 @@ func newPQScheduler(
 		chPriorities: chPriorities,
 		pq:           &pq,
 		sizes:        sizes,
+		dropCounts:   make(map[ChannelID]uint64),
 		enqueueCh:    make(chan Envelope, enqueueBuf),
 		dequeueCh:    make(chan Envelope, dequeueBuf),
 		closer:       tmsync.NewCloser(),
@@ -139,6 +146,28 @@ func (s *pqScheduler) closed() <-chan struct{} {
 	return s.closer.Done()
 }
 
+func (s *pqScheduler) len() int {
+	return len(s.enqueueCh) + int(atomic.LoadInt64(&s.pending)) + len(s.dequeueCh)
+}
+
+func (s *pqScheduler) dropped() map[ChannelID]uint64 {
+	s.droppedMtx.Lock()
+	defer s.droppedMtx.Unlock()
+
+	dropped := make(map[ChannelID]uint64, len(s.dropCounts))
+	for chID, count := range s.dropCounts {
+		dropped[chID] = count
+	}
+	return dropped
+}
+
+// drop records a dropped envelope for the given channel.
+func (s *pqScheduler) drop(chID ChannelID) {
+	s.droppedMtx.Lock()
+	s.dropCounts[chID]++
+	s.droppedMtx.Unlock()
+}
+
 // start starts non-blocking process that starts the priority queue scheduler.
 func (s *pqScheduler) start() {
 	go s.process()
@@ -204,6 +233,7 @@ func (s *pqScheduler) process() {
 							} else {
 								pqEnvTmpChIDStr := strconv.Itoa(int(pqEnvTmp.envelope.channelID))
 								s.metrics.PeerQueueDroppedMsgs.With("ch_id", pqEnvTmpChIDStr).Add(1)
+								s.drop(pqEnvTmp.envelope.channelID)
 								s.logger.Debug(
 									"dropped envelope",
 									"ch_id", pqEnvTmpChIDStr,
@@ -216,6 +246,7 @@ func (s *pqScheduler) process() {
 
 								// dequeue/drop from the priority queue
 								heap.Remove(s.pq, pqEnvTmp.index)
+								atomic.AddInt64(&s.pending, -1)
 
 								// update the size tracker
 								tmpSize -= pqEnvTmp.size
@@ -234,6 +265,7 @@ func (s *pqScheduler) process() {
 					// There is not sufficient capacity to drop lower priority Envelopes,
 					// so we drop the incoming Envelope.
 					s.metrics.PeerQueueDroppedMsgs.With("ch_id", chIDStr).Add(1)
+					s.drop(e.channelID)
 					s.logger.Debug(
 						"dropped envelope",
 						"ch_id", chIDStr,
@@ -248,6 +280,7 @@ func (s *pqScheduler) process() {
 
 			for s.pq.Len() > 0 {
 				pqEnv = heap.Pop(s.pq).(*pqEnvelope)
+				atomic.AddInt64(&s.pending, -1)
 				s.size -= pqEnv.size
 
 				// deduct the Envelope size from all the relevant cumulative sizes
@@ -279,6 +312,7 @@ func (s *pqScheduler) push(pqEnv *pqEnvelope) {
 
 	// enqueue the incoming Envelope
 	heap.Push(s.pq, pqEnv)
+	atomic.AddInt64(&s.pending, 1)
 	s.size += pqEnv.size
 	s.metrics.PeerQueueMsgSize.With("ch_id", chIDStr).Add(float64(pqEnv.size))
 
@@ -26,6 +26,13 @@ type queue interface {
 
 	// closed returns a channel that's closed when the scheduler is closed.
 	closed() <-chan struct{}
+
+	// len returns the number of envelopes currently held by the queue.
+	len() int
+
+	// dropped returns the number of envelopes dropped by the queue, by
+	// channel. Lossless queues return nil.
+	dropped() map[ChannelID]uint64
 }
 
 // fifoQueue is a simple unbuffered lossless queue that passes messages through
@@ -57,3 +64,11 @@ func (q *fifoQueue) close() {
 func (q *fifoQueue) closed() <-chan struct{} {
 	return q.closer.Done()
 }
+
+func (q *fifoQueue) len() int {
+	return len(q.queueCh)
+}
+
+func (q *fifoQueue) dropped() map[ChannelID]uint64 {
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"net"
 	"runtime"
+	"sort"
 	"sync"
 	"time"
 
@@ -166,6 +167,10 @@ type RouterOptions struct {
 	// are used to dial peers. This defaults to the value of
 	// runtime.NumCPU.
 	NumConcurrentDials func() int
+
+	// Capture enables capturing of inbound and outbound peer messages to a
+	// file for debugging, see CaptureOptions. Disabled if nil.
+	Capture *CaptureOptions
 }
 
 const (
@@ -252,12 +257,14 @@ type Router struct {
 	endpoints          []Endpoint
 	connTracker        connectionTracker
 	protocolTransports map[Protocol]Transport
+	capture            *messageCapture // nil if message capture is disabled
 	stopCh             chan struct{} // signals Router shutdown
 
 	peerMtx    sync.RWMutex
 	peerQueues map[types.NodeID]queue // outbound messages per peer for all channels
 	// the channels that the peer queue has open
 	peerChannels map[types.NodeID]channelIDs
+	peerTraffic  map[types.NodeID]*peerTraffic // traffic stats per connected peer
 	queueFactory func(int) queue
 
 	// FIXME: We don't strictly need to use a mutex for this if we seal the
@@ -306,6 +313,7 @@ func NewRouter(
 		channelMessages:    map[ChannelID]proto.Message{},
 		peerQueues:         map[types.NodeID]queue{},
 		peerChannels:       make(map[types.NodeID]channelIDs),
+		peerTraffic:        map[types.NodeID]*peerTraffic{},
 	}
 
 	router.BaseService = service.NewBaseService(logger, "router", router)
@@ -317,6 +325,13 @@ func NewRouter(
 
 	router.queueFactory = qf
 
+	if options.Capture != nil {
+		router.capture, err = newMessageCapture(*options.Capture)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	for _, transport := range transports {
 		for _, protocol := range transport.Protocols() {
 			if _, ok := router.protocolTransports[protocol]; !ok {
@@ -843,6 +858,25 @@ func (r *Router) handshakePeer(
 	return peerInfo, nil
 }
 
+// ProbePeer dials and handshakes with the given peer address, verifying that
+// the peer is reachable and compatible with us (see NodeInfo.CompatibleWith),
+// and then closes the connection again. It returns the peer's node info. The
+// probe does not affect the peer manager's connection state, and is used e.g.
+// by seed nodes to verify addresses before handing them out.
+func (r *Router) ProbePeer(ctx context.Context, address NodeAddress) (types.NodeInfo, error) {
+	conn, err := r.dialPeer(ctx, address)
+	if err != nil {
+		return types.NodeInfo{}, err
+	}
+	defer conn.Close()
+
+	peerInfo, err := r.handshakePeer(ctx, conn, address.NodeID)
+	if err != nil {
+		return types.NodeInfo{}, err
+	}
+	return peerInfo, nil
+}
+
 func (r *Router) runWithPeerMutex(fn func() error) error {
 	r.peerMtx.Lock()
 	defer r.peerMtx.Unlock()
@@ -857,10 +891,16 @@ func (r *Router) routePeer(peerID types.NodeID, conn Connection, channels channe
 	r.peerManager.Ready(peerID)
 
 	sendQueue := r.getOrMakeQueue(peerID, channels)
+	traffic := newPeerTraffic(conn, sendQueue)
+	r.peerMtx.Lock()
+	r.peerTraffic[peerID] = traffic
+	r.peerMtx.Unlock()
+
 	defer func() {
 		r.peerMtx.Lock()
 		delete(r.peerQueues, peerID)
 		delete(r.peerChannels, peerID)
+		delete(r.peerTraffic, peerID)
 		r.peerMtx.Unlock()
 
 		sendQueue.close()
@@ -874,11 +914,11 @@ func (r *Router) routePeer(peerID types.NodeID, conn Connection, channels channe
 	errCh := make(chan error, 2)
 
 	go func() {
-		errCh <- r.receivePeer(peerID, conn)
+		errCh <- r.receivePeer(peerID, conn, traffic)
 	}()
 
 	go func() {
-		errCh <- r.sendPeer(peerID, conn, sendQueue)
+		errCh <- r.sendPeer(peerID, conn, sendQueue, traffic)
 	}()
 
 	err := <-errCh
@@ -902,12 +942,13 @@ func (r *Router) routePeer(peerID types.NodeID, conn Connection, channels channe
 
 // receivePeer receives inbound messages from a peer, deserializes them and
 // passes them on to the appropriate channel.
-func (r *Router) receivePeer(peerID types.NodeID, conn Connection) error {
+func (r *Router) receivePeer(peerID types.NodeID, conn Connection, traffic *peerTraffic) error {
 	for {
 		chID, bz, err := conn.ReceiveMessage()
 		if err != nil {
 			return err
 		}
+		r.captureMessage(CaptureInbound, peerID, chID, bz)
 
 		r.channelMtx.RLock()
 		queue, ok := r.channelQueues[chID]
@@ -916,12 +957,14 @@ func (r *Router) receivePeer(peerID types.NodeID, conn Connection) error {
 
 		if !ok {
 			r.logger.Debug("dropping message for unknown channel", "peer", peerID, "channel", chID)
+			traffic.dropped(chID)
 			continue
 		}
 
 		msg := proto.Clone(messageType)
 		if err := proto.Unmarshal(bz, msg); err != nil {
 			r.logger.Error("message decoding failed, dropping message", "peer", peerID, "err", err)
+			traffic.dropped(chID)
 			continue
 		}
 
@@ -929,6 +972,7 @@ func (r *Router) receivePeer(peerID types.NodeID, conn Connection) error {
 			msg, err = wrapper.Unwrap()
 			if err != nil {
 				r.logger.Error("failed to unwrap message", "err", err)
+				traffic.dropped(chID)
 				continue
 			}
 		}
@@ -942,10 +986,12 @@ func (r *Router) receivePeer(peerID types.NodeID, conn Connection) error {
 				"peer_id", string(peerID),
 				"message_type", r.metrics.ValueToMetricLabel(msg)).Add(float64(proto.Size(msg)))
 			r.metrics.RouterChannelQueueSend.Observe(time.Since(start).Seconds())
+			traffic.received(chID, len(bz))
 			r.logger.Debug("received message", "peer", peerID, "message", msg)
 
 		case <-queue.closed():
 			r.logger.Debug("channel closed, dropping message", "peer", peerID, "channel", chID)
+			traffic.dropped(chID)
 
 		case <-r.stopCh:
 			return nil
@@ -954,7 +1000,7 @@ func (r *Router) receivePeer(peerID types.NodeID, conn Connection) error {
 }
 
 // sendPeer sends queued messages to a peer.
-func (r *Router) sendPeer(peerID types.NodeID, conn Connection, peerQueue queue) error {
+func (r *Router) sendPeer(peerID types.NodeID, conn Connection, peerQueue queue, traffic *peerTraffic) error {
 	for {
 		start := time.Now().UTC()
 
@@ -975,6 +1021,8 @@ func (r *Router) sendPeer(peerID types.NodeID, conn Connection, peerQueue queue)
 			if err = conn.SendMessage(envelope.channelID, bz); err != nil {
 				return err
 			}
+			traffic.sent(envelope.channelID, len(bz))
+			r.captureMessage(CaptureOutbound, peerID, envelope.channelID, bz)
 
 			r.logger.Debug("sent message", "peer", envelope.To, "message", envelope.Message)
 
@@ -987,6 +1035,35 @@ func (r *Router) sendPeer(peerID types.NodeID, conn Connection, peerQueue queue)
 	}
 }
 
+// captureMessage captures a message sent to or received from a peer, if
+// message capture is enabled.
+func (r *Router) captureMessage(direction string, peerID types.NodeID, chID ChannelID, bz []byte) {
+	if r.capture == nil {
+		return
+	}
+	if err := r.capture.capture(direction, peerID, chID, bz); err != nil {
+		r.logger.Error("failed to capture message", "peer", peerID, "channel", chID, "err", err)
+	}
+}
+
+// PeerStats returns connection and traffic statistics for all connected peers,
+// ordered by node ID.
+func (r *Router) PeerStats() []PeerStats {
+	r.peerMtx.RLock()
+	traffic := make(map[types.NodeID]*peerTraffic, len(r.peerTraffic))
+	for peerID, t := range r.peerTraffic {
+		traffic[peerID] = t
+	}
+	r.peerMtx.RUnlock()
+
+	stats := make([]PeerStats, 0, len(traffic))
+	for peerID, t := range traffic {
+		stats = append(stats, t.stats(peerID))
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].NodeID < stats[j].NodeID })
+	return stats
+}
+
 // evictPeers evicts connected peers as requested by the peer manager.
 func (r *Router) evictPeers() {
 	r.logger.Debug("starting evict routine")
@@ -1040,6 +1117,13 @@ func (r *Router) OnStart() error {
 		"transports", len(r.transports),
 	)
 
+	if r.capture != nil {
+		if err := r.capture.start(); err != nil {
+			return fmt.Errorf("failed to start message capture: %w", err)
+		}
+		r.logger.Info("capturing peer messages", "path", r.options.Capture.Path)
+	}
+
 	go r.dialPeers()
 	go r.evictPeers()
 
@@ -1085,6 +1169,12 @@ func (r *Router) OnStop() {
 	for _, q := range queues {
 		<-q.closed()
 	}
+
+	if r.capture != nil {
+		if err := r.capture.stop(); err != nil {
+			r.logger.Error("failed to close message capture", "err", err)
+		}
+	}
 }
 
 // stopCtx returns a new context that is canceled when the router stops.
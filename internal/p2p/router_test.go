@@ -94,6 +94,92 @@ func TestRouter_Network(t *testing.T) {
 	})
 }
 
+func TestRouter_Network_Partition(t *testing.T) {
+	t.Cleanup(leaktest.Check(t))
+
+	// Create a slow test network with echo reactors on all peers.
+	network := p2ptest.MakeNetwork(t, p2ptest.NetworkOptions{
+		NumNodes:   3,
+		BufferSize: 10,
+		Seed:       1,
+		LinkConditions: p2p.MemoryLinkConditions{
+			Latency: 10 * time.Millisecond,
+			Jitter:  5 * time.Millisecond,
+		},
+	})
+	local := network.RandomNode()
+	peers := network.Peers(local.NodeID)
+	channels := network.MakeChannels(t, chDesc)
+
+	network.Start(t)
+
+	channel := channels[local.NodeID]
+	for _, peer := range peers {
+		go echoReactor(channels[peer.NodeID])
+	}
+
+	// Isolate the first peer, and check that we only hear back from the other.
+	network.Partition([]types.NodeID{peers[0].NodeID})
+	p2ptest.RequireSend(t, channel, p2p.Envelope{
+		Broadcast: true,
+		Message:   &p2ptest.Message{Value: "foo"},
+	})
+	p2ptest.RequireReceive(t, channel, p2p.Envelope{
+		From:    peers[1].NodeID,
+		Message: &p2ptest.Message{Value: "foo"},
+	})
+	time.Sleep(100 * time.Millisecond)
+	p2ptest.RequireEmpty(t, channel)
+
+	// Once healed, the isolated peer should be reachable again.
+	network.Heal()
+	p2ptest.RequireSendReceive(t, channel, peers[0].NodeID,
+		&p2ptest.Message{Value: "bar"},
+		&p2ptest.Message{Value: "bar"},
+	)
+}
+
+func TestRouter_PeerStats(t *testing.T) {
+	t.Cleanup(leaktest.Check(t))
+
+	network := p2ptest.MakeNetwork(t, p2ptest.NetworkOptions{
+		NumNodes:       2,
+		BufferSize:     10,
+		LinkConditions: p2p.MemoryLinkConditions{Latency: 10 * time.Millisecond},
+	})
+	local := network.RandomNode()
+	peer := network.Peers(local.NodeID)[0]
+	channels := network.MakeChannels(t, chDesc)
+
+	network.Start(t)
+	go echoReactor(channels[peer.NodeID])
+
+	p2ptest.RequireSendReceive(t, channels[local.NodeID], peer.NodeID,
+		&p2ptest.Message{Value: "foo"},
+		&p2ptest.Message{Value: "foo"},
+	)
+
+	var stats []p2p.PeerStats
+	require.Eventually(t, func() bool {
+		stats = local.Router.PeerStats()
+		return len(stats) == 1 && len(stats[0].Channels) == 1 &&
+			stats[0].Channels[0].MessagesReceived == 1
+	}, time.Second, 10*time.Millisecond)
+
+	require.Equal(t, peer.NodeID, stats[0].NodeID)
+	require.Equal(t, p2p.MemoryProtocol, stats[0].Protocol)
+	require.Equal(t, 20*time.Millisecond, stats[0].Latency)
+	require.False(t, stats[0].ConnectedAt.IsZero())
+	require.Zero(t, stats[0].QueueDepth)
+
+	channel := stats[0].Channels[0]
+	require.Equal(t, chID, channel.ChannelID)
+	require.EqualValues(t, 1, channel.MessagesSent)
+	require.NotZero(t, channel.BytesSent)
+	require.Equal(t, channel.BytesSent, channel.BytesReceived)
+	require.Zero(t, channel.MessagesDropped)
+}
+
 func TestRouter_Channel_Basic(t *testing.T) {
 	t.Cleanup(leaktest.Check(t))
 
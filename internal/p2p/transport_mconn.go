@@ -9,6 +9,7 @@ import (
 	"net"
 	"strconv"
 	"sync"
+	"time"
 
 	"golang.org/x/net/netutil"
 
@@ -407,6 +408,15 @@ func (c *mConnConnection) ReceiveMessage() (ChannelID, []byte, error) {
 	}
 }
 
+// RoundTripTime returns the estimated round-trip time to the peer, or 0 if
+// it is not yet known.
+func (c *mConnConnection) RoundTripTime() time.Duration {
+	if c.mconn == nil {
+		return 0
+	}
+	return c.mconn.RoundTripTime()
+}
+
 // LocalEndpoint implements Connection.
 func (c *mConnConnection) LocalEndpoint() Endpoint {
 	endpoint := Endpoint{
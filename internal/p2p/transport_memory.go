@@ -5,8 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net"
 	"sync"
+	"time"
 
 	"github.com/tendermint/tendermint/crypto"
 	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
@@ -18,26 +20,194 @@ const (
 	MemoryProtocol Protocol = "memory"
 )
 
+// MemoryLinkConditions describes the simulated conditions of a directed link
+// between two memory transports. The zero value is a perfect link, where
+// messages are delivered instantly and reliably.
+type MemoryLinkConditions struct {
+	// Latency is the fixed one-way delay added to every message.
+	Latency time.Duration
+
+	// Jitter is the maximum additional random delay added to every message,
+	// drawn uniformly from [0, Jitter). Messages are never reordered.
+	Jitter time.Duration
+
+	// Bandwidth limits the link throughput in bytes per second, delaying
+	// messages by their transmission time. 0 means unlimited.
+	Bandwidth int64
+
+	// DropRate is the probability in [0, 1] that a message is silently lost.
+	DropRate float64
+}
+
+// isPerfect returns true if the link conditions don't affect delivery.
+func (c MemoryLinkConditions) isPerfect() bool {
+	return c == MemoryLinkConditions{}
+}
+
+// Validate validates the link conditions.
+func (c MemoryLinkConditions) Validate() error {
+	switch {
+	case c.Latency < 0:
+		return errors.New("negative latency")
+	case c.Jitter < 0:
+		return errors.New("negative jitter")
+	case c.Bandwidth < 0:
+		return errors.New("negative bandwidth")
+	case c.DropRate < 0 || c.DropRate > 1:
+		return fmt.Errorf("drop rate %v must be in [0, 1]", c.DropRate)
+	}
+	return nil
+}
+
+// memoryLink identifies a directed link between two memory transports.
+type memoryLink struct {
+	from types.NodeID
+	to   types.NodeID
+}
+
 // MemoryNetwork is an in-memory "network" that uses buffered Go channels to
 // communicate between endpoints. It is primarily meant for testing.
 //
 // Network endpoints are allocated via CreateTransport(), which takes a node ID,
 // and the endpoint is then immediately accessible via the URL "memory:<nodeID>".
+//
+// By default, the network delivers messages instantly and reliably. Link
+// conditions (latency, jitter, bandwidth and message loss) and partitions can
+// be configured at any time, and take effect for subsequent messages on both
+// existing and new connections. Random decisions are drawn from a source that
+// can be seeded via SetSeed() for reproducible tests.
 type MemoryNetwork struct {
 	logger log.Logger
 
 	mtx        sync.RWMutex
 	transports map[types.NodeID]*MemoryTransport
 	bufferSize int
+
+	condMtx           sync.RWMutex
+	defaultConditions MemoryLinkConditions
+	linkConditions    map[memoryLink]MemoryLinkConditions
+	partitions        map[types.NodeID]int // node ID -> partition group
+	rand              *rand.Rand
 }
 
 // NewMemoryNetwork creates a new in-memory network.
 func NewMemoryNetwork(logger log.Logger, bufferSize int) *MemoryNetwork {
 	return &MemoryNetwork{
-		bufferSize: bufferSize,
-		logger:     logger,
-		transports: map[types.NodeID]*MemoryTransport{},
+		bufferSize:     bufferSize,
+		logger:         logger,
+		transports:     map[types.NodeID]*MemoryTransport{},
+		linkConditions: map[memoryLink]MemoryLinkConditions{},
+		partitions:     map[types.NodeID]int{},
+		rand:           rand.New(rand.NewSource(time.Now().UnixNano())), // nolint:gosec
+	}
+}
+
+// SetSeed reseeds the random source used for jitter and message loss, making
+// these decisions deterministic for a given sequence of messages.
+func (n *MemoryNetwork) SetSeed(seed int64) {
+	n.condMtx.Lock()
+	defer n.condMtx.Unlock()
+	n.rand = rand.New(rand.NewSource(seed)) // nolint:gosec
+}
+
+// SetDefaultLinkConditions sets the conditions used for all links that don't
+// have explicit conditions set via SetLinkConditions().
+func (n *MemoryNetwork) SetDefaultLinkConditions(conditions MemoryLinkConditions) error {
+	if err := conditions.Validate(); err != nil {
+		return err
+	}
+	n.condMtx.Lock()
+	defer n.condMtx.Unlock()
+	n.defaultConditions = conditions
+	return nil
+}
+
+// SetLinkConditions sets the conditions for messages sent from one node to
+// another. Links are directed, so to affect both directions this must be
+// called for each of them.
+func (n *MemoryNetwork) SetLinkConditions(from, to types.NodeID, conditions MemoryLinkConditions) error {
+	if err := conditions.Validate(); err != nil {
+		return err
+	}
+	n.condMtx.Lock()
+	defer n.condMtx.Unlock()
+	n.linkConditions[memoryLink{from: from, to: to}] = conditions
+	return nil
+}
+
+// ResetLinkConditions removes any explicit conditions for the link between the
+// given nodes, reverting it to the default conditions.
+func (n *MemoryNetwork) ResetLinkConditions(from, to types.NodeID) {
+	n.condMtx.Lock()
+	defer n.condMtx.Unlock()
+	delete(n.linkConditions, memoryLink{from: from, to: to})
+}
+
+// Partition splits the network into the given groups of nodes. Nodes in
+// different groups can't dial each other, and messages between them on existing
+// connections are silently dropped. Nodes that aren't listed in any group form
+// an implicit group of their own. Any previous partition is replaced.
+func (n *MemoryNetwork) Partition(groups ...[]types.NodeID) {
+	n.condMtx.Lock()
+	defer n.condMtx.Unlock()
+	n.partitions = map[types.NodeID]int{}
+	for i, group := range groups {
+		for _, id := range group {
+			n.partitions[id] = i + 1 // 0 is the implicit group
+		}
+	}
+}
+
+// Heal removes any network partition.
+func (n *MemoryNetwork) Heal() {
+	n.Partition()
+}
+
+// isPartitioned returns true if the given nodes are in different partitions.
+func (n *MemoryNetwork) isPartitioned(a, b types.NodeID) bool {
+	n.condMtx.RLock()
+	defer n.condMtx.RUnlock()
+	return n.isPartitionedLocked(a, b)
+}
+
+func (n *MemoryNetwork) isPartitionedLocked(a, b types.NodeID) bool {
+	return n.partitions[a] != n.partitions[b]
+}
+
+// conditionsFor returns the link conditions for messages from one node to
+// another.
+func (n *MemoryNetwork) conditionsFor(from, to types.NodeID) MemoryLinkConditions {
+	n.condMtx.Lock()
+	defer n.condMtx.Unlock()
+
+	if conditions, ok := n.linkConditions[memoryLink{from: from, to: to}]; ok {
+		return conditions
+	}
+	return n.defaultConditions
+}
+
+// routeMessage decides the fate of a message sent across the given link. It
+// returns the conditions of the link, the random jitter to apply, and whether
+// the message should be dropped.
+func (n *MemoryNetwork) routeMessage(from, to types.NodeID) (MemoryLinkConditions, time.Duration, bool) {
+	n.condMtx.Lock()
+	defer n.condMtx.Unlock()
+
+	if n.isPartitionedLocked(from, to) {
+		return MemoryLinkConditions{}, 0, true
 	}
+	conditions, ok := n.linkConditions[memoryLink{from: from, to: to}]
+	if !ok {
+		conditions = n.defaultConditions
+	}
+	if conditions.DropRate > 0 && n.rand.Float64() < conditions.DropRate {
+		return conditions, 0, true
+	}
+	var jitter time.Duration
+	if conditions.Jitter > 0 {
+		jitter = time.Duration(n.rand.Int63n(int64(conditions.Jitter)))
+	}
+	return conditions, jitter, false
 }
 
 // CreateTransport creates a new memory transport endpoint with the given node
@@ -176,13 +346,16 @@ func (t *MemoryTransport) Dial(ctx context.Context, endpoint Endpoint) (Connecti
 	if peer == nil {
 		return nil, fmt.Errorf("unknown peer %q", nodeID)
 	}
+	if t.network.isPartitioned(t.nodeID, peer.nodeID) {
+		return nil, fmt.Errorf("peer %q is unreachable due to network partition", nodeID)
+	}
 
 	inCh := make(chan memoryMessage, t.bufferSize)
 	outCh := make(chan memoryMessage, t.bufferSize)
 	closer := tmsync.NewCloser()
 
-	outConn := newMemoryConnection(t.logger, t.nodeID, peer.nodeID, inCh, outCh, closer)
-	inConn := newMemoryConnection(peer.logger, peer.nodeID, t.nodeID, outCh, inCh, closer)
+	outConn := newMemoryConnection(t.logger, t.network, t.nodeID, peer.nodeID, inCh, outCh, closer)
+	inConn := newMemoryConnection(peer.logger, t.network, peer.nodeID, t.nodeID, outCh, inCh, closer)
 
 	select {
 	case peer.acceptCh <- inConn:
@@ -207,12 +380,23 @@ func (t *MemoryTransport) Close() error {
 // MemoryConnection is an in-memory connection between two transport endpoints.
 type MemoryConnection struct {
 	logger   log.Logger
+	network  *MemoryNetwork
 	localID  types.NodeID
 	remoteID types.NodeID
 
 	receiveCh <-chan memoryMessage
 	sendCh    chan<- memoryMessage
 	closer    *tmsync.Closer
+
+	// delayed messages are passed through delayCh to a delivery routine, which
+	// sends them on sendCh in order once they're due. delayedOnce starts the
+	// routine on first use, and delayMtx protects the remaining fields.
+	delayCh     chan memoryMessage
+	delayedOnce sync.Once
+	delayMtx    sync.Mutex
+	delayed     int       // number of messages pending delivery
+	lastDue     time.Time // delivery time of the last delayed message
+	linkBusy    time.Time // time until which the link is busy transmitting
 }
 
 // memoryMessage is passed internally, containing either a message or handshake.
@@ -220,6 +404,9 @@ type memoryMessage struct {
 	channelID ChannelID
 	message   []byte
 
+	// For simulated link conditions.
+	due time.Time
+
 	// For handshakes.
 	nodeInfo *types.NodeInfo
 	pubKey   crypto.PubKey
@@ -228,6 +415,7 @@ type memoryMessage struct {
 // newMemoryConnection creates a new MemoryConnection.
 func newMemoryConnection(
 	logger log.Logger,
+	network *MemoryNetwork,
 	localID types.NodeID,
 	remoteID types.NodeID,
 	receiveCh <-chan memoryMessage,
@@ -236,11 +424,13 @@ func newMemoryConnection(
 ) *MemoryConnection {
 	return &MemoryConnection{
 		logger:    logger.With("remote", remoteID),
+		network:   network,
 		localID:   localID,
 		remoteID:  remoteID,
 		receiveCh: receiveCh,
 		sendCh:    sendCh,
 		closer:    closer,
+		delayCh:   make(chan memoryMessage, cap(sendCh)),
 	}
 }
 
@@ -265,6 +455,12 @@ func (c *MemoryConnection) RemoteEndpoint() Endpoint {
 	}
 }
 
+// RoundTripTime returns the simulated round-trip latency of the connection.
+func (c *MemoryConnection) RoundTripTime() time.Duration {
+	return c.network.conditionsFor(c.localID, c.remoteID).Latency +
+		c.network.conditionsFor(c.remoteID, c.localID).Latency
+}
+
 // Handshake implements Connection.
 func (c *MemoryConnection) Handshake(
 	ctx context.Context,
@@ -323,15 +519,87 @@ func (c *MemoryConnection) SendMessage(chID ChannelID, msg []byte) error {
 	default:
 	}
 
+	conditions, jitter, drop := c.network.routeMessage(c.localID, c.remoteID)
+	if drop {
+		c.logger.Debug("dropped message", "chID", chID, "msg", msg)
+		return nil
+	}
+
+	// Messages are sent directly unless the link has non-perfect conditions
+	// or earlier messages are still pending, to preserve message ordering.
+	c.delayMtx.Lock()
+	if conditions.isPerfect() && c.delayed == 0 {
+		c.delayMtx.Unlock()
+		select {
+		case c.sendCh <- memoryMessage{channelID: chID, message: msg}:
+			c.logger.Debug("sent message", "chID", chID, "msg", msg)
+			return nil
+		case <-c.closer.Done():
+			return io.EOF
+		}
+	}
+
+	now := time.Now()
+	if c.linkBusy.Before(now) {
+		c.linkBusy = now
+	}
+	if conditions.Bandwidth > 0 {
+		c.linkBusy = c.linkBusy.Add(time.Duration(int64(len(msg)) * int64(time.Second) / conditions.Bandwidth))
+	}
+	due := c.linkBusy.Add(conditions.Latency + jitter)
+	if due.Before(c.lastDue) {
+		due = c.lastDue
+	}
+	c.lastDue = due
+	c.delayed++
+	c.delayMtx.Unlock()
+
+	c.delayedOnce.Do(func() { go c.deliverDelayed() })
 	select {
-	case c.sendCh <- memoryMessage{channelID: chID, message: msg}:
-		c.logger.Debug("sent message", "chID", chID, "msg", msg)
+	case c.delayCh <- memoryMessage{channelID: chID, message: msg, due: due}:
+		c.logger.Debug("sent delayed message", "chID", chID, "msg", msg, "due", due)
 		return nil
 	case <-c.closer.Done():
 		return io.EOF
 	}
 }
 
+// deliverDelayed delivers delayed messages to the remote endpoint in order,
+// once they're due.
+func (c *MemoryConnection) deliverDelayed() {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	for {
+		var msg memoryMessage
+		select {
+		case msg = <-c.delayCh:
+		case <-c.closer.Done():
+			return
+		}
+
+		if wait := time.Until(msg.due); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-timer.C:
+			case <-c.closer.Done():
+				return
+			}
+		}
+
+		select {
+		case c.sendCh <- memoryMessage{channelID: msg.channelID, message: msg.message}:
+		case <-c.closer.Done():
+			return
+		}
+
+		c.delayMtx.Lock()
+		c.delayed--
+		c.delayMtx.Unlock()
+	}
+}
+
 // Close implements Connection.
 func (c *MemoryConnection) Close() error {
 	select {
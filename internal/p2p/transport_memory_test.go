@@ -3,7 +3,9 @@ package p2p_test
 import (
 	"bytes"
 	"encoding/hex"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/tendermint/tendermint/internal/p2p"
@@ -33,3 +35,101 @@ func init() {
 		return transport
 	}
 }
+
+// makeMemoryPair creates a fresh memory network with two transports and
+// returns a handshaked connection pair between them.
+func makeMemoryPair(t *testing.T) (*p2p.MemoryNetwork, p2p.Connection, p2p.Connection) {
+	network := p2p.NewMemoryNetwork(log.TestingLogger(), 10)
+	network.SetSeed(1)
+	a := network.CreateTransport(types.NodeID(strings.Repeat("a", 40)))
+	b := network.CreateTransport(types.NodeID(strings.Repeat("b", 40)))
+	t.Cleanup(func() {
+		require.NoError(t, a.Close())
+		require.NoError(t, b.Close())
+	})
+	ab, ba := dialAcceptHandshake(t, a, b)
+	return network, ab, ba
+}
+
+func TestMemoryNetwork_Latency(t *testing.T) {
+	network, ab, ba := makeMemoryPair(t)
+	aID := types.NodeID(ab.LocalEndpoint().Path)
+	bID := types.NodeID(ab.RemoteEndpoint().Path)
+
+	require.Error(t, network.SetLinkConditions(aID, bID, p2p.MemoryLinkConditions{Latency: -1}))
+	require.NoError(t, network.SetLinkConditions(aID, bID, p2p.MemoryLinkConditions{
+		Latency: 100 * time.Millisecond,
+		Jitter:  10 * time.Millisecond,
+	}))
+
+	// Messages should be delayed, but delivered in order.
+	start := time.Now()
+	for i := byte(0); i < 5; i++ {
+		require.NoError(t, ab.SendMessage(1, []byte{i}))
+	}
+	for i := byte(0); i < 5; i++ {
+		_, msg, err := ba.ReceiveMessage()
+		require.NoError(t, err)
+		require.Equal(t, []byte{i}, msg)
+	}
+	require.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	// The reverse direction is unaffected.
+	start = time.Now()
+	require.NoError(t, ba.SendMessage(1, []byte("pong")))
+	_, msg, err := ab.ReceiveMessage()
+	require.NoError(t, err)
+	require.Equal(t, []byte("pong"), msg)
+	require.Less(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestMemoryNetwork_Bandwidth(t *testing.T) {
+	network, ab, ba := makeMemoryPair(t)
+	require.NoError(t, network.SetDefaultLinkConditions(p2p.MemoryLinkConditions{
+		Bandwidth: 10000,
+	}))
+
+	// 5 messages of 500 bytes at 10 kB/s should take at least 250 ms.
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, ab.SendMessage(1, make([]byte, 500)))
+	}
+	for i := 0; i < 5; i++ {
+		_, _, err := ba.ReceiveMessage()
+		require.NoError(t, err)
+	}
+	require.GreaterOrEqual(t, time.Since(start), 250*time.Millisecond)
+}
+
+func TestMemoryNetwork_DropRate(t *testing.T) {
+	network, ab, ba := makeMemoryPair(t)
+	require.NoError(t, network.SetDefaultLinkConditions(p2p.MemoryLinkConditions{DropRate: 1}))
+
+	require.NoError(t, ab.SendMessage(1, []byte("lost")))
+	require.NoError(t, network.SetDefaultLinkConditions(p2p.MemoryLinkConditions{}))
+	require.NoError(t, ab.SendMessage(1, []byte("found")))
+
+	_, msg, err := ba.ReceiveMessage()
+	require.NoError(t, err)
+	require.Equal(t, []byte("found"), msg)
+}
+
+func TestMemoryNetwork_Partition(t *testing.T) {
+	network, ab, ba := makeMemoryPair(t)
+	aID := types.NodeID(ab.LocalEndpoint().Path)
+	bID := types.NodeID(ab.RemoteEndpoint().Path)
+
+	network.Partition([]types.NodeID{aID}, []types.NodeID{bID})
+	require.NoError(t, ab.SendMessage(1, []byte("lost")))
+
+	// New dials across the partition should fail.
+	_, err := network.GetTransport(aID).Dial(ctx, network.GetTransport(bID).Endpoints()[0])
+	require.Error(t, err)
+
+	network.Heal()
+	require.NoError(t, ab.SendMessage(1, []byte("found")))
+
+	_, msg, err := ba.ReceiveMessage()
+	require.NoError(t, err)
+	require.Equal(t, []byte("found"), msg)
+}
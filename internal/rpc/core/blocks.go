@@ -192,15 +192,22 @@ func (env *Environment) BlockResults(ctx *rpctypes.Context, heightPtr *int64) (*
 
 // BlockSearch searches for a paginated set of blocks matching BeginBlock and
 // EndBlock event search criteria.
+//
+// If a cursor is given, the page following the cursor is returned instead of
+// the numbered page, without computing the total count. An empty cursor
+// selects the first page. In both cases, the cursor of the next page is
+// returned if there are more blocks.
 func (env *Environment) BlockSearch(
 	ctx *rpctypes.Context,
 	query string,
 	pagePtr, perPagePtr *int,
 	orderBy string,
+	cursorPtr *string,
 ) (*coretypes.ResultBlockSearch, error) {
 
-	if !indexer.KVSinkEnabled(env.EventSinks) {
-		return nil, fmt.Errorf("block searching is disabled due to no kvEventSink")
+	sink := indexer.SearchSink(env.EventSinks)
+	if sink == nil {
+		return nil, fmt.Errorf("block searching is disabled due to no kv, psql or sqlite event sink")
 	}
 
 	q, err := tmquery.New(query)
@@ -208,14 +215,11 @@ func (env *Environment) BlockSearch(
 		return nil, err
 	}
 
-	var kvsink indexer.EventSink
-	for _, sink := range env.EventSinks {
-		if sink.Type() == indexer.KV {
-			kvsink = sink
-		}
+	if cursorPtr != nil {
+		return env.blockSearchPage(ctx, sink, q, perPagePtr, orderBy, *cursorPtr)
 	}
 
-	results, err := kvsink.SearchBlockEvents(ctx.Context(), q)
+	results, err := sink.SearchBlockEvents(ctx.Context(), q)
 	if err != nil {
 		return nil, err
 	}
@@ -244,9 +248,50 @@ func (env *Environment) BlockSearch(
 	skipCount := validateSkipCount(page, perPage)
 	pageSize := tmmath.MinInt(perPage, totalCount-skipCount)
 
-	apiResults := make([]*coretypes.ResultBlock, 0, pageSize)
-	for i := skipCount; i < skipCount+pageSize; i++ {
-		block := env.BlockStore.LoadBlock(results[i])
+	apiResults := env.resultBlocks(results[skipCount : skipCount+pageSize])
+
+	var nextCursor string
+	if skipCount+pageSize < totalCount {
+		nextCursor = indexer.Cursor{Height: results[skipCount+pageSize-1]}.String()
+	}
+
+	return &coretypes.ResultBlockSearch{Blocks: apiResults, TotalCount: totalCount, NextCursor: nextCursor}, nil
+}
+
+// blockSearchPage returns the page of the blocks matching the query which
+// follows the cursor.
+func (env *Environment) blockSearchPage(
+	ctx *rpctypes.Context,
+	sink indexer.EventSink,
+	q *tmquery.Query,
+	perPagePtr *int,
+	orderBy string,
+	cursor string,
+) (*coretypes.ResultBlockSearch, error) {
+	page, err := env.searchPage(perPagePtr, orderBy, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	results, more, err := indexer.SearchBlockEventsPage(ctx.Context(), sink, q, page)
+	if err != nil {
+		return nil, err
+	}
+
+	var nextCursor string
+	if more {
+		nextCursor = indexer.Cursor{Height: results[len(results)-1]}.String()
+	}
+
+	return &coretypes.ResultBlockSearch{Blocks: env.resultBlocks(results), TotalCount: -1, NextCursor: nextCursor}, nil
+}
+
+// resultBlocks returns the RPC results of the blocks at the given heights,
+// skipping the blocks which are not in the block store.
+func (env *Environment) resultBlocks(heights []int64) []*coretypes.ResultBlock {
+	apiResults := make([]*coretypes.ResultBlock, 0, len(heights))
+	for _, height := range heights {
+		block := env.BlockStore.LoadBlock(height)
 		if block != nil {
 			blockMeta := env.BlockStore.LoadBlockMeta(block.Height)
 			if blockMeta != nil {
@@ -257,6 +302,5 @@ func (env *Environment) BlockSearch(
 			}
 		}
 	}
-
-	return &coretypes.ResultBlockSearch{Blocks: apiResults, TotalCount: totalCount}, nil
+	return apiResults
 }
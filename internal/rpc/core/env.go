@@ -9,6 +9,7 @@ import (
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/internal/consensus"
 	"github.com/tendermint/tendermint/internal/eventbus"
+	"github.com/tendermint/tendermint/internal/eventlog"
 	"github.com/tendermint/tendermint/internal/mempool"
 	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/internal/proxy"
@@ -30,6 +31,11 @@ const (
 	// must be less than the server's write timeout (see rpcserver.DefaultConfig)
 	SubscribeTimeout = 5 * time.Second
 
+	// MaxEventsWaitTime is the maximum time an events request waits for
+	// events. It must be less than the server's write timeout (see
+	// rpcserver.DefaultConfig).
+	MaxEventsWaitTime = 5 * time.Second
+
 	// genesisChunkSize is the maximum size, in bytes, of each
 	// chunk in the genesis structure for the chunked API
 	genesisChunkSize = 16 * 1024 * 1024 // 16
@@ -62,6 +68,10 @@ type peerManager interface {
 	Addresses(types.NodeID) []p2p.NodeAddress
 }
 
+type router interface {
+	PeerStats() []p2p.PeerStats
+}
+
 //----------------------------------------------
 // Environment contains objects and interfaces used by the RPC. It is expected
 // to be setup once during startup.
@@ -82,12 +92,15 @@ type Environment struct {
 
 	// interfaces for new p2p interfaces
 	PeerManager peerManager
+	Router      router
 
 	// objects
 	PubKey            crypto.PubKey
 	GenDoc            *types.GenesisDoc // cache the genesis structure
 	EventSinks        []indexer.EventSink
+	IndexerService    *indexer.Service
 	EventBus          *eventbus.EventBus // thread safe
+	EventLog          *eventlog.Log      // nil if disabled
 	Mempool           mempool.Mempool
 	BlockSyncReactor  consensus.BlockSyncReactor
 	StateSyncMetricer statesync.Metricer
@@ -140,6 +153,30 @@ func (env *Environment) validatePerPage(perPagePtr *int) int {
 	return perPage
 }
 
+// searchPage returns the page of search results selected by the per_page,
+// order_by and cursor parameters of a search. An empty cursor selects the
+// first page.
+func (env *Environment) searchPage(perPagePtr *int, orderBy, cursor string) (indexer.Page, error) {
+	page := indexer.Page{Limit: env.validatePerPage(perPagePtr)}
+
+	switch orderBy {
+	case "desc", "":
+		page.Desc = true
+	case "asc":
+	default:
+		return page, fmt.Errorf("expected order_by to be either `asc` or `desc` or empty: %w", coretypes.ErrInvalidRequest)
+	}
+
+	if cursor != "" {
+		after, err := indexer.ParseCursor(cursor)
+		if err != nil {
+			return page, fmt.Errorf("%v: %w", err, coretypes.ErrInvalidRequest)
+		}
+		page.After = &after
+	}
+	return page, nil
+}
+
 // InitGenesisChunks configures the environment and should be called on service
 // startup.
 func (env *Environment) InitGenesisChunks() error {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/tendermint/tendermint/internal/eventlog"
 	tmpubsub "github.com/tendermint/tendermint/libs/pubsub"
 	tmquery "github.com/tendermint/tendermint/libs/pubsub/query"
 	"github.com/tendermint/tendermint/rpc/coretypes"
@@ -23,9 +24,11 @@ func (env *Environment) Subscribe(ctx *rpctypes.Context, query string) (*coretyp
 	addr := ctx.RemoteAddr()
 
 	if env.EventBus.NumClients() >= env.Config.MaxSubscriptionClients {
-		return nil, fmt.Errorf("max_subscription_clients %d reached", env.Config.MaxSubscriptionClients)
+		return nil, fmt.Errorf("%w: max_subscription_clients %d reached",
+			coretypes.ErrTooManySubscriptions, env.Config.MaxSubscriptionClients)
 	} else if env.EventBus.NumClientSubscriptions(addr) >= env.Config.MaxSubscriptionsPerClient {
-		return nil, fmt.Errorf("max_subscriptions_per_client %d reached", env.Config.MaxSubscriptionsPerClient)
+		return nil, fmt.Errorf("%w: max_subscriptions_per_client %d reached",
+			coretypes.ErrTooManySubscriptions, env.Config.MaxSubscriptionsPerClient)
 	}
 
 	env.Logger.Info("Subscribe to query", "remote", addr, "query", query)
@@ -116,3 +119,67 @@ func (env *Environment) UnsubscribeAll(ctx *rpctypes.Context) (*coretypes.Result
 	}
 	return &coretypes.ResultUnsubscribe{}, nil
 }
+
+// Events returns the events of the event log following the cursor after (or
+// the oldest events, if empty) which match the query (or all the events, if
+// empty), in the order of their cursors. If no events match, it waits up to
+// wait_time (at most MaxEventsWaitTime) for events to be published.
+// More: https://docs.tendermint.com/master/rpc/#/Websocket/events
+func (env *Environment) Events(
+	ctx *rpctypes.Context,
+	query string,
+	maxItemsPtr *int,
+	after string,
+	waitTime string,
+) (*coretypes.ResultEvents, error) {
+	if env.EventLog == nil {
+		return nil, errors.New("the event log is disabled")
+	}
+
+	match := func(*eventlog.Item) (bool, error) { return true, nil }
+	if query != "" {
+		q, err := tmquery.New(query)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to parse query: %v", coretypes.ErrInvalidRequest, err)
+		}
+		match = func(item *eventlog.Item) (bool, error) { return q.Matches(item.Events) }
+	}
+	cursor, err := eventlog.ParseCursor(after)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", coretypes.ErrInvalidRequest, err)
+	}
+	var wait time.Duration
+	if waitTime != "" {
+		wait, err = time.ParseDuration(waitTime)
+		if err != nil || wait < 0 {
+			return nil, fmt.Errorf("%w: invalid wait_time %q", coretypes.ErrInvalidRequest, waitTime)
+		}
+		if wait > MaxEventsWaitTime {
+			wait = MaxEventsWaitTime
+		}
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx.Context(), wait)
+	defer cancel()
+	page, err := env.EventLog.WaitScan(waitCtx, cursor, env.validatePerPage(maxItemsPtr), match)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*coretypes.EventItem, len(page.Items))
+	for i, item := range page.Items {
+		items[i] = &coretypes.EventItem{
+			Cursor: item.Cursor.String(),
+			Event:  item.Type,
+			Data:   item.Data,
+			Events: item.Events,
+		}
+	}
+	return &coretypes.ResultEvents{
+		Items:      items,
+		More:       page.More,
+		NextCursor: page.Next.String(),
+		Oldest:     page.Oldest.String(),
+		Newest:     page.Newest.String(),
+	}, nil
+}
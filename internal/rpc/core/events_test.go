@@ -0,0 +1,85 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/eventbus"
+	"github.com/tendermint/tendermint/internal/eventlog"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestEvents(t *testing.T) {
+	lg, err := eventlog.New(eventlog.Options{WindowSize: time.Hour})
+	require.NoError(t, err)
+	eventBus := eventbus.NewDefault()
+	eventBus.SetEventLog(lg)
+	require.NoError(t, eventBus.Start())
+	t.Cleanup(func() { _ = eventBus.Stop() })
+
+	env := &Environment{EventBus: eventBus, EventLog: lg}
+	ctx := &rpctypes.Context{}
+	publishTx := func(height int64) {
+		require.NoError(t, eventBus.PublishEventTx(types.EventDataTx{
+			TxResult: abci.TxResult{Height: height, Tx: types.Tx("tx")},
+		}))
+	}
+
+	for h := int64(1); h <= 3; h++ {
+		require.NoError(t, eventBus.PublishEventNewBlockHeader(types.EventDataNewBlockHeader{}))
+		publishTx(h)
+	}
+
+	one := 1
+	res, err := env.Events(ctx, "tm.event = 'Tx'", &one, "", "")
+	require.NoError(t, err)
+	require.Len(t, res.Items, 1)
+	assert.True(t, res.More)
+	assert.Equal(t, types.EventTxValue, res.Items[0].Event)
+	assert.Equal(t, int64(1), res.Items[0].Data.(types.EventDataTx).Height)
+	assert.Equal(t, res.Items[0].Cursor, res.NextCursor)
+
+	res, err = env.Events(ctx, "tm.event = 'Tx'", nil, res.NextCursor, "")
+	require.NoError(t, err)
+	require.Len(t, res.Items, 2)
+	assert.False(t, res.More)
+	assert.Equal(t, int64(3), res.Items[1].Data.(types.EventDataTx).Height)
+	assert.Equal(t, res.Newest, res.NextCursor)
+
+	// The request waits for events following the cursor.
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		publishTx(4)
+	}()
+	res, err = env.Events(ctx, "tm.event = 'Tx'", nil, res.NextCursor, "5s")
+	require.NoError(t, err)
+	require.Len(t, res.Items, 1)
+	assert.Equal(t, int64(4), res.Items[0].Data.(types.EventDataTx).Height)
+
+	// Or returns no events after the wait time.
+	start := time.Now()
+	res, err = env.Events(ctx, "", nil, res.NextCursor, "20ms")
+	require.NoError(t, err)
+	assert.Empty(t, res.Items)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(20*time.Millisecond))
+
+	for _, tc := range []struct{ query, after, wait string }{
+		{"tm.event = ", "", ""},
+		{"", "cursor", ""},
+		{"", "", "5"},
+		{"", "", "-1s"},
+	} {
+		_, err = env.Events(ctx, tc.query, nil, tc.after, tc.wait)
+		assert.True(t, errors.Is(err, coretypes.ErrInvalidRequest), tc)
+	}
+
+	_, err = (&Environment{}).Events(ctx, "", nil, "", "")
+	assert.Error(t, err)
+}
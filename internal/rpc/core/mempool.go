@@ -73,12 +73,12 @@ func (env *Environment) BroadcastTxCommit(ctx *rpctypes.Context, tx types.Tx) (*
 
 	r := (<-resCh).GetCheckTx()
 
-	if !indexer.KVSinkEnabled(env.EventSinks) {
+	if indexer.SearchSink(env.EventSinks) == nil {
 		return &coretypes.ResultBroadcastTxCommit{
 				CheckTx: *r,
 				Hash:    tx.Hash(),
 			},
-			errors.New("cannot confirm transaction because kvEventSink is not enabled")
+			errors.New("cannot confirm transaction because no kv, psql or sqlite event sink is enabled")
 	}
 
 	startAt := time.Now()
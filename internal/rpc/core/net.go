@@ -3,9 +3,12 @@ package core
 import (
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/rpc/coretypes"
 	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"github.com/tendermint/tendermint/types"
 )
 
 // NetInfo returns network info.
@@ -13,6 +16,15 @@ import (
 func (env *Environment) NetInfo(ctx *rpctypes.Context) (*coretypes.ResultNetInfo, error) {
 	peerList := env.PeerManager.Peers()
 
+	var peerStats map[types.NodeID]p2p.PeerStats
+	if env.Router != nil {
+		stats := env.Router.PeerStats()
+		peerStats = make(map[types.NodeID]p2p.PeerStats, len(stats))
+		for _, s := range stats {
+			peerStats[s.NodeID] = s
+		}
+	}
+
 	peers := make([]coretypes.Peer, 0, len(peerList))
 	for _, peer := range peerList {
 		addrs := env.PeerManager.Addresses(peer)
@@ -20,10 +32,14 @@ func (env *Environment) NetInfo(ctx *rpctypes.Context) (*coretypes.ResultNetInfo
 			continue
 		}
 
-		peers = append(peers, coretypes.Peer{
+		p := coretypes.Peer{
 			ID:  peer,
 			URL: addrs[0].String(),
-		})
+		}
+		if stats, ok := peerStats[peer]; ok {
+			p.ConnectionStatus = peerConnectionStatus(stats)
+		}
+		peers = append(peers, p)
 	}
 
 	return &coretypes.ResultNetInfo{
@@ -34,6 +50,29 @@ func (env *Environment) NetInfo(ctx *rpctypes.Context) (*coretypes.ResultNetInfo
 	}, nil
 }
 
+// peerConnectionStatus converts router peer stats to their RPC representation.
+func peerConnectionStatus(stats p2p.PeerStats) *coretypes.PeerConnectionStatus {
+	status := &coretypes.PeerConnectionStatus{
+		Protocol:      string(stats.Protocol),
+		ConnectedAt:   stats.ConnectedAt,
+		Duration:      time.Since(stats.ConnectedAt),
+		Latency:       stats.Latency,
+		SendQueueSize: stats.QueueDepth,
+		Channels:      make([]coretypes.PeerChannelStatus, 0, len(stats.Channels)),
+	}
+	for _, ch := range stats.Channels {
+		status.Channels = append(status.Channels, coretypes.PeerChannelStatus{
+			ID:               uint16(ch.ChannelID),
+			MessagesSent:     ch.MessagesSent,
+			BytesSent:        ch.BytesSent,
+			MessagesReceived: ch.MessagesReceived,
+			BytesReceived:    ch.BytesReceived,
+			MessagesDropped:  ch.MessagesDropped,
+		})
+	}
+	return status
+}
+
 // Genesis returns genesis file.
 // More: https://docs.tendermint.com/master/rpc/#/Info/genesis
 func (env *Environment) Genesis(ctx *rpctypes.Context) (*coretypes.ResultGenesis, error) {
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/p2p/p2ptest"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// memoryTransport reports the listening state of a node of an in-memory test
+// network.
+type memoryTransport struct {
+	info types.NodeInfo
+}
+
+func (t memoryTransport) Listeners() []string      { return []string{"memory"} }
+func (t memoryTransport) IsListening() bool        { return true }
+func (t memoryTransport) NodeInfo() types.NodeInfo { return t.info }
+
+func TestNetInfo(t *testing.T) {
+	network := p2ptest.MakeNetwork(t, p2ptest.NetworkOptions{NumNodes: 2, BufferSize: 10})
+	local := network.RandomNode()
+	peer := network.Peers(local.NodeID)[0]
+	channels := network.MakeChannels(t, p2ptest.MakeChannelDesc(1))
+	network.Start(t)
+
+	p2ptest.RequireSend(t, channels[local.NodeID], p2p.Envelope{
+		To:      peer.NodeID,
+		Message: &p2ptest.Message{Value: "foo"},
+	})
+	p2ptest.RequireReceive(t, channels[peer.NodeID], p2p.Envelope{
+		From:    local.NodeID,
+		Message: &p2ptest.Message{Value: "foo"},
+	})
+
+	env := &Environment{
+		P2PTransport: memoryTransport{info: local.NodeInfo},
+		PeerManager:  local.PeerManager,
+		Router:       local.Router,
+	}
+	result, err := env.NetInfo(&rpctypes.Context{})
+	require.NoError(t, err)
+	require.True(t, result.Listening)
+	require.Equal(t, 1, result.NPeers)
+	require.Len(t, result.Peers, 1)
+
+	p := result.Peers[0]
+	require.Equal(t, peer.NodeID, p.ID)
+	require.Equal(t, peer.NodeAddress.String(), p.URL)
+	status := p.ConnectionStatus
+	require.NotNil(t, status)
+	require.Equal(t, string(p2p.MemoryProtocol), status.Protocol)
+	require.False(t, status.ConnectedAt.IsZero())
+	require.Less(t, time.Duration(0), status.Duration)
+	require.Len(t, status.Channels, 1)
+	require.EqualValues(t, 1, status.Channels[0].ID)
+	require.EqualValues(t, 1, status.Channels[0].MessagesSent)
+	require.NotZero(t, status.Channels[0].BytesSent)
+	require.Zero(t, status.Channels[0].MessagesDropped)
+}
@@ -16,6 +16,9 @@ func (env *Environment) GetRoutes() RoutesMap {
 		"unsubscribe":     rpc.NewWSRPCFunc(env.Unsubscribe, "query"),
 		"unsubscribe_all": rpc.NewWSRPCFunc(env.UnsubscribeAll, ""),
 
+		// events reads the event log over HTTP and websocket.
+		"events": rpc.NewRPCFunc(env.Events, "query,max_items,after,wait_time", false),
+
 		// info API
 		"health":               rpc.NewRPCFunc(env.Health, "", false),
 		"status":               rpc.NewRPCFunc(env.Status, "", false),
@@ -30,8 +33,8 @@ func (env *Environment) GetRoutes() RoutesMap {
 		"check_tx":             rpc.NewRPCFunc(env.CheckTx, "tx", true),
 		"remove_tx":            rpc.NewRPCFunc(env.RemoveTx, "txkey", false),
 		"tx":                   rpc.NewRPCFunc(env.Tx, "hash,prove", true),
-		"tx_search":            rpc.NewRPCFunc(env.TxSearch, "query,prove,page,per_page,order_by", false),
-		"block_search":         rpc.NewRPCFunc(env.BlockSearch, "query,page,per_page,order_by", false),
+		"tx_search":            rpc.NewRPCFunc(env.TxSearch, "query,prove,page,per_page,order_by,cursor", false),
+		"block_search":         rpc.NewRPCFunc(env.BlockSearch, "query,page,per_page,order_by,cursor", false),
 		"validators":           rpc.NewRPCFunc(env.Validators, "height,page,per_page", true),
 		"dump_consensus_state": rpc.NewRPCFunc(env.DumpConsensusState, "", false),
 		"consensus_state":      rpc.NewRPCFunc(env.GetConsensusState, "", false),
@@ -78,6 +78,17 @@ func (env *Environment) Status(ctx *rpctypes.Context) (*coretypes.ResultStatus,
 		ValidatorInfo: validatorInfo,
 	}
 
+	if env.IndexerService != nil {
+		for _, st := range env.IndexerService.Status() {
+			result.IndexerInfo = append(result.IndexerInfo, coretypes.IndexerInfo{
+				Sink:          string(st.Type),
+				IndexedHeight: st.IndexedHeight,
+				Lag:           st.Lag,
+				Backfilling:   st.Backfilling,
+			})
+		}
+	}
+
 	if env.StateSyncMetricer != nil {
 		result.SyncInfo.TotalSnapshots = env.StateSyncMetricer.TotalSnapshots()
 		result.SyncInfo.ChunkProcessAvgTime = env.StateSyncMetricer.ChunkProcessAvgTime()
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 
+	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/internal/state/indexer"
 	"github.com/tendermint/tendermint/libs/bytes"
 	tmmath "github.com/tendermint/tendermint/libs/math"
@@ -25,42 +26,42 @@ func (env *Environment) Tx(ctx *rpctypes.Context, hash bytes.HexBytes, prove boo
 	// decoding logic in the HTTP service will correctly translate from JSON.
 	// See https://github.com/tendermint/tendermint/issues/6802 for context.
 
-	if !indexer.KVSinkEnabled(env.EventSinks) {
-		return nil, errors.New("transaction querying is disabled due to no kvEventSink")
+	sink := indexer.SearchSink(env.EventSinks)
+	if sink == nil {
+		return nil, errors.New("transaction querying is disabled due to no kv, psql or sqlite event sink")
 	}
 
-	for _, sink := range env.EventSinks {
-		if sink.Type() == indexer.KV {
-			r, err := sink.GetTxByHash(hash)
-			if r == nil {
-				return nil, fmt.Errorf("tx (%X) not found, err: %w", hash, err)
-			}
-
-			height := r.Height
-			index := r.Index
+	r, err := sink.GetTxByHash(hash)
+	if r == nil {
+		return nil, fmt.Errorf("tx (%X) not found, err: %w", hash, err)
+	}
 
-			var proof types.TxProof
-			if prove {
-				block := env.BlockStore.LoadBlock(height)
-				proof = block.Data.Txs.Proof(int(index)) // XXX: overflow on 32-bit machines
-			}
+	height := r.Height
+	index := r.Index
 
-			return &coretypes.ResultTx{
-				Hash:     hash,
-				Height:   height,
-				Index:    index,
-				TxResult: r.Result,
-				Tx:       r.Tx,
-				Proof:    proof,
-			}, nil
-		}
+	var proof types.TxProof
+	if prove {
+		block := env.BlockStore.LoadBlock(height)
+		proof = block.Data.Txs.Proof(int(index)) // XXX: overflow on 32-bit machines
 	}
 
-	return nil, fmt.Errorf("transaction querying is disabled on this node due to the KV event sink being disabled")
+	return &coretypes.ResultTx{
+		Hash:     hash,
+		Height:   height,
+		Index:    index,
+		TxResult: r.Result,
+		Tx:       r.Tx,
+		Proof:    proof,
+	}, nil
 }
 
 // TxSearch allows you to query for multiple transactions results. It returns a
 // list of transactions (maximum ?per_page entries) and the total count.
+//
+// If a cursor is given, the page following the cursor is returned instead of
+// the numbered page, without computing the total count. An empty cursor
+// selects the first page. In both cases, the cursor of the next page is
+// returned if there are more transactions.
 // More: https://docs.tendermint.com/master/rpc/#/Info/tx_search
 func (env *Environment) TxSearch(
 	ctx *rpctypes.Context,
@@ -68,10 +69,12 @@ func (env *Environment) TxSearch(
 	prove bool,
 	pagePtr, perPagePtr *int,
 	orderBy string,
+	cursorPtr *string,
 ) (*coretypes.ResultTxSearch, error) {
 
-	if !indexer.KVSinkEnabled(env.EventSinks) {
-		return nil, fmt.Errorf("transaction searching is disabled due to no kvEventSink")
+	sink := indexer.SearchSink(env.EventSinks)
+	if sink == nil {
+		return nil, fmt.Errorf("transaction searching is disabled due to no kv, psql or sqlite event sink")
 	}
 
 	q, err := tmquery.New(query)
@@ -79,68 +82,111 @@ func (env *Environment) TxSearch(
 		return nil, err
 	}
 
-	for _, sink := range env.EventSinks {
-		if sink.Type() == indexer.KV {
-			results, err := sink.SearchTxEvents(ctx.Context(), q)
-			if err != nil {
-				return nil, err
-			}
+	if cursorPtr != nil {
+		return env.txSearchPage(ctx, sink, q, prove, perPagePtr, orderBy, *cursorPtr)
+	}
 
-			// sort results (must be done before pagination)
-			switch orderBy {
-			case "desc", "":
-				sort.Slice(results, func(i, j int) bool {
-					if results[i].Height == results[j].Height {
-						return results[i].Index > results[j].Index
-					}
-					return results[i].Height > results[j].Height
-				})
-			case "asc":
-				sort.Slice(results, func(i, j int) bool {
-					if results[i].Height == results[j].Height {
-						return results[i].Index < results[j].Index
-					}
-					return results[i].Height < results[j].Height
-				})
-			default:
-				return nil, fmt.Errorf("expected order_by to be either `asc` or `desc` or empty: %w", coretypes.ErrInvalidRequest)
+	results, err := sink.SearchTxEvents(ctx.Context(), q)
+	if err != nil {
+		return nil, err
+	}
+
+	// sort results (must be done before pagination)
+	switch orderBy {
+	case "desc", "":
+		sort.Slice(results, func(i, j int) bool {
+			if results[i].Height == results[j].Height {
+				return results[i].Index > results[j].Index
+			}
+			return results[i].Height > results[j].Height
+		})
+	case "asc":
+		sort.Slice(results, func(i, j int) bool {
+			if results[i].Height == results[j].Height {
+				return results[i].Index < results[j].Index
 			}
+			return results[i].Height < results[j].Height
+		})
+	default:
+		return nil, fmt.Errorf("expected order_by to be either `asc` or `desc` or empty: %w", coretypes.ErrInvalidRequest)
+	}
 
-			// paginate results
-			totalCount := len(results)
-			perPage := env.validatePerPage(perPagePtr)
+	// paginate results
+	totalCount := len(results)
+	perPage := env.validatePerPage(perPagePtr)
 
-			page, err := validatePage(pagePtr, perPage, totalCount)
-			if err != nil {
-				return nil, err
-			}
+	page, err := validatePage(pagePtr, perPage, totalCount)
+	if err != nil {
+		return nil, err
+	}
 
-			skipCount := validateSkipCount(page, perPage)
-			pageSize := tmmath.MinInt(perPage, totalCount-skipCount)
-
-			apiResults := make([]*coretypes.ResultTx, 0, pageSize)
-			for i := skipCount; i < skipCount+pageSize; i++ {
-				r := results[i]
-
-				var proof types.TxProof
-				if prove {
-					block := env.BlockStore.LoadBlock(r.Height)
-					proof = block.Data.Txs.Proof(int(r.Index)) // XXX: overflow on 32-bit machines
-				}
-
-				apiResults = append(apiResults, &coretypes.ResultTx{
-					Hash:     types.Tx(r.Tx).Hash(),
-					Height:   r.Height,
-					Index:    r.Index,
-					TxResult: r.Result,
-					Tx:       r.Tx,
-					Proof:    proof,
-				})
-			}
+	skipCount := validateSkipCount(page, perPage)
+	pageSize := tmmath.MinInt(perPage, totalCount-skipCount)
+
+	apiResults := make([]*coretypes.ResultTx, 0, pageSize)
+	for i := skipCount; i < skipCount+pageSize; i++ {
+		apiResults = append(apiResults, env.resultTx(results[i], prove))
+	}
+
+	var nextCursor string
+	if skipCount+pageSize < totalCount {
+		last := results[skipCount+pageSize-1]
+		nextCursor = indexer.Cursor{Height: last.Height, Index: last.Index}.String()
+	}
 
-			return &coretypes.ResultTxSearch{Txs: apiResults, TotalCount: totalCount}, nil
-		}
+	return &coretypes.ResultTxSearch{Txs: apiResults, TotalCount: totalCount, NextCursor: nextCursor}, nil
+}
+
+// txSearchPage returns the page of the transactions matching the query which
+// follows the cursor.
+func (env *Environment) txSearchPage(
+	ctx *rpctypes.Context,
+	sink indexer.EventSink,
+	q *tmquery.Query,
+	prove bool,
+	perPagePtr *int,
+	orderBy string,
+	cursor string,
+) (*coretypes.ResultTxSearch, error) {
+	page, err := env.searchPage(perPagePtr, orderBy, cursor)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, fmt.Errorf("transaction searching is disabled on this node due to the KV event sink being disabled")
+	results, more, err := indexer.SearchTxEventsPage(ctx.Context(), sink, q, page)
+	if err != nil {
+		return nil, err
+	}
+
+	apiResults := make([]*coretypes.ResultTx, 0, len(results))
+	for _, r := range results {
+		apiResults = append(apiResults, env.resultTx(r, prove))
+	}
+
+	var nextCursor string
+	if more {
+		last := results[len(results)-1]
+		nextCursor = indexer.Cursor{Height: last.Height, Index: last.Index}.String()
+	}
+
+	return &coretypes.ResultTxSearch{Txs: apiResults, TotalCount: -1, NextCursor: nextCursor}, nil
+}
+
+// resultTx returns the RPC result of the transaction, with its proof if
+// requested.
+func (env *Environment) resultTx(r *abci.TxResult, prove bool) *coretypes.ResultTx {
+	var proof types.TxProof
+	if prove {
+		block := env.BlockStore.LoadBlock(r.Height)
+		proof = block.Data.Txs.Proof(int(r.Index)) // XXX: overflow on 32-bit machines
+	}
+
+	return &coretypes.ResultTx{
+		Hash:     types.Tx(r.Tx).Hash(),
+		Height:   r.Height,
+		Index:    r.Index,
+		TxResult: r.Result,
+		Tx:       r.Tx,
+		Proof:    proof,
+	}
 }
@@ -0,0 +1,140 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	rpcserver "github.com/tendermint/tendermint/rpc/jsonrpc/server"
+)
+
+// routes maps the methods of the service to the routes of the JSON RPC
+// interface, whose allow lists apply to them.
+var routes = map[string]string{
+	"Health":             "health",
+	"Status":             "status",
+	"NetInfo":            "net_info",
+	"DumpConsensusState": "dump_consensus_state",
+	"ConsensusState":     "consensus_state",
+	"BlockchainInfo":     "blockchain",
+	"GenesisChunked":     "genesis_chunked",
+	"Block":              "block",
+	"BlockByHash":        "block_by_hash",
+	"BlockResults":       "block_results",
+	"Commit":             "commit",
+	"Validators":         "validators",
+	"ConsensusParams":    "consensus_params",
+	"Tx":                 "tx",
+	"TxSearch":           "tx_search",
+	"BlockSearch":        "block_search",
+	"CheckTx":            "check_tx",
+	"RemoveTx":           "remove_tx",
+	"UnconfirmedTxs":     "unconfirmed_txs",
+	"NumUnconfirmedTxs":  "num_unconfirmed_txs",
+	"BroadcastTxAsync":   "broadcast_tx_async",
+	"BroadcastTxSync":    "broadcast_tx_sync",
+	"BroadcastTxCommit":  "broadcast_tx_commit",
+	"BroadcastEvidence":  "broadcast_evidence",
+	"ABCIInfo":           "abci_info",
+	"ABCIQuery":          "abci_query",
+	"Events":             "subscribe",
+}
+
+// AuthServerOptions returns the options of a server authenticating the
+// clients as configured, and restricting the methods each client may call to
+// those allowed for the matching routes of the JSON RPC interface.
+//
+// Clients send their bearer token in the "authorization" metadata, or
+// authenticate with a client certificate if the server uses TLS credentials
+// verifying them.
+func AuthServerOptions(config *rpcserver.AuthConfig) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(
+			ctx context.Context,
+			req interface{},
+			info *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler,
+		) (interface{}, error) {
+			client, err := authorize(ctx, config, info.FullMethod)
+			if err != nil {
+				return nil, err
+			}
+			return handler(context.WithValue(ctx, clientContextKey{}, client), req)
+		}),
+		grpc.ChainStreamInterceptor(func(
+			srv interface{},
+			stream grpc.ServerStream,
+			info *grpc.StreamServerInfo,
+			handler grpc.StreamHandler,
+		) error {
+			client, err := authorize(stream.Context(), config, info.FullMethod)
+			if err != nil {
+				return err
+			}
+			return handler(srv, serverStream{
+				ServerStream: stream,
+				ctx:          context.WithValue(stream.Context(), clientContextKey{}, client),
+			})
+		}),
+	}
+}
+
+type clientContextKey struct{}
+
+// clientFromContext returns the name of the authenticated client of a call,
+// or "" if the server doesn't authenticate clients.
+func clientFromContext(ctx context.Context) string {
+	client, _ := ctx.Value(clientContextKey{}).(string)
+	return client
+}
+
+// serverStream is a server stream with the given context.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s serverStream) Context() context.Context { return s.ctx }
+
+// methodRoute returns the route of the JSON RPC interface matching the method
+// of a call, and whether there is one.
+func methodRoute(fullMethod string) (string, string, bool) {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	route, ok := routes[method]
+	return method, route, ok
+}
+
+// authorize authenticates the client of a call and checks it may call the
+// method, returning the name of the client.
+func authorize(ctx context.Context, config *rpcserver.AuthConfig, fullMethod string) (string, error) {
+	var authorization string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+	var state *tls.ConnectionState
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			state = &info.State
+		}
+	}
+
+	client, err := config.Authenticate(authorization, state)
+	if err != nil {
+		return "", status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	method, route, ok := methodRoute(fullMethod)
+	if !ok || !config.Allowed(client, route) {
+		return "", status.Errorf(codes.PermissionDenied, "%s may not call %s", client, method)
+	}
+	return client, nil
+}
@@ -0,0 +1,81 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	rpcproto "github.com/tendermint/tendermint/proto/tendermint/rpc"
+	rpcserver "github.com/tendermint/tendermint/rpc/jsonrpc/server"
+)
+
+type testService struct {
+	rpcproto.UnimplementedRPCServiceServer
+}
+
+func (testService) Health(context.Context, *rpcproto.HealthRequest) (*rpcproto.HealthResponse, error) {
+	return &rpcproto.HealthResponse{}, nil
+}
+
+func (testService) BroadcastTxCommit(
+	context.Context,
+	*rpcproto.BroadcastTxRequest,
+) (*rpcproto.BroadcastTxCommitResponse, error) {
+	return &rpcproto.BroadcastTxCommitResponse{}, nil
+}
+
+func (testService) Events(*rpcproto.EventsRequest, rpcproto.RPCService_EventsServer) error {
+	return nil
+}
+
+func TestAuthServerOptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	auth := &rpcserver.AuthConfig{
+		Keys: map[string]string{"ops": "ops-token", "user": "user-token"},
+		Routes: map[string][]string{
+			"broadcast_tx_commit": {"ops"},
+			"subscribe":           {"ops"},
+		},
+	}
+	srv := grpc.NewServer(AuthServerOptions(auth)...)
+	rpcproto.RegisterRPCServiceServer(srv, &testService{})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go srv.Serve(l) //nolint:errcheck // ignore for tests
+	defer srv.Stop()
+
+	conn, err := grpc.DialContext(ctx, l.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := rpcproto.NewRPCServiceClient(conn)
+
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	}
+
+	_, err = client.Health(ctx, &rpcproto.HealthRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = client.Health(withToken("other-token"), &rpcproto.HealthRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = client.Health(withToken("user-token"), &rpcproto.HealthRequest{})
+	assert.NoError(t, err)
+	_, err = client.BroadcastTxCommit(withToken("user-token"), &rpcproto.BroadcastTxRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.BroadcastTxCommit(withToken("ops-token"), &rpcproto.BroadcastTxRequest{})
+	assert.NoError(t, err)
+
+	stream, err := client.Events(withToken("user-token"), &rpcproto.EventsRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
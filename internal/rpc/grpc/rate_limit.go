@@ -0,0 +1,87 @@
+package grpc
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	rpcserver "github.com/tendermint/tendermint/rpc/jsonrpc/server"
+)
+
+// RateLimitServerOptions returns the options of a server limiting the rate of
+// the calls of each client, which cost as much as the matching routes of the
+// JSON RPC interface. Clients are identified by their name if they
+// authenticated, so the options must follow those of AuthServerOptions, if
+// any, and by their IP address otherwise.
+//
+// Calls over the limit fail with the ResourceExhausted code, and a
+// "retry-after" header (in seconds) when waiting helps.
+func RateLimitServerOptions(limiter *rpcserver.RateLimiter) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(
+			ctx context.Context,
+			req interface{},
+			info *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler,
+		) (interface{}, error) {
+			if err := allow(ctx, limiter, info.FullMethod, func(md metadata.MD) error {
+				return grpc.SetHeader(ctx, md)
+			}); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(
+			srv interface{},
+			stream grpc.ServerStream,
+			info *grpc.StreamServerInfo,
+			handler grpc.StreamHandler,
+		) error {
+			if err := allow(stream.Context(), limiter, info.FullMethod, stream.SetHeader); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	}
+}
+
+// allow takes the cost of a call from the bucket of its client, returning an
+// error if the client exceeded its rate limit. setHeader sets the headers of
+// the response.
+func allow(
+	ctx context.Context,
+	limiter *rpcserver.RateLimiter,
+	fullMethod string,
+	setHeader func(metadata.MD) error,
+) error {
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String() // e.g. a unix socket
+		}
+		ip = host
+	}
+	method, route, ok := methodRoute(fullMethod)
+	if !ok {
+		route = method
+	}
+
+	wait, err := limiter.Allow(clientFromContext(ctx), ip, route)
+	if err == nil {
+		return nil
+	}
+	if wait > 0 {
+		retryAfter := strconv.Itoa(int(math.Ceil(float64(wait) / float64(time.Second))))
+		_ = setHeader(metadata.Pairs("retry-after", retryAfter))
+	}
+	return status.Error(codes.ResourceExhausted, err.Error())
+}
@@ -0,0 +1,135 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	rpcproto "github.com/tendermint/tendermint/proto/tendermint/rpc"
+	rpcserver "github.com/tendermint/tendermint/rpc/jsonrpc/server"
+)
+
+// rejectedCounter counts the rejected requests by reason.
+type rejectedCounter struct {
+	mtx    *sync.Mutex
+	counts map[string]float64
+	reason string
+}
+
+func (c *rejectedCounter) With(labelsAndValues ...string) metrics.Counter {
+	cc := *c
+	cc.reason = labelsAndValues[len(labelsAndValues)-1]
+	return &cc
+}
+
+func (c *rejectedCounter) Add(delta float64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.counts[c.reason] += delta
+}
+
+func (c *rejectedCounter) count(reason string) float64 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.counts[reason]
+}
+
+func TestRateLimitServerOptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Each client may spend 5 units, and no more for the duration of the test.
+	counter := &rejectedCounter{mtx: new(sync.Mutex), counts: make(map[string]float64)}
+	limiter := rpcserver.NewRateLimiter(&rpcserver.RateLimitConfig{
+		Rate:  0.001,
+		Burst: 5,
+		Costs: map[string]int{"broadcast_tx_commit": 4},
+	}, &rpcserver.Metrics{RejectedRequests: counter})
+	auth := &rpcserver.AuthConfig{
+		Keys: map[string]string{"ops": "ops-token", "user": "user-token"},
+	}
+
+	var opts []grpc.ServerOption
+	opts = append(opts, AuthServerOptions(auth)...)
+	opts = append(opts, RateLimitServerOptions(limiter)...)
+	srv := grpc.NewServer(opts...)
+	rpcproto.RegisterRPCServiceServer(srv, &testService{})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go srv.Serve(l) //nolint:errcheck // ignore for tests
+	defer srv.Stop()
+
+	conn, err := grpc.DialContext(ctx, l.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	client := rpcproto.NewRPCServiceClient(conn)
+
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	}
+
+	// The calls cost as much as their routes.
+	_, err = client.BroadcastTxCommit(withToken("user-token"), &rpcproto.BroadcastTxRequest{})
+	require.NoError(t, err)
+	_, err = client.Health(withToken("user-token"), &rpcproto.HealthRequest{})
+	require.NoError(t, err)
+	var header metadata.MD
+	_, err = client.Health(withToken("user-token"), &rpcproto.HealthRequest{}, grpc.Header(&header))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"1000"}, header.Get("retry-after"))
+
+	stream, err := client.Events(withToken("user-token"), &rpcproto.EventsRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Clients are limited separately, by their name.
+	_, err = client.BroadcastTxCommit(withToken("ops-token"), &rpcproto.BroadcastTxRequest{})
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 2, counter.count("rate_limit"))
+}
+
+func TestRateLimitServerOptionsIP(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	counter := &rejectedCounter{mtx: new(sync.Mutex), counts: make(map[string]float64)}
+	limiter := rpcserver.NewRateLimiter(&rpcserver.RateLimitConfig{
+		Rate:  0.001,
+		Burst: 2,
+	}, &rpcserver.Metrics{RejectedRequests: counter})
+
+	srv := grpc.NewServer(RateLimitServerOptions(limiter)...)
+	rpcproto.RegisterRPCServiceServer(srv, &testService{})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go srv.Serve(l) //nolint:errcheck // ignore for tests
+	defer srv.Stop()
+
+	// Clients which don't authenticate are limited by their IP address, over
+	// all their connections.
+	for i := 0; i < 2; i++ {
+		conn, err := grpc.DialContext(ctx, l.Addr().String(), grpc.WithInsecure())
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = rpcproto.NewRPCServiceClient(conn).Health(ctx, &rpcproto.HealthRequest{})
+		require.NoError(t, err)
+	}
+	conn, err := grpc.DialContext(ctx, l.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = rpcproto.NewRPCServiceClient(conn).Health(ctx, &rpcproto.HealthRequest{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	assert.EqualValues(t, 1, counter.count("rate_limit"))
+}
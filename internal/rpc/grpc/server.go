@@ -0,0 +1,460 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	rpccore "github.com/tendermint/tendermint/internal/rpc/core"
+	"github.com/tendermint/tendermint/libs/log"
+	tmpubsub "github.com/tendermint/tendermint/libs/pubsub"
+	tmquery "github.com/tendermint/tendermint/libs/pubsub/query"
+	rpcproto "github.com/tendermint/tendermint/proto/tendermint/rpc"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// subBufferSize is the number of events buffered for a stream, to allow some
+// slowness in clients.
+const subBufferSize = 100
+
+// Server implements RPCServiceServer (generated via protobuf services) by
+// calling the methods of the RPC environment, as the JSON-RPC server does.
+type Server struct {
+	env    *rpccore.Environment
+	logger log.Logger
+}
+
+// NewServer creates a gRPC server for the RPC environment.
+func NewServer(env *rpccore.Environment, logger log.Logger) *Server {
+	return &Server{
+		env:    env,
+		logger: logger,
+	}
+}
+
+var _ rpcproto.RPCServiceServer = (*Server)(nil)
+
+// rpcContext returns the context passed to the methods of the RPC environment,
+// which carries the context and the address of the client.
+func rpcContext(ctx context.Context) *rpctypes.Context {
+	req := (&http.Request{RemoteAddr: remoteAddr(ctx)}).WithContext(ctx)
+	return &rpctypes.Context{HTTPReq: req}
+}
+
+// remoteAddr returns the address of the client.
+func remoteAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// toStatus converts an error returned by the RPC environment to a gRPC status.
+func toStatus(err error) error {
+	code := codes.Unknown
+	switch {
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, coretypes.ErrInvalidRequest),
+		errors.Is(err, coretypes.ErrZeroOrNegativeHeight),
+		errors.Is(err, coretypes.ErrZeroOrNegativePerPage),
+		errors.Is(err, coretypes.ErrPageOutOfRange):
+		code = codes.InvalidArgument
+	case errors.Is(err, coretypes.ErrHeightExceedsChainHead),
+		errors.Is(err, coretypes.ErrHeightNotAvailable):
+		code = codes.OutOfRange
+	}
+	return status.Error(code, err.Error())
+}
+
+// heightPtr returns the height parameter of a request, which selects the
+// latest height when zero.
+func heightPtr(height int64) *int64 {
+	if height == 0 {
+		return nil
+	}
+	return &height
+}
+
+// intPtr returns a page or limit parameter of a request, which selects the
+// default value when zero.
+func intPtr(n int32) *int {
+	if n == 0 {
+		return nil
+	}
+	i := int(n)
+	return &i
+}
+
+// cursorPtr returns the cursor parameter of a search request.
+func cursorPtr(cursor *rpcproto.Cursor) *string {
+	if cursor == nil {
+		return nil
+	}
+	return &cursor.Position
+}
+
+func (s *Server) Health(ctx context.Context, req *rpcproto.HealthRequest) (*rpcproto.HealthResponse, error) {
+	if _, err := s.env.Health(rpcContext(ctx)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &rpcproto.HealthResponse{}, nil
+}
+
+func (s *Server) Status(ctx context.Context, req *rpcproto.StatusRequest) (*rpcproto.StatusResponse, error) {
+	res, err := s.env.Status(rpcContext(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	pb, err := res.ToProto()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "converting status to proto: %v", err)
+	}
+	return pb, nil
+}
+
+func (s *Server) NetInfo(ctx context.Context, req *rpcproto.NetInfoRequest) (*rpcproto.NetInfoResponse, error) {
+	res, err := s.env.NetInfo(rpcContext(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return res.ToProto(), nil
+}
+
+func (s *Server) DumpConsensusState(ctx context.Context, req *rpcproto.DumpConsensusStateRequest) (
+	*rpcproto.DumpConsensusStateResponse, error) {
+	res, err := s.env.DumpConsensusState(rpcContext(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return res.ToProto(), nil
+}
+
+func (s *Server) ConsensusState(ctx context.Context, req *rpcproto.ConsensusStateRequest) (
+	*rpcproto.ConsensusStateResponse, error) {
+	res, err := s.env.GetConsensusState(rpcContext(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &rpcproto.ConsensusStateResponse{RoundState: res.RoundState}, nil
+}
+
+func (s *Server) BlockchainInfo(ctx context.Context, req *rpcproto.BlockchainInfoRequest) (
+	*rpcproto.BlockchainInfoResponse, error) {
+	res, err := s.env.BlockchainInfo(rpcContext(ctx), req.MinHeight, req.MaxHeight)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return res.ToProto(), nil
+}
+
+func (s *Server) GenesisChunked(ctx context.Context, req *rpcproto.GenesisChunkedRequest) (
+	*rpcproto.GenesisChunkedResponse, error) {
+	res, err := s.env.GenesisChunked(rpcContext(ctx), uint(req.Chunk))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &rpcproto.GenesisChunkedResponse{
+		Chunk: uint32(res.ChunkNumber),
+		Total: uint32(res.TotalChunks),
+		Data:  res.Data,
+	}, nil
+}
+
+func (s *Server) Block(ctx context.Context, req *rpcproto.BlockRequest) (*rpcproto.BlockResponse, error) {
+	res, err := s.env.Block(rpcContext(ctx), heightPtr(req.Height))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return blockToProto(res)
+}
+
+func (s *Server) BlockByHash(ctx context.Context, req *rpcproto.BlockByHashRequest) (*rpcproto.BlockResponse, error) {
+	res, err := s.env.BlockByHash(rpcContext(ctx), req.Hash)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return blockToProto(res)
+}
+
+func blockToProto(res *coretypes.ResultBlock) (*rpcproto.BlockResponse, error) {
+	pb, err := res.ToProto()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "converting block to proto: %v", err)
+	}
+	return pb, nil
+}
+
+func (s *Server) BlockResults(ctx context.Context, req *rpcproto.BlockResultsRequest) (
+	*rpcproto.BlockResultsResponse, error) {
+	res, err := s.env.BlockResults(rpcContext(ctx), heightPtr(req.Height))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &rpcproto.BlockResultsResponse{
+		Height:                res.Height,
+		TxsResults:            res.TxsResults,
+		TotalGasUsed:          res.TotalGasUsed,
+		BeginBlockEvents:      res.BeginBlockEvents,
+		EndBlockEvents:        res.EndBlockEvents,
+		ValidatorUpdates:      res.ValidatorUpdates,
+		ConsensusParamUpdates: res.ConsensusParamUpdates,
+	}, nil
+}
+
+func (s *Server) Commit(ctx context.Context, req *rpcproto.CommitRequest) (*rpcproto.CommitResponse, error) {
+	res, err := s.env.Commit(rpcContext(ctx), heightPtr(req.Height))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return res.ToProto(), nil
+}
+
+func (s *Server) Validators(ctx context.Context, req *rpcproto.ValidatorsRequest) (
+	*rpcproto.ValidatorsResponse, error) {
+	res, err := s.env.Validators(rpcContext(ctx), heightPtr(req.Height), intPtr(req.Page), intPtr(req.PerPage))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	pb, err := res.ToProto()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "converting validators to proto: %v", err)
+	}
+	return pb, nil
+}
+
+func (s *Server) ConsensusParams(ctx context.Context, req *rpcproto.ConsensusParamsRequest) (
+	*rpcproto.ConsensusParamsResponse, error) {
+	res, err := s.env.ConsensusParams(rpcContext(ctx), heightPtr(req.Height))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &rpcproto.ConsensusParamsResponse{
+		BlockHeight:     res.BlockHeight,
+		ConsensusParams: res.ConsensusParams.ToProto(),
+	}, nil
+}
+
+func (s *Server) Tx(ctx context.Context, req *rpcproto.TxRequest) (*rpcproto.TxResponse, error) {
+	res, err := s.env.Tx(rpcContext(ctx), req.Hash, req.Prove)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return res.ToProto(), nil
+}
+
+func (s *Server) TxSearch(ctx context.Context, req *rpcproto.TxSearchRequest) (*rpcproto.TxSearchResponse, error) {
+	res, err := s.env.TxSearch(rpcContext(ctx), req.Query, req.Prove, intPtr(req.Page), intPtr(req.PerPage),
+		req.OrderBy, cursorPtr(req.Cursor))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return res.ToProto(), nil
+}
+
+func (s *Server) BlockSearch(ctx context.Context, req *rpcproto.BlockSearchRequest) (
+	*rpcproto.BlockSearchResponse, error) {
+	res, err := s.env.BlockSearch(rpcContext(ctx), req.Query, intPtr(req.Page), intPtr(req.PerPage),
+		req.OrderBy, cursorPtr(req.Cursor))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	pb, err := res.ToProto()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "converting blocks to proto: %v", err)
+	}
+	return pb, nil
+}
+
+func (s *Server) CheckTx(ctx context.Context, req *rpcproto.CheckTxRequest) (*rpcproto.CheckTxResponse, error) {
+	res, err := s.env.CheckTx(rpcContext(ctx), req.Tx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &rpcproto.CheckTxResponse{CheckTx: res.ResponseCheckTx}, nil
+}
+
+func (s *Server) RemoveTx(ctx context.Context, req *rpcproto.RemoveTxRequest) (*rpcproto.RemoveTxResponse, error) {
+	var key types.TxKey
+	if len(req.TxKey) != len(key) {
+		return nil, status.Errorf(codes.InvalidArgument, "tx key must be %d bytes long", len(key))
+	}
+	copy(key[:], req.TxKey)
+	if err := s.env.RemoveTx(rpcContext(ctx), key); err != nil {
+		return nil, toStatus(err)
+	}
+	return &rpcproto.RemoveTxResponse{}, nil
+}
+
+func (s *Server) UnconfirmedTxs(ctx context.Context, req *rpcproto.UnconfirmedTxsRequest) (
+	*rpcproto.UnconfirmedTxsResponse, error) {
+	res, err := s.env.UnconfirmedTxs(rpcContext(ctx), intPtr(req.Limit))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return res.ToProto(), nil
+}
+
+func (s *Server) NumUnconfirmedTxs(ctx context.Context, req *rpcproto.NumUnconfirmedTxsRequest) (
+	*rpcproto.UnconfirmedTxsResponse, error) {
+	res, err := s.env.NumUnconfirmedTxs(rpcContext(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return res.ToProto(), nil
+}
+
+func (s *Server) BroadcastTxAsync(ctx context.Context, req *rpcproto.BroadcastTxRequest) (
+	*rpcproto.BroadcastTxResponse, error) {
+	res, err := s.env.BroadcastTxAsync(rpcContext(ctx), req.Tx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return broadcastTxToProto(res), nil
+}
+
+func (s *Server) BroadcastTxSync(ctx context.Context, req *rpcproto.BroadcastTxRequest) (
+	*rpcproto.BroadcastTxResponse, error) {
+	res, err := s.env.BroadcastTxSync(rpcContext(ctx), req.Tx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return broadcastTxToProto(res), nil
+}
+
+func broadcastTxToProto(res *coretypes.ResultBroadcastTx) *rpcproto.BroadcastTxResponse {
+	return &rpcproto.BroadcastTxResponse{
+		Code:         res.Code,
+		Data:         res.Data,
+		Log:          res.Log,
+		Codespace:    res.Codespace,
+		MempoolError: res.MempoolError,
+		Hash:         res.Hash,
+	}
+}
+
+func (s *Server) BroadcastTxCommit(ctx context.Context, req *rpcproto.BroadcastTxRequest) (
+	*rpcproto.BroadcastTxCommitResponse, error) {
+	res, err := s.env.BroadcastTxCommit(rpcContext(ctx), req.Tx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &rpcproto.BroadcastTxCommitResponse{
+		CheckTx:   res.CheckTx,
+		DeliverTx: res.DeliverTx,
+		Hash:      res.Hash,
+		Height:    res.Height,
+	}, nil
+}
+
+func (s *Server) BroadcastEvidence(ctx context.Context, req *rpcproto.BroadcastEvidenceRequest) (
+	*rpcproto.BroadcastEvidenceResponse, error) {
+	ev, err := types.EvidenceFromProto(req.Evidence)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid evidence: %v", err)
+	}
+	res, err := s.env.BroadcastEvidence(rpcContext(ctx), ev)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &rpcproto.BroadcastEvidenceResponse{Hash: res.Hash}, nil
+}
+
+func (s *Server) ABCIInfo(ctx context.Context, req *rpcproto.ABCIInfoRequest) (*rpcproto.ABCIInfoResponse, error) {
+	res, err := s.env.ABCIInfo(rpcContext(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &rpcproto.ABCIInfoResponse{Response: res.Response}, nil
+}
+
+func (s *Server) ABCIQuery(ctx context.Context, req *rpcproto.ABCIQueryRequest) (*rpcproto.ABCIQueryResponse, error) {
+	res, err := s.env.ABCIQuery(rpcContext(ctx), req.Path, req.Data, req.Height, req.Prove)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &rpcproto.ABCIQueryResponse{Response: res.Response}, nil
+}
+
+// Events subscribes the client to the query and streams the events until the
+// call is canceled. As with the websocket subscriptions, the subscriptions are
+// limited per client address, and the stream ends with an error if the client
+// doesn't read the events fast enough.
+func (s *Server) Events(req *rpcproto.EventsRequest, stream rpcproto.RPCService_EventsServer) error {
+	ctx := stream.Context()
+	addr := remoteAddr(ctx)
+	eventBus := s.env.EventBus
+
+	if eventBus.NumClients() >= s.env.Config.MaxSubscriptionClients {
+		return status.Errorf(codes.ResourceExhausted,
+			"max_subscription_clients %d reached", s.env.Config.MaxSubscriptionClients)
+	} else if eventBus.NumClientSubscriptions(addr) >= s.env.Config.MaxSubscriptionsPerClient {
+		return status.Errorf(codes.ResourceExhausted,
+			"max_subscriptions_per_client %d reached", s.env.Config.MaxSubscriptionsPerClient)
+	}
+
+	q, err := tmquery.New(req.Query)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "failed to parse query: %v", err)
+	}
+
+	s.logger.Info("Subscribe to query", "remote", addr, "query", req.Query)
+	sub, err := eventBus.SubscribeWithArgs(ctx, tmpubsub.SubscribeArgs{
+		ClientID: addr,
+		Query:    q,
+		Limit:    subBufferSize,
+	})
+	if errors.Is(err, tmpubsub.ErrAlreadySubscribed) {
+		return status.Error(codes.AlreadyExists, err.Error())
+	} else if err != nil {
+		return toStatus(err)
+	}
+	defer func() {
+		err := eventBus.Unsubscribe(context.Background(), tmpubsub.UnsubscribeArgs{
+			Subscriber: addr,
+			Query:      q,
+		})
+		if err != nil && !errors.Is(err, tmpubsub.ErrSubscriptionNotFound) {
+			s.logger.Error("Failed to unsubscribe from query", "remote", addr, "query", req.Query, "err", err)
+		}
+	}()
+
+	// Send the headers, so that the client knows it is subscribed before it
+	// receives any event.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		msg, err := sub.Next(ctx)
+		if errors.Is(err, tmpubsub.ErrTerminated) {
+			return status.Error(codes.Aborted, err.Error())
+		} else if err != nil {
+			return toStatus(err)
+		}
+
+		res := &coretypes.ResultEvent{
+			SubscriptionID: msg.SubscriptionID(),
+			Query:          req.Query,
+			Data:           msg.Data(),
+			Events:         msg.Events(),
+		}
+		pb, err := res.ToProto()
+		if err != nil {
+			s.logger.Error("Failed to convert event to proto", "query", req.Query, "err", err)
+			continue
+		}
+		if err := stream.Send(pb); err != nil {
+			return err
+		}
+	}
+}
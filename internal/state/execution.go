@@ -44,6 +44,9 @@ type BlockExecutor struct {
 	logger  log.Logger
 	metrics *Metrics
 
+	// if set, retain heights requested by the app are handed to the pruner.
+	pruner *Pruner
+
 	// cache the verification results over a single height
 	cache map[string]struct{}
 }
@@ -56,6 +59,15 @@ func BlockExecutorWithMetrics(metrics *Metrics) BlockExecutorOption {
 	}
 }
 
+// BlockExecutorWithPruner makes the BlockExecutor hand the retain height
+// requested by the application to the given pruner instead of pruning blocks
+// itself.
+func BlockExecutorWithPruner(pruner *Pruner) BlockExecutorOption {
+	return func(blockExec *BlockExecutor) {
+		blockExec.pruner = pruner
+	}
+}
+
 // NewBlockExecutor returns a new BlockExecutor with a NopEventBus.
 // Call SetEventBus to provide one.
 func NewBlockExecutor(
@@ -218,7 +230,11 @@ func (blockExec *BlockExecutor) ApplyBlock(
 	fail.Fail() // XXX
 
 	// Prune old heights, if requested by ABCI app.
-	if retainHeight > 0 {
+	if blockExec.pruner != nil {
+		if err := blockExec.pruner.SetApplicationRetainHeight(retainHeight); err != nil {
+			return state, err
+		}
+	} else if retainHeight > 0 {
 		pruned, err := blockExec.pruneBlocks(retainHeight)
 		if err != nil {
 			blockExec.logger.Error("failed to prune blocks", "retain_height", retainHeight, "err", err)
@@ -563,6 +579,36 @@ func fireEvents(
 	}
 }
 
+// UpdateStateWithResponses validates a block against the state and returns the
+// state updated with the block and the ABCI responses from executing it.
+// Unlike ApplyBlock, the block is not executed against the application, so the
+// AppHash of the returned state is left unchanged and must be set by the
+// caller. It is used to import blocks which have been executed elsewhere.
+func UpdateStateWithResponses(
+	state State,
+	blockID types.BlockID,
+	block *types.Block,
+	abciResponses *tmstate.ABCIResponses,
+) (State, error) {
+	if err := validateBlock(state, block); err != nil {
+		return state, ErrInvalidBlock(err)
+	}
+	if abciResponses.EndBlock == nil {
+		return state, errors.New("missing EndBlock response")
+	}
+
+	abciValUpdates := abciResponses.EndBlock.ValidatorUpdates
+	if err := validateValidatorUpdates(abciValUpdates, state.ConsensusParams.Validator); err != nil {
+		return state, fmt.Errorf("error in validator updates: %v", err)
+	}
+	validatorUpdates, err := types.PB2TM.ValidatorUpdates(abciValUpdates)
+	if err != nil {
+		return state, err
+	}
+
+	return updateState(state, blockID, &block.Header, abciResponses, validatorUpdates)
+}
+
 //----------------------------------------------------------------------------------------------------
 // Execute block without state. TODO: eliminate
 
@@ -34,3 +34,15 @@ func UpdateState(
 func ValidateValidatorUpdates(abciUpdates []abci.ValidatorUpdate, params types.ValidatorParams) error {
 	return validateValidatorUpdates(abciUpdates, params)
 }
+
+// ABCIResponsesKey is an alias for abciResponsesKey exported from store.go,
+// exclusively and explicitly for testing.
+func ABCIResponsesKey(height int64) []byte {
+	return abciResponsesKey(height)
+}
+
+// ValidatorsKey is an alias for validatorsKey exported from store.go,
+// exclusively and explicitly for testing.
+func ValidatorsKey(height int64) []byte {
+	return validatorsKey(height)
+}
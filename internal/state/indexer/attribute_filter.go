@@ -0,0 +1,47 @@
+package indexer
+
+// AttributeFilter selects the event attributes to index by their composite
+// key, of the form "type.key", overriding the choice of the application for
+// the attributes it marked for indexing. A nil filter selects all attributes.
+type AttributeFilter struct {
+	include map[string]struct{}
+	exclude map[string]struct{}
+}
+
+// NewAttributeFilter returns a filter selecting the attributes whose composite
+// key is listed in include, or all attributes if include is empty, except for
+// those whose composite key is listed in exclude. It returns nil if both lists
+// are empty.
+func NewAttributeFilter(include, exclude []string) *AttributeFilter {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
+	}
+
+	f := &AttributeFilter{exclude: make(map[string]struct{}, len(exclude))}
+	if len(include) != 0 {
+		f.include = make(map[string]struct{}, len(include))
+		for _, key := range include {
+			f.include[key] = struct{}{}
+		}
+	}
+	for _, key := range exclude {
+		f.exclude[key] = struct{}{}
+	}
+	return f
+}
+
+// Match reports whether the attribute with the given composite key is
+// selected for indexing.
+func (f *AttributeFilter) Match(compositeKey string) bool {
+	if f == nil {
+		return true
+	}
+	if _, ok := f.exclude[compositeKey]; ok {
+		return false
+	}
+	if f.include == nil {
+		return true
+	}
+	_, ok := f.include[compositeKey]
+	return ok
+}
@@ -0,0 +1,37 @@
+package indexer_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tendermint/tendermint/internal/state/indexer"
+)
+
+func TestAttributeFilter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		expected map[string]bool
+	}{
+		{"empty", nil, nil, map[string]bool{"transfer.recipient": true, "transfer.amount": true}},
+		{"include", []string{"transfer.recipient"}, nil,
+			map[string]bool{"transfer.recipient": true, "transfer.amount": false, "message.sender": false}},
+		{"exclude", nil, []string{"transfer.amount"},
+			map[string]bool{"transfer.recipient": true, "transfer.amount": false, "message.sender": true}},
+		{"both", []string{"transfer.recipient", "transfer.amount"}, []string{"transfer.amount"},
+			map[string]bool{"transfer.recipient": true, "transfer.amount": false, "message.sender": false}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			f := indexer.NewAttributeFilter(tc.include, tc.exclude)
+			for key, expected := range tc.expected {
+				assert.Equal(t, expected, f.Match(key), key)
+			}
+		})
+	}
+
+	assert.Nil(t, indexer.NewAttributeFilter(nil, []string{}))
+}
@@ -0,0 +1,276 @@
+package indexer
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+// backfillRetryInterval is the interval between attempts to backfill the latest
+// block, whose ABCI responses are saved after the block.
+const backfillRetryInterval = 100 * time.Millisecond
+
+// BlockStore is the part of the block store used to backfill the event sinks.
+type BlockStore interface {
+	Base() int64
+	Height() int64
+	LoadBlock(height int64) *types.Block
+}
+
+// StateStore is the part of the state store used to backfill the event sinks.
+type StateStore interface {
+	LoadABCIResponses(height int64) (*tmstate.ABCIResponses, error)
+}
+
+// SinkStatus is the indexing progress of an event sink.
+type SinkStatus struct {
+	// Type is the type of the event sink.
+	Type EventSinkType
+	// IndexedHeight is the height up to which all blocks have been indexed.
+	IndexedHeight int64
+	// Lag is the number of blocks between the indexed height and the latest
+	// block.
+	Lag int64
+	// Backfilling reports whether the missing blocks are being indexed in the
+	// background.
+	Backfilling bool
+}
+
+// sinkProgress tracks the heights indexed by an event sink. It is protected by
+// the mutex of the service.
+type sinkProgress struct {
+	sink EventSink
+
+	// indexed is the height up to which all blocks have been indexed.
+	indexed int64
+	// runFrom and runTo are the bounds of the last range of blocks indexed
+	// above indexed+1, while a gap is being backfilled, or 0 if none.
+	runFrom, runTo int64
+	// backfilling reports whether the gap is being backfilled.
+	backfilling bool
+}
+
+// advance records that the block at the given height has been indexed, and
+// reports whether the indexed height changed.
+func (p *sinkProgress) advance(height int64) bool {
+	switch {
+	case height <= p.indexed:
+		return false
+
+	case height == p.indexed+1:
+		p.indexed = height
+		if p.runFrom == height+1 {
+			p.indexed = p.runTo
+			p.runFrom, p.runTo = 0, 0
+		}
+		return true
+
+	case height >= p.runFrom && height <= p.runTo:
+	case p.runTo != 0 && height == p.runTo+1:
+		p.runTo = height
+	default:
+		p.runFrom, p.runTo = height, height
+	}
+	return false
+}
+
+// ordered reports whether the sink must index the blocks in order, so that it
+// isn't indexed live while it is backfilled.
+func (p *sinkProgress) ordered() bool {
+	return IndexesInOrder(p.sink)
+}
+
+// indexedHeightKey returns the key of the indexed height of the sink in the
+// database of the service.
+func indexedHeightKey(sinkType EventSinkType) []byte {
+	return []byte("indexedHeight/" + sinkType)
+}
+
+// loadProgress loads the indexed height of each event sink, and marks the sinks
+// missing blocks of the block store for backfilling. If no height was recorded
+// for a sink, which was either just enabled or enabled before heights were
+// tracked, it is assumed to be up to date if it has indexed both the first and
+// the latest block, and to have indexed no block otherwise.
+func (is *Service) loadProgress() error {
+	is.mtx.Lock()
+	defer is.mtx.Unlock()
+
+	base, height := is.blockStore.Base(), is.blockStore.Height()
+	is.latest = height
+	for _, sink := range is.eventSinks {
+		if sink.Type() == NULL {
+			continue
+		}
+		p := &sinkProgress{sink: sink}
+
+		bz, err := is.db.Get(indexedHeightKey(sink.Type()))
+		switch {
+		case err != nil:
+			return fmt.Errorf("loading indexed height of %s event sink: %w", sink.Type(), err)
+		case len(bz) == 8:
+			p.indexed = int64(binary.BigEndian.Uint64(bz))
+		case bz != nil:
+			return fmt.Errorf("invalid indexed height of %s event sink", sink.Type())
+		case height > 0:
+			hasBase, err := sink.HasBlock(base)
+			if err != nil {
+				return err
+			}
+			hasLatest, err := sink.HasBlock(height)
+			if err != nil {
+				return err
+			}
+			if hasBase && hasLatest {
+				p.indexed = height
+			}
+		}
+
+		// Pruned blocks can't be backfilled.
+		if p.indexed < base-1 {
+			p.indexed = base - 1
+		}
+		p.backfilling = p.indexed < height
+		is.progress = append(is.progress, p)
+	}
+	is.reportLag()
+	return nil
+}
+
+// markIndexed records that the sink has indexed the block at the given height.
+func (is *Service) markIndexed(p *sinkProgress, height int64) {
+	is.mtx.Lock()
+	defer is.mtx.Unlock()
+
+	if p.advance(height) {
+		bz := make([]byte, 8)
+		binary.BigEndian.PutUint64(bz, uint64(p.indexed))
+		if err := is.db.Set(indexedHeightKey(p.sink.Type()), bz); err != nil {
+			is.Logger.Error("failed to save indexed height",
+				"sink", p.sink.Type(), "height", p.indexed, "err", err)
+		}
+	}
+	is.reportLag()
+}
+
+// reportLag updates the lag metric of the sinks. The caller must hold the
+// mutex.
+func (is *Service) reportLag() {
+	for _, p := range is.progress {
+		is.metrics.Lag.With("sink_type", string(p.sink.Type())).Set(float64(is.lag(p)))
+	}
+}
+
+// lag returns the number of blocks the sink has yet to index. The caller must
+// hold the mutex.
+func (is *Service) lag(p *sinkProgress) int64 {
+	if is.latest <= p.indexed {
+		return 0
+	}
+	return is.latest - p.indexed
+}
+
+// Status returns the indexing progress of the event sinks. It returns nil if
+// the indexed heights aren't tracked.
+func (is *Service) Status() []SinkStatus {
+	is.mtx.Lock()
+	defer is.mtx.Unlock()
+
+	var status []SinkStatus
+	for _, p := range is.progress {
+		status = append(status, SinkStatus{
+			Type:          p.sink.Type(),
+			IndexedHeight: p.indexed,
+			Lag:           is.lag(p),
+			Backfilling:   p.backfilling,
+		})
+	}
+	return status
+}
+
+// backfill indexes the blocks the sink is missing from the block store, until
+// it has caught up with the latest block, the context is done or an error
+// occurs. The blocks which can't be backfilled are tried again once the
+// service is restarted.
+func (is *Service) backfill(ctx context.Context, p *sinkProgress) {
+	defer is.wg.Done()
+
+	start := time.Now()
+	for first := true; ctx.Err() == nil; first = false {
+		is.mtx.Lock()
+		height := p.indexed + 1
+		if height > is.blockStore.Height() {
+			p.backfilling = false
+			is.mtx.Unlock()
+			is.Logger.Info("backfilled event sink", "sink", p.sink.Type(), "height", height-1,
+				"duration", time.Since(start))
+			return
+		}
+		is.mtx.Unlock()
+		if first {
+			is.Logger.Info("backfilling event sink", "sink", p.sink.Type(), "from", height)
+		}
+
+		if err := is.indexHeight(p.sink, height); err != nil {
+			if height == is.blockStore.Height() {
+				// The block may still be executed, so wait for its ABCI
+				// responses or for the block to be indexed live.
+				select {
+				case <-ctx.Done():
+				case <-time.After(backfillRetryInterval):
+				}
+				continue
+			}
+			is.mtx.Lock()
+			p.backfilling = false
+			is.mtx.Unlock()
+			is.Logger.Error("failed to backfill event sink", "sink", p.sink.Type(), "height", height, "err", err)
+			return
+		}
+		is.metrics.BlocksBackfilled.Add(1)
+		is.markIndexed(p, height)
+	}
+}
+
+// indexHeight indexes the block at the given height and its transactions in
+// the sink, loading them from the block and state stores.
+func (is *Service) indexHeight(sink EventSink, height int64) error {
+	b := is.blockStore.LoadBlock(height)
+	if b == nil {
+		return fmt.Errorf("block %d not found in the block store", height)
+	}
+	r, err := is.stateStore.LoadABCIResponses(height)
+	if err != nil {
+		return fmt.Errorf("loading ABCI responses of block %d: %w", height, err)
+	}
+	if len(r.DeliverTxs) != len(b.Txs) {
+		return fmt.Errorf("block %d has %d transactions but %d results", height, len(b.Txs), len(r.DeliverTxs))
+	}
+
+	hdr := types.EventDataNewBlockHeader{
+		Header: b.Header,
+		NumTxs: int64(len(b.Txs)),
+	}
+	if r.BeginBlock != nil {
+		hdr.ResultBeginBlock = *r.BeginBlock
+	}
+	if r.EndBlock != nil {
+		hdr.ResultEndBlock = *r.EndBlock
+	}
+	batch := NewBatch(hdr.NumTxs)
+	for i, tx := range b.Txs {
+		if err := batch.Add(&abci.TxResult{
+			Height: height,
+			Index:  uint32(i),
+			Tx:     tx,
+			Result: *r.DeliverTxs[i],
+		}); err != nil {
+			return err
+		}
+	}
+	return IndexBlock(sink, hdr, batch.Ops)
+}
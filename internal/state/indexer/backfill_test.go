@@ -0,0 +1,154 @@
+package indexer_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/eventbus"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/file"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/kv"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+// testStores implements the block and state stores used for backfilling, with
+// a transaction in every block.
+type testStores struct {
+	mtx    sync.Mutex
+	height int64
+}
+
+func (s *testStores) Base() int64 { return 1 }
+
+func (s *testStores) Height() int64 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.height
+}
+
+func (s *testStores) LoadBlock(height int64) *types.Block {
+	if height > s.Height() {
+		return nil
+	}
+	return &types.Block{
+		Header: types.Header{Height: height},
+		Data:   types.Data{Txs: types.Txs{testTx(height)}},
+	}
+}
+
+func (s *testStores) LoadABCIResponses(height int64) (*tmstate.ABCIResponses, error) {
+	if height > s.Height() {
+		return nil, fmt.Errorf("no ABCI responses at height %d", height)
+	}
+	return &tmstate.ABCIResponses{
+		DeliverTxs: []*abci.ResponseDeliverTx{{}},
+		BeginBlock: &abci.ResponseBeginBlock{},
+		EndBlock:   &abci.ResponseEndBlock{},
+	}, nil
+}
+
+// commit adds a block to the stores and publishes its events.
+func (s *testStores) commit(t *testing.T, eventBus *eventbus.EventBus) {
+	s.mtx.Lock()
+	s.height++
+	height := s.height
+	s.mtx.Unlock()
+
+	require.NoError(t, eventBus.PublishEventNewBlockHeader(types.EventDataNewBlockHeader{
+		Header: types.Header{Height: height},
+		NumTxs: 1,
+	}))
+	require.NoError(t, eventBus.PublishEventTx(types.EventDataTx{TxResult: abci.TxResult{
+		Height: height,
+		Tx:     testTx(height),
+	}}))
+}
+
+func testTx(height int64) types.Tx {
+	return types.Tx(fmt.Sprintf("tx%d", height))
+}
+
+func TestServiceBackfill(t *testing.T) {
+	stores := &testStores{height: 20}
+	db := dbm.NewMemDB()
+	kvSink := kv.NewEventSink(dbm.NewMemDB(), nil)
+	fileSink, err := file.NewEventSink(t.TempDir())
+	require.NoError(t, err)
+
+	startService := func(sinks ...indexer.EventSink) (*indexer.Service, *eventbus.EventBus) {
+		eventBus := eventbus.NewDefault()
+		eventBus.SetLogger(tmlog.TestingLogger())
+		require.NoError(t, eventBus.Start())
+		t.Cleanup(func() { _ = eventBus.Stop() })
+
+		service := indexer.NewService(indexer.ServiceArgs{
+			Sinks:      sinks,
+			EventBus:   eventBus,
+			Logger:     tmlog.TestingLogger(),
+			BlockStore: stores,
+			StateStore: stores,
+			DB:         db,
+		})
+		require.NoError(t, service.Start())
+		return service, eventBus
+	}
+	waitIndexed := func(service *indexer.Service, height int64) {
+		require.Eventually(t, func() bool {
+			for _, st := range service.Status() {
+				if st.IndexedHeight != height || st.Backfilling {
+					return false
+				}
+			}
+			return true
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	// The sinks are backfilled while new blocks are indexed.
+	service, eventBus := startService(kvSink, fileSink)
+	for i := 0; i < 5; i++ {
+		stores.commit(t, eventBus)
+	}
+	waitIndexed(service, 25)
+	for height := int64(1); height <= 25; height++ {
+		ok, err := kvSink.HasBlock(height)
+		require.NoError(t, err)
+		assert.True(t, ok, height)
+
+		res, err := kvSink.GetTxByHash(testTx(height).Hash())
+		require.NoError(t, err)
+		require.NotNil(t, res, height)
+		assert.Equal(t, height, res.Height)
+	}
+	assert.EqualValues(t, 25, fileSink.Checkpoint().Height)
+	for _, st := range service.Status() {
+		assert.Zero(t, st.Lag)
+	}
+
+	// The indexed heights are kept in the database across restarts, so only
+	// the blocks committed while the service was stopped are backfilled, which
+	// is checked by indexing them in an empty sink.
+	require.NoError(t, service.Stop())
+	stores.mtx.Lock()
+	stores.height += 3
+	stores.mtx.Unlock()
+
+	kvSink = kv.NewEventSink(dbm.NewMemDB(), nil)
+	service, _ = startService(kvSink)
+	t.Cleanup(func() { _ = service.Stop() })
+	waitIndexed(service, 28)
+
+	for height := int64(1); height <= 28; height++ {
+		ok, err := kvSink.HasBlock(height)
+		require.NoError(t, err)
+		assert.Equal(t, height > 25, ok, height)
+	}
+}
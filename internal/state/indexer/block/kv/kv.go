@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
@@ -19,17 +20,34 @@ import (
 
 var _ indexer.BlockIndexer = (*BlockerIndexer)(nil)
 
+// pruneBatchSize is the maximum number of keys deleted per batch when pruning.
+const pruneBatchSize = 1000
+
 // BlockerIndexer implements a block indexer, indexing BeginBlock and EndBlock
 // events with an underlying KV store. Block events are indexed by their height,
 // such that matching search criteria returns the respective block height(s).
 type BlockerIndexer struct {
-	store dbm.DB
+	store  dbm.DB
+	filter *indexer.AttributeFilter
 }
 
-func New(store dbm.DB) *BlockerIndexer {
-	return &BlockerIndexer{
+// Option sets an optional parameter on the BlockerIndexer.
+type Option func(*BlockerIndexer)
+
+// WithAttributeFilter restricts the event attributes indexed by the
+// BlockerIndexer to those selected by the filter.
+func WithAttributeFilter(filter *indexer.AttributeFilter) Option {
+	return func(idx *BlockerIndexer) { idx.filter = filter }
+}
+
+func New(store dbm.DB, options ...Option) *BlockerIndexer {
+	idx := &BlockerIndexer{
 		store: store,
 	}
+	for _, option := range options {
+		option(idx)
+	}
+	return idx
 }
 
 // Has returns true if the given height has been indexed. An error is returned
@@ -49,6 +67,7 @@ func (idx *BlockerIndexer) Has(height int64) (bool, error) {
 // primary key: encode(block.height | height) => encode(height)
 // BeginBlock events: encode(eventType.eventAttr|eventValue|height|begin_block) => encode(height)
 // EndBlock events: encode(eventType.eventAttr|eventValue|height|end_block) => encode(height)
+// event keys: encode(blockEvents | height) => the keys of the events above
 func (idx *BlockerIndexer) Index(bh types.EventDataNewBlockHeader) error {
 	batch := idx.store.NewBatch()
 	defer batch.Close()
@@ -65,18 +84,234 @@ func (idx *BlockerIndexer) Index(bh types.EventDataNewBlockHeader) error {
 	}
 
 	// 2. index BeginBlock events
-	if err := idx.indexEvents(batch, bh.ResultBeginBlock.Events, "begin_block", height); err != nil {
+	keys := make(map[string]struct{})
+	if err := idx.indexEvents(batch, keys, bh.ResultBeginBlock.Events, "begin_block", height); err != nil {
 		return fmt.Errorf("failed to index BeginBlock events: %w", err)
 	}
 
 	// 3. index EndBlock events
-	if err := idx.indexEvents(batch, bh.ResultEndBlock.Events, "end_block", height); err != nil {
+	if err := idx.indexEvents(batch, keys, bh.ResultEndBlock.Events, "end_block", height); err != nil {
 		return fmt.Errorf("failed to index EndBlock events: %w", err)
 	}
 
+	// 4. record the event keys, along with those of any previous indexing of
+	// the block, so that they are deleted along with the block
+	if err := idx.setEventKeys(batch, keys, height); err != nil {
+		return fmt.Errorf("failed to record block event keys: %w", err)
+	}
+
 	return batch.WriteSync()
 }
 
+// setEventKeys records the given event keys of the block at the given height,
+// merged with the keys already recorded.
+func (idx *BlockerIndexer) setEventKeys(batch dbm.Batch, keys map[string]struct{}, height int64) error {
+	key, err := blockEventsKey(height)
+	if err != nil {
+		return err
+	}
+	bz, err := idx.store.Get(key)
+	if err != nil {
+		return err
+	}
+	recorded, err := decodeKeys(bz)
+	if err != nil {
+		return err
+	}
+	for _, k := range recorded {
+		keys[string(k)] = struct{}{}
+	}
+
+	sorted := make([][]byte, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, []byte(k))
+	}
+	sort.Slice(sorted, func(i, j int) bool { return string(sorted[i]) < string(sorted[j]) })
+	return batch.Set(key, encodeKeys(sorted))
+}
+
+// Prune removes all blocks below the given height from the index, along with
+// their indexed BeginBlock and EndBlock events.
+func (idx *BlockerIndexer) Prune(retainHeight int64) error {
+	return idx.deleteHeights(0, retainHeight)
+}
+
+// DeleteFrom removes all blocks at and above the given height from the index,
+// along with their indexed BeginBlock and EndBlock events. It is used when
+// rolling back the state, since the blocks will be indexed again once they
+// have been executed again.
+func (idx *BlockerIndexer) DeleteFrom(height int64) error {
+	return idx.deleteHeights(height, math.MaxInt64)
+}
+
+// deleteHeights removes all blocks with heights in [fromHeight, toHeight) from
+// the index, along with their events. The blocks are found with a range scan
+// of the height index, and their events from the event keys recorded for each
+// block. Since block events are keyed by attribute first, removing the events
+// of blocks indexed before the event keys were recorded requires a scan over
+// the whole index, so this is only done if any such block was removed.
+func (idx *BlockerIndexer) deleteHeights(fromHeight, toHeight int64) error {
+	start, err := heightKey(fromHeight)
+	if err != nil {
+		return err
+	}
+	end, err := heightKey(toHeight)
+	if err != nil {
+		return err
+	}
+
+	var unrecordedFrom, unrecordedTo int64 // the range of blocks without event keys
+	for {
+		batch := idx.store.NewBatch()
+		next, from, to, err := idx.deleteBatch(batch, start, end)
+		if err == nil {
+			err = batch.WriteSync()
+		}
+		if closeErr := batch.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		if from != 0 && (unrecordedFrom == 0 || from < unrecordedFrom) {
+			unrecordedFrom = from
+		}
+		if to > unrecordedTo {
+			unrecordedTo = to
+		}
+		if next == nil {
+			break
+		}
+		start = next
+	}
+	if unrecordedFrom == 0 {
+		return nil
+	}
+
+	_, err = idx.pruneKeys(nil, nil, func(key []byte) bool {
+		var (
+			compositeKey, typ, eventValue string
+			height                        int64
+		)
+		remaining, err := orderedcode.Parse(string(key), &compositeKey, &eventValue, &height, &typ)
+		if err != nil || len(remaining) != 0 {
+			return false
+		}
+		return height >= unrecordedFrom && height <= unrecordedTo && (typ == "begin_block" || typ == "end_block")
+	})
+	return err
+}
+
+// deleteBatch adds deletions for up to pruneBatchSize blocks in the given range
+// of the height index to the batch, along with their recorded event keys. It
+// returns the key to continue from, or nil once the end of the range has been
+// reached, and the range of the heights of the blocks without event keys, or
+// 0 if none.
+func (idx *BlockerIndexer) deleteBatch(batch dbm.Batch, start, end []byte) (next []byte, from, to int64, err error) {
+	iter, err := idx.store.Iterator(start, end)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer iter.Close()
+
+	deleted := 0
+	for ; iter.Valid(); iter.Next() {
+		var (
+			prefix string
+			height int64
+		)
+		if _, err := orderedcode.Parse(string(iter.Key()), &prefix, &height); err != nil {
+			return nil, 0, 0, fmt.Errorf("failed to parse height key %X: %w", iter.Key(), err)
+		}
+
+		eventsKey, err := blockEventsKey(height)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		bz, err := idx.store.Get(eventsKey)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if bz == nil {
+			if from == 0 {
+				from = height
+			}
+			to = height
+		}
+		keys, err := decodeKeys(bz)
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("failed to decode event keys of block %d: %w", height, err)
+		}
+		for _, key := range append(keys, eventsKey, iter.Key()) {
+			if err := batch.Delete(key); err != nil {
+				return nil, 0, 0, err
+			}
+		}
+
+		deleted++
+		if deleted == pruneBatchSize {
+			return append([]byte{}, iter.Key()...), from, to, iter.Error()
+		}
+	}
+	return nil, from, to, iter.Error()
+}
+
+// pruneKeys deletes the keys in the given range that match the filter, in
+// batches of at most pruneBatchSize keys. It returns the number of keys
+// deleted.
+func (idx *BlockerIndexer) pruneKeys(start, end []byte, filter func(key []byte) bool) (int, error) {
+	total := 0
+	for {
+		batch := idx.store.NewBatch()
+		pruned, next, err := idx.pruneBatch(batch, start, end, filter)
+		if err == nil {
+			err = batch.WriteSync()
+		}
+		if closeErr := batch.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return total, err
+		}
+		total += pruned
+		if next == nil {
+			return total, nil
+		}
+		start = next
+	}
+}
+
+// pruneBatch adds deletions for up to pruneBatchSize matching keys in the
+// given range to the batch. It returns the number of keys deleted and the key
+// to continue from, or nil once the end of the range has been reached.
+func (idx *BlockerIndexer) pruneBatch(
+	batch dbm.Batch,
+	start, end []byte,
+	filter func(key []byte) bool,
+) (int, []byte, error) {
+	iter, err := idx.store.Iterator(start, end)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer iter.Close()
+
+	pruned := 0
+	for ; iter.Valid(); iter.Next() {
+		if !filter(iter.Key()) {
+			continue
+		}
+		if err := batch.Delete(iter.Key()); err != nil {
+			return pruned, nil, err
+		}
+
+		pruned++
+		if pruned == pruneBatchSize {
+			return pruned, append([]byte{}, iter.Key()...), iter.Error()
+		}
+	}
+	return pruned, nil, iter.Error()
+}
+
 // Search performs a query for block heights that match a given BeginBlock
 // and Endblock event search criteria. The given query can match against zero,
 // one or more block heights. In the case of height queries, i.e. block.height=H,
@@ -91,10 +326,274 @@ func (idx *BlockerIndexer) Search(ctx context.Context, q *query.Query) ([]int64,
 	default:
 	}
 
-	conditions, err := q.Conditions()
+	filteredHeights, err := idx.search(ctx, q.Expr())
+	if err != nil {
+		return nil, err
+	}
+
+	// fetch matching heights
+	results = make([]int64, 0, len(filteredHeights))
+heights:
+	for _, hBz := range filteredHeights {
+		h := int64FromBytes(hBz)
+
+		ok, err := idx.Has(h)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			results = append(results, h)
+		}
+
+		select {
+		case <-ctx.Done():
+			break heights
+
+		default:
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i] < results[j] })
+
+	return results, nil
+}
+
+// SearchPage returns the heights of the page of the blocks matching the query,
+// and whether more blocks follow the page.
+//
+// If the query is an equality condition, or a conjunction of equality
+// conditions and comparisons of the height, the keys of the first equality
+// condition, which are ordered by height, are scanned lazily from the cursor
+// of the page. Otherwise, all the matching blocks are searched for and sorted
+// first.
+func (idx *BlockerIndexer) SearchPage(ctx context.Context, q *query.Query, page indexer.Page) ([]int64, bool, error) {
+	scan, ok := indexer.LookForPageScan(q.Expr(), types.BlockHeightKey)
+	if !ok {
+		results, err := idx.Search(ctx, q)
+		if err != nil {
+			return nil, false, err
+		}
+		results, more := indexer.PageHeights(results, page)
+		return results, more, nil
+	}
+
+	prefix, err := orderedcode.Append(nil, scan.Equal.CompositeKey, fmt.Sprintf("%v", scan.Equal.Operand))
+	if err != nil {
+		return nil, false, err
+	}
+	start, end := prefix, prefixEnd(prefix)
+	var it dbm.Iterator
+	if page.Desc {
+		if page.After != nil {
+			if end, err = orderedcode.Append(append([]byte{}, prefix...), page.After.Height); err != nil {
+				return nil, false, err
+			}
+		}
+		it, err = idx.store.ReverseIterator(start, end)
+	} else {
+		if page.After != nil {
+			if start, err = orderedcode.Append(append([]byte{}, prefix...), page.After.Height+1); err != nil {
+				return nil, false, err
+			}
+		}
+		it, err = idx.store.Iterator(start, end)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse query conditions: %w", err)
+		return nil, false, fmt.Errorf("failed to create iterator: %w", err)
+	}
+	defer it.Close()
+
+	results := make([]int64, 0, page.Limit)
+	var last int64
+	for ; it.Valid(); it.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+
+		var (
+			height int64
+			typ    string
+		)
+		if _, err := orderedcode.Parse(string(it.Key()[len(prefix):]), &height, &typ); err != nil {
+			return nil, false, fmt.Errorf("failed to parse event key: %w", err)
+		}
+		// the begin and end block events of a block are adjacent
+		if height == last || !scan.MatchHeight(height) {
+			continue
+		}
+		last = height
+
+		ok, err := idx.matchEqualities(scan.Others, height)
+		if err != nil {
+			return nil, false, err
+		} else if !ok {
+			continue
+		}
+		if ok, err = idx.Has(height); err != nil {
+			return nil, false, err
+		} else if !ok {
+			continue
+		}
+
+		if len(results) == page.Limit {
+			return results, true, nil
+		}
+		results = append(results, height)
+	}
+	if err := it.Error(); err != nil {
+		return nil, false, err
+	}
+
+	return results, false, nil
+}
+
+// matchEqualities reports whether the block at the given height satisfies all
+// the equality conditions.
+func (idx *BlockerIndexer) matchEqualities(conditions []query.Condition, height int64) (bool, error) {
+	for _, c := range conditions {
+		prefix, err := orderedcode.Append(nil, c.CompositeKey, fmt.Sprintf("%v", c.Operand), height)
+		if err != nil {
+			return false, err
+		}
+		it, err := dbm.IteratePrefix(idx.store, prefix)
+		if err != nil {
+			return false, fmt.Errorf("failed to create prefix iterator: %w", err)
+		}
+		ok := it.Valid()
+		err = it.Error()
+		it.Close()
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// search returns the heights of the blocks matching the expression.
+//
+// The conditions of a conjunction are matched together, so that they narrow
+// down each other's key ranges, and its negated arguments are removed from the
+// matches of its other arguments. Only negations which are not part of a
+// conjunction with other arguments require iterating over all blocks.
+func (idx *BlockerIndexer) search(ctx context.Context, e *query.Expr) (map[string][]byte, error) {
+	switch e.Type {
+	case query.ExprCondition:
+		return idx.searchConditions(ctx, []query.Condition{e.Condition})
+
+	case query.ExprOr:
+		filteredHeights := make(map[string][]byte)
+		for _, arg := range e.Args {
+			heights, err := idx.search(ctx, arg)
+			if err != nil {
+				return nil, err
+			}
+			for k, v := range heights {
+				filteredHeights[k] = v
+			}
+		}
+		return filteredHeights, nil
+
+	case query.ExprNot:
+		filteredHeights, err := idx.searchAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return idx.subtract(ctx, filteredHeights, e.Args)
+	}
+
+	var (
+		conditions []query.Condition
+		negated    []*query.Expr
+		others     []*query.Expr
+	)
+	for _, arg := range e.Args {
+		switch arg.Type {
+		case query.ExprCondition:
+			conditions = append(conditions, arg.Condition)
+		case query.ExprNot:
+			negated = append(negated, arg.Args[0])
+		default:
+			others = append(others, arg)
+		}
+	}
+
+	var (
+		heightsInitialized bool
+		filteredHeights    map[string][]byte
+		err                error
+	)
+	if len(conditions) > 0 {
+		filteredHeights, err = idx.searchConditions(ctx, conditions)
+		if err != nil {
+			return nil, err
+		}
+		heightsInitialized = true
 	}
+	for _, arg := range others {
+		if heightsInitialized && len(filteredHeights) == 0 {
+			break
+		}
+
+		heights, err := idx.search(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+		if !heightsInitialized {
+			filteredHeights, heightsInitialized = heights, true
+			continue
+		}
+		for k := range filteredHeights {
+			if heights[k] == nil {
+				delete(filteredHeights, k)
+			}
+		}
+	}
+	if !heightsInitialized {
+		filteredHeights, err = idx.searchAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return idx.subtract(ctx, filteredHeights, negated)
+}
+
+// subtract removes the heights of the blocks matching any of the expressions
+// from filteredHeights.
+func (idx *BlockerIndexer) subtract(
+	ctx context.Context,
+	filteredHeights map[string][]byte,
+	exprs []*query.Expr,
+) (map[string][]byte, error) {
+	for _, e := range exprs {
+		if len(filteredHeights) == 0 {
+			break
+		}
+
+		heights, err := idx.search(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+		for k := range heights {
+			delete(filteredHeights, k)
+		}
+	}
+	return filteredHeights, nil
+}
+
+// searchAll returns the heights of all blocks, which are all indexed by their
+// height.
+func (idx *BlockerIndexer) searchAll(ctx context.Context) (map[string][]byte, error) {
+	return idx.searchConditions(ctx, []query.Condition{{CompositeKey: types.BlockHeightKey, Op: query.OpExists}})
+}
+
+// searchConditions returns the heights of the blocks matching all of the
+// conditions.
+func (idx *BlockerIndexer) searchConditions(
+	ctx context.Context,
+	conditions []query.Condition,
+) (map[string][]byte, error) {
+	var heightsInitialized bool
+	filteredHeights := make(map[string][]byte)
 
 	// If there is an exact height query, return the result immediately
 	// (if it exists).
@@ -106,15 +605,13 @@ func (idx *BlockerIndexer) Search(ctx context.Context, q *query.Query) ([]int64,
 		}
 
 		if ok {
-			return []int64{height}, nil
+			heightBz := int64ToBytes(height)
+			filteredHeights[string(heightBz)] = heightBz
 		}
 
-		return results, nil
+		return filteredHeights, nil
 	}
 
-	var heightsInitialized bool
-	filteredHeights := make(map[string][]byte)
-
 	// conditions to skip because they're handled before "everything else"
 	skipIndexes := make([]int, 0)
 
@@ -184,31 +681,7 @@ func (idx *BlockerIndexer) Search(ctx context.Context, q *query.Query) ([]int64,
 		}
 	}
 
-	// fetch matching heights
-	results = make([]int64, 0, len(filteredHeights))
-heights:
-	for _, hBz := range filteredHeights {
-		h := int64FromBytes(hBz)
-
-		ok, err := idx.Has(h)
-		if err != nil {
-			return nil, err
-		}
-		if ok {
-			results = append(results, h)
-		}
-
-		select {
-		case <-ctx.Done():
-			break heights
-
-		default:
-		}
-	}
-
-	sort.Slice(results, func(i, j int) bool { return results[i] < results[j] })
-
-	return results, nil
+	return filteredHeights, nil
 }
 
 // matchRange returns all matching block heights that match a given QueryRange
@@ -389,6 +862,51 @@ func (idx *BlockerIndexer) match(
 			return nil, err
 		}
 
+	case c.Op == query.OpIn:
+	iterIn:
+		for _, operand := range c.Operand.([]interface{}) {
+			if c.CompositeKey == types.BlockHeightKey {
+				// blocks are indexed by their height in the primary key
+				height, ok := operand.(int64)
+				if !ok {
+					continue
+				}
+				ok, err := idx.Has(height)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					tmpHeights[string(int64ToBytes(height))] = int64ToBytes(height)
+				}
+				continue
+			}
+
+			prefix, err := orderedcode.Append(nil, c.CompositeKey, fmt.Sprintf("%v", operand))
+			if err != nil {
+				return nil, err
+			}
+
+			it, err := dbm.IteratePrefix(idx.store, prefix)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create prefix iterator: %w", err)
+			}
+			for ; it.Valid(); it.Next() {
+				tmpHeights[string(it.Value())] = it.Value()
+			}
+			err = it.Error()
+			it.Close()
+			if err != nil {
+				return nil, err
+			}
+
+			select {
+			case <-ctx.Done():
+				break iterIn
+
+			default:
+			}
+		}
+
 	case c.Op == query.OpContains:
 		prefix, err := orderedcode.Append(nil, c.CompositeKey)
 		if err != nil {
@@ -423,6 +941,54 @@ func (idx *BlockerIndexer) match(
 			return nil, err
 		}
 
+	case c.Op == query.OpStartsWith || c.Op == query.OpMatches:
+		// Only scan the range of the values starting with the prefix, or the
+		// literal prefix of the pattern.
+		var (
+			valuePrefix string
+			matchValue  func(string) bool
+		)
+		if c.Op == query.OpStartsWith {
+			valuePrefix = c.Operand.(string)
+			matchValue = func(v string) bool { return strings.HasPrefix(v, valuePrefix) }
+		} else {
+			pattern := c.Operand.(*query.Pattern)
+			valuePrefix, matchValue = pattern.Prefix(), pattern.Match
+		}
+
+		prefix, err := eventValuePrefix(c.CompositeKey, valuePrefix)
+		if err != nil {
+			return nil, err
+		}
+
+		it, err := dbm.IteratePrefix(idx.store, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create prefix iterator: %w", err)
+		}
+		defer it.Close()
+
+	iterPrefix:
+		for ; it.Valid(); it.Next() {
+			eventValue, err := parseValueFromEventKey(it.Key())
+			if err != nil {
+				continue
+			}
+
+			if matchValue(eventValue) {
+				tmpHeights[string(it.Value())] = it.Value()
+			}
+
+			select {
+			case <-ctx.Done():
+				break iterPrefix
+
+			default:
+			}
+		}
+		if err := it.Error(); err != nil {
+			return nil, err
+		}
+
 	default:
 		return nil, errors.New("other operators should be handled already")
 	}
@@ -456,7 +1022,14 @@ func (idx *BlockerIndexer) match(
 	return filteredHeights, nil
 }
 
-func (idx *BlockerIndexer) indexEvents(batch dbm.Batch, events []abci.Event, typ string, height int64) error {
+// indexEvents adds the keys of the given events to the batch, and to keys.
+func (idx *BlockerIndexer) indexEvents(
+	batch dbm.Batch,
+	keys map[string]struct{},
+	events []abci.Event,
+	typ string,
+	height int64,
+) error {
 	heightBz := int64ToBytes(height)
 
 	for _, event := range events {
@@ -470,13 +1043,14 @@ func (idx *BlockerIndexer) indexEvents(batch dbm.Batch, events []abci.Event, typ
 				continue
 			}
 
-			// index iff the event specified index:true and it's not a reserved event
+			// index iff the event specified index:true, it's not a reserved event
+			// and it's not filtered out
 			compositeKey := fmt.Sprintf("%s.%s", event.Type, attr.Key)
 			if compositeKey == types.BlockHeightKey {
 				return fmt.Errorf("event type and attribute key \"%s\" is reserved; please use a different key", compositeKey)
 			}
 
-			if attr.GetIndex() {
+			if attr.GetIndex() && idx.filter.Match(compositeKey) {
 				key, err := eventKey(compositeKey, typ, attr.Value, height)
 				if err != nil {
 					return fmt.Errorf("failed to create block index key: %w", err)
@@ -485,6 +1059,7 @@ func (idx *BlockerIndexer) indexEvents(batch dbm.Batch, events []abci.Event, typ
 				if err := batch.Set(key, heightBz); err != nil {
 					return err
 				}
+				keys[string(key)] = struct{}{}
 			}
 		}
 	}
@@ -9,6 +9,7 @@ import (
 	dbm "github.com/tendermint/tm-db"
 
 	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/state/indexer"
 	blockidxkv "github.com/tendermint/tendermint/internal/state/indexer/block/kv"
 	"github.com/tendermint/tendermint/libs/pubsub/query"
 	"github.com/tendermint/tendermint/types"
@@ -140,3 +141,195 @@ func TestBlockIndexer(t *testing.T) {
 		})
 	}
 }
+
+func TestBlockIndexerExpressions(t *testing.T) {
+	store := dbm.NewPrefixDB(dbm.NewMemDB(), []byte("block_events"))
+	indexer := blockidxkv.New(store)
+
+	proposers := []string{"FCAA001", "FCAA002", "FCAA003", "FCAA001"}
+	for i, proposer := range proposers {
+		require.NoError(t, indexer.Index(types.EventDataNewBlockHeader{
+			Header: types.Header{Height: int64(i + 1)},
+			ResultBeginBlock: abci.ResponseBeginBlock{
+				Events: []abci.Event{{
+					Type:       "begin_event",
+					Attributes: []abci.EventAttribute{{Key: "proposer", Value: proposer, Index: true}},
+				}},
+			},
+			ResultEndBlock: abci.ResponseEndBlock{
+				Events: []abci.Event{{
+					Type:       "end_event",
+					Attributes: []abci.EventAttribute{{Key: "foo", Value: fmt.Sprint(100 * (i + 1)), Index: true}},
+				}},
+			},
+		}))
+	}
+
+	testCases := map[string][]int64{
+		"begin_event.proposer = 'FCAA002' OR begin_event.proposer = 'FCAA003'":  {2, 3},
+		"begin_event.proposer IN ('FCAA002', 'FCAA003', 'FCAA009')":             {2, 3},
+		"block.height IN (1, 3, 7)":                                             {1, 3},
+		"block.height IN (1, 3) AND begin_event.proposer = 'FCAA001'":           {1},
+		"begin_event.proposer = 'FCAA001' AND NOT end_event.foo = 100":          {4},
+		"NOT begin_event.proposer = 'FCAA001'":                                  {2, 3},
+		"NOT (begin_event.proposer = 'FCAA001' OR end_event.foo <= 200)":        {3},
+		"end_event.foo > 100 AND (block.height = 2 OR block.height > 3)":        {2, 4},
+		"NOT block.height = 2 AND NOT begin_event.proposer IN ('FCAA001')":      {3},
+		"begin_event.proposer = 'FCAA009' OR NOT end_event.foo EXISTS":          {},
+		"begin_event.proposer STARTS WITH 'FCAA00'":                             {1, 2, 3, 4},
+		"begin_event.proposer STARTS WITH 'FCAA001'":                            {1, 4},
+		"begin_event.proposer STARTS WITH 'CAA'":                                {},
+		"end_event.foo STARTS WITH '1' OR begin_event.proposer MATCHES '*3'":    {1, 3},
+		"begin_event.proposer MATCHES 'FCAA00?' AND end_event.foo MATCHES '4*'": {4},
+		"begin_event.proposer MATCHES 'FCAA00'":                                 {},
+	}
+
+	for q, heights := range testCases {
+		results, err := indexer.Search(context.Background(), query.MustParse(q))
+		require.NoError(t, err)
+		require.Equal(t, heights, results, q)
+	}
+}
+
+func TestBlockIndexerSearchPage(t *testing.T) {
+	store := dbm.NewPrefixDB(dbm.NewMemDB(), []byte("block_events"))
+	blockIndexer := blockidxkv.New(store)
+
+	for h := int64(1); h <= 10; h++ {
+		require.NoError(t, blockIndexer.Index(types.EventDataNewBlockHeader{
+			Header: types.Header{Height: h},
+			ResultBeginBlock: abci.ResponseBeginBlock{
+				Events: []abci.Event{{
+					Type:       "begin_event",
+					Attributes: []abci.EventAttribute{{Key: "proposer", Value: fmt.Sprint("FCAA00", h%3), Index: true}},
+				}},
+			},
+			ResultEndBlock: abci.ResponseEndBlock{
+				Events: []abci.Event{{
+					Type: "end_event",
+					Attributes: []abci.EventAttribute{
+						{Key: "foo", Value: fmt.Sprint(h % 2), Index: true},
+						{Key: "proposer", Value: fmt.Sprint("FCAA00", h%3), Index: true},
+					},
+				}},
+			},
+		}))
+	}
+
+	queries := []string{
+		// scanned lazily
+		"begin_event.proposer = 'FCAA001'",
+		"end_event.proposer = 'FCAA001' AND begin_event.proposer = 'FCAA001'",
+		"end_event.foo = 0 AND block.height >= 3 AND block.height < 9",
+		"end_event.foo = 1 AND begin_event.proposer = 'FCAA002'",
+		"begin_event.proposer = 'FCAA009'",
+		// searched for and sorted first
+		"end_event.foo > 0",
+		"block.height <= 3 OR end_event.foo = 0",
+	}
+	for _, q := range queries {
+		for _, desc := range []bool{false, true} {
+			heights, err := blockIndexer.Search(context.Background(), query.MustParse(q))
+			require.NoError(t, err)
+			expected, _ := indexer.PageHeights(heights, indexer.Page{Desc: desc, Limit: len(heights) + 1})
+
+			var (
+				page   = indexer.Page{Desc: desc, Limit: 2}
+				actual = []int64{}
+			)
+			for {
+				results, more, err := blockIndexer.SearchPage(context.Background(), query.MustParse(q), page)
+				require.NoError(t, err)
+				require.LessOrEqual(t, len(results), page.Limit)
+				actual = append(actual, results...)
+				if !more {
+					break
+				}
+				require.Len(t, results, page.Limit)
+				page.After = &indexer.Cursor{Height: results[len(results)-1]}
+			}
+			require.Equal(t, expected, actual, "%s (desc: %v)", q, desc)
+		}
+	}
+}
+
+func TestBlockIndexerPrune(t *testing.T) {
+	store := dbm.NewPrefixDB(dbm.NewMemDB(), []byte("block_events"))
+	indexer := blockidxkv.New(store)
+
+	for i := 1; i <= 12; i++ {
+		require.NoError(t, indexer.Index(types.EventDataNewBlockHeader{
+			Header: types.Header{Height: int64(i)},
+			ResultBeginBlock: abci.ResponseBeginBlock{
+				Events: []abci.Event{{
+					Type:       "begin_event",
+					Attributes: []abci.EventAttribute{{Key: "proposer", Value: "FCAA001", Index: true}},
+				}},
+			},
+			ResultEndBlock: abci.ResponseEndBlock{
+				Events: []abci.Event{{
+					Type:       "end_event",
+					Attributes: []abci.EventAttribute{{Key: "foo", Value: fmt.Sprintf("%d", i), Index: true}},
+				}},
+			},
+		}))
+	}
+
+	require.NoError(t, indexer.Prune(10))
+
+	for i := int64(1); i <= 12; i++ {
+		has, err := indexer.Has(i)
+		require.NoError(t, err)
+		require.Equal(t, i >= 10, has, "height %d", i)
+	}
+
+	results, err := indexer.Search(context.Background(), query.MustParse("begin_event.proposer = 'FCAA001'"))
+	require.NoError(t, err)
+	require.Equal(t, []int64{10, 11, 12}, results)
+
+	results, err = indexer.Search(context.Background(), query.MustParse("end_event.foo <= 10"))
+	require.NoError(t, err)
+	require.Equal(t, []int64{10}, results)
+
+	// Pruning again is a no-op.
+	require.NoError(t, indexer.Prune(10))
+	results, err = indexer.Search(context.Background(), query.MustParse("block.height >= 1"))
+	require.NoError(t, err)
+	require.Equal(t, []int64{10, 11, 12}, results)
+
+	// Blocks at and above a height can be deleted as well.
+	require.NoError(t, indexer.DeleteFrom(12))
+	results, err = indexer.Search(context.Background(), query.MustParse("begin_event.proposer = 'FCAA001'"))
+	require.NoError(t, err)
+	require.Equal(t, []int64{10, 11}, results)
+}
+
+func TestBlockIndexerAttributeFilter(t *testing.T) {
+	store := dbm.NewPrefixDB(dbm.NewMemDB(), []byte("block_events"))
+	filter := indexer.NewAttributeFilter(nil, []string{"end_event.foo"})
+	blockIndexer := blockidxkv.New(store, blockidxkv.WithAttributeFilter(filter))
+
+	require.NoError(t, blockIndexer.Index(types.EventDataNewBlockHeader{
+		Header: types.Header{Height: 1},
+		ResultBeginBlock: abci.ResponseBeginBlock{
+			Events: []abci.Event{{
+				Type:       "begin_event",
+				Attributes: []abci.EventAttribute{{Key: "proposer", Value: "FCAA001", Index: true}},
+			}},
+		},
+		ResultEndBlock: abci.ResponseEndBlock{
+			Events: []abci.Event{{
+				Type:       "end_event",
+				Attributes: []abci.EventAttribute{{Key: "foo", Value: "100", Index: true}},
+			}},
+		},
+	}))
+
+	results, err := blockIndexer.Search(context.Background(), query.MustParse("begin_event.proposer = 'FCAA001'"))
+	require.NoError(t, err)
+	require.Equal(t, []int64{1}, results)
+
+	results, err = blockIndexer.Search(context.Background(), query.MustParse("end_event.foo = 100"))
+	require.NoError(t, err)
+	require.Empty(t, results)
+}
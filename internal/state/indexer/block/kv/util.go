@@ -2,6 +2,7 @@ package kv
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -39,6 +40,51 @@ func heightKey(height int64) ([]byte, error) {
 	)
 }
 
+// blockEventsKeyPrefix is the prefix of the keys recording the event keys of
+// each block. Since composite keys always contain a dot, it can't be mistaken
+// for the composite key of an event.
+const blockEventsKeyPrefix = "blockEvents"
+
+// blockEventsKey returns the key recording the keys of the events indexed for
+// the block at the given height, so that they can be deleted along with the
+// block without scanning the index.
+func blockEventsKey(height int64) ([]byte, error) {
+	return orderedcode.Append(
+		nil,
+		blockEventsKeyPrefix,
+		height,
+	)
+}
+
+// encodeKeys encodes a list of keys, each prefixed with its length.
+func encodeKeys(keys [][]byte) []byte {
+	// The encoding is never nil, so that blocks without events can be told
+	// apart from blocks indexed before the event keys were recorded.
+	bz := []byte{}
+	var buf [binary.MaxVarintLen64]byte
+	for _, key := range keys {
+		n := binary.PutUvarint(buf[:], uint64(len(key)))
+		bz = append(bz, buf[:n]...)
+		bz = append(bz, key...)
+	}
+	return bz
+}
+
+// decodeKeys decodes a list of keys encoded by encodeKeys.
+func decodeKeys(bz []byte) ([][]byte, error) {
+	var keys [][]byte
+	for len(bz) > 0 {
+		n, size := binary.Uvarint(bz)
+		if size <= 0 || uint64(len(bz)-size) < n {
+			return nil, errors.New("invalid encoding of event keys")
+		}
+		bz = bz[size:]
+		keys = append(keys, bz[:n])
+		bz = bz[n:]
+	}
+	return keys, nil
+}
+
 func eventKey(compositeKey, typ, eventValue string, height int64) ([]byte, error) {
 	return orderedcode.Append(
 		nil,
@@ -49,6 +95,33 @@ func eventKey(compositeKey, typ, eventValue string, height int64) ([]byte, error
 	)
 }
 
+// eventValuePrefix returns the prefix shared by the keys of the events with
+// the given composite key whose value starts with valuePrefix. Since such keys
+// are contiguous in the store, they can be found with a range scan.
+func eventValuePrefix(compositeKey, valuePrefix string) ([]byte, error) {
+	key, err := orderedcode.Append(nil, compositeKey, valuePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	// Strip the terminator of the encoded value, leaving a prefix of the
+	// encoding of every value starting with valuePrefix.
+	return key[:len(key)-2], nil
+}
+
+// prefixEnd returns the exclusive end of the key range with the given prefix,
+// or nil if the range is unbounded.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		end[i]++
+		if end[i] != 0 {
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
 func parseValueFromPrimaryKey(key []byte) (string, error) {
 	var (
 		compositeKey string
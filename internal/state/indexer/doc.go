@@ -12,7 +12,7 @@ An ABCI application can emit events during block and transaction execution in th
 
 for example "transfer.amount=10000".
 
-An operator can enable one or both of the supported indexing sinks via the
+An operator can enable one or more of the supported indexing sinks via the
 'tx-index.indexer' Tendermint configuration.
 
 Example:
@@ -49,8 +49,19 @@ manually or invoke schema creation via the CLI:
 
 	$ psql <flags> -f state/indexer/sink/psql/schema.sql
 
-The "psql" indexing sink prohibits queries via RPC. When using a PostgreSQL sink,
-queries can and should be made directly against the database using SQL.
+The "psql" indexing sink serves queries via RPC if the "kv" sink is not enabled,
+translating them into SQL. More complex queries can and should be made directly
+against the database using SQL.
+
+The "sqlite" indexing sink stores events in an embedded SQLite database file,
+set by 'tx-index.sqlite-path', using the same schema as the "psql" sink. The
+schema is created when the file is first opened, and the file may be read by
+external tools, such as the sqlite3 shell, while Tendermint is running.
+
+The "file" indexing sink appends blocks and transaction results as JSON lines to
+rotating files in 'tx-index.file-path', for consumption by external pipelines.
+It records the last fully written height in a checkpoint file, and doesn't
+support queries.
 
 The following are some example SQL queries against the database schema:
 
@@ -2,6 +2,7 @@ package indexer
 
 import (
 	"context"
+	"fmt"
 
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/libs/pubsub/query"
@@ -11,9 +12,11 @@ import (
 type EventSinkType string
 
 const (
-	NULL EventSinkType = "null"
-	KV   EventSinkType = "kv"
-	PSQL EventSinkType = "psql"
+	NULL   EventSinkType = "null"
+	KV     EventSinkType = "kv"
+	PSQL   EventSinkType = "psql"
+	SQLITE EventSinkType = "sqlite"
+	FILE   EventSinkType = "file"
 )
 
 //go:generate ../../../scripts/mockery_generate.sh EventSink
@@ -32,25 +35,70 @@ type EventSink interface {
 	// must guarantee the index of given transactions are in order.
 	IndexTxEvents([]*abci.TxResult) error
 
-	// SearchBlockEvents provides the block search by given query conditions. This function only
-	// supported by the kvEventSink.
+	// SearchBlockEvents provides the block search by given query conditions. This function is only
+	// supported by the kv, psql and sqlite event sinks.
 	SearchBlockEvents(context.Context, *query.Query) ([]int64, error)
 
-	// SearchTxEvents provides the transaction search by given query conditions. This function only
-	// supported by the kvEventSink.
+	// SearchTxEvents provides the transaction search by given query conditions. This function is only
+	// supported by the kv, psql and sqlite event sinks.
 	SearchTxEvents(context.Context, *query.Query) ([]*abci.TxResult, error)
 
-	// GetTxByHash provides the transaction search by given transaction hash. This function only
-	// supported by the kvEventSink.
+	// GetTxByHash provides the transaction search by given transaction hash. This function is only
+	// supported by the kv, psql and sqlite event sinks.
 	GetTxByHash([]byte) (*abci.TxResult, error)
 
-	// HasBlock provides the transaction search by given transaction hash. This function only
-	// supported by the kvEventSink.
+	// HasBlock provides the transaction search by given transaction hash. This function is only
+	// supported by the kv, psql and sqlite event sinks.
 	HasBlock(int64) (bool, error)
 
+	// Prune removes the blocks and transactions below the given height, along
+	// with their events. It is called when blocks are pruned, so that searches
+	// don't return results whose blocks no longer exist. Sinks which don't
+	// store events, or which leave their removal to the operator, do nothing.
+	Prune(retainHeight int64) error
+
 	// Type checks the eventsink structure type.
 	Type() EventSinkType
 
 	// Stop will close the data store connection, if the eventsink supports it.
 	Stop() error
 }
+
+// EventSinkDeleter is implemented by event sinks which support deleting the
+// events above a height, e.g. when the state is rolled back or the events are
+// re-indexed from scratch.
+type EventSinkDeleter interface {
+	// DeleteFrom removes the blocks and transactions from the given height
+	// and above, along with their events.
+	DeleteFrom(height int64) error
+}
+
+// AtomicIndexer is implemented by event sinks which can index a block header
+// together with the results of its transactions in a single write, so that
+// readers never observe a partially indexed block.
+type AtomicIndexer interface {
+	// IndexBlockAndTxEvents indexes the block header and the given results
+	// of the transactions of the block.
+	IndexBlockAndTxEvents(types.EventDataNewBlockHeader, []*abci.TxResult) error
+}
+
+// IndexBlock indexes the block header and the results of its transactions in
+// the sink, in a single write if the sink implements AtomicIndexer.
+func IndexBlock(sink EventSink, h types.EventDataNewBlockHeader, txrs []*abci.TxResult) error {
+	if ai, ok := sink.(AtomicIndexer); ok {
+		if err := ai.IndexBlockAndTxEvents(h, txrs); err != nil {
+			return fmt.Errorf("indexing block %d and its transactions: %w", h.Header.Height, err)
+		}
+		return nil
+	}
+	if err := sink.IndexBlockEvents(h); err != nil {
+		return fmt.Errorf("indexing block %d: %w", h.Header.Height, err)
+	}
+	if len(txrs) == 0 {
+		return nil
+	}
+	if err := sink.IndexTxEvents(txrs); err != nil {
+		return fmt.Errorf("indexing transactions of block %d: %w", h.Header.Height, err)
+	}
+	return nil
+}
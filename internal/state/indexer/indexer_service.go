@@ -2,8 +2,12 @@ package indexer
 
 import (
 	"context"
+	"sync"
 	"time"
 
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/internal/eventbus"
 	"github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/libs/pubsub"
@@ -13,6 +17,11 @@ import (
 
 // Service connects event bus, transaction and block indexers together in
 // order to index transactions and blocks coming from the event bus.
+//
+// If a block store, state store and database are provided, the service also
+// tracks the height up to which each event sink has indexed all blocks. When
+// started, it backfills the blocks the sinks are missing from the stores in
+// the background, while the blocks coming from the event bus are indexed.
 type Service struct {
 	service.BaseService
 
@@ -20,6 +29,17 @@ type Service struct {
 	eventBus   *eventbus.EventBus
 	metrics    *Metrics
 
+	blockStore BlockStore
+	stateStore StateStore
+	db         dbm.DB
+
+	mtx      sync.Mutex
+	progress []*sinkProgress
+	latest   int64 // the latest height known to the service
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
 	currentBlock struct {
 		header types.EventDataNewBlockHeader
 		height int64
@@ -33,6 +53,9 @@ func NewService(args ServiceArgs) *Service {
 		eventSinks: args.Sinks,
 		eventBus:   args.EventBus,
 		metrics:    args.Metrics,
+		blockStore: args.BlockStore,
+		stateStore: args.StateStore,
+		db:         args.DB,
 	}
 	if is.metrics == nil {
 		is.metrics = NopMetrics()
@@ -89,30 +112,23 @@ func (is *Service) publish(msg pubsub.Message) error {
 
 	if curr.Pending == 0 {
 		// INDEX: We have all the transactions we expect for the current block.
-		for _, sink := range is.eventSinks {
-			start := time.Now()
-			if err := sink.IndexBlockEvents(is.currentBlock.header); err != nil {
-				is.Logger.Error("failed to index block header",
-					"height", is.currentBlock.height, "err", err)
-			} else {
-				is.metrics.BlockEventsSeconds.Observe(time.Since(start).Seconds())
-				is.metrics.BlocksIndexed.Add(1)
-				is.Logger.Debug("indexed block",
-					"height", is.currentBlock.height, "sink", sink.Type())
+		is.mtx.Lock()
+		if is.currentBlock.height > is.latest {
+			is.latest = is.currentBlock.height
+			is.reportLag()
+		}
+		is.mtx.Unlock()
+
+		for i, sink := range is.eventSinks {
+			p := is.sinkProgress(i)
+			if p != nil && p.ordered() && is.isBackfilling(p) {
+				// The block will be indexed by the backfill.
+				continue
 			}
 
-			if curr.Size() != 0 {
-				start := time.Now()
-				err := sink.IndexTxEvents(curr.Ops)
-				if err != nil {
-					is.Logger.Error("failed to index block txs",
-						"height", is.currentBlock.height, "err", err)
-				} else {
-					is.metrics.TxEventsSeconds.Observe(time.Since(start).Seconds())
-					is.metrics.TransactionsIndexed.Add(float64(curr.Size()))
-					is.Logger.Debug("indexed txs",
-						"height", is.currentBlock.height, "sink", sink.Type())
-				}
+			indexed := is.indexBlock(sink, is.currentBlock.header, curr.Ops)
+			if p != nil && indexed {
+				is.markIndexed(p, is.currentBlock.height)
 			}
 		}
 		is.currentBlock.batch = nil // return to the WAIT state for the next block
@@ -121,30 +137,132 @@ func (is *Service) publish(msg pubsub.Message) error {
 	return nil
 }
 
+// indexBlock indexes the block header and the results of its transactions in
+// the sink, and reports whether it succeeded. Sinks implementing AtomicIndexer
+// index both in a single write, whose latency is reported as that of the
+// block events.
+func (is *Service) indexBlock(sink EventSink, hdr types.EventDataNewBlockHeader, txrs []*abci.TxResult) bool {
+	height := hdr.Header.Height
+	if ai, ok := sink.(AtomicIndexer); ok {
+		start := time.Now()
+		if err := ai.IndexBlockAndTxEvents(hdr, txrs); err != nil {
+			is.Logger.Error("failed to index block and txs", "height", height, "err", err)
+			return false
+		}
+		is.metrics.BlockEventsSeconds.Observe(time.Since(start).Seconds())
+		is.metrics.BlocksIndexed.Add(1)
+		is.metrics.TransactionsIndexed.Add(float64(len(txrs)))
+		is.Logger.Debug("indexed block and txs", "height", height, "sink", sink.Type())
+		return true
+	}
+
+	indexed := true
+	start := time.Now()
+	if err := sink.IndexBlockEvents(hdr); err != nil {
+		indexed = false
+		is.Logger.Error("failed to index block header", "height", height, "err", err)
+	} else {
+		is.metrics.BlockEventsSeconds.Observe(time.Since(start).Seconds())
+		is.metrics.BlocksIndexed.Add(1)
+		is.Logger.Debug("indexed block", "height", height, "sink", sink.Type())
+	}
+
+	if len(txrs) != 0 {
+		start := time.Now()
+		if err := sink.IndexTxEvents(txrs); err != nil {
+			indexed = false
+			is.Logger.Error("failed to index block txs", "height", height, "err", err)
+		} else {
+			is.metrics.TxEventsSeconds.Observe(time.Since(start).Seconds())
+			is.metrics.TransactionsIndexed.Add(float64(len(txrs)))
+			is.Logger.Debug("indexed txs", "height", height, "sink", sink.Type())
+		}
+	}
+	return indexed
+}
+
+// sinkProgress returns the progress of the i-th event sink, or nil if it isn't
+// tracked.
+func (is *Service) sinkProgress(i int) *sinkProgress {
+	is.mtx.Lock()
+	defer is.mtx.Unlock()
+	for _, p := range is.progress {
+		if p.sink == is.eventSinks[i] {
+			return p
+		}
+	}
+	return nil
+}
+
+// isBackfilling reports whether the sink is being backfilled.
+func (is *Service) isBackfilling(p *sinkProgress) bool {
+	is.mtx.Lock()
+	defer is.mtx.Unlock()
+	return p.backfilling
+}
+
+// tracking reports whether the indexed heights of the sinks are tracked.
+func (is *Service) tracking() bool {
+	return is.blockStore != nil && is.stateStore != nil && is.db != nil
+}
+
 // OnStart implements part of service.Service. It registers an observer for the
-// indexer if the underlying event sinks support indexing.
+// indexer if the underlying event sinks support indexing, and starts
+// backfilling the sinks which are missing blocks.
 //
 // TODO(creachadair): Can we get rid of the "enabled" check?
 func (is *Service) OnStart() error {
-	// If the event sinks support indexing, register an observer to capture
-	// block header data for the indexer.
-	if IndexingEnabled(is.eventSinks) {
-		err := is.eventBus.Observe(context.TODO(), is.publish,
-			types.EventQueryNewBlockHeader, types.EventQueryTx)
-		if err != nil {
+	if !IndexingEnabled(is.eventSinks) {
+		return nil
+	}
+
+	// Load the progress before observing the event bus, so that the sinks
+	// which must index blocks in order are not indexed live while they are
+	// backfilled.
+	if is.tracking() {
+		if err := is.loadProgress(); err != nil {
 			return err
 		}
 	}
+
+	// Register an observer to capture block header data for the indexer.
+	err := is.eventBus.Observe(context.TODO(), is.publish,
+		types.EventQueryNewBlockHeader, types.EventQueryTx)
+	if err != nil {
+		return err
+	}
+
+	var ctx context.Context
+	ctx, is.cancel = context.WithCancel(context.Background())
+	is.mtx.Lock()
+	defer is.mtx.Unlock()
+	for _, p := range is.progress {
+		if p.backfilling {
+			is.wg.Add(1)
+			go is.backfill(ctx, p)
+		}
+	}
 	return nil
 }
 
-// OnStop implements service.Service by closing the event sinks.
+// OnStop implements service.Service by stopping the backfills and closing the
+// event sinks, along with the database of the service.
 func (is *Service) OnStop() {
+	if is.cancel != nil {
+		is.cancel()
+	}
+	is.wg.Wait()
+
 	for _, sink := range is.eventSinks {
 		if err := sink.Stop(); err != nil {
 			is.Logger.Error("failed to close eventsink", "eventsink", sink.Type(), "err", err)
 		}
 	}
+	if is.db != nil {
+		if err := is.db.Close(); err != nil {
+			is.Logger.Error("failed to close indexer database", "err", err)
+		}
+	}
 }
 
 // ServiceArgs are arguments for constructing a new indexer service.
@@ -153,6 +271,13 @@ type ServiceArgs struct {
 	EventBus *eventbus.EventBus
 	Metrics  *Metrics
 	Logger   log.Logger
+
+	// BlockStore and StateStore are used to backfill the event sinks, and DB
+	// records the indexed height of each sink. Backfilling is disabled unless
+	// all of them are set. The service closes DB when stopped.
+	BlockStore BlockStore
+	StateStore StateStore
+	DB         dbm.DB
 }
 
 // KVSinkEnabled returns the given eventSinks is containing KVEventSink.
@@ -166,13 +291,36 @@ func KVSinkEnabled(sinks []EventSink) bool {
 	return false
 }
 
+// SearchSink returns the event sink used to serve queries, which is the kv
+// event sink if enabled and the psql or sqlite event sink otherwise, or nil if
+// none of them is enabled.
+func SearchSink(sinks []EventSink) EventSink {
+	var searchSink EventSink
+	for _, sink := range sinks {
+		switch sink.Type() {
+		case KV:
+			return sink
+		case PSQL, SQLITE:
+			searchSink = sink
+		}
+	}
+	return searchSink
+}
+
 // IndexingEnabled returns the given eventSinks is supporting the indexing services.
 func IndexingEnabled(sinks []EventSink) bool {
 	for _, sink := range sinks {
-		if sink.Type() == KV || sink.Type() == PSQL {
+		switch sink.Type() {
+		case KV, PSQL, SQLITE, FILE:
 			return true
 		}
 	}
 
 	return false
 }
+
+// IndexesInOrder reports whether the sink must index the blocks in order of
+// height, so that it can't index several blocks concurrently.
+func IndexesInOrder(sink EventSink) bool {
+	return sink.Type() == FILE
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -18,7 +19,9 @@ import (
 	"github.com/tendermint/tendermint/internal/eventbus"
 	"github.com/tendermint/tendermint/internal/state/indexer"
 	"github.com/tendermint/tendermint/internal/state/indexer/sink/kv"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/null"
 	"github.com/tendermint/tendermint/internal/state/indexer/sink/psql"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/sqlite"
 	tmlog "github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/types"
 
@@ -58,7 +61,7 @@ func TestIndexerServiceIndexesBlocks(t *testing.T) {
 	assert.Nil(t, err)
 
 	store := dbm.NewMemDB()
-	eventSinks := []indexer.EventSink{kv.NewEventSink(store), pSink}
+	eventSinks := []indexer.EventSink{kv.NewEventSink(store, nil), pSink}
 	assert.True(t, indexer.KVSinkEnabled(eventSinks))
 	assert.True(t, indexer.IndexingEnabled(eventSinks))
 
@@ -112,6 +115,22 @@ func TestIndexerServiceIndexesBlocks(t *testing.T) {
 	assert.Nil(t, teardown(t, pool))
 }
 
+func TestSearchSink(t *testing.T) {
+	kvSink := kv.NewEventSink(dbm.NewMemDB(), nil)
+	psqlSink, err := psql.NewEventSink(fmt.Sprintf(dsn, user, password, port, dbName), "test-chainID")
+	require.NoError(t, err)
+	sqliteSink, err := sqlite.NewEventSink(filepath.Join(t.TempDir(), "tx_index.sqlite"), "test-chainID")
+	require.NoError(t, err)
+	defer sqliteSink.Stop()
+	nullSink := null.NewEventSink()
+
+	assert.Nil(t, indexer.SearchSink(nil))
+	assert.Nil(t, indexer.SearchSink([]indexer.EventSink{nullSink}))
+	assert.Equal(t, psqlSink, indexer.SearchSink([]indexer.EventSink{nullSink, psqlSink}))
+	assert.Equal(t, sqliteSink, indexer.SearchSink([]indexer.EventSink{nullSink, sqliteSink}))
+	assert.Equal(t, kvSink, indexer.SearchSink([]indexer.EventSink{psqlSink, kvSink}))
+}
+
 func readSchema() ([]*schema.Migration, error) {
 	filename := "./sink/psql/schema.sql"
 	contents, err := os.ReadFile(filename)
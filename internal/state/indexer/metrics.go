@@ -24,6 +24,12 @@ type Metrics struct {
 
 	// Number of transactions indexed.
 	TransactionsIndexed metrics.Counter
+
+	// Number of blocks an event sink is behind the latest block, by sink type.
+	Lag metrics.Gauge
+
+	// Number of blocks indexed by backfilling event sinks.
+	BlocksBackfilled metrics.Counter
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
@@ -59,6 +65,18 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Name:      "transactions_indexed",
 			Help:      "Number of transactions indexed.",
 		}, labels).With(labelsAndValues...),
+		Lag: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "lag",
+			Help:      "Number of blocks an event sink is behind the latest block, by sink type.",
+		}, append(labels, "sink_type")).With(labelsAndValues...),
+		BlocksBackfilled: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "blocks_backfilled",
+			Help:      "Number of blocks indexed by backfilling event sinks.",
+		}, labels).With(labelsAndValues...),
 	}
 }
 
@@ -69,5 +87,7 @@ func NopMetrics() *Metrics {
 		TxEventsSeconds:     discard.NewHistogram(),
 		BlocksIndexed:       discard.NewCounter(),
 		TransactionsIndexed: discard.NewCounter(),
+		Lag:                 discard.NewGauge(),
+		BlocksBackfilled:    discard.NewCounter(),
 	}
 }
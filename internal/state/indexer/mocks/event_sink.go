@@ -92,6 +92,20 @@ func (_m *EventSink) IndexTxEvents(_a0 []*types.TxResult) error {
 	return r0
 }
 
+// Prune provides a mock function with given fields: retainHeight
+func (_m *EventSink) Prune(retainHeight int64) error {
+	ret := _m.Called(retainHeight)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(retainHeight)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SearchBlockEvents provides a mock function with given fields: _a0, _a1
 func (_m *EventSink) SearchBlockEvents(_a0 context.Context, _a1 *query.Query) ([]int64, error) {
 	ret := _m.Called(_a0, _a1)
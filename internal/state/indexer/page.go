@@ -0,0 +1,267 @@
+package indexer
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+)
+
+// Cursor is the position of a search result in the order of the results: the
+// height of a block, or the height and index of a transaction.
+type Cursor struct {
+	Height int64
+	Index  uint32
+}
+
+// cursorSize is the size of an encoded cursor, before base64 encoding.
+const cursorSize = 12
+
+// String returns the opaque encoding of the cursor, which is passed to clients.
+func (c Cursor) String() string {
+	bz := make([]byte, cursorSize)
+	binary.BigEndian.PutUint64(bz, uint64(c.Height))
+	binary.BigEndian.PutUint32(bz[8:], c.Index)
+	return base64.RawURLEncoding.EncodeToString(bz)
+}
+
+// ParseCursor decodes a cursor returned by String.
+func ParseCursor(s string) (Cursor, error) {
+	bz, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(bz) != cursorSize {
+		return Cursor{}, fmt.Errorf("invalid cursor %q", s)
+	}
+	c := Cursor{
+		Height: int64(binary.BigEndian.Uint64(bz)),
+		Index:  binary.BigEndian.Uint32(bz[8:]),
+	}
+	if c.Height <= 0 {
+		return Cursor{}, fmt.Errorf("invalid cursor %q", s)
+	}
+	return c, nil
+}
+
+// Page selects a page of the results of a search, which are ordered by height,
+// and for transactions by index within a block.
+type Page struct {
+	// After is the position of the result preceding the page, or nil for the
+	// first page.
+	After *Cursor
+	// Desc orders the results by descending rather than ascending position.
+	Desc bool
+	// Limit is the maximum number of results in the page.
+	Limit int
+}
+
+// Less reports whether the position a precedes the position b in the order
+// of the page.
+func (p Page) Less(a, b Cursor) bool {
+	if a.Height != b.Height {
+		return (a.Height < b.Height) != p.Desc
+	}
+	if a.Index != b.Index {
+		return (a.Index < b.Index) != p.Desc
+	}
+	return false
+}
+
+// Includes reports whether the position follows the cursor of the page, if
+// any, so that it may be part of the page.
+func (p Page) Includes(c Cursor) bool {
+	return p.After == nil || p.Less(*p.After, c)
+}
+
+// TxPageSearcher is implemented by event sinks which can search for a page of
+// transactions without loading all the matching transactions.
+type TxPageSearcher interface {
+	// SearchTxEventsPage returns the page of the transactions matching the
+	// query, and whether more transactions follow the page.
+	SearchTxEventsPage(ctx context.Context, q *query.Query, page Page) ([]*abci.TxResult, bool, error)
+}
+
+// BlockPageSearcher is implemented by event sinks which can search for a page
+// of blocks without loading all the matching blocks.
+type BlockPageSearcher interface {
+	// SearchBlockEventsPage returns the heights of the page of the blocks
+	// matching the query, and whether more blocks follow the page.
+	SearchBlockEventsPage(ctx context.Context, q *query.Query, page Page) ([]int64, bool, error)
+}
+
+// SearchTxEventsPage returns the page of the transactions matching the query,
+// and whether more transactions follow the page. If the sink doesn't implement
+// TxPageSearcher, all the matching transactions are searched for first.
+func SearchTxEventsPage(
+	ctx context.Context,
+	sink EventSink,
+	q *query.Query,
+	page Page,
+) ([]*abci.TxResult, bool, error) {
+	if page.Limit <= 0 {
+		return nil, false, errors.New("the page limit must be positive")
+	}
+	if searcher, ok := sink.(TxPageSearcher); ok {
+		return searcher.SearchTxEventsPage(ctx, q, page)
+	}
+
+	results, err := sink.SearchTxEvents(ctx, q)
+	if err != nil {
+		return nil, false, err
+	}
+	results, more := PageTxResults(results, page)
+	return results, more, nil
+}
+
+// SearchBlockEventsPage returns the heights of the page of the blocks matching
+// the query, and whether more blocks follow the page. If the sink doesn't
+// implement BlockPageSearcher, all the matching blocks are searched for first.
+func SearchBlockEventsPage(ctx context.Context, sink EventSink, q *query.Query, page Page) ([]int64, bool, error) {
+	if page.Limit <= 0 {
+		return nil, false, errors.New("the page limit must be positive")
+	}
+	if searcher, ok := sink.(BlockPageSearcher); ok {
+		return searcher.SearchBlockEventsPage(ctx, q, page)
+	}
+
+	heights, err := sink.SearchBlockEvents(ctx, q)
+	if err != nil {
+		return nil, false, err
+	}
+	heights, more := PageHeights(heights, page)
+	return heights, more, nil
+}
+
+// PageTxResults sorts the transaction results in the order of the page, and
+// returns the page of them, and whether more results follow the page.
+func PageTxResults(results []*abci.TxResult, page Page) ([]*abci.TxResult, bool) {
+	sort.Slice(results, func(i, j int) bool {
+		return page.Less(txCursor(results[i]), txCursor(results[j]))
+	})
+	start := sort.Search(len(results), func(i int) bool {
+		return page.Includes(txCursor(results[i]))
+	})
+	results = results[start:]
+	if len(results) > page.Limit {
+		return results[:page.Limit], true
+	}
+	return results, false
+}
+
+// PageHeights sorts the block heights in the order of the page, and returns
+// the page of them, and whether more heights follow the page.
+func PageHeights(heights []int64, page Page) ([]int64, bool) {
+	sort.Slice(heights, func(i, j int) bool {
+		return page.Less(Cursor{Height: heights[i]}, Cursor{Height: heights[j]})
+	})
+	start := sort.Search(len(heights), func(i int) bool {
+		return page.Includes(Cursor{Height: heights[i]})
+	})
+	heights = heights[start:]
+	if len(heights) > page.Limit {
+		return heights[:page.Limit], true
+	}
+	return heights, false
+}
+
+// PageScan describes how to read a page of the results of a query lazily, by
+// scanning the index of one of its equality conditions. Since the keys of the
+// index start with the composite key and value of an event, followed by the
+// height of the block, the results satisfying the condition are ordered by
+// position in the index.
+type PageScan struct {
+	// Equal is the equality condition whose index is scanned.
+	Equal query.Condition
+	// Others are the other equality conditions the results must satisfy.
+	Others []query.Condition
+	// Heights are the comparisons the height of the results must satisfy.
+	Heights []query.Condition
+}
+
+// LookForPageScan returns how to scan a page of the results of the expression
+// lazily. It returns false unless the expression is an equality condition, or
+// a conjunction of equality conditions and comparisons of heightKey with
+// integers which includes an equality condition on another key.
+func LookForPageScan(e *query.Expr, heightKey string) (PageScan, bool) {
+	args := []*query.Expr{e}
+	if e.Type == query.ExprAnd {
+		args = e.Args
+	}
+
+	var (
+		scan  PageScan
+		equal bool
+	)
+	for _, arg := range args {
+		if arg.Type != query.ExprCondition {
+			return PageScan{}, false
+		}
+		c := arg.Condition
+
+		if c.CompositeKey == heightKey {
+			if _, ok := c.Operand.(int64); !ok {
+				return PageScan{}, false
+			}
+			switch c.Op {
+			case query.OpLessEqual, query.OpGreaterEqual, query.OpLess, query.OpGreater, query.OpEqual:
+			default:
+				return PageScan{}, false
+			}
+			scan.Heights = append(scan.Heights, c)
+			continue
+		}
+
+		switch c.Operand.(type) {
+		case string, int64, float64:
+		default:
+			return PageScan{}, false
+		}
+		switch {
+		case c.Op != query.OpEqual:
+			return PageScan{}, false
+		case !equal:
+			scan.Equal, equal = c, true
+		default:
+			scan.Others = append(scan.Others, c)
+		}
+	}
+	return scan, equal
+}
+
+// MatchHeight reports whether the height satisfies the comparisons of the
+// scan.
+func (s PageScan) MatchHeight(height int64) bool {
+	for _, c := range s.Heights {
+		operand := c.Operand.(int64)
+		switch c.Op {
+		case query.OpLessEqual:
+			if height > operand {
+				return false
+			}
+		case query.OpGreaterEqual:
+			if height < operand {
+				return false
+			}
+		case query.OpLess:
+			if height >= operand {
+				return false
+			}
+		case query.OpGreater:
+			if height <= operand {
+				return false
+			}
+		case query.OpEqual:
+			if height != operand {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func txCursor(r *abci.TxResult) Cursor {
+	return Cursor{Height: r.Height, Index: r.Index}
+}
@@ -0,0 +1,89 @@
+package indexer_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+)
+
+func TestCursor(t *testing.T) {
+	for _, c := range []indexer.Cursor{{Height: 1}, {Height: 100, Index: 7}, {Height: 1 << 62, Index: 1<<32 - 1}} {
+		parsed, err := indexer.ParseCursor(c.String())
+		require.NoError(t, err)
+		assert.Equal(t, c, parsed)
+	}
+
+	for _, s := range []string{"", "abc", indexer.Cursor{}.String(), indexer.Cursor{Height: -1}.String()} {
+		_, err := indexer.ParseCursor(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestPageTxResults(t *testing.T) {
+	var results []*abci.TxResult
+	for _, pos := range [][2]int64{{2, 1}, {1, 0}, {3, 0}, {2, 0}, {1, 1}} {
+		results = append(results, &abci.TxResult{Height: pos[0], Index: uint32(pos[1])})
+	}
+	positions := func(results []*abci.TxResult) [][2]int64 {
+		var pos [][2]int64
+		for _, r := range results {
+			pos = append(pos, [2]int64{r.Height, int64(r.Index)})
+		}
+		return pos
+	}
+
+	page, more := indexer.PageTxResults(results, indexer.Page{Limit: 2})
+	assert.Equal(t, [][2]int64{{1, 0}, {1, 1}}, positions(page))
+	assert.True(t, more)
+
+	page, more = indexer.PageTxResults(results, indexer.Page{After: &indexer.Cursor{Height: 1, Index: 1}, Limit: 3})
+	assert.Equal(t, [][2]int64{{2, 0}, {2, 1}, {3, 0}}, positions(page))
+	assert.False(t, more)
+
+	page, more = indexer.PageTxResults(results, indexer.Page{After: &indexer.Cursor{Height: 2, Index: 1}, Desc: true, Limit: 2})
+	assert.Equal(t, [][2]int64{{2, 0}, {1, 1}}, positions(page))
+	assert.True(t, more)
+
+	heights, more := indexer.PageHeights([]int64{5, 1, 3, 4}, indexer.Page{After: &indexer.Cursor{Height: 4}, Desc: true, Limit: 5})
+	assert.Equal(t, []int64{3, 1}, heights)
+	assert.False(t, more)
+}
+
+func TestLookForPageScan(t *testing.T) {
+	testCases := []struct {
+		q       string
+		ok      bool
+		equal   string
+		others  int
+		heights int
+	}{
+		{"account.owner = 'Ivan'", true, "account.owner", 0, 0},
+		{"tx.height > 5 AND account.owner = 'Ivan' AND account.number = 1", true, "account.owner", 1, 1},
+		{"tx.height = 5", false, "", 0, 0},
+		{"account.owner = 'Ivan' AND tx.height = 'five'", false, "", 0, 0},
+		{"account.owner = 'Ivan' AND account.number > 1", false, "", 0, 0},
+		{"account.owner = 'Ivan' OR account.number = 1", false, "", 0, 0},
+		{"account.owner = 'Ivan' AND NOT account.number = 1", false, "", 0, 0},
+		{"account.date = DATE 2013-05-03", false, "", 0, 0},
+	}
+	for _, tc := range testCases {
+		scan, ok := indexer.LookForPageScan(query.MustParse(tc.q).Expr(), "tx.height")
+		require.Equal(t, tc.ok, ok, tc.q)
+		if ok {
+			assert.Equal(t, tc.equal, scan.Equal.CompositeKey, tc.q)
+			assert.Len(t, scan.Others, tc.others, tc.q)
+			assert.Len(t, scan.Heights, tc.heights, tc.q)
+		}
+	}
+
+	scan, ok := indexer.LookForPageScan(query.MustParse("a.b = 1 AND tx.height > 2 AND tx.height <= 4").Expr(), "tx.height")
+	require.True(t, ok)
+	for height, expected := range map[int64]bool{2: false, 3: true, 4: true, 5: false} {
+		assert.Equal(t, expected, scan.MatchHeight(height), height)
+	}
+}
@@ -0,0 +1,341 @@
+// Package file implements an event sink which appends blocks and transaction
+// results to files, for consumption by external data pipelines.
+//
+// Each block header and transaction result is written as a JSON-encoded Record
+// on a line of its own (NDJSON), in the order of execution. The lines are
+// appended to the head file "events.ndjson" in the sink's directory, which is
+// rotated to "events.ndjson.000", "events.ndjson.001", and so on, as it grows
+// beyond its size limit (see autofile.Group). Rotated files are never removed
+// by the sink.
+//
+// Once all records of a block have been written, the files are synced to disk
+// and the checkpoint file "checkpoint.json" is atomically replaced, recording
+// the height of the block along with the index and size of the head file. Only
+// lines up to the checkpoint are guaranteed to be complete: a consumer should
+// not process records above the checkpoint height, and can resume after the
+// last height it processed. When the sink is opened, data written after the
+// checkpoint is discarded, and blocks at or below the checkpoint height are
+// never written again, so re-indexing resumes exactly after the checkpoint.
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/libs/autofile"
+	"github.com/tendermint/tendermint/internal/libs/tempfile"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/libs/bytes"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	tmos "github.com/tendermint/tendermint/libs/os"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+	"github.com/tendermint/tendermint/types"
+)
+
+const (
+	// HeadFileName is the name of the head file the records are appended to.
+	HeadFileName = "events.ndjson"
+	// CheckpointFileName is the name of the checkpoint file.
+	CheckpointFileName = "checkpoint.json"
+)
+
+// errSearchNotSupported is returned by the search functions of the sink.
+var errSearchNotSupported = errors.New("the file event sink does not support searching")
+
+// Record is a line of the event files, holding either a block header or a
+// transaction result, along with its events decoded into a map from composite
+// keys of the form "type.key" to attribute values.
+type Record struct {
+	Height   int64                          `json:"height"`
+	Block    *types.EventDataNewBlockHeader `json:"block,omitempty"`
+	TxHash   bytes.HexBytes                 `json:"tx_hash,omitempty"`
+	TxResult *abci.TxResult                 `json:"tx_result,omitempty"`
+	Events   map[string][]string            `json:"events"`
+}
+
+// Checkpoint records the last block fully written to the event files.
+type Checkpoint struct {
+	// Height is the height of the last block whose header and transaction
+	// results have all been written, or 0 if no block has been written.
+	Height int64 `json:"height"`
+	// Index is the group index of the head file at the checkpoint.
+	Index int `json:"index"`
+	// Offset is the size of the head file at the checkpoint.
+	Offset int64 `json:"offset"`
+}
+
+// LoadCheckpoint loads the checkpoint from the sink's directory. It returns an
+// empty checkpoint if the sink hasn't written any block yet.
+func LoadCheckpoint(dir string) (Checkpoint, error) {
+	var cp Checkpoint
+	bz, err := os.ReadFile(filepath.Join(dir, CheckpointFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	} else if err != nil {
+		return cp, err
+	}
+	if err := tmjson.Unmarshal(bz, &cp); err != nil {
+		return cp, fmt.Errorf("decoding checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+// EventSink is an indexer backend writing block and transaction events to
+// rotating NDJSON files. It doesn't support searching.
+type EventSink struct {
+	mtx        sync.Mutex
+	dir        string
+	group      *autofile.Group
+	checkpoint Checkpoint
+
+	// The block being written, and the number of its transaction results
+	// which have not been written yet.
+	pendingHeight int64
+	pendingTxs    int64
+}
+
+// NewEventSink constructs an event sink writing to the files in dir, which is
+// created if it doesn't exist. Data written after the last checkpoint, for
+// example if the node crashed while writing a block, is discarded. The options
+// configure the file group, such as its head size limit.
+func NewEventSink(dir string, groupOptions ...func(*autofile.Group)) (*EventSink, error) {
+	if err := tmos.EnsureDir(dir, 0700); err != nil {
+		return nil, err
+	}
+	cp, err := LoadCheckpoint(dir)
+	if err != nil {
+		return nil, err
+	}
+	group, err := autofile.OpenGroup(filepath.Join(dir, HeadFileName), groupOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	es := &EventSink{
+		dir:        dir,
+		group:      group,
+		checkpoint: cp,
+	}
+	if err := es.truncate(); err != nil {
+		group.Close()
+		return nil, fmt.Errorf("discarding data after the checkpoint: %w", err)
+	}
+	return es, nil
+}
+
+// Type returns the structure type for this sink, which is file.
+func (es *EventSink) Type() indexer.EventSinkType { return indexer.FILE }
+
+// Checkpoint returns the last checkpoint written by the sink.
+func (es *EventSink) Checkpoint() Checkpoint {
+	es.mtx.Lock()
+	defer es.mtx.Unlock()
+	return es.checkpoint
+}
+
+// IndexBlockEvents writes the block header, part of the indexer.EventSink
+// interface. Blocks at or below the checkpoint height are skipped. If the block
+// has no transactions, it is complete and a checkpoint is written.
+func (es *EventSink) IndexBlockEvents(h types.EventDataNewBlockHeader) error {
+	es.mtx.Lock()
+	defer es.mtx.Unlock()
+
+	if h.Header.Height <= es.checkpoint.Height {
+		return nil // we already wrote this block; quietly succeed
+	}
+	if es.pendingTxs > 0 {
+		// The previous block is incomplete, so discard it.
+		if err := es.truncate(); err != nil {
+			return fmt.Errorf("discarding incomplete block %d: %w", es.pendingHeight, err)
+		}
+	}
+
+	events := append([]abci.Event{}, h.ResultBeginBlock.Events...)
+	events = append(events, h.ResultEndBlock.Events...)
+	decoded := decodeEvents(events)
+	decoded[types.BlockHeightKey] = []string{fmt.Sprint(h.Header.Height)}
+
+	if err := es.writeRecord(Record{
+		Height: h.Header.Height,
+		Block:  &h,
+		Events: decoded,
+	}); err != nil {
+		return fmt.Errorf("writing block header: %w", err)
+	}
+
+	es.pendingHeight = h.Header.Height
+	es.pendingTxs = h.NumTxs
+	if es.pendingTxs <= 0 {
+		return es.commit()
+	}
+	return nil
+}
+
+// IndexTxEvents writes the transaction results, part of the indexer.EventSink
+// interface. The results must belong to the block written last. Once all its
+// results have been written, the block is complete and a checkpoint is
+// written.
+func (es *EventSink) IndexTxEvents(txrs []*abci.TxResult) error {
+	es.mtx.Lock()
+	defer es.mtx.Unlock()
+
+	for _, txr := range txrs {
+		if txr.Height <= es.checkpoint.Height {
+			continue // we already wrote this transaction; quietly succeed
+		}
+		if txr.Height != es.pendingHeight || es.pendingTxs <= 0 {
+			return fmt.Errorf("transaction result at height %d does not belong to the block being written",
+				txr.Height)
+		}
+
+		hash := types.Tx(txr.Tx).Hash()
+		decoded := decodeEvents(txr.Result.Events)
+		decoded[types.TxHashKey] = []string{fmt.Sprintf("%X", hash)}
+		decoded[types.TxHeightKey] = []string{fmt.Sprint(txr.Height)}
+
+		if err := es.writeRecord(Record{
+			Height:   txr.Height,
+			TxHash:   hash,
+			TxResult: txr,
+			Events:   decoded,
+		}); err != nil {
+			return fmt.Errorf("writing tx_result: %w", err)
+		}
+		es.pendingTxs--
+	}
+
+	if es.pendingHeight > es.checkpoint.Height && es.pendingTxs == 0 {
+		return es.commit()
+	}
+	return nil
+}
+
+// SearchBlockEvents is not supported by the file event sink.
+func (es *EventSink) SearchBlockEvents(ctx context.Context, q *query.Query) ([]int64, error) {
+	return nil, errSearchNotSupported
+}
+
+// SearchTxEvents is not supported by the file event sink.
+func (es *EventSink) SearchTxEvents(ctx context.Context, q *query.Query) ([]*abci.TxResult, error) {
+	return nil, errSearchNotSupported
+}
+
+// GetTxByHash is not supported by the file event sink.
+func (es *EventSink) GetTxByHash(hash []byte) (*abci.TxResult, error) {
+	return nil, errSearchNotSupported
+}
+
+// HasBlock reports whether the block at the given height has been fully
+// written, that is whether it is at or below the checkpoint height.
+func (es *EventSink) HasBlock(h int64) (bool, error) {
+	es.mtx.Lock()
+	defer es.mtx.Unlock()
+	return h <= es.checkpoint.Height, nil
+}
+
+// Prune does nothing, part of the indexer.EventSink interface: the event files
+// are meant for consumption by external pipelines, which are responsible for
+// removing the rotated files they have processed.
+func (es *EventSink) Prune(retainHeight int64) error {
+	return nil
+}
+
+// Stop closes the event files. An incomplete block is discarded when the sink
+// is opened again.
+func (es *EventSink) Stop() error {
+	es.mtx.Lock()
+	defer es.mtx.Unlock()
+	es.group.Close()
+	return nil
+}
+
+// writeRecord appends the record as a line to the head file.
+func (es *EventSink) writeRecord(r Record) error {
+	bz, err := tmjson.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = es.group.Write(append(bz, '\n'))
+	return err
+}
+
+// commit syncs the pending block to disk, rotates the head file if it reached
+// its size limit, and writes a checkpoint for the block.
+func (es *EventSink) commit() error {
+	if err := es.group.FlushAndSync(); err != nil {
+		return fmt.Errorf("syncing event files: %w", err)
+	}
+	size, err := es.group.Head.Size()
+	if err != nil {
+		return err
+	}
+	if limit := es.group.HeadSizeLimit(); limit > 0 && size >= limit {
+		es.group.RotateFile()
+		size = 0
+	}
+
+	cp := Checkpoint{
+		Height: es.pendingHeight,
+		Index:  es.group.MaxIndex(),
+		Offset: size,
+	}
+	bz, err := tmjson.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tempfile.WriteFileAtomic(filepath.Join(es.dir, CheckpointFileName), bz, 0600); err != nil {
+		return fmt.Errorf("writing checkpoint: %w", err)
+	}
+	es.checkpoint = cp
+	es.pendingTxs = 0
+	return nil
+}
+
+// truncate discards the data written to the head file after the checkpoint.
+// Since the head file is only rotated right before a checkpoint is written, if
+// the head file has been rotated since the checkpoint, no data has been
+// written to the new head file after the checkpoint.
+func (es *EventSink) truncate() error {
+	if err := es.group.FlushAndSync(); err != nil {
+		return err
+	}
+	size, err := es.group.Head.Size()
+	if err != nil {
+		return err
+	}
+
+	offset := es.checkpoint.Offset
+	if es.group.MaxIndex() != es.checkpoint.Index {
+		offset = 0
+	}
+	if size > offset {
+		if err := os.Truncate(es.group.Head.Path, offset); err != nil {
+			return err
+		}
+	}
+	es.pendingTxs = 0
+	return nil
+}
+
+// decodeEvents returns the attribute values of the events by composite key.
+func decodeEvents(events []abci.Event) map[string][]string {
+	decoded := make(map[string][]string)
+	for _, evt := range events {
+		if evt.Type == "" {
+			continue
+		}
+		for _, attr := range evt.Attributes {
+			if attr.Key == "" {
+				continue
+			}
+			compositeKey := evt.Type + "." + attr.Key
+			decoded[compositeKey] = append(decoded[compositeKey], attr.Value)
+		}
+	}
+	return decoded
+}
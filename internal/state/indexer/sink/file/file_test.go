@@ -0,0 +1,221 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/libs/autofile"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+	"github.com/tendermint/tendermint/types"
+)
+
+// Verify that the type satisfies the EventSink interface.
+var _ indexer.EventSink = (*EventSink)(nil)
+
+func TestType(t *testing.T) {
+	es, err := NewEventSink(t.TempDir())
+	require.NoError(t, err)
+	defer es.Stop()
+	assert.Equal(t, indexer.FILE, es.Type())
+}
+
+func TestIndexing(t *testing.T) {
+	dir := t.TempDir()
+	es, err := NewEventSink(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, es.IndexBlockEvents(newTestBlockHeader(1, 0)))
+	assert.Equal(t, Checkpoint{Height: 1, Offset: headSize(t, dir)}, es.Checkpoint())
+
+	require.NoError(t, es.IndexBlockEvents(newTestBlockHeader(2, 2)))
+	ok, err := es.HasBlock(2)
+	require.NoError(t, err)
+	assert.False(t, ok, "the block is incomplete")
+
+	require.NoError(t, es.IndexTxEvents([]*abci.TxResult{newTestTxResult(2, 0), newTestTxResult(2, 1)}))
+	ok, err = es.HasBlock(2)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	require.NoError(t, es.Stop())
+
+	// The checkpoint is persisted.
+	cp, err := LoadCheckpoint(dir)
+	require.NoError(t, err)
+	assert.Equal(t, Checkpoint{Height: 2, Offset: headSize(t, dir)}, cp)
+
+	records := readRecords(t, filepath.Join(dir, HeadFileName))
+	require.Len(t, records, 4)
+	assert.EqualValues(t, 1, records[0].Height)
+	assert.Equal(t, []string{"FCAA001"}, records[0].Events["begin_event.proposer"])
+	assert.Equal(t, []string{"1"}, records[0].Events[types.BlockHeightKey])
+	assert.EqualValues(t, 2, records[1].Block.Header.Height)
+	for i, r := range records[2:] {
+		require.NotNil(t, r.TxResult)
+		assert.EqualValues(t, i, r.TxResult.Index)
+		assert.Equal(t, types.Tx(r.TxResult.Tx).Hash(), []byte(r.TxHash))
+		assert.Equal(t, []string{"Ivan"}, r.Events["account.owner"])
+		assert.Equal(t, []string{"2"}, r.Events[types.TxHeightKey])
+	}
+
+	// Search isn't supported.
+	_, err = es.SearchTxEvents(context.Background(), query.MustParse("account.owner = 'Ivan'"))
+	require.Error(t, err)
+	_, err = es.SearchBlockEvents(context.Background(), query.MustParse("block.height = 1"))
+	require.Error(t, err)
+	_, err = es.GetTxByHash(types.Tx("tx").Hash())
+	require.Error(t, err)
+}
+
+func TestResume(t *testing.T) {
+	dir := t.TempDir()
+	es, err := NewEventSink(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, es.IndexBlockEvents(newTestBlockHeader(1, 1)))
+	require.NoError(t, es.IndexTxEvents([]*abci.TxResult{newTestTxResult(1, 0)}))
+	// Block 2 is interrupted after its header has been written.
+	require.NoError(t, es.IndexBlockEvents(newTestBlockHeader(2, 1)))
+	require.NoError(t, es.Stop())
+	require.Len(t, readRecords(t, filepath.Join(dir, HeadFileName)), 3)
+
+	// Reopening the sink discards the incomplete block.
+	es, err = NewEventSink(dir)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, es.Checkpoint().Height)
+	require.Len(t, readRecords(t, filepath.Join(dir, HeadFileName)), 2)
+
+	// Blocks at or below the checkpoint are skipped, so re-indexing from an
+	// earlier height resumes exactly after the checkpoint.
+	for h := int64(1); h <= 3; h++ {
+		require.NoError(t, es.IndexBlockEvents(newTestBlockHeader(h, 1)))
+		require.NoError(t, es.IndexTxEvents([]*abci.TxResult{newTestTxResult(h, 0)}))
+	}
+	require.NoError(t, es.Stop())
+
+	records := readRecords(t, filepath.Join(dir, HeadFileName))
+	require.Len(t, records, 6)
+	for i, r := range records {
+		assert.EqualValues(t, i/2+1, r.Height)
+	}
+}
+
+func TestIncompleteBlock(t *testing.T) {
+	dir := t.TempDir()
+	es, err := NewEventSink(dir)
+	require.NoError(t, err)
+	defer es.Stop()
+
+	// The transactions of block 1 are never written, so block 1 is discarded
+	// when block 2 starts.
+	require.NoError(t, es.IndexBlockEvents(newTestBlockHeader(1, 1)))
+	require.NoError(t, es.IndexBlockEvents(newTestBlockHeader(2, 0)))
+	assert.EqualValues(t, 2, es.Checkpoint().Height)
+
+	records := readRecords(t, filepath.Join(dir, HeadFileName))
+	require.Len(t, records, 1)
+	assert.EqualValues(t, 2, records[0].Height)
+
+	// Transaction results must belong to the block being written.
+	require.NoError(t, es.IndexBlockEvents(newTestBlockHeader(3, 1)))
+	require.Error(t, es.IndexTxEvents([]*abci.TxResult{newTestTxResult(4, 0)}))
+}
+
+func TestRotation(t *testing.T) {
+	dir := t.TempDir()
+	es, err := NewEventSink(dir, autofile.GroupHeadSizeLimit(1000))
+	require.NoError(t, err)
+
+	for h := int64(1); h <= 20; h++ {
+		require.NoError(t, es.IndexBlockEvents(newTestBlockHeader(h, 1)))
+		require.NoError(t, es.IndexTxEvents([]*abci.TxResult{newTestTxResult(h, 0)}))
+	}
+	cp := es.Checkpoint()
+	require.NoError(t, es.Stop())
+	assert.EqualValues(t, 20, cp.Height)
+	assert.Positive(t, cp.Index)
+
+	// Files are only rotated between blocks, so each file holds complete
+	// blocks, and all blocks are written in order.
+	var records []Record
+	for i := 0; i < cp.Index; i++ {
+		rs := readRecords(t, filepath.Join(dir, fmt.Sprintf("%s.%03d", HeadFileName, i)))
+		require.NotEmpty(t, rs)
+		require.NotNil(t, rs[0].Block)
+		require.NotNil(t, rs[len(rs)-1].TxResult)
+		records = append(records, rs...)
+	}
+	records = append(records, readRecords(t, filepath.Join(dir, HeadFileName))...)
+	require.Len(t, records, 40)
+	for i, r := range records {
+		assert.EqualValues(t, i/2+1, r.Height)
+	}
+
+	// Reopening the sink keeps the rotated files.
+	es, err = NewEventSink(dir, autofile.GroupHeadSizeLimit(1000))
+	require.NoError(t, err)
+	defer es.Stop()
+	require.NoError(t, es.IndexBlockEvents(newTestBlockHeader(21, 0)))
+	assert.GreaterOrEqual(t, es.Checkpoint().Index, cp.Index)
+}
+
+func newTestBlockHeader(height, numTxs int64) types.EventDataNewBlockHeader {
+	return types.EventDataNewBlockHeader{
+		Header: types.Header{Height: height},
+		NumTxs: numTxs,
+		ResultBeginBlock: abci.ResponseBeginBlock{
+			Events: []abci.Event{{
+				Type:       "begin_event",
+				Attributes: []abci.EventAttribute{{Key: "proposer", Value: "FCAA001", Index: true}},
+			}},
+		},
+	}
+}
+
+func newTestTxResult(height int64, index uint32) *abci.TxResult {
+	return &abci.TxResult{
+		Height: height,
+		Index:  index,
+		Tx:     types.Tx([]byte{byte(height), byte(index)}),
+		Result: abci.ResponseDeliverTx{
+			Code: abci.CodeTypeOK,
+			Events: []abci.Event{{
+				Type:       "account",
+				Attributes: []abci.EventAttribute{{Key: "owner", Value: "Ivan", Index: true}},
+			}},
+		},
+	}
+}
+
+func readRecords(t *testing.T, path string) []Record {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		require.NoError(t, tmjson.Unmarshal(scanner.Bytes(), &r))
+		records = append(records, r)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func headSize(t *testing.T, dir string) int64 {
+	t.Helper()
+	fi, err := os.Stat(filepath.Join(dir, HeadFileName))
+	require.NoError(t, err)
+	return fi.Size()
+}
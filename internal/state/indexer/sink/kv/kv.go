@@ -2,6 +2,7 @@ package kv
 
 import (
 	"context"
+	"fmt"
 
 	dbm "github.com/tendermint/tm-db"
 
@@ -13,7 +14,11 @@ import (
 	"github.com/tendermint/tendermint/types"
 )
 
-var _ indexer.EventSink = (*EventSink)(nil)
+var (
+	_ indexer.EventSink         = (*EventSink)(nil)
+	_ indexer.TxPageSearcher    = (*EventSink)(nil)
+	_ indexer.BlockPageSearcher = (*EventSink)(nil)
+)
 
 // The EventSink is an aggregator for redirecting the call path of the tx/block kvIndexer.
 // For the implementation details please see the kv.go in the indexer/block and indexer/tx folder.
@@ -23,10 +28,13 @@ type EventSink struct {
 	store dbm.DB
 }
 
-func NewEventSink(store dbm.DB) indexer.EventSink {
+// NewEventSink returns an event sink indexing the events in store. If filter
+// is not nil, only the event attributes it selects are indexed, for both
+// transaction and block events.
+func NewEventSink(store dbm.DB, filter *indexer.AttributeFilter) indexer.EventSink {
 	return &EventSink{
-		txi:   kvt.NewTxIndex(store),
-		bi:    kvb.New(store),
+		txi:   kvt.NewTxIndex(store, kvt.WithAttributeFilter(filter)),
+		bi:    kvb.New(store, kvb.WithAttributeFilter(filter)),
 		store: store,
 	}
 }
@@ -51,6 +59,26 @@ func (kves *EventSink) SearchTxEvents(ctx context.Context, q *query.Query) ([]*a
 	return kves.txi.Search(ctx, q)
 }
 
+// SearchBlockEventsPage returns the heights of the page of the blocks matching
+// the query, reading them lazily from the index when possible.
+func (kves *EventSink) SearchBlockEventsPage(
+	ctx context.Context,
+	q *query.Query,
+	page indexer.Page,
+) ([]int64, bool, error) {
+	return kves.bi.SearchPage(ctx, q, page)
+}
+
+// SearchTxEventsPage returns the page of the transactions matching the query,
+// reading them lazily from the index when possible.
+func (kves *EventSink) SearchTxEventsPage(
+	ctx context.Context,
+	q *query.Query,
+	page indexer.Page,
+) ([]*abci.TxResult, bool, error) {
+	return kves.txi.SearchPage(ctx, q, page)
+}
+
 func (kves *EventSink) GetTxByHash(hash []byte) (*abci.TxResult, error) {
 	return kves.txi.Get(hash)
 }
@@ -59,6 +87,30 @@ func (kves *EventSink) HasBlock(h int64) (bool, error) {
 	return kves.bi.Has(h)
 }
 
+// Prune removes all transactions and blocks below the given height from the
+// index, along with their events.
+func (kves *EventSink) Prune(retainHeight int64) error {
+	if err := kves.txi.Prune(retainHeight); err != nil {
+		return fmt.Errorf("failed to prune transactions: %w", err)
+	}
+	if err := kves.bi.Prune(retainHeight); err != nil {
+		return fmt.Errorf("failed to prune blocks: %w", err)
+	}
+	return nil
+}
+
+// DeleteFrom removes all transactions and blocks at and above the given
+// height from the index, along with their events.
+func (kves *EventSink) DeleteFrom(height int64) error {
+	if err := kves.txi.DeleteFrom(height); err != nil {
+		return fmt.Errorf("failed to delete transactions: %w", err)
+	}
+	if err := kves.bi.DeleteFrom(height); err != nil {
+		return fmt.Errorf("failed to delete blocks: %w", err)
+	}
+	return nil
+}
+
 func (kves *EventSink) Stop() error {
 	return kves.store.Close()
 }
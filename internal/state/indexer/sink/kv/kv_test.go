@@ -12,24 +12,25 @@ import (
 	"github.com/stretchr/testify/require"
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/sinktest"
 	kvtx "github.com/tendermint/tendermint/internal/state/indexer/tx/kv"
 	"github.com/tendermint/tendermint/libs/pubsub/query"
 	"github.com/tendermint/tendermint/types"
 )
 
 func TestType(t *testing.T) {
-	kvSink := NewEventSink(dbm.NewMemDB())
+	kvSink := NewEventSink(dbm.NewMemDB(), nil)
 	assert.Equal(t, indexer.KV, kvSink.Type())
 }
 
 func TestStop(t *testing.T) {
-	kvSink := NewEventSink(dbm.NewMemDB())
+	kvSink := NewEventSink(dbm.NewMemDB(), nil)
 	assert.Nil(t, kvSink.Stop())
 }
 
 func TestBlockFuncs(t *testing.T) {
 	store := dbm.NewPrefixDB(dbm.NewMemDB(), []byte("block_events"))
-	indexer := NewEventSink(store)
+	indexer := NewEventSink(store, nil)
 
 	require.NoError(t, indexer.IndexBlockEvents(types.EventDataNewBlockHeader{
 		Header: types.Header{Height: 1},
@@ -66,100 +67,21 @@ func TestBlockFuncs(t *testing.T) {
 	b, e := indexer.HasBlock(1)
 	assert.Nil(t, e)
 	assert.True(t, b)
+	b, e = indexer.HasBlock(2)
+	assert.Nil(t, e)
+	assert.False(t, b)
+}
 
-	for i := 2; i < 12; i++ {
-		var index bool
-		if i%2 == 0 {
-			index = true
-		}
-
-		require.NoError(t, indexer.IndexBlockEvents(types.EventDataNewBlockHeader{
-			Header: types.Header{Height: int64(i)},
-			ResultBeginBlock: abci.ResponseBeginBlock{
-				Events: []abci.Event{
-					{
-						Type: "begin_event",
-						Attributes: []abci.EventAttribute{
-							{
-								Key:   "proposer",
-								Value: "FCAA001",
-								Index: true,
-							},
-						},
-					},
-				},
-			},
-			ResultEndBlock: abci.ResponseEndBlock{
-				Events: []abci.Event{
-					{
-						Type: "end_event",
-						Attributes: []abci.EventAttribute{
-							{
-								Key:   "foo",
-								Value: fmt.Sprintf("%d", i),
-								Index: index,
-							},
-						},
-					},
-				},
-			},
-		}))
-	}
-
-	testCases := map[string]struct {
-		q       *query.Query
-		results []int64
-	}{
-		"block.height = 100": {
-			q:       query.MustParse("block.height = 100"),
-			results: []int64{},
-		},
-		"block.height = 5": {
-			q:       query.MustParse("block.height = 5"),
-			results: []int64{5},
-		},
-		"begin_event.key1 = 'value1'": {
-			q:       query.MustParse("begin_event.key1 = 'value1'"),
-			results: []int64{},
-		},
-		"begin_event.proposer = 'FCAA001'": {
-			q:       query.MustParse("begin_event.proposer = 'FCAA001'"),
-			results: []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
-		},
-		"end_event.foo <= 5": {
-			q:       query.MustParse("end_event.foo <= 5"),
-			results: []int64{2, 4},
-		},
-		"end_event.foo >= 100": {
-			q:       query.MustParse("end_event.foo >= 100"),
-			results: []int64{1},
-		},
-		"block.height > 2 AND end_event.foo <= 8": {
-			q:       query.MustParse("block.height > 2 AND end_event.foo <= 8"),
-			results: []int64{4, 6, 8},
-		},
-		"begin_event.proposer CONTAINS 'FFFFFFF'": {
-			q:       query.MustParse("begin_event.proposer CONTAINS 'FFFFFFF'"),
-			results: []int64{},
-		},
-		"begin_event.proposer CONTAINS 'FCAA001'": {
-			q:       query.MustParse("begin_event.proposer CONTAINS 'FCAA001'"),
-			results: []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
-		},
-	}
+func TestBlockSearch(t *testing.T) {
+	sinktest.BlockSearch(t, NewEventSink(dbm.NewMemDB(), nil))
+}
 
-	for name, tc := range testCases {
-		tc := tc
-		t.Run(name, func(t *testing.T) {
-			results, err := indexer.SearchBlockEvents(context.Background(), tc.q)
-			require.NoError(t, err)
-			require.Equal(t, tc.results, results)
-		})
-	}
+func TestTxSearch(t *testing.T) {
+	sinktest.TxSearch(t, NewEventSink(dbm.NewMemDB(), nil))
 }
 
 func TestTxSearchWithCancelation(t *testing.T) {
-	indexer := NewEventSink(dbm.NewMemDB())
+	indexer := NewEventSink(dbm.NewMemDB(), nil)
 
 	txResult := txResultWithEvents([]abci.Event{
 		{Type: "account", Attributes: []abci.EventAttribute{{Key: "number", Value: "1", Index: true}}},
@@ -182,7 +104,7 @@ func TestTxSearchWithCancelation(t *testing.T) {
 
 func TestTxSearchDeprecatedIndexing(t *testing.T) {
 	esdb := dbm.NewMemDB()
-	indexer := NewEventSink(esdb)
+	indexer := NewEventSink(esdb, nil)
 
 	// index tx using events indexing (composite key)
 	txResult1 := txResultWithEvents([]abci.Event{
@@ -261,7 +183,7 @@ func TestTxSearchDeprecatedIndexing(t *testing.T) {
 }
 
 func TestTxSearchOneTxWithMultipleSameTagsButDifferentValues(t *testing.T) {
-	indexer := NewEventSink(dbm.NewMemDB())
+	indexer := NewEventSink(dbm.NewMemDB(), nil)
 
 	txResult := txResultWithEvents([]abci.Event{
 		{Type: "account", Attributes: []abci.EventAttribute{{Key: "number", Value: "1", Index: true}}},
@@ -283,7 +205,7 @@ func TestTxSearchOneTxWithMultipleSameTagsButDifferentValues(t *testing.T) {
 }
 
 func TestTxSearchMultipleTxs(t *testing.T) {
-	indexer := NewEventSink(dbm.NewMemDB())
+	indexer := NewEventSink(dbm.NewMemDB(), nil)
 
 	// indexed first, but bigger height (to test the order of transactions)
 	txResult := txResultWithEvents([]abci.Event{
@@ -10,6 +10,7 @@ import (
 )
 
 var _ indexer.EventSink = (*EventSink)(nil)
+var _ indexer.EventSinkDeleter = (*EventSink)(nil)
 
 // EventSink implements a no-op indexer.
 type EventSink struct{}
@@ -46,6 +47,16 @@ func (nes *EventSink) HasBlock(h int64) (bool, error) {
 	return false, nil
 }
 
+// Prune is a no-op, since the null sink doesn't store anything.
+func (nes *EventSink) Prune(retainHeight int64) error {
+	return nil
+}
+
+// DeleteFrom is a no-op, since the null sink doesn't store anything.
+func (nes *EventSink) DeleteFrom(height int64) error {
+	return nil
+}
+
 func (nes *EventSink) Stop() error {
 	return nil
 }
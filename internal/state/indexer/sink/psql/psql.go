@@ -4,7 +4,7 @@ package psql
 import (
 	"context"
 	"database/sql"
-	"errors"
+	_ "embed" // for the schema
 	"fmt"
 	"strings"
 	"time"
@@ -24,12 +24,22 @@ const (
 	driverName      = "postgres"
 )
 
+// Schema is the database schema of the event sink, as defined in
+// state/indexer/sink/psql/schema.sql.
+//
+//go:embed schema.sql
+var Schema string
+
 // EventSink is an indexer backend providing the tx/block index services.  This
 // implementation stores records in a PostgreSQL database using the schema
 // defined in state/indexer/sink/psql/schema.sql.
+//
+// The same implementation serves other SQL databases using the schema, with
+// the SQL dialect of the database (see NewEventSinkWithDB).
 type EventSink struct {
 	store   *sql.DB
 	chainID string
+	dialect Dialect
 }
 
 // NewEventSink constructs an event sink associated with the PostgreSQL
@@ -41,18 +51,32 @@ func NewEventSink(connStr, chainID string) (*EventSink, error) {
 		return nil, err
 	}
 
+	return NewEventSinkWithDB(db, chainID, Postgres), nil
+}
+
+// NewEventSinkWithDB constructs an event sink storing records in db, which
+// must use the sink's schema and the given SQL dialect. Events written to the
+// sink are attributed to the specified chainID.
+func NewEventSinkWithDB(db *sql.DB, chainID string, dialect Dialect) *EventSink {
 	return &EventSink{
 		store:   db,
 		chainID: chainID,
-	}, nil
+		dialect: dialect,
+	}
 }
 
-// DB returns the underlying Postgres connection used by the sink.
+// DB returns the underlying database connection used by the sink.
 // This is exported to support testing.
 func (es *EventSink) DB() *sql.DB { return es.store }
 
-// Type returns the structure type for this sink, which is Postgres.
-func (es *EventSink) Type() indexer.EventSinkType { return indexer.PSQL }
+// Type returns the structure type for this sink, which is Postgres, or SQLite
+// for a sink using the SQLite dialect.
+func (es *EventSink) Type() indexer.EventSinkType {
+	if es.dialect == SQLite {
+		return indexer.SQLITE
+	}
+	return indexer.PSQL
+}
 
 // runInTransaction executes query in a fresh database transaction.
 // If query reports an error, the transaction is rolled back and the
@@ -146,112 +170,329 @@ func (es *EventSink) IndexBlockEvents(h types.EventDataNewBlockHeader) error {
 	ts := time.Now().UTC()
 
 	return runInTransaction(es.store, func(dbtx *sql.Tx) error {
-		// Add the block to the blocks table and report back its row ID for use
-		// in indexing the events for the block.
-		blockID, err := queryWithID(dbtx, `
-INSERT INTO `+tableBlocks+` (height, chain_id, created_at)
-  VALUES ($1, $2, $3)
-  ON CONFLICT DO NOTHING
-  RETURNING rowid;
-`, h.Header.Height, es.chainID, ts)
-		if err == sql.ErrNoRows {
-			return nil // we already saw this block; quietly succeed
-		} else if err != nil {
-			return fmt.Errorf("indexing block header: %w", err)
-		}
+		return indexBlockHeader(dbtx, es.chainID, ts, h)
+	})
+}
 
-		// Insert the special block meta-event for height.
-		if err := insertEvents(dbtx, blockID, 0, []abci.Event{
-			makeIndexedEvent(types.BlockHeightKey, fmt.Sprint(h.Header.Height)),
-		}); err != nil {
-			return fmt.Errorf("block meta-events: %w", err)
-		}
-		// Insert all the block events. Order is important here,
-		if err := insertEvents(dbtx, blockID, 0, h.ResultBeginBlock.Events); err != nil {
-			return fmt.Errorf("begin-block events: %w", err)
-		}
-		if err := insertEvents(dbtx, blockID, 0, h.ResultEndBlock.Events); err != nil {
-			return fmt.Errorf("end-block events: %w", err)
+// IndexTxEvents indexes the specified transaction results, part of the
+// indexer.EventSink interface. The results are written in a single database
+// transaction. The block header must have been indexed first.
+func (es *EventSink) IndexTxEvents(txrs []*abci.TxResult) error {
+	ts := time.Now().UTC()
+
+	return runInTransaction(es.store, func(dbtx *sql.Tx) error {
+		for _, txr := range txrs {
+			if err := indexTxResult(dbtx, es.chainID, ts, txr); err != nil {
+				return err
+			}
 		}
 		return nil
 	})
 }
 
-func (es *EventSink) IndexTxEvents(txrs []*abci.TxResult) error {
+// IndexBlockAndTxEvents indexes the specified block header and the results of
+// the transactions of the block in a single database transaction, so readers
+// never observe the block without its transactions. It implements
+// indexer.AtomicIndexer, which the indexer service uses to index each block.
+func (es *EventSink) IndexBlockAndTxEvents(h types.EventDataNewBlockHeader, txrs []*abci.TxResult) error {
 	ts := time.Now().UTC()
 
-	for _, txr := range txrs {
-		// Encode the result message in protobuf wire format for indexing.
-		resultData, err := proto.Marshal(txr)
-		if err != nil {
-			return fmt.Errorf("marshaling tx_result: %w", err)
+	return runInTransaction(es.store, func(dbtx *sql.Tx) error {
+		if err := indexBlockHeader(dbtx, es.chainID, ts, h); err != nil {
+			return err
 		}
+		for _, txr := range txrs {
+			if err := indexTxResult(dbtx, es.chainID, ts, txr); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
 
-		// Index the hash of the underlying transaction as a hex string.
-		txHash := fmt.Sprintf("%X", types.Tx(txr.Tx).Hash())
+// indexBlockHeader inserts a block header and its events into the database
+// associated with dbtx, unless the block has already been indexed.
+func indexBlockHeader(dbtx *sql.Tx, chainID string, ts time.Time, h types.EventDataNewBlockHeader) error {
+	// Add the block to the blocks table and report back its row ID for use
+	// in indexing the events for the block.
+	blockID, err := queryWithID(dbtx, `
+INSERT INTO `+tableBlocks+` (height, chain_id, created_at)
+  VALUES ($1, $2, $3)
+  ON CONFLICT DO NOTHING
+  RETURNING rowid;
+`, h.Header.Height, chainID, ts)
+	if err == sql.ErrNoRows {
+		return nil // we already saw this block; quietly succeed
+	} else if err != nil {
+		return fmt.Errorf("indexing block header: %w", err)
+	}
 
-		if err := runInTransaction(es.store, func(dbtx *sql.Tx) error {
-			// Find the block associated with this transaction. The block header
-			// must have been indexed prior to the transactions belonging to it.
-			blockID, err := queryWithID(dbtx, `
+	// Insert the special block meta-event for height.
+	if err := insertEvents(dbtx, blockID, 0, []abci.Event{
+		makeIndexedEvent(types.BlockHeightKey, fmt.Sprint(h.Header.Height)),
+	}); err != nil {
+		return fmt.Errorf("block meta-events: %w", err)
+	}
+	// Insert all the block events. Order is important here,
+	if err := insertEvents(dbtx, blockID, 0, h.ResultBeginBlock.Events); err != nil {
+		return fmt.Errorf("begin-block events: %w", err)
+	}
+	if err := insertEvents(dbtx, blockID, 0, h.ResultEndBlock.Events); err != nil {
+		return fmt.Errorf("end-block events: %w", err)
+	}
+	return nil
+}
+
+// indexTxResult inserts a transaction result and its events into the database
+// associated with dbtx.
+func indexTxResult(dbtx *sql.Tx, chainID string, ts time.Time, txr *abci.TxResult) error {
+	// Encode the result message in protobuf wire format for indexing.
+	resultData, err := proto.Marshal(txr)
+	if err != nil {
+		return fmt.Errorf("marshaling tx_result: %w", err)
+	}
+
+	// Index the hash of the underlying transaction as a hex string.
+	txHash := fmt.Sprintf("%X", types.Tx(txr.Tx).Hash())
+
+	// Find the block associated with this transaction. The block header
+	// must have been indexed prior to the transactions belonging to it.
+	blockID, err := queryWithID(dbtx, `
 SELECT rowid FROM `+tableBlocks+` WHERE height = $1 AND chain_id = $2;
-`, txr.Height, es.chainID)
-			if err != nil {
-				return fmt.Errorf("finding block ID: %w", err)
-			}
+`, txr.Height, chainID)
+	if err != nil {
+		return fmt.Errorf("finding block ID: %w", err)
+	}
 
-			// Insert a record for this tx_result and capture its ID for indexing events.
-			txID, err := queryWithID(dbtx, `
-INSERT INTO `+tableTxResults+` (block_id, index, created_at, tx_hash, tx_result)
+	// Insert a record for this tx_result and capture its ID for indexing events.
+	txID, err := queryWithID(dbtx, `
+INSERT INTO `+tableTxResults+` (block_id, "index", created_at, tx_hash, tx_result)
   VALUES ($1, $2, $3, $4, $5)
   ON CONFLICT DO NOTHING
   RETURNING rowid;
 `, blockID, txr.Index, ts, txHash, resultData)
-			if err == sql.ErrNoRows {
-				return nil // we already saw this transaction; quietly succeed
-			} else if err != nil {
-				return fmt.Errorf("indexing tx_result: %w", err)
-			}
-
-			// Insert the special transaction meta-events for hash and height.
-			if err := insertEvents(dbtx, blockID, txID, []abci.Event{
-				makeIndexedEvent(types.TxHashKey, txHash),
-				makeIndexedEvent(types.TxHeightKey, fmt.Sprint(txr.Height)),
-			}); err != nil {
-				return fmt.Errorf("indexing transaction meta-events: %w", err)
-			}
-			// Index any events packaged with the transaction.
-			if err := insertEvents(dbtx, blockID, txID, txr.Result.Events); err != nil {
-				return fmt.Errorf("indexing transaction events: %w", err)
-			}
-			return nil
+	if err == sql.ErrNoRows {
+		return nil // we already saw this transaction; quietly succeed
+	} else if err != nil {
+		return fmt.Errorf("indexing tx_result: %w", err)
+	}
 
-		}); err != nil {
-			return err
-		}
+	// Insert the special transaction meta-events for hash and height.
+	if err := insertEvents(dbtx, blockID, txID, []abci.Event{
+		makeIndexedEvent(types.TxHashKey, txHash),
+		makeIndexedEvent(types.TxHeightKey, fmt.Sprint(txr.Height)),
+	}); err != nil {
+		return fmt.Errorf("indexing transaction meta-events: %w", err)
+	}
+	// Index any events packaged with the transaction.
+	if err := insertEvents(dbtx, blockID, txID, txr.Result.Events); err != nil {
+		return fmt.Errorf("indexing transaction events: %w", err)
 	}
 	return nil
 }
 
-// SearchBlockEvents is not implemented by this sink, and reports an error for all queries.
+// SearchBlockEvents returns the heights of the blocks whose events match the
+// query, in ascending order. It is part of the indexer.EventSink interface.
 func (es *EventSink) SearchBlockEvents(ctx context.Context, q *query.Query) ([]int64, error) {
-	return nil, errors.New("block search is not supported via the postgres event sink")
+	b := &queryBuilder{dialect: es.dialect}
+	pred, err := b.blockPredicate(q.Expr())
+	if err != nil {
+		return nil, err
+	}
+	return es.searchBlocks(ctx, b, pred+`
+  ORDER BY height`)
 }
 
-// SearchTxEvents is not implemented by this sink, and reports an error for all queries.
+// SearchBlockEventsPage returns the heights of the page of the blocks whose
+// events match the query, and whether more blocks follow the page. It
+// implements indexer.BlockPageSearcher.
+func (es *EventSink) SearchBlockEventsPage(
+	ctx context.Context,
+	q *query.Query,
+	page indexer.Page,
+) ([]int64, bool, error) {
+	b := &queryBuilder{dialect: es.dialect}
+	pred, err := b.blockPredicate(q.Expr())
+	if err != nil {
+		return nil, false, err
+	}
+	results, err := es.searchBlocks(ctx, b, pred+b.pageClause(page, false))
+	if err != nil || len(results) <= page.Limit {
+		return results, false, err
+	}
+	return results[:page.Limit], true, nil
+}
+
+// searchBlocks returns the heights of the blocks of the chain matching the
+// predicate, which may be followed by an ordering and a limit.
+func (es *EventSink) searchBlocks(ctx context.Context, b *queryBuilder, pred string) ([]int64, error) {
+	rows, err := es.store.QueryContext(ctx, `
+SELECT height FROM `+tableBlocks+`
+  WHERE chain_id = `+b.arg(es.chainID)+`
+  AND `+pred+`;
+`, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("searching blocks: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]int64, 0)
+	for rows.Next() {
+		var height int64
+		if err := rows.Scan(&height); err != nil {
+			return nil, err
+		}
+		results = append(results, height)
+	}
+	return results, rows.Err()
+}
+
+// SearchTxEvents returns the results of the transactions whose events match
+// the query, ordered by height and index. It is part of the
+// indexer.EventSink interface.
 func (es *EventSink) SearchTxEvents(ctx context.Context, q *query.Query) ([]*abci.TxResult, error) {
-	return nil, errors.New("tx search is not supported via the postgres event sink")
+	b := &queryBuilder{dialect: es.dialect}
+	pred, err := b.txPredicate(q.Expr())
+	if err != nil {
+		return nil, err
+	}
+	return es.searchTxs(ctx, b, pred+`
+  ORDER BY height, "index"`)
+}
+
+// SearchTxEventsPage returns the results of the page of the transactions whose
+// events match the query, and whether more transactions follow the page. It
+// implements indexer.TxPageSearcher.
+func (es *EventSink) SearchTxEventsPage(
+	ctx context.Context,
+	q *query.Query,
+	page indexer.Page,
+) ([]*abci.TxResult, bool, error) {
+	b := &queryBuilder{dialect: es.dialect}
+	pred, err := b.txPredicate(q.Expr())
+	if err != nil {
+		return nil, false, err
+	}
+	results, err := es.searchTxs(ctx, b, pred+b.pageClause(page, true))
+	if err != nil || len(results) <= page.Limit {
+		return results, false, err
+	}
+	return results[:page.Limit], true, nil
+}
+
+// searchTxs returns the results of the transactions of the chain matching the
+// predicate, which may be followed by an ordering and a limit.
+func (es *EventSink) searchTxs(ctx context.Context, b *queryBuilder, pred string) ([]*abci.TxResult, error) {
+	rows, err := es.store.QueryContext(ctx, `
+SELECT tx_result FROM `+tableTxResults+`
+  JOIN `+tableBlocks+` ON (`+tableTxResults+`.block_id = `+tableBlocks+`.rowid)
+  WHERE chain_id = `+b.arg(es.chainID)+`
+  AND `+pred+`;
+`, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("searching transactions: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]*abci.TxResult, 0)
+	for rows.Next() {
+		var resultData []byte
+		if err := rows.Scan(&resultData); err != nil {
+			return nil, err
+		}
+		txr := new(abci.TxResult)
+		if err := proto.Unmarshal(resultData, txr); err != nil {
+			return nil, fmt.Errorf("unmarshaling tx_result: %w", err)
+		}
+		results = append(results, txr)
+	}
+	return results, rows.Err()
 }
 
-// GetTxByHash is not implemented by this sink, and reports an error for all queries.
+// GetTxByHash returns the result of the transaction with the given hash, or
+// nil if it has not been indexed. It is part of the indexer.EventSink
+// interface.
 func (es *EventSink) GetTxByHash(hash []byte) (*abci.TxResult, error) {
-	return nil, errors.New("getTxByHash is not supported via the postgres event sink")
+	if len(hash) == 0 {
+		return nil, indexer.ErrorEmptyHash
+	}
+
+	var resultData []byte
+	err := es.store.QueryRow(`
+SELECT tx_result FROM `+tableTxResults+`
+  JOIN `+tableBlocks+` ON (`+tableTxResults+`.block_id = `+tableBlocks+`.rowid)
+  WHERE tx_hash = $1 AND chain_id = $2
+  ORDER BY height DESC LIMIT 1;
+`, fmt.Sprintf("%X", hash), es.chainID).Scan(&resultData)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("looking up transaction: %w", err)
+	}
+
+	txr := new(abci.TxResult)
+	if err := proto.Unmarshal(resultData, txr); err != nil {
+		return nil, fmt.Errorf("unmarshaling tx_result: %w", err)
+	}
+	return txr, nil
 }
 
-// HasBlock is not implemented by this sink, and reports an error for all queries.
+// HasBlock reports whether the block at the given height has been indexed. It
+// is part of the indexer.EventSink interface.
 func (es *EventSink) HasBlock(h int64) (bool, error) {
-	return false, errors.New("hasBlock is not supported via the postgres event sink")
+	var exists bool
+	if err := es.store.QueryRow(`
+SELECT EXISTS (SELECT 1 FROM `+tableBlocks+` WHERE height = $1 AND chain_id = $2);
+`, h, es.chainID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("looking up block: %w", err)
+	}
+	return exists, nil
+}
+
+// Prune removes all blocks and transactions below the given height from the
+// sink, along with their events. It is part of the indexer.EventSink
+// interface.
+func (es *EventSink) Prune(retainHeight int64) error {
+	return es.deleteBlocks("height < $1", retainHeight)
+}
+
+// DeleteFrom removes all blocks and transactions at and above the given height
+// from the sink, along with their events. It is used when rolling back the
+// state, since the blocks will otherwise not be indexed again once they have
+// been executed again.
+func (es *EventSink) DeleteFrom(height int64) error {
+	return es.deleteBlocks("height >= $1", height)
+}
+
+// deleteBlocks removes the blocks whose height satisfies the given condition,
+// where $1 is bound to height, along with their transactions and events.
+func (es *EventSink) deleteBlocks(cond string, height int64) error {
+	return runInTransaction(es.store, func(dbtx *sql.Tx) error {
+		// Delete rows in the reverse order of their references.
+		for _, query := range []string{`
+DELETE FROM ` + tableAttributes + ` WHERE event_id IN (
+  SELECT ` + tableEvents + `.rowid FROM ` + tableEvents + `
+    JOIN ` + tableBlocks + ` ON (` + tableEvents + `.block_id = ` + tableBlocks + `.rowid)
+  WHERE ` + cond + ` AND chain_id = $2
+);
+`, `
+DELETE FROM ` + tableEvents + ` WHERE block_id IN (
+  SELECT rowid FROM ` + tableBlocks + ` WHERE ` + cond + ` AND chain_id = $2
+);
+`, `
+DELETE FROM ` + tableTxResults + ` WHERE block_id IN (
+  SELECT rowid FROM ` + tableBlocks + ` WHERE ` + cond + ` AND chain_id = $2
+);
+`, `
+DELETE FROM ` + tableBlocks + ` WHERE ` + cond + ` AND chain_id = $2;
+`} {
+			if _, err := dbtx.Exec(query, height, es.chainID); err != nil {
+				return fmt.Errorf("deleting events: %w", err)
+			}
+		}
+		return nil
+	})
 }
 
-// Stop closes the underlying PostgreSQL database.
+// Stop closes the underlying database.
 func (es *EventSink) Stop() error { return es.store.Close() }
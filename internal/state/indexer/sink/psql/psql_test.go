@@ -19,6 +19,8 @@ import (
 	"github.com/stretchr/testify/require"
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/sinktest"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
 	"github.com/tendermint/tendermint/types"
 
 	// Register the Postgres database driver.
@@ -27,6 +29,10 @@ import (
 
 // Verify that the type satisfies the EventSink interface.
 var _ indexer.EventSink = (*EventSink)(nil)
+var _ indexer.EventSinkDeleter = (*EventSink)(nil)
+var _ indexer.AtomicIndexer = (*EventSink)(nil)
+var _ indexer.TxPageSearcher = (*EventSink)(nil)
+var _ indexer.BlockPageSearcher = (*EventSink)(nil)
 
 var (
 	doPauseAtExit = flag.Bool("pause-at-exit", false,
@@ -150,13 +156,17 @@ func TestIndexing(t *testing.T) {
 		verifyBlock(t, 1)
 		verifyBlock(t, 2)
 
-		verifyNotImplemented(t, "hasBlock", func() (bool, error) { return indexer.HasBlock(1) })
-		verifyNotImplemented(t, "hasBlock", func() (bool, error) { return indexer.HasBlock(2) })
+		ok, err := indexer.HasBlock(1)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = indexer.HasBlock(2)
+		require.NoError(t, err)
+		assert.False(t, ok)
 
-		verifyNotImplemented(t, "block search", func() (bool, error) {
-			v, err := indexer.SearchBlockEvents(context.Background(), nil)
-			return v != nil, err
-		})
+		heights, err := indexer.SearchBlockEvents(context.Background(),
+			query.MustParse("begin_event.proposer = 'FCAA001'"))
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1}, heights)
 
 		require.NoError(t, verifyTimeStamp(tableBlocks))
 
@@ -183,14 +193,14 @@ func TestIndexing(t *testing.T) {
 		require.NoError(t, verifyTimeStamp(tableTxResults))
 		require.NoError(t, verifyTimeStamp(viewTxEvents))
 
-		verifyNotImplemented(t, "getTxByHash", func() (bool, error) {
-			txr, err := indexer.GetTxByHash(types.Tx(txResult.Tx).Hash())
-			return txr != nil, err
-		})
-		verifyNotImplemented(t, "tx search", func() (bool, error) {
-			txr, err := indexer.SearchTxEvents(context.Background(), nil)
-			return txr != nil, err
-		})
+		txr, err = indexer.GetTxByHash(types.Tx(txResult.Tx).Hash())
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(txResult, txr))
+
+		txrs, err := indexer.SearchTxEvents(context.Background(), query.MustParse("account.owner = 'Ivan'"))
+		require.NoError(t, err)
+		require.Len(t, txrs, 1)
+		assert.True(t, proto.Equal(txResult, txrs[0]))
 
 		// try to insert the duplicate tx events.
 		err = indexer.IndexTxEvents([]*abci.TxResult{txResult})
@@ -198,6 +208,17 @@ func TestIndexing(t *testing.T) {
 	})
 }
 
+// Each search test uses its own chain ID, so that the data indexed by other
+// tests does not match.
+
+func TestBlockSearch(t *testing.T) {
+	sinktest.BlockSearch(t, &EventSink{store: testDB(), chainID: "test-block-search"})
+}
+
+func TestTxSearch(t *testing.T) {
+	sinktest.TxSearch(t, &EventSink{store: testDB(), chainID: "test-tx-search"})
+}
+
 func TestStop(t *testing.T) {
 	indexer := &EventSink{store: testDB()}
 	require.NoError(t, indexer.Stop())
@@ -321,20 +342,6 @@ SELECT type, height, chain_id FROM `+viewBlockEvents+`
 	}
 }
 
-// verifyNotImplemented calls f and verifies that it returns both a
-// false-valued flag and a non-nil error whose string matching the expected
-// "not supported" message with label prefixed.
-func verifyNotImplemented(t *testing.T, label string, f func() (bool, error)) {
-	t.Helper()
-	t.Logf("Verifying that %q reports it is not implemented", label)
-
-	want := label + " is not supported via the postgres event sink"
-	ok, err := f()
-	assert.False(t, ok)
-	require.NotNil(t, err)
-	assert.Equal(t, want, err.Error())
-}
-
 // waitForInterrupt blocks until a SIGINT is received by the process.
 func waitForInterrupt() {
 	ch := make(chan os.Signal, 1)
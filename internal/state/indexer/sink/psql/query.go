@@ -0,0 +1,266 @@
+package psql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+	"github.com/tendermint/tendermint/types"
+)
+
+// Patterns for attribute values which can be cast to numbers or timestamps.
+// Values are cast only if they match, since a failed cast aborts the query.
+const (
+	numericPattern   = `^-?[0-9]+(\.[0-9]+)?$`
+	timestampPattern = `^[0-9]{4}-[0-9]{2}-[0-9]{2}` +
+		`(T[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?(Z|[+-][0-9]{2}:[0-9]{2}))?$`
+)
+
+// Dialect identifies the SQL dialect of the database backing an EventSink.
+// The dialects differ only in how search queries are translated.
+type Dialect int
+
+const (
+	// Postgres is the dialect of PostgreSQL databases.
+	Postgres Dialect = iota
+	// SQLite is the dialect of SQLite databases. The database connection must
+	// provide a regexp function, which SQLite uses for the REGEXP operator.
+	SQLite
+)
+
+// sqlOperators maps comparison operators to their SQL equivalents.
+var sqlOperators = map[query.Operator]string{
+	query.OpLessEqual:    "<=",
+	query.OpGreaterEqual: ">=",
+	query.OpLess:         "<",
+	query.OpGreater:      ">",
+	query.OpEqual:        "=",
+}
+
+// queryBuilder translates query conditions into SQL predicates, collecting
+// their arguments in order.
+type queryBuilder struct {
+	dialect Dialect
+	args    []interface{}
+}
+
+// arg adds an argument and returns its placeholder. SQLite numbers "$"
+// placeholders in the order they appear in the query rather than by their
+// name, so it uses explicitly numbered "?" placeholders instead.
+func (b *queryBuilder) arg(v interface{}) string {
+	b.args = append(b.args, v)
+	if b.dialect == SQLite {
+		return fmt.Sprintf("?%d", len(b.args))
+	}
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// txPredicate returns a predicate which a row of the tx_results table joined
+// with the blocks table must match to satisfy the expression. The conditions
+// on the transaction's hash and height use the columns of those tables, all
+// other conditions match the transaction's event attributes.
+func (b *queryBuilder) txPredicate(e *query.Expr) (string, error) {
+	return b.predicate(e, func(c query.Condition) (string, error) {
+		if hash, ok := c.Operand.(string); ok && c.CompositeKey == types.TxHashKey && c.Op == query.OpEqual {
+			return tableTxResults + ".tx_hash = " + b.arg(strings.ToUpper(hash)), nil
+		}
+		if pred, ok := b.heightPredicate(c, types.TxHeightKey); ok {
+			return pred, nil
+		}
+
+		pred, err := b.attributePredicate(c)
+		if err != nil {
+			return "", err
+		}
+		return tableTxResults + `.rowid IN (
+    SELECT tx_id FROM event_attributes WHERE tx_id IS NOT NULL AND ` + pred + `)`, nil
+	})
+}
+
+// blockPredicate returns a predicate which a row of the blocks table must
+// match to satisfy the expression. Conditions on the block height use its
+// column, all other conditions match the block's events, excluding
+// transaction events.
+func (b *queryBuilder) blockPredicate(e *query.Expr) (string, error) {
+	return b.predicate(e, func(c query.Condition) (string, error) {
+		if pred, ok := b.heightPredicate(c, types.BlockHeightKey); ok {
+			return pred, nil
+		}
+
+		pred, err := b.attributePredicate(c)
+		if err != nil {
+			return "", err
+		}
+		return tableBlocks + `.rowid IN (
+    SELECT block_id FROM event_attributes WHERE tx_id IS NULL AND ` + pred + `)`, nil
+	})
+}
+
+// pageClause returns the condition and the ordering selecting the page of
+// the results of a search, and the limit of one more result than the page, so
+// the caller can tell whether more results follow it. The results are ordered
+// by height, and by index within a block if index is set.
+func (b *queryBuilder) pageClause(page indexer.Page, index bool) string {
+	cmp, order := ">", ""
+	if page.Desc {
+		cmp, order = "<", " DESC"
+	}
+
+	var clause string
+	if page.After != nil {
+		height := b.arg(page.After.Height)
+		if index {
+			clause = fmt.Sprintf(`
+  AND (height %[1]s %[2]s OR (height = %[2]s AND "index" %[1]s %[3]s))`,
+				cmp, height, b.arg(page.After.Index))
+		} else {
+			clause = "\n  AND height " + cmp + " " + height
+		}
+	}
+	clause += "\n  ORDER BY height" + order
+	if index {
+		clause += `, "index"` + order
+	}
+	return clause + "\n  LIMIT " + b.arg(page.Limit+1)
+}
+
+// predicate translates the expression into a predicate, using condPred to
+// translate its conditions. A condition with the IN operator is translated as
+// the disjunction of the equalities with each of its operands.
+func (b *queryBuilder) predicate(e *query.Expr, condPred func(query.Condition) (string, error)) (string, error) {
+	var (
+		args []*query.Expr
+		sep  string
+	)
+	switch e.Type {
+	case query.ExprAnd:
+		args, sep = e.Args, " AND "
+
+	case query.ExprOr:
+		args, sep = e.Args, " OR "
+
+	case query.ExprNot:
+		pred, err := b.predicate(e.Args[0], condPred)
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + pred, nil
+
+	default:
+		c := e.Condition
+		if c.Op != query.OpIn {
+			pred, err := condPred(c)
+			if err != nil {
+				return "", err
+			}
+			return "(" + pred + ")", nil
+		}
+		for _, operand := range c.Operand.([]interface{}) {
+			args = append(args, &query.Expr{
+				Type:      query.ExprCondition,
+				Condition: query.Condition{CompositeKey: c.CompositeKey, Op: query.OpEqual, Operand: operand},
+			})
+		}
+		sep = " OR "
+	}
+
+	preds := make([]string, 0, len(args))
+	for _, arg := range args {
+		pred, err := b.predicate(arg, condPred)
+		if err != nil {
+			return "", err
+		}
+		preds = append(preds, pred)
+	}
+	return "(" + strings.Join(preds, sep) + ")", nil
+}
+
+// heightPredicate returns a predicate on the height column of the blocks
+// table, if the condition compares the given height key with an integer.
+func (b *queryBuilder) heightPredicate(c query.Condition, heightKey string) (string, bool) {
+	height, ok := c.Operand.(int64)
+	if !ok || c.CompositeKey != heightKey {
+		return "", false
+	}
+	op, ok := sqlOperators[c.Op]
+	if !ok {
+		return "", false
+	}
+	return tableBlocks + ".height " + op + " " + b.arg(height), true
+}
+
+// attributePredicate returns a predicate which a row of the event_attributes
+// view must match to satisfy the condition.
+func (b *queryBuilder) attributePredicate(c query.Condition) (string, error) {
+	pred := "composite_key = " + b.arg(c.CompositeKey)
+
+	switch c.Op {
+	case query.OpExists:
+		return pred, nil
+
+	case query.OpContains:
+		s, ok := c.Operand.(string)
+		if !ok {
+			return "", fmt.Errorf("operator CONTAINS requires a string operand, got %v", c.Operand)
+		}
+		if b.dialect == SQLite {
+			return pred + " AND instr(value, " + b.arg(s) + ") > 0", nil
+		}
+		return pred + " AND strpos(value, " + b.arg(s) + ") > 0", nil
+
+	case query.OpStartsWith:
+		s, ok := c.Operand.(string)
+		if !ok {
+			return "", fmt.Errorf("operator STARTS WITH requires a string operand, got %v", c.Operand)
+		}
+		prefix := b.arg(s)
+		if b.dialect == SQLite {
+			return pred + " AND substr(value, 1, length(" + prefix + ")) = " + prefix, nil
+		}
+		return pred + " AND left(value, char_length(" + prefix + ")) = " + prefix, nil
+
+	case query.OpMatches:
+		pattern, ok := c.Operand.(*query.Pattern)
+		if !ok {
+			return "", fmt.Errorf("operator MATCHES requires a pattern operand, got %v", c.Operand)
+		}
+		if b.dialect == SQLite {
+			return pred + " AND value REGEXP " + b.arg(pattern.Regexp()), nil
+		}
+		return pred + " AND value ~ " + b.arg(pattern.Regexp()), nil
+	}
+
+	op, ok := sqlOperators[c.Op]
+	if !ok {
+		return "", fmt.Errorf("unsupported operator %v", c.Op)
+	}
+	switch operand := c.Operand.(type) {
+	case string:
+		if c.Op != query.OpEqual {
+			return "", fmt.Errorf("condition on %s: strings can only be compared for equality", c.CompositeKey)
+		}
+		return pred + " AND value = " + b.arg(operand), nil
+
+	case int64, float64:
+		if b.dialect == SQLite {
+			return fmt.Sprintf("%s AND (CASE WHEN value REGEXP '%s' THEN CAST(value AS NUMERIC) END) %s %s",
+				pred, numericPattern, op, b.arg(operand)), nil
+		}
+		return fmt.Sprintf("%s AND (CASE WHEN value ~ '%s' THEN value::numeric END) %s %s",
+			pred, numericPattern, op, b.arg(operand)), nil
+
+	case time.Time:
+		if b.dialect == SQLite {
+			// SQLite has no timestamp type, so compare Julian day numbers.
+			return fmt.Sprintf("%s AND (CASE WHEN value REGEXP '%s' THEN julianday(value) END) %s julianday(%s)",
+				pred, timestampPattern, op, b.arg(operand.UTC().Format(time.RFC3339Nano))), nil
+		}
+		return fmt.Sprintf("%s AND (CASE WHEN value ~ '%s' THEN value::timestamptz END) %s %s",
+			pred, timestampPattern, op, b.arg(operand)), nil
+
+	default:
+		return "", fmt.Errorf("unsupported operand %v of type %T", operand, operand)
+	}
+}
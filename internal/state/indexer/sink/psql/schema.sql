@@ -2,6 +2,9 @@
   This file defines the database schema for the PostgresQL ("psql") event sink
   implementation in Tendermint. The operator must create a database and install
   this schema before using the database to index events.
+
+  The "sqlite" event sink uses the same schema. Names that are keywords in
+  SQLite, such as "index", are quoted.
  */
 
 -- The blocks table records metadata about each block.
@@ -30,7 +33,7 @@ CREATE TABLE tx_results (
   -- The block to which this transaction belongs.
   block_id BIGINT NOT NULL REFERENCES blocks(rowid),
   -- The sequential index of the transaction within the block.
-  index INTEGER NOT NULL,
+  "index" INTEGER NOT NULL,
   -- When this result record was logged into the sink, in UTC.
   created_at TIMESTAMPTZ NOT NULL,
   -- The hex-encoded hash of the transaction.
@@ -38,7 +41,7 @@ CREATE TABLE tx_results (
   -- The protobuf wire encoding of the TxResult message.
   tx_result BYTEA NOT NULL,
 
-  UNIQUE (block_id, index)
+  UNIQUE (block_id, "index")
 );
 
 -- The events table records events. All events (both block and transaction) are
@@ -55,6 +58,10 @@ CREATE TABLE events (
   type VARCHAR NOT NULL
 );
 
+-- Index events by block, so that the events of pruned blocks can be deleted
+-- without scanning the whole table.
+CREATE INDEX idx_events_block_id ON events(block_id);
+
 -- The attributes table records event attributes.
 CREATE TABLE attributes (
    event_id      BIGINT NOT NULL REFERENCES events(rowid),
@@ -79,7 +86,7 @@ CREATE VIEW block_events AS
 
 -- A joined view of all transaction events.
 CREATE VIEW tx_events AS
-  SELECT height, index, chain_id, type, key, composite_key, value, tx_results.created_at
+  SELECT height, "index", chain_id, type, key, composite_key, value, tx_results.created_at
   FROM blocks JOIN tx_results ON (blocks.rowid = tx_results.block_id)
   JOIN event_attributes ON (tx_results.rowid = event_attributes.tx_id)
   WHERE event_attributes.tx_id IS NOT NULL;
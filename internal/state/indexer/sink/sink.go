@@ -6,9 +6,11 @@ import (
 
 	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/file"
 	"github.com/tendermint/tendermint/internal/state/indexer/sink/kv"
 	"github.com/tendermint/tendermint/internal/state/indexer/sink/null"
 	"github.com/tendermint/tendermint/internal/state/indexer/sink/psql"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/sqlite"
 )
 
 // EventSinksFromConfig constructs a slice of indexer.EventSink using the provided
@@ -43,7 +45,8 @@ func EventSinksFromConfig(cfg *config.Config, dbProvider config.DBProvider, chai
 				return nil, err
 			}
 
-			eventSinks = append(eventSinks, kv.NewEventSink(store))
+			filter := indexer.NewAttributeFilter(cfg.TxIndex.IncludeKeys, cfg.TxIndex.ExcludeKeys)
+			eventSinks = append(eventSinks, kv.NewEventSink(store, filter))
 
 		case indexer.PSQL:
 			conn := cfg.TxIndex.PsqlConn
@@ -56,6 +59,29 @@ func EventSinksFromConfig(cfg *config.Config, dbProvider config.DBProvider, chai
 				return nil, err
 			}
 			eventSinks = append(eventSinks, es)
+
+		case indexer.SQLITE:
+			if cfg.TxIndex.SqlitePath == "" {
+				return nil, errors.New("the sqlite database path cannot be empty")
+			}
+
+			es, err := sqlite.NewEventSink(cfg.TxIndex.SqliteFile(), chainID)
+			if err != nil {
+				return nil, err
+			}
+			eventSinks = append(eventSinks, es)
+
+		case indexer.FILE:
+			if cfg.TxIndex.FilePath == "" {
+				return nil, errors.New("the file sink directory cannot be empty")
+			}
+
+			es, err := file.NewEventSink(cfg.TxIndex.FileDir())
+			if err != nil {
+				return nil, err
+			}
+			eventSinks = append(eventSinks, es)
+
 		default:
 			return nil, errors.New("unsupported event sink type")
 		}
@@ -0,0 +1,264 @@
+// Package sinktest provides the search tests shared by the event sinks, so
+// that all sinks return the same results for the same queries.
+package sinktest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+	"github.com/tendermint/tendermint/types"
+)
+
+// pageLimits are the page limits with which the searches are repeated.
+var pageLimits = []int{1, 2, 5}
+
+// BlockSearch indexes blocks 1 to 11 with the sink, which must be empty, and
+// requires the searches of the blocks to return the expected heights, all at
+// once and by page in either order.
+func BlockSearch(t *testing.T, sink indexer.EventSink) {
+	for i := int64(1); i < 12; i++ {
+		foo := fmt.Sprint(i)
+		if i == 1 {
+			foo = "100"
+		}
+		require.NoError(t, sink.IndexBlockEvents(types.EventDataNewBlockHeader{
+			Header: types.Header{Height: i},
+			ResultBeginBlock: abci.ResponseBeginBlock{
+				Events: []abci.Event{makeIndexedEvent("begin_event.proposer", "FCAA001")},
+			},
+			ResultEndBlock: abci.ResponseEndBlock{
+				Events: []abci.Event{{
+					Type: "end_event",
+					Attributes: []abci.EventAttribute{
+						// only blocks 1 and those at even heights index the attribute
+						{Key: "foo", Value: foo, Index: i == 1 || i%2 == 0},
+					},
+				}},
+			},
+		}))
+	}
+
+	testCases := map[string][]int64{
+		"block.height = 100":                                        {},
+		"block.height = 5":                                          {5},
+		"begin_event.key1 = 'value1'":                               {},
+		"begin_event.proposer = 'FCAA001'":                          {1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+		"end_event.foo <= 5":                                        {2, 4},
+		"end_event.foo >= 100":                                      {1},
+		"block.height > 2 AND end_event.foo <= 8":                   {4, 6, 8},
+		"begin_event.proposer CONTAINS 'FFFFFFF'":                   {},
+		"begin_event.proposer CONTAINS 'FCAA001'":                   {1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+		"end_event.foo EXISTS":                                      {1, 2, 4, 6, 8, 10},
+		"block.height IN (3, 5, 100)":                               {3, 5},
+		"block.height < 3 OR block.height > 10":                     {1, 2, 11},
+		"end_event.foo EXISTS AND NOT end_event.foo IN (2, 4, 100)": {6, 8, 10},
+		"NOT (block.height > 2 OR end_event.foo EXISTS)":            {},
+		"begin_event.proposer STARTS WITH 'FCAA'":                   {1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+		"begin_event.proposer STARTS WITH 'CAA'":                    {},
+		"begin_event.proposer MATCHES 'F*0?1'":                      {1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+		"begin_event.proposer MATCHES 'FCAA00'":                     {},
+	}
+	ctx := context.Background()
+	for q, expect := range testCases {
+		q, expect := q, expect
+		t.Run(q, func(t *testing.T) {
+			results, err := sink.SearchBlockEvents(ctx, query.MustParse(q))
+			require.NoError(t, err)
+			require.Equal(t, expect, results)
+
+			for _, desc := range []bool{false, true} {
+				// all the results, in the order of the pages
+				want, _ := indexer.PageHeights(append([]int64(nil), expect...),
+					indexer.Page{Desc: desc, Limit: len(expect) + 1})
+				for _, limit := range pageLimits {
+					page := indexer.Page{Desc: desc, Limit: limit}
+					var got []int64
+					for {
+						heights, more, err := indexer.SearchBlockEventsPage(ctx, sink, query.MustParse(q), page)
+						require.NoError(t, err)
+						require.LessOrEqual(t, len(heights), limit)
+						got = append(got, heights...)
+						if !more {
+							break
+						}
+						require.NotEmpty(t, heights)
+						page.After = &indexer.Cursor{Height: heights[len(heights)-1]}
+					}
+					require.Equal(t, want, got, "desc=%v limit=%d", desc, limit)
+				}
+			}
+		})
+	}
+}
+
+// TxSearch indexes transactions at heights 1 and 2 with the sink, which must
+// be empty, and requires the searches of the transactions to return the
+// expected results, all at once and by page in either order.
+func TxSearch(t *testing.T, sink indexer.EventSink) {
+	for h := int64(1); h <= 2; h++ {
+		require.NoError(t, sink.IndexBlockEvents(types.EventDataNewBlockHeader{Header: types.Header{Height: h}}))
+	}
+
+	// indexed first, but bigger height (to test the order of transactions)
+	txResult1 := txResultWithEvents(2, 1, "Bob's account", []abci.Event{
+		makeIndexedEvent("account.number", "1"),
+		makeIndexedEvent("account.owner", "Ivan"),
+		makeIndexedEvent("account.date", "2013-05-03T14:45:00Z"),
+	})
+	// indexed second, but smaller height
+	txResult2 := txResultWithEvents(1, 2, "Alice's account", []abci.Event{
+		makeIndexedEvent("account.number", "2"),
+		makeIndexedEvent("account.number", "3"),
+	})
+	// indexed third, with a similar event which must not match
+	// https://github.com/tendermint/tendermint/issues/2908
+	txResult3 := txResultWithEvents(2, 0, "Mike's account", []abci.Event{
+		makeIndexedEvent("account.number.id", "1"),
+		{Type: "", Attributes: []abci.EventAttribute{{Key: "not_allowed", Value: "boom", Index: true}}},
+	})
+	require.NoError(t, sink.IndexTxEvents([]*abci.TxResult{txResult1, txResult2, txResult3}))
+
+	hash1 := types.Tx(txResult1.Tx).Hash()
+	testCases := []struct {
+		q       string
+		results []*abci.TxResult
+		// sqlOnly marks the searches by decimal number and time, which the kv
+		// sink does not support.
+		sqlOnly bool
+	}{
+		// search by hash, in either case
+		{fmt.Sprintf("tx.hash = '%X'", hash1), []*abci.TxResult{txResult1}, false},
+		{fmt.Sprintf("tx.hash = '%x'", hash1), []*abci.TxResult{txResult1}, false},
+		// search by height
+		{"tx.height = 2", []*abci.TxResult{txResult3, txResult1}, false},
+		{"tx.height < 2", []*abci.TxResult{txResult2}, false},
+		// search by exact match (one key)
+		{"account.number = 1", []*abci.TxResult{txResult1}, false},
+		{"account.owner = 'Ivan'", []*abci.TxResult{txResult1}, false},
+		// search by range, matching any of the values of a key
+		{"account.number >= 1 AND account.number <= 5", []*abci.TxResult{txResult2, txResult1}, false},
+		{"account.number >= 3", []*abci.TxResult{txResult2}, false},
+		{"account.number <= 1.5", []*abci.TxResult{txResult1}, true},
+		// search by several keys
+		{"account.number = 1 AND account.owner = 'Ivan'", []*abci.TxResult{txResult1}, false},
+		{"account.number = 2 AND account.owner = 'Ivan'", []*abci.TxResult{}, false},
+		{"tx.height = 1 AND account.number >= 1", []*abci.TxResult{txResult2}, false},
+		// search by substring and existence
+		{"account.owner CONTAINS 'va'", []*abci.TxResult{txResult1}, false},
+		{"account.owner EXISTS", []*abci.TxResult{txResult1}, false},
+		// search by time
+		{"account.date >= TIME 2013-05-03T14:45:00Z", []*abci.TxResult{txResult1}, true},
+		{"account.date > TIME 2013-05-03T14:45:00Z", []*abci.TxResult{}, false},
+		{"account.date < DATE 2013-05-03", []*abci.TxResult{}, false},
+		{"account.date < DATE 2013-05-04", []*abci.TxResult{txResult1}, true},
+		// search using not allowed key
+		{"not_allowed = 'boom'", []*abci.TxResult{}, false},
+		// search for not existing tx result
+		{"account.number >= 4 AND account.number <= 5", []*abci.TxResult{}, false},
+		// search by disjunction, negation and lists
+		{"account.owner = 'Ivan' OR account.number = 3", []*abci.TxResult{txResult2, txResult1}, false},
+		{"account.number IN (3, 4)", []*abci.TxResult{txResult2}, false},
+		{"tx.height IN (1) OR account.number.id EXISTS", []*abci.TxResult{txResult2, txResult3}, false},
+		{"NOT account.owner EXISTS", []*abci.TxResult{txResult2, txResult3}, false},
+		{"tx.height = 2 AND NOT (account.number = 1 OR account.owner = 'Igor')", []*abci.TxResult{txResult3}, false},
+		// search by prefix and pattern
+		{"account.owner STARTS WITH 'Iv'", []*abci.TxResult{txResult1}, false},
+		{"account.owner STARTS WITH 'va'", []*abci.TxResult{}, false},
+		{"account.date MATCHES '2013-??-03T*Z'", []*abci.TxResult{txResult1}, false},
+		{"account.number MATCHES '.'", []*abci.TxResult{}, false},
+	}
+
+	ctx := context.Background()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.q, func(t *testing.T) {
+			if tc.sqlOnly && sink.Type() == indexer.KV {
+				t.Skip("the kv sink does not support the search")
+			}
+			results, err := sink.SearchTxEvents(ctx, query.MustParse(tc.q))
+			require.NoError(t, err)
+			if sink.Type() == indexer.KV {
+				// The kv sink returns the results in no particular order.
+				results, _ = indexer.PageTxResults(results, indexer.Page{Limit: len(results) + 1})
+			}
+			requireTxResults(t, tc.results, results)
+
+			for _, desc := range []bool{false, true} {
+				// all the results, in the order of the pages
+				want, _ := indexer.PageTxResults(append([]*abci.TxResult(nil), tc.results...),
+					indexer.Page{Desc: desc, Limit: len(tc.results) + 1})
+				for _, limit := range pageLimits {
+					page := indexer.Page{Desc: desc, Limit: limit}
+					var got []*abci.TxResult
+					for {
+						txrs, more, err := indexer.SearchTxEventsPage(ctx, sink, query.MustParse(tc.q), page)
+						require.NoError(t, err)
+						require.LessOrEqual(t, len(txrs), limit)
+						got = append(got, txrs...)
+						if !more {
+							break
+						}
+						require.NotEmpty(t, txrs)
+						last := txrs[len(txrs)-1]
+						page.After = &indexer.Cursor{Height: last.Height, Index: last.Index}
+					}
+					requireTxResults(t, want, got)
+				}
+			}
+		})
+	}
+
+	// transactions can be looked up by hash
+	txr, err := sink.GetTxByHash(hash1)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(txResult1, txr))
+	txr, err = sink.GetTxByHash(types.Tx("unknown").Hash())
+	require.NoError(t, err)
+	assert.Nil(t, txr)
+}
+
+// requireTxResults requires the results to equal the expected results, in
+// order.
+func requireTxResults(t *testing.T, expect, results []*abci.TxResult) {
+	t.Helper()
+	require.Len(t, results, len(expect))
+	for i, txr := range results {
+		assert.True(t, proto.Equal(expect[i], txr), "result %d: %v", i, txr)
+	}
+}
+
+// txResultWithEvents constructs a transaction result with the specified
+// position, transaction and events.
+func txResultWithEvents(height int64, index uint32, tx string, events []abci.Event) *abci.TxResult {
+	return &abci.TxResult{
+		Height: height,
+		Index:  index,
+		Tx:     types.Tx(tx),
+		Result: abci.ResponseDeliverTx{
+			Data:   []byte{0},
+			Code:   abci.CodeTypeOK,
+			Events: events,
+		},
+	}
+}
+
+// makeIndexedEvent constructs an event with a single indexed attribute, from
+// the composite key "type.key" of the attribute.
+func makeIndexedEvent(compositeKey, value string) abci.Event {
+	i := strings.Index(compositeKey, ".")
+	if i < 0 {
+		return abci.Event{Type: compositeKey}
+	}
+	return abci.Event{Type: compositeKey[:i], Attributes: []abci.EventAttribute{
+		{Key: compositeKey[i+1:], Value: value, Index: true},
+	}}
+}
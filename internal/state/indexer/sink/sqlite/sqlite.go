@@ -0,0 +1,159 @@
+// Package sqlite implements an event sink backed by an embedded SQLite
+// database.
+//
+// The sink uses the schema and the query translation of the psql event sink,
+// and creates the schema when it opens a new database file. The database is
+// opened in write-ahead logging (WAL) mode, so external tools may read it
+// while the node is running, for example with:
+//
+//	sqlite3 -readonly data/tx_index.sqlite
+//
+// Each block is written along with the results of its transactions in a
+// single database transaction, so readers never observe a partially indexed
+// block.
+package sqlite
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"modernc.org/sqlite"
+
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/psql"
+	tmos "github.com/tendermint/tendermint/libs/os"
+)
+
+// driverName is the name of the pure Go SQLite driver, which doesn't require
+// cgo, so the sink is available in all builds.
+const driverName = "sqlite"
+
+// busyTimeout is the time in milliseconds a connection waits for a lock held
+// by another connection, such as an external reader checkpointing the WAL.
+const busyTimeout = 5000
+
+func init() {
+	// The function is registered for all connections of the driver.
+	sqlite.MustRegisterDeterministicScalarFunction("regexp", 2, matchRegexpFunc)
+}
+
+// regexpCacheSize bounds the number of patterns cached by matchRegexp. Search
+// queries use a few fixed patterns, along with the patterns of MATCHES
+// conditions, so once the cache is full further patterns are not cached.
+const regexpCacheSize = 256
+
+var (
+	regexpMtx   sync.Mutex
+	regexpCache = make(map[string]*regexp.Regexp)
+)
+
+// matchRegexpFunc implements the regexp function, which SQLite calls for the
+// expression "value REGEXP pattern". NULL values match no pattern.
+func matchRegexpFunc(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	pattern, ok := textArg(args[0])
+	if !ok {
+		return nil, fmt.Errorf("invalid regexp pattern %v", args[0])
+	}
+	value, ok := textArg(args[1])
+	if !ok {
+		return false, nil
+	}
+	return matchRegexp(pattern, value)
+}
+
+// textArg returns the text of an argument of a function.
+func textArg(arg driver.Value) (string, bool) {
+	switch arg := arg.(type) {
+	case string:
+		return arg, true
+	case []byte:
+		return string(arg), true
+	default:
+		return "", false
+	}
+}
+
+// matchRegexp reports whether the value matches the pattern.
+func matchRegexp(pattern, value string) (bool, error) {
+	regexpMtx.Lock()
+	re, ok := regexpCache[pattern]
+	regexpMtx.Unlock()
+	if ok {
+		return re.MatchString(value), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	regexpMtx.Lock()
+	if len(regexpCache) < regexpCacheSize {
+		regexpCache[pattern] = re
+	}
+	regexpMtx.Unlock()
+	return re.MatchString(value), nil
+}
+
+// NewEventSink constructs an event sink associated with the SQLite database
+// file at path, which is created along with the schema if it doesn't exist.
+// Events written to the sink are attributed to the specified chainID.
+func NewEventSink(path, chainID string) (*psql.EventSink, error) {
+	if err := tmos.EnsureDir(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", busyTimeout))
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Set("_time_format", "sqlite")
+	// Take the write lock when a transaction begins rather than on its first
+	// write, so concurrent writers wait for the busy timeout instead of
+	// failing with a deadlock.
+	params.Set("_txlock", "immediate")
+
+	db, err := sql.Open(driverName, "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if err := createSchema(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating schema of %s: %w", path, err)
+	}
+	return psql.NewEventSinkWithDB(db, chainID, psql.SQLite), nil
+}
+
+// createSchema creates the schema of the psql event sink, unless the database
+// already has it. The schema is translated to SQLite, where only an INTEGER
+// PRIMARY KEY column is assigned automatically. Indexes added to the schema
+// since the database was created are added to it.
+func createSchema(db *sql.DB) error {
+	var exists bool
+	if err := db.QueryRow(`
+SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'blocks');
+`).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		_, err := db.Exec(`
+CREATE INDEX IF NOT EXISTS idx_events_block_id ON events(block_id);
+`)
+		return err
+	}
+
+	schema := strings.ReplaceAll(psql.Schema, "BIGSERIAL PRIMARY KEY", "INTEGER PRIMARY KEY")
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(schema); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
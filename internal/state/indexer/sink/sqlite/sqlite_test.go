@@ -0,0 +1,266 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/psql"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/sinktest"
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+	"github.com/tendermint/tendermint/types"
+)
+
+const chainID = "test-chainID"
+
+// Verify that the type satisfies the EventSink interface.
+var _ indexer.EventSink = (*psql.EventSink)(nil)
+var _ indexer.TxPageSearcher = (*psql.EventSink)(nil)
+var _ indexer.BlockPageSearcher = (*psql.EventSink)(nil)
+
+func newTestSink(t *testing.T) (*psql.EventSink, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tx_index.sqlite")
+	sink, err := NewEventSink(path, chainID)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sink.Stop() })
+	return sink, path
+}
+
+func TestType(t *testing.T) {
+	sink, _ := newTestSink(t)
+	assert.Equal(t, indexer.SQLITE, sink.Type())
+}
+
+func TestIndexing(t *testing.T) {
+	sink, path := newTestSink(t)
+
+	require.NoError(t, sink.IndexBlockEvents(newTestBlockHeader(1)))
+	// Attempting to reindex the same events should gracefully succeed.
+	require.NoError(t, sink.IndexBlockEvents(newTestBlockHeader(1)))
+
+	ok, err := sink.HasBlock(1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = sink.HasBlock(2)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	txResult := txResultWithEvents(1, 0, "HELLO WORLD", []abci.Event{
+		makeIndexedEvent("account.number", "1"),
+		makeIndexedEvent("account.owner", "Ivan"),
+		{Type: "", Attributes: []abci.EventAttribute{{Key: "not_allowed", Value: "Vlad", Index: true}}},
+	})
+	require.NoError(t, sink.IndexTxEvents([]*abci.TxResult{txResult}))
+	// try to insert the duplicate tx events.
+	require.NoError(t, sink.IndexTxEvents([]*abci.TxResult{txResult}))
+
+	txr, err := sink.GetTxByHash(types.Tx(txResult.Tx).Hash())
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(txResult, txr))
+
+	_, err = sink.GetTxByHash(nil)
+	require.ErrorIs(t, err, indexer.ErrorEmptyHash)
+
+	// Reopening the database keeps the indexed data and the schema.
+	require.NoError(t, sink.Stop())
+	sink, err = NewEventSink(path, chainID)
+	require.NoError(t, err)
+	defer sink.Stop()
+
+	txrs, err := sink.SearchTxEvents(context.Background(), query.MustParse("account.owner = 'Ivan'"))
+	require.NoError(t, err)
+	require.Len(t, txrs, 1)
+	assert.True(t, proto.Equal(txResult, txrs[0]))
+}
+
+func TestIndexTxEventsAtomic(t *testing.T) {
+	sink, _ := newTestSink(t)
+	require.NoError(t, sink.IndexBlockEvents(newTestBlockHeader(1)))
+
+	// The second result refers to a block which has not been indexed, so
+	// none of the results of the batch are written.
+	ok := txResultWithEvents(1, 0, "ok", nil)
+	missing := txResultWithEvents(2, 0, "missing", nil)
+	require.Error(t, sink.IndexTxEvents([]*abci.TxResult{ok, missing}))
+
+	txr, err := sink.GetTxByHash(types.Tx(ok.Tx).Hash())
+	require.NoError(t, err)
+	assert.Nil(t, txr)
+}
+
+func TestIndexBlockAndTxEvents(t *testing.T) {
+	sink, _ := newTestSink(t)
+
+	tx := txResultWithEvents(1, 0, "tx", nil)
+	require.NoError(t, indexer.IndexBlock(sink, newTestBlockHeader(1), []*abci.TxResult{tx}))
+	hasBlock, err := sink.HasBlock(1)
+	require.NoError(t, err)
+	assert.True(t, hasBlock)
+	txr, err := sink.GetTxByHash(types.Tx(tx.Tx).Hash())
+	require.NoError(t, err)
+	assert.NotNil(t, txr)
+
+	// The block isn't written without its transactions.
+	missing := txResultWithEvents(3, 0, "missing", nil)
+	require.Error(t, indexer.IndexBlock(sink, newTestBlockHeader(2), []*abci.TxResult{missing}))
+	hasBlock, err = sink.HasBlock(2)
+	require.NoError(t, err)
+	assert.False(t, hasBlock)
+}
+
+func TestBlockSearch(t *testing.T) {
+	sink, _ := newTestSink(t)
+	sinktest.BlockSearch(t, sink)
+}
+
+func TestTxSearch(t *testing.T) {
+	sink, _ := newTestSink(t)
+	sinktest.TxSearch(t, sink)
+}
+
+func TestDeleteFrom(t *testing.T) {
+	sink, _ := newTestSink(t)
+	for h := int64(1); h <= 3; h++ {
+		require.NoError(t, sink.IndexBlockEvents(newTestBlockHeader(h)))
+		require.NoError(t, sink.IndexTxEvents([]*abci.TxResult{
+			txResultWithEvents(h, 0, fmt.Sprintf("tx%d", h), []abci.Event{makeIndexedEvent("account.number", "1")}),
+		}))
+	}
+
+	require.NoError(t, sink.DeleteFrom(2))
+
+	heights, err := sink.SearchBlockEvents(context.Background(), query.MustParse("begin_event.proposer = 'FCAA001'"))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, heights)
+	txrs, err := sink.SearchTxEvents(context.Background(), query.MustParse("account.number = 1"))
+	require.NoError(t, err)
+	require.Len(t, txrs, 1)
+	assert.EqualValues(t, 1, txrs[0].Height)
+
+	// The deleted blocks can be indexed again.
+	require.NoError(t, sink.IndexBlockEvents(newTestBlockHeader(2)))
+	ok, err := sink.HasBlock(2)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestPrune(t *testing.T) {
+	sink, path := newTestSink(t)
+	for h := int64(1); h <= 3; h++ {
+		require.NoError(t, sink.IndexBlockEvents(newTestBlockHeader(h)))
+		require.NoError(t, sink.IndexTxEvents([]*abci.TxResult{
+			txResultWithEvents(h, 0, fmt.Sprintf("tx%d", h), []abci.Event{makeIndexedEvent("account.number", "1")}),
+		}))
+	}
+
+	// The blocks are indexed alike, so each holds a third of the rows.
+	db, err := sql.Open(driverName, "file:"+path+"?mode=ro")
+	require.NoError(t, err)
+	defer db.Close()
+	countRows := func() map[string]int {
+		counts := make(map[string]int)
+		for _, table := range []string{"blocks", "tx_results", "events", "attributes"} {
+			var count int
+			require.NoError(t, db.QueryRow("SELECT count(*) FROM "+table).Scan(&count))
+			counts[table] = count
+		}
+		return counts
+	}
+	before := countRows()
+
+	require.NoError(t, sink.Prune(3))
+
+	heights, err := sink.SearchBlockEvents(context.Background(), query.MustParse("begin_event.proposer = 'FCAA001'"))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3}, heights)
+	txrs, err := sink.SearchTxEvents(context.Background(), query.MustParse("account.number = 1"))
+	require.NoError(t, err)
+	require.Len(t, txrs, 1)
+	assert.EqualValues(t, 3, txrs[0].Height)
+	txr, err := sink.GetTxByHash(types.Tx("tx1").Hash())
+	require.NoError(t, err)
+	assert.Nil(t, txr)
+
+	// No row of the pruned blocks is left behind.
+	for table, count := range countRows() {
+		assert.Equal(t, before[table]/3, count, table)
+	}
+}
+
+func TestReadOnlyWhileWriting(t *testing.T) {
+	sink, path := newTestSink(t)
+	require.NoError(t, sink.IndexBlockEvents(newTestBlockHeader(1)))
+
+	// An external reader opens the database read-only, and keeps a read
+	// transaction open while the sink goes on writing.
+	reader, err := sql.Open(driverName, "file:"+path+"?mode=ro")
+	require.NoError(t, err)
+	defer reader.Close()
+
+	rtx, err := reader.Begin()
+	require.NoError(t, err)
+	var count int
+	require.NoError(t, rtx.QueryRow("SELECT count(*) FROM blocks").Scan(&count))
+	assert.Equal(t, 1, count)
+
+	require.NoError(t, sink.IndexBlockEvents(newTestBlockHeader(2)))
+
+	// The reader's transaction keeps its snapshot, and later reads see the
+	// new block.
+	require.NoError(t, rtx.QueryRow("SELECT count(*) FROM blocks").Scan(&count))
+	assert.Equal(t, 1, count)
+	require.NoError(t, rtx.Rollback())
+	require.NoError(t, reader.QueryRow("SELECT count(*) FROM blocks").Scan(&count))
+	assert.Equal(t, 2, count)
+}
+
+// newTestBlockHeader constructs a block header at the given height, with
+// known begin and end block events.
+func newTestBlockHeader(height int64) types.EventDataNewBlockHeader {
+	return types.EventDataNewBlockHeader{
+		Header: types.Header{Height: height},
+		ResultBeginBlock: abci.ResponseBeginBlock{
+			Events: []abci.Event{makeIndexedEvent("begin_event.proposer", "FCAA001")},
+		},
+		ResultEndBlock: abci.ResponseEndBlock{
+			Events: []abci.Event{makeIndexedEvent("end_event.foo", "100")},
+		},
+	}
+}
+
+// txResultWithEvents constructs a transaction result at the given height and
+// index, that includes the specified events.
+func txResultWithEvents(height int64, index uint32, tx string, events []abci.Event) *abci.TxResult {
+	return &abci.TxResult{
+		Height: height,
+		Index:  index,
+		Tx:     types.Tx(tx),
+		Result: abci.ResponseDeliverTx{
+			Data:   []byte{0},
+			Code:   abci.CodeTypeOK,
+			Events: events,
+		},
+	}
+}
+
+// makeIndexedEvent constructs an event with a single indexed attribute from a
+// composite key of the form "type.name".
+func makeIndexedEvent(compositeKey, value string) abci.Event {
+	i := strings.Index(compositeKey, ".")
+	if i < 0 {
+		return abci.Event{Type: compositeKey}
+	}
+	return abci.Event{Type: compositeKey[:i], Attributes: []abci.EventAttribute{
+		{Key: compositeKey[i+1:], Value: value, Index: true},
+	}}
+}
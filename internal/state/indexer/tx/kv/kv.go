@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 
@@ -19,19 +20,37 @@ import (
 
 var _ indexer.TxIndexer = (*TxIndex)(nil)
 
+// pruneBatchSize is the maximum number of transactions deleted per batch when
+// pruning.
+const pruneBatchSize = 1000
+
 // TxIndex is the simplest possible indexer
 // It is backed by two kv stores:
 // 1. txhash - result  (primary key)
 // 2. event - txhash   (secondary key)
 type TxIndex struct {
-	store dbm.DB
+	store  dbm.DB
+	filter *indexer.AttributeFilter
+}
+
+// Option sets an optional parameter on the TxIndex.
+type Option func(*TxIndex)
+
+// WithAttributeFilter restricts the event attributes indexed by the TxIndex to
+// those selected by the filter.
+func WithAttributeFilter(filter *indexer.AttributeFilter) Option {
+	return func(txi *TxIndex) { txi.filter = filter }
 }
 
 // NewTxIndex creates new KV indexer.
-func NewTxIndex(store dbm.DB) *TxIndex {
-	return &TxIndex{
+func NewTxIndex(store dbm.DB, options ...Option) *TxIndex {
+	txi := &TxIndex{
 		store: store,
 	}
+	for _, option := range options {
+		option(txi)
+	}
+	return txi
 }
 
 // Get gets transaction from the TxIndex storage and returns it or nil if the
@@ -107,13 +126,13 @@ func (txi *TxIndex) indexEvents(result *abci.TxResult, hash []byte, store dbm.Ba
 				continue
 			}
 
-			// index if `index: true` is set
+			// index if `index: true` is set and the attribute is not filtered out
 			compositeTag := fmt.Sprintf("%s.%s", event.Type, attr.Key)
 			// ensure event does not conflict with a reserved prefix key
 			if compositeTag == types.TxHashKey || compositeTag == types.TxHeightKey {
 				return fmt.Errorf("event type and attribute key \"%s\" is reserved; please use a different key", compositeTag)
 			}
-			if attr.GetIndex() {
+			if attr.GetIndex() && txi.filter.Match(compositeTag) {
 				err := store.Set(keyFromEvent(compositeTag, attr.Value, result), hash)
 				if err != nil {
 					return err
@@ -125,6 +144,184 @@ func (txi *TxIndex) indexEvents(result *abci.TxResult, hash []byte, store dbm.Ba
 	return nil
 }
 
+// Prune removes all transactions below the given height from the index, along
+// with their indexed events. Since the height index is ordered by the decimal
+// representation of heights, the whole index is scanned the first time the
+// index is pruned. The height below which it was pruned is then recorded, and
+// the transactions at each of the following heights are looked up by prefix.
+func (txi *TxIndex) Prune(retainHeight int64) error {
+	bz, err := txi.store.Get(prunedHeightKey())
+	if err != nil {
+		return err
+	}
+	if bz == nil {
+		if err := txi.deleteHeights(0, retainHeight); err != nil {
+			return err
+		}
+		return txi.store.SetSync(prunedHeightKey(), int64ToBytes(retainHeight))
+	}
+
+	prunedHeight := int64FromBytes(bz)
+	batch := txi.store.NewBatch()
+	defer func() { batch.Close() }()
+	pruned := 0
+	for height := prunedHeight; height < retainHeight; height++ {
+		n, err := txi.pruneHeight(batch, height)
+		if err != nil {
+			return err
+		}
+		pruned += n
+		if pruned < pruneBatchSize && height+1 < retainHeight {
+			continue
+		}
+
+		// Record the progress along with the deletions, so that an interrupted
+		// pruning resumes where it stopped.
+		if err := batch.Set(prunedHeightKey(), int64ToBytes(height+1)); err != nil {
+			return err
+		}
+		if err := batch.WriteSync(); err != nil {
+			return err
+		}
+		if err := batch.Close(); err != nil {
+			return err
+		}
+		batch = txi.store.NewBatch()
+		pruned = 0
+	}
+	return nil
+}
+
+// pruneHeight adds deletions for the transactions at the given height and their
+// events to the batch, returning the number of transactions deleted.
+func (txi *TxIndex) pruneHeight(batch dbm.Batch, height int64) (int, error) {
+	it, err := dbm.IteratePrefix(txi.store, prefixFromCompositeKeyAndValue(types.TxHeightKey, strconv.FormatInt(height, 10)))
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+
+	pruned := 0
+	for ; it.Valid(); it.Next() {
+		if err := txi.deleteTx(batch, it.Value(), height, height+1); err != nil {
+			return pruned, err
+		}
+		if err := batch.Delete(it.Key()); err != nil {
+			return pruned, err
+		}
+		pruned++
+	}
+	return pruned, it.Error()
+}
+
+// DeleteFrom removes all transactions at and above the given height from the
+// index, along with their indexed events. It is used when rolling back the
+// state, since the transactions will be indexed again once the blocks have
+// been executed again.
+func (txi *TxIndex) DeleteFrom(height int64) error {
+	return txi.deleteHeights(height, math.MaxInt64)
+}
+
+// deleteHeights removes all transactions with heights in [fromHeight,
+// toHeight) from the index, along with their indexed events. It iterates over
+// the height index of all indexed transactions, deleting up to pruneBatchSize
+// transactions per batch.
+func (txi *TxIndex) deleteHeights(fromHeight, toHeight int64) error {
+	start := prefixFromCompositeKey(types.TxHeightKey)
+	end := prefixEnd(start)
+	for {
+		batch := txi.store.NewBatch()
+		next, err := txi.pruneBatch(batch, start, end, fromHeight, toHeight)
+		if err == nil {
+			err = batch.WriteSync()
+		}
+		if closeErr := batch.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		if next == nil {
+			return nil
+		}
+		start = next
+	}
+}
+
+// pruneBatch adds deletions for up to pruneBatchSize transactions with heights
+// in [fromHeight, toHeight) to the batch, starting at the given height index
+// key. It returns the key to continue from, or nil once the end has been
+// reached.
+func (txi *TxIndex) pruneBatch(batch dbm.Batch, start, end []byte, fromHeight, toHeight int64) ([]byte, error) {
+	iter, err := txi.store.Iterator(start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	pruned := 0
+	for ; iter.Valid(); iter.Next() {
+		var (
+			compositeKey, value string
+			height, index       int64
+		)
+		if _, err := orderedcode.Parse(string(iter.Key()), &compositeKey, &value, &height, &index); err != nil {
+			return nil, fmt.Errorf("failed to parse height key %X: %w", iter.Key(), err)
+		}
+		if height < fromHeight || height >= toHeight {
+			continue
+		}
+		if err := txi.deleteTx(batch, iter.Value(), fromHeight, toHeight); err != nil {
+			return nil, err
+		}
+		if err := batch.Delete(iter.Key()); err != nil {
+			return nil, err
+		}
+
+		pruned++
+		if pruned == pruneBatchSize {
+			return append([]byte{}, iter.Key()...), iter.Error()
+		}
+	}
+	return nil, iter.Error()
+}
+
+// deleteTx adds deletions for the transaction with the given hash and its
+// events to the batch, unless the hash has since been indexed again at a
+// height outside of [fromHeight, toHeight).
+func (txi *TxIndex) deleteTx(batch dbm.Batch, hash []byte, fromHeight, toHeight int64) error {
+	rawBytes, err := txi.store.Get(primaryKey(hash))
+	if err != nil || rawBytes == nil {
+		return err
+	}
+	txResult := new(abci.TxResult)
+	if err := proto.Unmarshal(rawBytes, txResult); err != nil {
+		return fmt.Errorf("error reading TxResult: %v", err)
+	}
+	if txResult.Height < fromHeight || txResult.Height >= toHeight {
+		return nil
+	}
+
+	// Delete the keys of all attributes, not only those currently selected for
+	// indexing, since the attribute filter may have changed since the
+	// transaction was indexed.
+	for _, event := range txResult.Result.Events {
+		if len(event.Type) == 0 {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if len(attr.Key) == 0 {
+				continue
+			}
+			compositeTag := fmt.Sprintf("%s.%s", event.Type, attr.Key)
+			if err := batch.Delete(keyFromEvent(compositeTag, attr.Value, txResult)); err != nil {
+				return err
+			}
+		}
+	}
+	return batch.Delete(primaryKey(hash))
+}
+
 // Search performs a search using the given query.
 //
 // It breaks the query into conditions (like "tx.height > 5"). For each
@@ -144,29 +341,265 @@ func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*abci.TxResul
 	default:
 	}
 
-	var hashesInitialized bool
-	filteredHashes := make(map[string][]byte)
+	filteredHashes, err := txi.search(ctx, q.Expr())
+	if err != nil {
+		return nil, err
+	}
 
-	// get a list of conditions (like "tx.height > 5")
-	conditions, err := q.Conditions()
+	results := make([]*abci.TxResult, 0, len(filteredHashes))
+hashes:
+	for _, h := range filteredHashes {
+		res, err := txi.Get(h)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Tx{%X}: %w", h, err)
+		}
+		results = append(results, res)
+
+		// Potentially exit early.
+		select {
+		case <-ctx.Done():
+			break hashes
+		default:
+		}
+	}
+
+	return results, nil
+}
+
+// SearchPage returns the page of the transactions matching the query, and
+// whether more transactions follow the page.
+//
+// If the query is an equality condition, or a conjunction of equality
+// conditions and comparisons of the height, the keys of the first equality
+// condition, which are ordered by height and index, are scanned lazily from the
+// cursor of the page, and only the transactions of the page are loaded.
+// Otherwise, all the matching transactions are searched for and sorted first.
+func (txi *TxIndex) SearchPage(ctx context.Context, q *query.Query, page indexer.Page) ([]*abci.TxResult, bool, error) {
+	scan, ok := indexer.LookForPageScan(q.Expr(), types.TxHeightKey)
+	if ok {
+		// a hash condition is served by the primary key instead
+		_, hash, _ := lookForHashes(append([]query.Condition{scan.Equal}, scan.Others...))
+		ok = !hash
+	}
+	if !ok {
+		results, err := txi.Search(ctx, q)
+		if err != nil {
+			return nil, false, err
+		}
+		results, more := indexer.PageTxResults(results, page)
+		return results, more, nil
+	}
+
+	prefix := prefixForCondition(scan.Equal, 0)
+	start, end := prefix, prefixEnd(prefix)
+	var (
+		it  dbm.Iterator
+		err error
+	)
+	if page.Desc {
+		if page.After != nil {
+			end = appendPosition(prefix, page.After.Height, int64(page.After.Index))
+		}
+		it, err = txi.store.ReverseIterator(start, end)
+	} else {
+		if page.After != nil {
+			start = appendPosition(prefix, page.After.Height, int64(page.After.Index)+1)
+		}
+		it, err = txi.store.Iterator(start, end)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("error during parsing conditions from query: %w", err)
+		return nil, false, err
+	}
+	defer it.Close()
+
+	results := make([]*abci.TxResult, 0, page.Limit)
+	for ; it.Valid(); it.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+
+		var height, index int64
+		if _, err := orderedcode.Parse(string(it.Key()[len(prefix):]), &height, &index); err != nil {
+			return nil, false, fmt.Errorf("failed to parse event key: %w", err)
+		}
+		if !scan.MatchHeight(height) {
+			continue
+		}
+		ok, err := txi.matchEqualities(scan.Others, height, index)
+		if err != nil {
+			return nil, false, err
+		} else if !ok {
+			continue
+		}
+
+		if len(results) == page.Limit {
+			return results, true, nil
+		}
+		res, err := txi.Get(it.Value())
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get Tx{%X}: %w", it.Value(), err)
+		}
+		if res != nil {
+			results = append(results, res)
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, false, err
+	}
+
+	return results, false, nil
+}
+
+// matchEqualities reports whether the transaction at the given height and
+// index satisfies all the equality conditions.
+func (txi *TxIndex) matchEqualities(conditions []query.Condition, height, index int64) (bool, error) {
+	for _, c := range conditions {
+		ok, err := txi.store.Has(secondaryKey(c.CompositeKey, fmt.Sprintf("%v", c.Operand), height, uint32(index)))
+		if err != nil || !ok {
+			return false, err
+		}
 	}
+	return true, nil
+}
+
+// search returns the hashes of the transactions matching the expression.
+//
+// The conditions of a conjunction are matched together, so that they narrow
+// down each other's key ranges, and its negated arguments are removed from the
+// matches of its other arguments. Only negations which are not part of a
+// conjunction with other arguments require iterating over all transactions.
+func (txi *TxIndex) search(ctx context.Context, e *query.Expr) (map[string][]byte, error) {
+	switch e.Type {
+	case query.ExprCondition:
+		return txi.searchConditions(ctx, []query.Condition{e.Condition})
+
+	case query.ExprOr:
+		filteredHashes := make(map[string][]byte)
+		for _, arg := range e.Args {
+			hashes, err := txi.search(ctx, arg)
+			if err != nil {
+				return nil, err
+			}
+			for k, v := range hashes {
+				filteredHashes[k] = v
+			}
+		}
+		return filteredHashes, nil
+
+	case query.ExprNot:
+		filteredHashes, err := txi.searchAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return txi.subtract(ctx, filteredHashes, e.Args)
+	}
+
+	var (
+		conditions []query.Condition
+		negated    []*query.Expr
+		others     []*query.Expr
+	)
+	for _, arg := range e.Args {
+		switch arg.Type {
+		case query.ExprCondition:
+			conditions = append(conditions, arg.Condition)
+		case query.ExprNot:
+			negated = append(negated, arg.Args[0])
+		default:
+			others = append(others, arg)
+		}
+	}
+
+	var (
+		hashesInitialized bool
+		filteredHashes    map[string][]byte
+		err               error
+	)
+	if len(conditions) > 0 {
+		filteredHashes, err = txi.searchConditions(ctx, conditions)
+		if err != nil {
+			return nil, err
+		}
+		hashesInitialized = true
+	}
+	for _, arg := range others {
+		if hashesInitialized && len(filteredHashes) == 0 {
+			break
+		}
+
+		hashes, err := txi.search(ctx, arg)
+		if err != nil {
+			return nil, err
+		}
+		if !hashesInitialized {
+			filteredHashes, hashesInitialized = hashes, true
+			continue
+		}
+		for k := range filteredHashes {
+			if hashes[k] == nil {
+				delete(filteredHashes, k)
+			}
+		}
+	}
+	if !hashesInitialized {
+		filteredHashes, err = txi.searchAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return txi.subtract(ctx, filteredHashes, negated)
+}
+
+// subtract removes the hashes of the transactions matching any of the
+// expressions from filteredHashes.
+func (txi *TxIndex) subtract(
+	ctx context.Context,
+	filteredHashes map[string][]byte,
+	exprs []*query.Expr,
+) (map[string][]byte, error) {
+	for _, e := range exprs {
+		if len(filteredHashes) == 0 {
+			break
+		}
+
+		hashes, err := txi.search(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+		for k := range hashes {
+			delete(filteredHashes, k)
+		}
+	}
+	return filteredHashes, nil
+}
+
+// searchAll returns the hashes of all transactions, which are all indexed by
+// their height.
+func (txi *TxIndex) searchAll(ctx context.Context) (map[string][]byte, error) {
+	return txi.searchConditions(ctx, []query.Condition{{CompositeKey: types.TxHeightKey, Op: query.OpExists}})
+}
+
+// searchConditions returns the hashes of the transactions matching all of the
+// conditions.
+func (txi *TxIndex) searchConditions(ctx context.Context, conditions []query.Condition) (map[string][]byte, error) {
+	var hashesInitialized bool
+	filteredHashes := make(map[string][]byte)
 
-	// if there is a hash condition, return the result immediately
-	hash, ok, err := lookForHash(conditions)
+	// if there is a hash condition, return its transactions immediately
+	hashes, ok, err := lookForHashes(conditions)
 	if err != nil {
 		return nil, fmt.Errorf("error during searching for a hash in the query: %w", err)
 	} else if ok {
-		res, err := txi.Get(hash)
-		switch {
-		case err != nil:
-			return []*abci.TxResult{}, fmt.Errorf("error while retrieving the result: %w", err)
-		case res == nil:
-			return []*abci.TxResult{}, nil
-		default:
-			return []*abci.TxResult{res}, nil
+		for _, hash := range hashes {
+			ok, err := txi.store.Has(primaryKey(hash))
+			if err != nil {
+				return nil, fmt.Errorf("error while retrieving the result: %w", err)
+			}
+			if ok {
+				filteredHashes[string(hash)] = hash
+			}
 		}
+		return filteredHashes, nil
 	}
 
 	// conditions to skip because they're handled before "everything else"
@@ -218,32 +651,33 @@ func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*abci.TxResul
 		}
 	}
 
-	results := make([]*abci.TxResult, 0, len(filteredHashes))
-hashes:
-	for _, h := range filteredHashes {
-		res, err := txi.Get(h)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get Tx{%X}: %w", h, err)
-		}
-		results = append(results, res)
-
-		// Potentially exit early.
-		select {
-		case <-ctx.Done():
-			break hashes
-		default:
-		}
-	}
-
-	return results, nil
+	return filteredHashes, nil
 }
 
-func lookForHash(conditions []query.Condition) (hash []byte, ok bool, err error) {
+// lookForHashes returns the hashes of a "tx.hash=X" or "tx.hash IN (X, Y)"
+// condition, if there is one.
+func lookForHashes(conditions []query.Condition) (hashes [][]byte, ok bool, err error) {
 	for _, c := range conditions {
-		if c.CompositeKey == types.TxHashKey {
-			decoded, err := hex.DecodeString(c.Operand.(string))
-			return decoded, true, err
+		if c.CompositeKey != types.TxHashKey {
+			continue
+		}
+
+		operands := []interface{}{c.Operand}
+		if c.Op == query.OpIn {
+			operands = c.Operand.([]interface{})
+		}
+		for _, operand := range operands {
+			s, ok := operand.(string)
+			if !ok {
+				return nil, true, fmt.Errorf("%s must be compared with a string, got %v", types.TxHashKey, operand)
+			}
+			decoded, err := hex.DecodeString(s)
+			if err != nil {
+				return nil, true, err
+			}
+			hashes = append(hashes, decoded)
 		}
+		return hashes, true, nil
 	}
 	return
 }
@@ -325,6 +759,31 @@ func (txi *TxIndex) match(
 			panic(err)
 		}
 
+	case c.Op == query.OpIn:
+		// XXX: startKey does not apply here, since each operand has a prefix of
+		// its own (e.g. "account.owner/Ivan/" and "account.owner/Igor/").
+	iterIn:
+		for _, operand := range c.Operand.([]interface{}) {
+			it, err := dbm.IteratePrefix(txi.store, prefixFromCompositeKeyAndValue(c.CompositeKey, fmt.Sprintf("%v", operand)))
+			if err != nil {
+				panic(err)
+			}
+			for ; it.Valid(); it.Next() {
+				tmpHashes[string(it.Value())] = it.Value()
+			}
+			if err := it.Error(); err != nil {
+				panic(err)
+			}
+			it.Close()
+
+			// Potentially exit early.
+			select {
+			case <-ctx.Done():
+				break iterIn
+			default:
+			}
+		}
+
 	case c.Op == query.OpContains:
 		// XXX: startKey does not apply here.
 		// For example, if startKey = "account.owner/an/" and search query = "account.owner CONTAINS an"
@@ -355,6 +814,49 @@ func (txi *TxIndex) match(
 		if err := it.Error(); err != nil {
 			panic(err)
 		}
+	case c.Op == query.OpStartsWith || c.Op == query.OpMatches:
+		// XXX: startKey does not apply here either. Instead, only the range of
+		// the values starting with the prefix, or the literal prefix of the
+		// pattern, is scanned (e.g. "account.owner/Iv" for "account.owner
+		// STARTS WITH 'Iv'" or "account.owner MATCHES 'Iv*n'").
+		var (
+			valuePrefix string
+			matchValue  func(string) bool
+		)
+		if c.Op == query.OpStartsWith {
+			valuePrefix = c.Operand.(string)
+			matchValue = func(v string) bool { return strings.HasPrefix(v, valuePrefix) }
+		} else {
+			pattern := c.Operand.(*query.Pattern)
+			valuePrefix, matchValue = pattern.Prefix(), pattern.Match
+		}
+
+		it, err := dbm.IteratePrefix(txi.store, prefixFromCompositeKeyAndValuePrefix(c.CompositeKey, valuePrefix))
+		if err != nil {
+			panic(err)
+		}
+		defer it.Close()
+
+	iterPrefix:
+		for ; it.Valid(); it.Next() {
+			value, err := parseValueFromKey(it.Key())
+			if err != nil {
+				continue
+			}
+			if matchValue(value) {
+				tmpHashes[string(it.Value())] = it.Value()
+			}
+
+			// Potentially exit early.
+			select {
+			case <-ctx.Done():
+				break iterPrefix
+			default:
+			}
+		}
+		if err := it.Error(); err != nil {
+			panic(err)
+		}
 	default:
 		panic("other operators should be handled already")
 	}
@@ -503,6 +1005,17 @@ iter:
 // 3. The height of the Tx that aligns with the key and value.
 // 4. The index of the Tx that aligns with the key and value
 
+// prunedHeightKey is the key of the height below which the index was pruned.
+// Since composite keys always contain a dot, it can't be mistaken for the
+// composite key of an event.
+func prunedHeightKey() []byte {
+	key, err := orderedcode.Append(nil, "prunedHeight")
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
 // the hash/primary key
 func primaryKey(hash []byte) []byte {
 	key, err := orderedcode.Append(
@@ -568,6 +1081,19 @@ func prefixFromCompositeKey(compositeKey string) []byte {
 	return key
 }
 
+// prefixEnd returns the exclusive end of the key range with the given prefix,
+// or nil if the range is unbounded.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		end[i]++
+		if end[i] != 0 {
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
 func prefixFromCompositeKeyAndValue(compositeKey, value string) []byte {
 	key, err := orderedcode.Append(nil, compositeKey, value)
 	if err != nil {
@@ -576,6 +1102,27 @@ func prefixFromCompositeKeyAndValue(compositeKey, value string) []byte {
 	return key
 }
 
+// prefixFromCompositeKeyAndValuePrefix returns the prefix shared by the keys
+// of the events with the given composite key whose value starts with
+// valuePrefix. Since such keys are contiguous in the store, they can be found
+// with a range scan.
+func prefixFromCompositeKeyAndValuePrefix(compositeKey, valuePrefix string) []byte {
+	key := prefixFromCompositeKeyAndValue(compositeKey, valuePrefix)
+	// Strip the terminator of the encoded value, leaving a prefix of the
+	// encoding of every value starting with valuePrefix.
+	return key[:len(key)-2]
+}
+
+// appendPosition appends the height and index of a transaction to the prefix
+// of its event keys.
+func appendPosition(prefix []byte, height, index int64) []byte {
+	key, err := orderedcode.Append(append([]byte{}, prefix...), height, index)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
 // a small utility function for getting a keys prefix based on a condition and a height
 func prefixForCondition(c query.Condition, height int64) []byte {
 	key := prefixFromCompositeKeyAndValue(c.CompositeKey, fmt.Sprintf("%v", c.Operand))
@@ -144,6 +144,67 @@ func TestTxSearch(t *testing.T) {
 	}
 }
 
+func TestTxSearchExpressions(t *testing.T) {
+	txIndexer := NewTxIndex(dbm.NewMemDB())
+
+	owners := []string{"Ivan", "Igor", "Pavel", "Ivan"}
+	hashes := make(map[string]int64)
+	for i, owner := range owners {
+		txResult := txResultWithEvents([]abci.Event{
+			{Type: "account", Attributes: []abci.EventAttribute{
+				{Key: "owner", Value: owner, Index: true},
+				{Key: "number", Value: fmt.Sprint(i + 1), Index: true},
+			}},
+		})
+		txResult.Tx = types.Tx(fmt.Sprintf("tx%d", i))
+		txResult.Height = int64(i + 1)
+		require.NoError(t, txIndexer.Index([]*abci.TxResult{txResult}))
+		hashes[string(txResult.Tx)] = txResult.Height
+	}
+	hash := types.Tx("tx1").Hash()
+
+	testCases := []struct {
+		q       string
+		heights []int64
+	}{
+		{"account.owner = 'Igor' OR account.owner = 'Pavel'", []int64{2, 3}},
+		{"account.owner IN ('Igor', 'Pavel', 'Vlad')", []int64{2, 3}},
+		{"account.number IN (1, 3)", []int64{1, 3}},
+		{"tx.height IN (2, 4)", []int64{2, 4}},
+		{fmt.Sprintf("tx.hash IN ('%X', '%X')", hash, types.Tx("tx9").Hash()), []int64{2}},
+		{"account.owner = 'Ivan' AND (account.number = 1 OR account.number = 3)", []int64{1}},
+		{"account.owner = 'Ivan' AND NOT account.number = 1", []int64{4}},
+		{"account.number >= 2 AND NOT account.owner IN ('Igor', 'Ivan')", []int64{3}},
+		{"NOT account.owner = 'Ivan'", []int64{2, 3}},
+		{"NOT (account.owner = 'Ivan' OR account.number < 3)", []int64{3}},
+		{"NOT account.owner EXISTS", []int64{}},
+		{"NOT NOT account.owner = 'Pavel'", []int64{3}},
+		{"account.owner = 'Vlad' OR NOT account.number > 1", []int64{1}},
+		{"(account.owner = 'Ivan' OR account.owner = 'Pavel') AND (account.number = 3 OR account.number = 4)", []int64{3, 4}},
+		{"account.owner STARTS WITH 'I'", []int64{1, 2, 4}},
+		{"account.owner STARTS WITH 'Iv' AND account.number > 1", []int64{4}},
+		{"account.owner STARTS WITH 'Ivana'", []int64{}},
+		{"account.owner STARTS WITH ''", []int64{1, 2, 3, 4}},
+		{"account.owner MATCHES 'I*r'", []int64{2}},
+		{"account.owner MATCHES '*a*'", []int64{1, 3, 4}},
+		{"account.owner MATCHES 'Iva'", []int64{}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.q, func(t *testing.T) {
+			results, err := txIndexer.Search(context.Background(), query.MustParse(tc.q))
+			require.NoError(t, err)
+
+			heights := make([]int64, 0, len(results))
+			for _, txr := range results {
+				heights = append(heights, hashes[string(txr.Tx)])
+			}
+			assert.ElementsMatch(t, tc.heights, heights)
+		})
+	}
+}
+
 func TestTxSearchWithCancelation(t *testing.T) {
 	indexer := NewTxIndex(dbm.NewMemDB())
 
@@ -317,6 +378,69 @@ func TestTxSearchMultipleTxs(t *testing.T) {
 	require.Len(t, results, 3)
 }
 
+func TestTxSearchPage(t *testing.T) {
+	txIndexer := NewTxIndex(dbm.NewMemDB())
+
+	owners := []string{"Ivan", "Igor", "Ivan"}
+	for h := int64(1); h <= 5; h++ {
+		results := make([]*abci.TxResult, 0, len(owners))
+		for i, owner := range owners {
+			txResult := txResultWithEvents([]abci.Event{
+				{Type: "account", Attributes: []abci.EventAttribute{
+					{Key: "owner", Value: owner, Index: true},
+					{Key: "number", Value: fmt.Sprint(i), Index: true},
+				}},
+			})
+			txResult.Tx = types.Tx(fmt.Sprintf("tx%d.%d", h, i))
+			txResult.Height = h
+			txResult.Index = uint32(i)
+			results = append(results, txResult)
+		}
+		require.NoError(t, txIndexer.Index(results))
+	}
+
+	queries := []string{
+		// scanned lazily
+		"account.owner = 'Ivan'",
+		"account.owner = 'Ivan' AND tx.height > 2 AND tx.height <= 4",
+		"account.owner = 'Ivan' AND account.number = 2",
+		"account.number = 1",
+		"account.owner = 'Vlad'",
+		// searched for and sorted first
+		"account.number >= 1",
+		"account.owner = 'Igor' OR account.number = 0",
+	}
+	for _, q := range queries {
+		for _, desc := range []bool{false, true} {
+			results, err := txIndexer.Search(context.Background(), query.MustParse(q))
+			require.NoError(t, err)
+			expected, _ := indexer.PageTxResults(results, indexer.Page{Desc: desc, Limit: len(results) + 1})
+
+			var (
+				page   = indexer.Page{Desc: desc, Limit: 2}
+				actual []*abci.TxResult
+			)
+			for {
+				results, more, err := txIndexer.SearchPage(context.Background(), query.MustParse(q), page)
+				require.NoError(t, err)
+				require.LessOrEqual(t, len(results), page.Limit)
+				actual = append(actual, results...)
+				if !more {
+					break
+				}
+				require.Len(t, results, page.Limit)
+				last := results[len(results)-1]
+				page.After = &indexer.Cursor{Height: last.Height, Index: last.Index}
+			}
+
+			require.Equal(t, len(expected), len(actual), q)
+			for i := range expected {
+				assert.True(t, proto.Equal(expected[i], actual[i]), "%s: result %d", q, i)
+			}
+		}
+	}
+}
+
 func txResultWithEvents(events []abci.Event) *abci.TxResult {
 	tx := types.Tx("HELLO WORLD")
 	return &abci.TxResult{
@@ -377,3 +501,99 @@ func BenchmarkTxIndex500(b *testing.B)   { benchmarkTxIndex(500, b) }
 func BenchmarkTxIndex1000(b *testing.B)  { benchmarkTxIndex(1000, b) }
 func BenchmarkTxIndex2000(b *testing.B)  { benchmarkTxIndex(2000, b) }
 func BenchmarkTxIndex10000(b *testing.B) { benchmarkTxIndex(10000, b) }
+
+func TestTxIndexPrune(t *testing.T) {
+	txIndexer := NewTxIndex(dbm.NewMemDB())
+
+	hashes := make([][]byte, 0, 12)
+	for i := int64(1); i <= 12; i++ {
+		txResult := txResultWithEvents([]abci.Event{
+			{Type: "account", Attributes: []abci.EventAttribute{{Key: "number", Value: "1", Index: true}}},
+		})
+		txResult.Tx = types.Tx(fmt.Sprintf("tx%d", i))
+		txResult.Height = i
+		hashes = append(hashes, types.Tx(txResult.Tx).Hash())
+		require.NoError(t, txIndexer.Index([]*abci.TxResult{txResult}))
+	}
+
+	require.NoError(t, txIndexer.Prune(10))
+
+	for i, hash := range hashes {
+		height := int64(i) + 1
+		txResult, err := txIndexer.Get(hash)
+		require.NoError(t, err)
+		if height < 10 {
+			require.Nil(t, txResult, "height %d", height)
+		} else {
+			require.NotNil(t, txResult, "height %d", height)
+		}
+	}
+
+	results, err := txIndexer.Search(context.Background(), query.MustParse("account.number = 1"))
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	results, err = txIndexer.Search(context.Background(), query.MustParse("tx.height < 10"))
+	require.NoError(t, err)
+	require.Empty(t, results)
+
+	// Pruning again only looks up the transactions at the heights since the
+	// last pruning.
+	require.NoError(t, txIndexer.Prune(11))
+	txResult, err := txIndexer.Get(hashes[9])
+	require.NoError(t, err)
+	require.Nil(t, txResult)
+
+	results, err = txIndexer.Search(context.Background(), query.MustParse("account.number = 1"))
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	// Transactions at and above a height can be deleted as well.
+	require.NoError(t, txIndexer.DeleteFrom(12))
+	txResult, err = txIndexer.Get(hashes[11])
+	require.NoError(t, err)
+	require.Nil(t, txResult)
+
+	results, err = txIndexer.Search(context.Background(), query.MustParse("account.number = 1"))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+}
+
+func TestTxIndexAttributeFilter(t *testing.T) {
+	store := dbm.NewMemDB()
+	newTxResult := func(height int64) *abci.TxResult {
+		txResult := txResultWithEvents([]abci.Event{
+			{Type: "transfer", Attributes: []abci.EventAttribute{
+				{Key: "recipient", Value: "Ivan", Index: true},
+				{Key: "amount", Value: "5", Index: true},
+				{Key: "memo", Value: "hi", Index: false},
+			}},
+		})
+		txResult.Tx = types.Tx(fmt.Sprintf("tx%d", height))
+		txResult.Height = height
+		return txResult
+	}
+	search := func(txIndexer *TxIndex, q string) int {
+		results, err := txIndexer.Search(context.Background(), query.MustParse(q))
+		require.NoError(t, err)
+		return len(results)
+	}
+
+	// Only included attributes which the application marked for indexing and
+	// which are not excluded are indexed.
+	txIndexer := NewTxIndex(store, WithAttributeFilter(indexer.NewAttributeFilter(
+		[]string{"transfer.recipient", "transfer.amount", "transfer.memo"}, []string{"transfer.amount"})))
+	require.NoError(t, txIndexer.Index([]*abci.TxResult{newTxResult(1)}))
+	require.Equal(t, 1, search(txIndexer, "transfer.recipient = 'Ivan'"))
+	require.Equal(t, 0, search(txIndexer, "transfer.amount = 5"))
+	require.Equal(t, 0, search(txIndexer, "transfer.memo = 'hi'"))
+	require.Equal(t, 1, search(txIndexer, "tx.height = 1"))
+
+	// After changing the filter, deleting and re-indexing the transaction
+	// removes the attributes which are no longer indexed.
+	txIndexer = NewTxIndex(store, WithAttributeFilter(indexer.NewAttributeFilter(nil, []string{"transfer.recipient"})))
+	require.NoError(t, txIndexer.DeleteFrom(1))
+	require.NoError(t, txIndexer.Index([]*abci.TxResult{newTxResult(1)}))
+	require.Equal(t, 0, search(txIndexer, "transfer.recipient = 'Ivan'"))
+	require.Equal(t, 1, search(txIndexer, "transfer.amount = 5"))
+}
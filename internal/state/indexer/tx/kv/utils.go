@@ -1,5 +1,7 @@
 package kv
 
+import "encoding/binary"
+
 // IntInSlice returns true if a is found in the list.
 func intInSlice(a int, list []int) bool {
 	for _, b := range list {
@@ -9,3 +11,14 @@ func intInSlice(a int, list []int) bool {
 	}
 	return false
 }
+
+func int64FromBytes(bz []byte) int64 {
+	v, _ := binary.Varint(bz)
+	return v
+}
+
+func int64ToBytes(i int64) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutVarint(buf, i)
+	return buf[:n]
+}
@@ -0,0 +1,269 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/service"
+)
+
+// PruningPolicy is the operator's pruning policy. The node prunes up to the
+// more conservative (i.e. lower) of the retain height requested by the
+// application and the retain height implied by the policy.
+type PruningPolicy struct {
+	// Archive disables all pruning, including pruning requested by the
+	// application.
+	Archive bool
+
+	// KeepBlocks is the number of most recent blocks to keep. 0 disables
+	// pruning by height.
+	KeepBlocks int64
+
+	// KeepTime is the minimum age of blocks to keep. 0 disables pruning by age.
+	KeepTime time.Duration
+}
+
+// PrunerArgs are arguments for constructing a new Pruner.
+type PrunerArgs struct {
+	StateStore Store
+	BlockStore BlockStore
+	// EventSinks are pruned along with blocks.
+	EventSinks []indexer.EventSink
+	Policy     PruningPolicy
+	Interval   time.Duration
+	Logger     log.Logger
+}
+
+// Pruner is a service that periodically prunes blocks, states and indexed
+// events according to the operator's pruning policy and the retain height
+// requested by the application. When a Pruner is set on the BlockExecutor,
+// the application's retain height is passed on to the Pruner instead of being
+// acted upon directly.
+type Pruner struct {
+	service.BaseService
+
+	stateStore Store
+	blockStore BlockStore
+	eventSinks []indexer.EventSink
+	policy     PruningPolicy
+	interval   time.Duration
+	logger     log.Logger
+
+	mtx sync.Mutex
+	// appRetainHeight is the latest retain height requested by the
+	// application, or 0 if none. It is persisted in the state store, so that
+	// it is still honored after a restart.
+	appRetainHeight int64
+	// indexerRetainHeight is the height below which event sinks were last
+	// pruned.
+	indexerRetainHeight int64
+}
+
+// NewPruner constructs a new pruning service from the given arguments. It
+// loads the application's latest retain height from the state store.
+func NewPruner(args PrunerArgs) (*Pruner, error) {
+	appRetainHeight, err := args.StateStore.LoadApplicationRetainHeight()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load application retain height: %w", err)
+	}
+	p := &Pruner{
+		stateStore: args.StateStore,
+		blockStore: args.BlockStore,
+		eventSinks: args.EventSinks,
+		policy:     args.Policy,
+		interval:   args.Interval,
+		logger:     args.Logger,
+
+		appRetainHeight: appRetainHeight,
+	}
+	if p.logger == nil {
+		p.logger = log.NewNopLogger()
+	}
+	p.BaseService = *service.NewBaseService(p.logger, "Pruner", p)
+	return p, nil
+}
+
+// SetApplicationRetainHeight records the retain height requested by the
+// application in its latest ResponseCommit. A height of 0 means the
+// application does not request any pruning. The height is persisted in the
+// state store whenever it changes.
+func (p *Pruner) SetApplicationRetainHeight(height int64) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if height == p.appRetainHeight {
+		return nil
+	}
+	if err := p.stateStore.SaveApplicationRetainHeight(height); err != nil {
+		return fmt.Errorf("failed to save application retain height: %w", err)
+	}
+	p.appRetainHeight = height
+	return nil
+}
+
+// OnStart implements service.Service.
+func (p *Pruner) OnStart() error {
+	go p.pruneRoutine()
+	return nil
+}
+
+// OnStop implements service.Service.
+func (p *Pruner) OnStop() {}
+
+func (p *Pruner) pruneRoutine() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.Quit():
+			return
+		case <-ticker.C:
+			retainHeight, err := p.RetainHeight()
+			if err != nil {
+				p.logger.Error("failed to determine retain height", "err", err)
+				continue
+			}
+			pruned, err := p.Prune(retainHeight)
+			if err != nil {
+				p.logger.Error("failed to prune", "retain_height", retainHeight, "err", err)
+				continue
+			}
+			if pruned > 0 {
+				p.logger.Info("pruned blocks", "pruned", pruned, "retain_height", retainHeight)
+			}
+		}
+	}
+}
+
+// RetainHeight returns the height below which data should currently be pruned,
+// or 0 if nothing should be pruned. It is the more conservative of the
+// application's retain height and the operator's pruning policy.
+func (p *Pruner) RetainHeight() (int64, error) {
+	if p.policy.Archive {
+		return 0, nil
+	}
+
+	p.mtx.Lock()
+	appRetainHeight := p.appRetainHeight
+	p.mtx.Unlock()
+
+	operatorRetainHeight, err := p.operatorRetainHeight()
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case appRetainHeight > 0 && operatorRetainHeight > 0:
+		if appRetainHeight < operatorRetainHeight {
+			return appRetainHeight, nil
+		}
+		return operatorRetainHeight, nil
+	case appRetainHeight > 0:
+		return appRetainHeight, nil
+	default:
+		return operatorRetainHeight, nil
+	}
+}
+
+// operatorRetainHeight returns the retain height implied by the operator's
+// pruning policy, or 0 if the policy does not prune anything. Blocks which may
+// still be needed to verify evidence are never pruned.
+func (p *Pruner) operatorRetainHeight() (int64, error) {
+	if p.policy.KeepBlocks <= 0 && p.policy.KeepTime <= 0 {
+		return 0, nil
+	}
+
+	base, height := p.blockStore.Base(), p.blockStore.Height()
+	if base == 0 || height == 0 {
+		return 0, nil
+	}
+
+	state, err := p.stateStore.Load()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load state: %w", err)
+	}
+	if state.IsEmpty() {
+		return 0, nil
+	}
+
+	retainHeight := height
+	if p.policy.KeepBlocks > 0 {
+		retainHeight = min(retainHeight, height-p.policy.KeepBlocks+1)
+	}
+	if p.policy.KeepTime > 0 {
+		retainHeight = min(retainHeight, p.firstHeightAfter(base, height, state.LastBlockTime.Add(-p.policy.KeepTime)))
+	}
+
+	evidenceParams := state.ConsensusParams.Evidence
+	retainHeight = min(retainHeight, state.LastBlockHeight-evidenceParams.MaxAgeNumBlocks)
+	retainHeight = min(retainHeight, p.firstHeightAfter(base, height, state.LastBlockTime.Add(-evidenceParams.MaxAgeDuration)))
+
+	if retainHeight <= 0 {
+		return 0, nil
+	}
+	return retainHeight, nil
+}
+
+// firstHeightAfter returns the lowest height in [base, height] whose block time
+// is not before the given time, or height if there is none.
+func (p *Pruner) firstHeightAfter(base, height int64, t time.Time) int64 {
+	i := sort.Search(int(height-base+1), func(i int) bool {
+		meta := p.blockStore.LoadBlockMeta(base + int64(i))
+		// Treat missing blocks as recent, so they are never pruned.
+		return meta == nil || !meta.Header.Time.Before(t)
+	})
+	return min(base+int64(i), height)
+}
+
+// Prune prunes blocks, states and indexed events below the given retain height,
+// returning the number of blocks pruned.
+func (p *Pruner) Prune(retainHeight int64) (uint64, error) {
+	if retainHeight <= 0 {
+		return 0, nil
+	}
+
+	p.mtx.Lock()
+	pruneIndexers := retainHeight > p.indexerRetainHeight
+	p.mtx.Unlock()
+	if pruneIndexers {
+		if err := pruneEventSinks(p.eventSinks, retainHeight); err != nil {
+			return 0, err
+		}
+		p.mtx.Lock()
+		p.indexerRetainHeight = retainHeight
+		p.mtx.Unlock()
+	}
+
+	if retainHeight <= p.blockStore.Base() {
+		return 0, nil
+	}
+	pruned, err := p.blockStore.PruneBlocks(retainHeight)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune block store: %w", err)
+	}
+	if err := p.stateStore.PruneStates(retainHeight); err != nil {
+		return 0, fmt.Errorf("failed to prune state store: %w", err)
+	}
+	return pruned, nil
+}
+
+// pruneEventSinks prunes the events below the retain height from the sinks.
+func pruneEventSinks(sinks []indexer.EventSink, retainHeight int64) error {
+	for _, sink := range sinks {
+		if err := sink.Prune(retainHeight); err != nil {
+			return fmt.Errorf("failed to prune %s event sink: %w", sink.Type(), err)
+		}
+	}
+	return nil
+}
+
+func min(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
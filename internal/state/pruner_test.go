@@ -0,0 +1,150 @@
+package state_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	indexermocks "github.com/tendermint/tendermint/internal/state/indexer/mocks"
+	"github.com/tendermint/tendermint/internal/state/mocks"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestPrunerRetainHeight(t *testing.T) {
+	genesisTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	blockTime := func(height int64) time.Time {
+		return genesisTime.Add(time.Duration(height) * time.Minute)
+	}
+
+	testcases := map[string]struct {
+		policy          state.PruningPolicy
+		appRetainHeight int64
+		expected        int64
+	}{
+		"no policy or app retain height": {state.PruningPolicy{}, 0, 0},
+		"app retain height only":         {state.PruningPolicy{}, 95, 95},
+		"archive":                        {state.PruningPolicy{Archive: true}, 95, 0},
+		"keep blocks":                    {state.PruningPolicy{KeepBlocks: 50}, 0, 51},
+		"keep time":                      {state.PruningPolicy{KeepTime: 30 * time.Minute}, 0, 70},
+		"keep blocks and time":           {state.PruningPolicy{KeepBlocks: 50, KeepTime: 60 * time.Minute}, 0, 40},
+		"app is more conservative":       {state.PruningPolicy{KeepBlocks: 50}, 40, 40},
+		"operator is more conservative":  {state.PruningPolicy{KeepBlocks: 50}, 60, 51},
+		"keep evidence":                  {state.PruningPolicy{KeepBlocks: 5}, 0, 75},
+		"keep more than stored":          {state.PruningPolicy{KeepBlocks: 1000}, 0, 0},
+	}
+	for name, tc := range testcases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			blockStore := &mocks.BlockStore{}
+			blockStore.On("Base").Return(int64(1))
+			blockStore.On("Height").Return(int64(100))
+			blockStore.On("LoadBlockMeta", mock.Anything).Return(func(height int64) *types.BlockMeta {
+				return &types.BlockMeta{Header: types.Header{Height: height, Time: blockTime(height)}}
+			})
+
+			params := types.DefaultConsensusParams()
+			params.Evidence.MaxAgeNumBlocks = 20
+			params.Evidence.MaxAgeDuration = 25 * time.Minute
+			stateStore := &mocks.Store{}
+			stateStore.On("LoadApplicationRetainHeight").Return(int64(0), nil)
+			stateStore.On("SaveApplicationRetainHeight", mock.Anything).Return(nil)
+			stateStore.On("Load").Return(state.State{
+				ChainID:         "test",
+				LastBlockHeight: 100,
+				LastBlockTime:   blockTime(100),
+				ConsensusParams: *params,
+				Validators:      types.NewValidatorSet(nil),
+			}, nil)
+
+			pruner, err := state.NewPruner(state.PrunerArgs{
+				StateStore: stateStore,
+				BlockStore: blockStore,
+				Policy:     tc.policy,
+				Interval:   time.Second,
+			})
+			require.NoError(t, err)
+			require.NoError(t, pruner.SetApplicationRetainHeight(tc.appRetainHeight))
+
+			retainHeight, err := pruner.RetainHeight()
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, retainHeight)
+		})
+	}
+}
+
+func TestPrunerPrune(t *testing.T) {
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("Base").Return(int64(1)).Once()
+	blockStore.On("PruneBlocks", int64(51)).Return(uint64(50), nil).Once()
+	blockStore.On("Base").Return(int64(51))
+
+	stateStore := &mocks.Store{}
+	stateStore.On("LoadApplicationRetainHeight").Return(int64(0), nil)
+	stateStore.On("PruneStates", int64(51)).Return(nil).Once()
+
+	var sinks []indexer.EventSink
+	for i := 0; i < 2; i++ {
+		sink := &indexermocks.EventSink{}
+		sink.On("Prune", int64(51)).Return(nil).Once()
+		sinks = append(sinks, sink)
+	}
+	pruner, err := state.NewPruner(state.PrunerArgs{
+		StateStore: stateStore,
+		BlockStore: blockStore,
+		EventSinks: sinks,
+		Interval:   time.Second,
+	})
+	require.NoError(t, err)
+
+	pruned, err := pruner.Prune(51)
+	require.NoError(t, err)
+	require.EqualValues(t, 50, pruned)
+
+	// Pruning again to the same height does nothing.
+	pruned, err = pruner.Prune(51)
+	require.NoError(t, err)
+	require.Zero(t, pruned)
+
+	pruned, err = pruner.Prune(0)
+	require.NoError(t, err)
+	require.Zero(t, pruned)
+
+	blockStore.AssertExpectations(t)
+	stateStore.AssertExpectations(t)
+	for _, sink := range sinks {
+		sink.(*indexermocks.EventSink).AssertExpectations(t)
+	}
+}
+
+func TestPrunerRestart(t *testing.T) {
+	stateStore := state.NewStore(dbm.NewMemDB())
+	newPruner := func() *state.Pruner {
+		pruner, err := state.NewPruner(state.PrunerArgs{
+			StateStore: stateStore,
+			BlockStore: &mocks.BlockStore{},
+			Interval:   time.Second,
+		})
+		require.NoError(t, err)
+		return pruner
+	}
+
+	pruner := newPruner()
+	require.NoError(t, pruner.SetApplicationRetainHeight(40))
+
+	// The application's retain height is still honored after a restart.
+	pruner = newPruner()
+	retainHeight, err := pruner.RetainHeight()
+	require.NoError(t, err)
+	require.EqualValues(t, 40, retainHeight)
+
+	require.NoError(t, pruner.SetApplicationRetainHeight(0))
+	pruner = newPruner()
+	retainHeight, err = pruner.RetainHeight()
+	require.NoError(t, err)
+	require.Zero(t, retainHeight)
+}
@@ -1,9 +1,11 @@
 package state
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 
+	"github.com/tendermint/tendermint/internal/state/indexer"
 	"github.com/tendermint/tendermint/version"
 )
 
@@ -102,3 +104,166 @@ func Rollback(bs BlockStore, ss Store) (int64, []byte, error) {
 
 	return rolledBackState.LastBlockHeight, rolledBackState.AppHash, nil
 }
+
+// RollbackOptions configures a rollback with RollbackTo.
+type RollbackOptions struct {
+	// DeleteBlocks removes the blocks above the target height from the block
+	// store. Otherwise, the blocks are executed again when the node starts.
+	DeleteBlocks bool
+
+	// EventSinks are the event sinks to remove the events above the target
+	// height from, which must support deleting events.
+	EventSinks []indexer.EventSink
+
+	// DryRun only computes the rolled back state, without changing anything.
+	DryRun bool
+}
+
+// RollbackTo overwrites the current Tendermint state with the state after the
+// block at the target height, which must still be present in the block and
+// state stores, and returns the rolled back state. Unlike Rollback, any number
+// of heights can be rolled back. The blocks above the target height, and their
+// events in the given event sinks, can optionally be deleted.
+// Note that this function does not affect application state.
+func RollbackTo(bs BlockStore, ss Store, targetHeight int64, opts RollbackOptions) (State, error) {
+	currentState, err := ss.Load()
+	if err != nil {
+		return State{}, err
+	}
+	if currentState.IsEmpty() {
+		return State{}, errors.New("no state found")
+	}
+
+	storeHeight := bs.Height()
+	if storeHeight < currentState.LastBlockHeight {
+		return State{}, fmt.Errorf("statestore height (%d) is above blockstore height (%d)",
+			currentState.LastBlockHeight, storeHeight)
+	}
+	if base := bs.Base(); targetHeight < base || targetHeight < currentState.InitialHeight {
+		return State{}, fmt.Errorf("target height %d is below the blockstore base (%d) or the initial height (%d)",
+			targetHeight, base, currentState.InitialHeight)
+	}
+	if targetHeight > currentState.LastBlockHeight {
+		return State{}, fmt.Errorf("target height %d is above the statestore height (%d)",
+			targetHeight, currentState.LastBlockHeight)
+	}
+
+	for _, sink := range opts.EventSinks {
+		if _, ok := sink.(indexer.EventSinkDeleter); !ok {
+			return State{}, fmt.Errorf("event sink %s does not support deleting events", sink.Type())
+		}
+	}
+
+	// the state may already be at the target height if a previous rollback
+	// was interrupted while deleting blocks.
+	rolledBackState := currentState
+	if targetHeight < currentState.LastBlockHeight {
+		if rolledBackState, err = loadStateAt(bs, ss, currentState, targetHeight); err != nil {
+			return State{}, err
+		}
+	}
+	if opts.DryRun {
+		return rolledBackState, nil
+	}
+
+	for _, sink := range opts.EventSinks {
+		if err := sink.(indexer.EventSinkDeleter).DeleteFrom(targetHeight + 1); err != nil {
+			return State{}, fmt.Errorf("failed to delete events from event sink %s: %w", sink.Type(), err)
+		}
+	}
+
+	// record that the blocks above the target height are kept, so that they
+	// are replayed when the node starts. This is recorded before the state is
+	// saved, and only applies while the state is at the target height.
+	rollbackHeight := int64(0)
+	if !opts.DeleteBlocks && storeHeight > targetHeight+1 {
+		rollbackHeight = targetHeight
+	}
+	if err := ss.SaveRollbackHeight(rollbackHeight); err != nil {
+		return State{}, fmt.Errorf("failed to save rollback height: %w", err)
+	}
+
+	// persist the new state. This overrides the current one. NOTE: this will
+	// also persist the validator set and consensus params over the existing
+	// structures, but both should be the same
+	if err := ss.Save(rolledBackState); err != nil {
+		return State{}, fmt.Errorf("failed to save rolled back state: %w", err)
+	}
+
+	if opts.DeleteBlocks && storeHeight > targetHeight {
+		if _, err := bs.DeleteBlocksFrom(targetHeight + 1); err != nil {
+			return State{}, fmt.Errorf("failed to delete blocks: %w", err)
+		}
+	}
+
+	return rolledBackState, nil
+}
+
+// loadStateAt reconstructs the state after the block at the given height from
+// the block and state stores.
+func loadStateAt(bs BlockStore, ss Store, currentState State, height int64) (State, error) {
+	block := bs.LoadBlockMeta(height)
+	if block == nil {
+		return State{}, fmt.Errorf("block at height %d not found", height)
+	}
+	// the results of executing the block are recorded in the next header
+	nextBlock := bs.LoadBlockMeta(height + 1)
+	if nextBlock == nil {
+		return State{}, fmt.Errorf("block at height %d not found", height+1)
+	}
+
+	lastValidators, err := ss.LoadValidators(height)
+	if err != nil {
+		return State{}, err
+	}
+	validators, err := ss.LoadValidators(height + 1)
+	if err != nil {
+		return State{}, err
+	}
+	nextValidators, err := ss.LoadValidators(height + 2)
+	if err != nil {
+		return State{}, err
+	}
+	if !bytes.Equal(validators.Hash(), nextBlock.Header.ValidatorsHash) ||
+		!bytes.Equal(nextValidators.Hash(), nextBlock.Header.NextValidatorsHash) {
+		return State{}, fmt.Errorf("stored validators do not match the block at height %d", height+1)
+	}
+	valChangeHeight, err := ss.LoadValidatorsChangeHeight(height + 2)
+	if err != nil {
+		return State{}, err
+	}
+
+	params, err := ss.LoadConsensusParams(height + 1)
+	if err != nil {
+		return State{}, err
+	}
+	paramsChangeHeight, err := ss.LoadConsensusParamsChangeHeight(height + 1)
+	if err != nil {
+		return State{}, err
+	}
+
+	return State{
+		Version: Version{
+			Consensus: nextBlock.Header.Version,
+			Software:  version.TMVersion,
+		},
+		// immutable fields
+		ChainID:       currentState.ChainID,
+		InitialHeight: currentState.InitialHeight,
+
+		LastBlockHeight: height,
+		LastBlockID:     block.BlockID,
+		LastBlockTime:   block.Header.Time,
+
+		NextValidators:              nextValidators,
+		Validators:                  validators,
+		LastValidators:              lastValidators,
+		LastHeightValidatorsChanged: valChangeHeight,
+
+		ConsensusParams:                  params,
+		LastHeightConsensusParamsChanged: paramsChangeHeight,
+
+		LastResultsHash: nextBlock.Header.LastResultsHash,
+		AppHash:         nextBlock.Header.AppHash,
+	}, nil
+}
@@ -6,9 +6,20 @@ import (
 	"github.com/stretchr/testify/require"
 	dbm "github.com/tendermint/tm-db"
 
+	abciclient "github.com/tendermint/tendermint/abci/client"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/encoding"
+	memmock "github.com/tendermint/tendermint/internal/mempool/mock"
+	"github.com/tendermint/tendermint/internal/proxy"
 	"github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	indexermocks "github.com/tendermint/tendermint/internal/state/indexer/mocks"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/kv"
 	"github.com/tendermint/tendermint/internal/state/mocks"
+	"github.com/tendermint/tendermint/internal/store"
 	"github.com/tendermint/tendermint/internal/test/factory"
+	"github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/types"
 	"github.com/tendermint/tendermint/version"
 )
@@ -100,6 +111,121 @@ func TestRollbackDifferentStateHeight(t *testing.T) {
 	require.Equal(t, err.Error(), "statestore height (100) is not one below or equal to blockstore height (102)")
 }
 
+func TestRollbackTo(t *testing.T) {
+	const height = 8
+
+	app := &testApp{}
+	proxyApp := proxy.NewAppConns(abciclient.NewLocalCreator(app), proxy.NopMetrics())
+	require.NoError(t, proxyApp.Start())
+	defer proxyApp.Stop() //nolint:errcheck // ignore for tests
+
+	currentState, stateDB, privVals := makeState(2, 1)
+	stateStore := state.NewStore(stateDB)
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+	eventSink := kv.NewEventSink(dbm.NewMemDB(), nil)
+	blockExec := state.NewBlockExecutor(
+		stateStore,
+		log.TestingLogger(),
+		proxyApp.Consensus(),
+		memmock.Mempool{},
+		state.EmptyEvidencePool{},
+		blockStore,
+	)
+
+	// add a validator at height 3, which takes effect at height 5
+	newVal := ed25519.GenPrivKey()
+	newValPubKey, err := encoding.PubKeyToProto(newVal.PubKey())
+	require.NoError(t, err)
+	privVals[newVal.PubKey().Address().String()] = types.NewMockPVWithParams(newVal, false, false)
+
+	states := map[int64]state.State{}
+	lastCommit := types.NewCommit(0, 0, types.BlockID{}, nil)
+	for h := int64(1); h <= height; h++ {
+		app.ValidatorUpdates = nil
+		if h == 3 {
+			app.ValidatorUpdates = []abci.ValidatorUpdate{{PubKey: newValPubKey, Power: 10}}
+		}
+
+		proposerAddr := currentState.Validators.GetProposer().Address
+		block, partSet := currentState.MakeBlock(h, factory.MakeTenTxs(h), lastCommit, nil, proposerAddr)
+		blockID := types.BlockID{Hash: block.Hash(), PartSetHeader: partSet.Header()}
+
+		currentState, err = blockExec.ApplyBlock(currentState, blockID, block)
+		require.NoError(t, err)
+		// the block time is loaded from the store in canonical form
+		expectState := currentState.Copy()
+		expectState.LastBlockTime = expectState.LastBlockTime.Round(0).UTC()
+		states[h] = expectState
+
+		lastCommit, err = makeValidCommit(h, blockID, currentState.LastValidators, privVals)
+		require.NoError(t, err)
+		blockStore.SaveBlock(block, partSet, lastCommit)
+
+		require.NoError(t, eventSink.IndexBlockEvents(types.EventDataNewBlockHeader{Header: block.Header}))
+		require.NoError(t, eventSink.IndexTxEvents([]*abci.TxResult{{Height: h, Tx: block.Txs[0]}}))
+	}
+	require.EqualValues(t, 5, currentState.LastHeightValidatorsChanged)
+
+	loadState := func() state.State {
+		s, err := stateStore.Load()
+		require.NoError(t, err)
+		return s
+	}
+
+	// invalid target heights
+	_, err = state.RollbackTo(blockStore, stateStore, 0, state.RollbackOptions{})
+	require.Error(t, err)
+	_, err = state.RollbackTo(blockStore, stateStore, height+1, state.RollbackOptions{})
+	require.Error(t, err)
+
+	// event sinks must support deleting events
+	sink := &indexermocks.EventSink{}
+	sink.On("Type").Return(indexer.PSQL)
+	_, err = state.RollbackTo(blockStore, stateStore, 4, state.RollbackOptions{
+		EventSinks: []indexer.EventSink{sink},
+	})
+	require.Error(t, err)
+
+	// a dry run doesn't change anything
+	rolledBackState, err := state.RollbackTo(blockStore, stateStore, 4, state.RollbackOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, states[4], rolledBackState)
+	require.Equal(t, states[height], loadState())
+
+	// roll back across the validator change, keeping the blocks
+	rolledBackState, err = state.RollbackTo(blockStore, stateStore, 4, state.RollbackOptions{
+		EventSinks: []indexer.EventSink{eventSink},
+	})
+	require.NoError(t, err)
+	require.Equal(t, states[4], rolledBackState)
+	require.Equal(t, states[4], loadState())
+	require.EqualValues(t, height, blockStore.Height())
+	rollbackHeight, err := stateStore.LoadRollbackHeight()
+	require.NoError(t, err)
+	require.EqualValues(t, 4, rollbackHeight)
+
+	hasBlock, err := eventSink.HasBlock(4)
+	require.NoError(t, err)
+	require.True(t, hasBlock)
+	hasBlock, err = eventSink.HasBlock(5)
+	require.NoError(t, err)
+	require.False(t, hasBlock)
+	txResult, err := eventSink.GetTxByHash(blockStore.LoadBlock(5).Txs[0].Hash())
+	require.NoError(t, err)
+	require.Nil(t, txResult)
+
+	// roll back further, deleting the blocks
+	rolledBackState, err = state.RollbackTo(blockStore, stateStore, 2, state.RollbackOptions{DeleteBlocks: true})
+	require.NoError(t, err)
+	require.Equal(t, states[2], rolledBackState)
+	require.Equal(t, states[2], loadState())
+	require.EqualValues(t, 2, blockStore.Height())
+	require.EqualValues(t, 2, blockStore.LoadSeenCommit().Height)
+	rollbackHeight, err = stateStore.LoadRollbackHeight()
+	require.NoError(t, err)
+	require.Zero(t, rollbackHeight)
+}
+
 func setupStateStore(t *testing.T, height int64) state.Store {
 	stateStore := state.NewStore(dbm.NewMemDB())
 	valSet, _ := factory.RandValidatorSet(5, 10)
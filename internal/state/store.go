@@ -2,14 +2,18 @@ package state
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 
 	"github.com/gogo/protobuf/proto"
 	"github.com/google/orderedcode"
 	dbm "github.com/tendermint/tm-db"
 
 	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/libs/compress"
 	tmmath "github.com/tendermint/tendermint/libs/math"
 	tmos "github.com/tendermint/tendermint/libs/os"
 	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
@@ -28,11 +32,15 @@ const (
 //------------------------------------------------------------------------
 
 const (
-	// prefixes are unique across all tm db's
+	// prefixes are unique across all tm db's: 0-4 are used by the block
+	// store, 9-10 by the evidence pool and 11-12 by the light client store,
+	// so new prefixes of the state store follow the highest of them
 	prefixValidators      = int64(5)
 	prefixConsensusParams = int64(6)
 	prefixABCIResponses   = int64(7)
 	prefixState           = int64(8)
+	prefixRollback        = int64(13)
+	prefixAppRetainHeight = int64(14)
 )
 
 func encodeKey(prefix int64, height int64) []byte {
@@ -55,8 +63,9 @@ func abciResponsesKey(height int64) []byte {
 	return encodeKey(prefixABCIResponses, height)
 }
 
-// stateKey should never change after being set in init()
-var stateKey []byte
+// stateKey, rollbackKey and appRetainHeightKey should never change after
+// being set in init()
+var stateKey, rollbackKey, appRetainHeightKey []byte
 
 func init() {
 	var err error
@@ -64,6 +73,14 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+	rollbackKey, err = orderedcode.Append(nil, prefixRollback)
+	if err != nil {
+		panic(err)
+	}
+	appRetainHeightKey, err = orderedcode.Append(nil, prefixAppRetainHeight)
+	if err != nil {
+		panic(err)
+	}
 }
 
 //----------------------
@@ -83,12 +100,29 @@ type Store interface {
 	LoadABCIResponses(int64) (*tmstate.ABCIResponses, error)
 	// LoadConsensusParams loads the consensus params for a given height
 	LoadConsensusParams(int64) (types.ConsensusParams, error)
+	// LoadValidatorsChangeHeight loads the height at which the validator set
+	// for a given height last changed
+	LoadValidatorsChangeHeight(int64) (int64, error)
+	// LoadConsensusParamsChangeHeight loads the height at which the consensus
+	// params for a given height last changed
+	LoadConsensusParamsChangeHeight(int64) (int64, error)
 	// Save overwrites the previous state with the updated one
 	Save(State) error
 	// SaveABCIResponses saves ABCIResponses for a given height
 	SaveABCIResponses(int64, *tmstate.ABCIResponses) error
 	// SaveValidatorSet saves the validator set at a given height
 	SaveValidatorSets(int64, int64, *types.ValidatorSet) error
+	// LoadRollbackHeight loads the height recorded by SaveRollbackHeight, or 0
+	LoadRollbackHeight() (int64, error)
+	// SaveRollbackHeight records the height the state was rolled back to
+	// while keeping the blocks above it, which are replayed on start (0 clears it)
+	SaveRollbackHeight(int64) error
+	// LoadApplicationRetainHeight loads the height recorded by
+	// SaveApplicationRetainHeight, or 0
+	LoadApplicationRetainHeight() (int64, error)
+	// SaveApplicationRetainHeight records the latest retain height requested
+	// by the application (0 clears it)
+	SaveApplicationRetainHeight(int64) error
 	// Bootstrap is used for bootstrapping state when not starting from a initial height.
 	Bootstrap(State) error
 	// PruneStates takes the height from which to prune up to (exclusive)
@@ -97,14 +131,43 @@ type Store interface {
 
 // dbStore wraps a db (github.com/tendermint/tm-db)
 type dbStore struct {
-	db dbm.DB
+	db         dbm.DB
+	compressor *compress.Compressor
 }
 
 var _ Store = (*dbStore)(nil)
 
+// StoreOption sets an optional parameter on the dbStore.
+type StoreOption func(*dbStore)
+
+// StoreWithCompressor sets the compressor used for new ABCI responses.
+func StoreWithCompressor(compressor *compress.Compressor) StoreOption {
+	return func(store *dbStore) {
+		store.compressor = compressor
+	}
+}
+
+// corruptedDataError is returned when data read from the database can't be
+// decoded, because it has been corrupted or its spec has changed.
+type corruptedDataError struct {
+	err error
+}
+
+func (e corruptedDataError) Error() string {
+	return fmt.Sprintf("data has been corrupted or its spec has changed: %v", e.err)
+}
+
+func (e corruptedDataError) Unwrap() error {
+	return e.err
+}
+
 // NewStore creates the dbStore of the state pkg.
-func NewStore(db dbm.DB) Store {
-	return dbStore{db}
+func NewStore(db dbm.DB, options ...StoreOption) Store {
+	store := dbStore{db: db}
+	for _, option := range options {
+		option(&store)
+	}
+	return store
 }
 
 // LoadState loads the State from the database.
@@ -411,6 +474,19 @@ func ABCIResponsesResultsHash(ar *tmstate.ABCIResponses) []byte {
 // before we called s.Save(). It can also be used to produce Merkle proofs of
 // the result of txs.
 func (store dbStore) LoadABCIResponses(height int64) (*tmstate.ABCIResponses, error) {
+	abciResponses, err := store.loadABCIResponses(height)
+	var corruptErr corruptedDataError
+	if errors.As(err, &corruptErr) {
+		// DATA HAS BEEN CORRUPTED OR THE SPEC HAS CHANGED
+		tmos.Exit(fmt.Sprintf(`LoadABCIResponses: Data has been corrupted or its spec has
+                changed: %v\n`, corruptErr.err))
+	}
+	return abciResponses, err
+}
+
+// loadABCIResponses is like LoadABCIResponses, but returns a
+// corruptedDataError if the ABCI responses can't be decoded.
+func (store dbStore) loadABCIResponses(height int64) (*tmstate.ABCIResponses, error) {
 	buf, err := store.db.Get(abciResponsesKey(height))
 	if err != nil {
 		return nil, err
@@ -421,17 +497,70 @@ func (store dbStore) LoadABCIResponses(height int64) (*tmstate.ABCIResponses, er
 	}
 
 	abciResponses := new(tmstate.ABCIResponses)
-	err = abciResponses.Unmarshal(buf)
+	buf, err = compress.Decompress(buf)
+	if err == nil {
+		err = abciResponses.Unmarshal(buf)
+	}
 	if err != nil {
-		// DATA HAS BEEN CORRUPTED OR THE SPEC HAS CHANGED
-		tmos.Exit(fmt.Sprintf(`LoadABCIResponses: Data has been corrupted or its spec has
-                changed: %v\n`, err))
+		return nil, corruptedDataError{err}
 	}
 	// TODO: ensure that buf is completely read.
 
 	return abciResponses, nil
 }
 
+// LoadRollbackHeight loads the height the state was rolled back to by
+// RollbackTo while keeping the blocks above it, or 0 if there is none.
+func (store dbStore) LoadRollbackHeight() (int64, error) {
+	return store.loadHeight(rollbackKey)
+}
+
+// SaveRollbackHeight records the height the state was rolled back to while
+// keeping the blocks above it. The handshake only replays blocks beyond the
+// next height when the state is at this height. A height of 0 clears it.
+func (store dbStore) SaveRollbackHeight(height int64) error {
+	return store.saveHeight(rollbackKey, height)
+}
+
+// LoadApplicationRetainHeight loads the latest retain height requested by the
+// application, or 0 if there is none.
+func (store dbStore) LoadApplicationRetainHeight() (int64, error) {
+	return store.loadHeight(appRetainHeightKey)
+}
+
+// SaveApplicationRetainHeight records the latest retain height requested by
+// the application, so that the pruner keeps honoring it after a restart. A
+// height of 0 clears it.
+func (store dbStore) SaveApplicationRetainHeight(height int64) error {
+	return store.saveHeight(appRetainHeightKey, height)
+}
+
+// loadHeight loads the height stored under the key, or 0 if there is none.
+func (store dbStore) loadHeight(key []byte) (int64, error) {
+	buf, err := store.db.Get(key)
+	if err != nil {
+		return 0, err
+	}
+	if len(buf) == 0 {
+		return 0, nil
+	}
+	height, n := binary.Varint(buf)
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid height %X", buf)
+	}
+	return height, nil
+}
+
+// saveHeight stores the height under the key, or deletes the key if the
+// height is 0.
+func (store dbStore) saveHeight(key []byte, height int64) error {
+	if height == 0 {
+		return store.db.DeleteSync(key)
+	}
+	buf := make([]byte, binary.MaxVarintLen64)
+	return store.db.SetSync(key, buf[:binary.PutVarint(buf, height)])
+}
+
 // SaveABCIResponses persists the ABCIResponses to the database.
 // This is useful in case we crash after app.Commit and before s.Save().
 // Responses are indexed by height so they can also be loaded later to produce
@@ -457,10 +586,23 @@ func (store dbStore) saveABCIResponses(height int64, abciResponses *tmstate.ABCI
 	if err != nil {
 		return err
 	}
+	bz, err = store.compressor.Compress(bz)
+	if err != nil {
+		return err
+	}
 
 	return store.db.SetSync(abciResponsesKey(height), bz)
 }
 
+// RecompressABCIResponses rewrites all ABCI responses stored in the state
+// database with the given compressor, skipping responses that are already
+// stored with its algorithm, and returns the number of responses rewritten.
+// It can be interrupted by cancelling the context and resumed by calling it
+// again.
+func RecompressABCIResponses(ctx context.Context, db dbm.DB, compressor *compress.Compressor) (uint64, error) {
+	return compress.Recompress(ctx, db, abciResponsesKey(0), abciResponsesKey(math.MaxInt64), compressor)
+}
+
 // SaveValidatorSets is used to save the validator set over multiple heights.
 // It is exposed so that a backfill operation during state sync can populate
 // the store with the necessary amount of validator sets to verify any evidence
@@ -484,14 +626,29 @@ func (store dbStore) SaveValidatorSets(lowerHeight, upperHeight int64, vals *typ
 // LoadValidators loads the ValidatorSet for a given height.
 // Returns ErrNoValSetForHeight if the validator set can't be found for this height.
 func (store dbStore) LoadValidators(height int64) (*types.ValidatorSet, error) {
+	vals, err := store.loadValidators(height)
+	var corruptErr corruptedDataError
+	if errors.As(err, &corruptErr) {
+		// DATA HAS BEEN CORRUPTED OR THE SPEC HAS CHANGED
+		tmos.Exit(fmt.Sprintf(`LoadValidators: Data has been corrupted or its spec has changed:
+                %v\n`, corruptErr.err))
+	}
+	return vals, err
+}
 
-	valInfo, err := loadValidatorsInfo(store.db, height)
+// loadValidators is like LoadValidators, but returns a corruptedDataError if
+// a validator set can't be decoded.
+func (store dbStore) loadValidators(height int64) (*types.ValidatorSet, error) {
+	valInfo, err := readValidatorsInfo(store.db, height)
 	if err != nil {
+		if errors.As(err, &corruptedDataError{}) {
+			return nil, err
+		}
 		return nil, ErrNoValSetForHeight{height}
 	}
 	if valInfo.ValidatorSet == nil {
 		lastStoredHeight := lastStoredHeightFor(height, valInfo.LastHeightChanged)
-		valInfo2, err := loadValidatorsInfo(store.db, lastStoredHeight)
+		valInfo2, err := readValidatorsInfo(store.db, lastStoredHeight)
 		if err != nil || valInfo2.ValidatorSet == nil {
 			return nil,
 				fmt.Errorf("couldn't find validators at height %d (height %d was originally requested): %w",
@@ -524,6 +681,17 @@ func (store dbStore) LoadValidators(height int64) (*types.ValidatorSet, error) {
 	return vip, nil
 }
 
+// LoadValidatorsChangeHeight loads the height at which the validator set for
+// the given height last changed, which is recorded along with every validator
+// set.
+func (store dbStore) LoadValidatorsChangeHeight(height int64) (int64, error) {
+	valInfo, err := loadValidatorsInfo(store.db, height)
+	if err != nil {
+		return 0, ErrNoValSetForHeight{height}
+	}
+	return valInfo.LastHeightChanged, nil
+}
+
 func lastStoredHeightFor(height, lastHeightChanged int64) int64 {
 	checkpointHeight := height - height%valSetCheckpointInterval
 	return tmmath.MaxInt64(checkpointHeight, lastHeightChanged)
@@ -531,6 +699,19 @@ func lastStoredHeightFor(height, lastHeightChanged int64) int64 {
 
 // CONTRACT: Returned ValidatorsInfo can be mutated.
 func loadValidatorsInfo(db dbm.DB, height int64) (*tmstate.ValidatorsInfo, error) {
+	v, err := readValidatorsInfo(db, height)
+	var corruptErr corruptedDataError
+	if errors.As(err, &corruptErr) {
+		// DATA HAS BEEN CORRUPTED OR THE SPEC HAS CHANGED
+		tmos.Exit(fmt.Sprintf(`LoadValidators: Data has been corrupted or its spec has changed:
+                %v\n`, corruptErr.err))
+	}
+	return v, err
+}
+
+// readValidatorsInfo is like loadValidatorsInfo, but returns a
+// corruptedDataError if the validators info can't be decoded.
+func readValidatorsInfo(db dbm.DB, height int64) (*tmstate.ValidatorsInfo, error) {
 	buf, err := db.Get(validatorsKey(height))
 	if err != nil {
 		return nil, err
@@ -543,9 +724,7 @@ func loadValidatorsInfo(db dbm.DB, height int64) (*tmstate.ValidatorsInfo, error
 	v := new(tmstate.ValidatorsInfo)
 	err = v.Unmarshal(buf)
 	if err != nil {
-		// DATA HAS BEEN CORRUPTED OR THE SPEC HAS CHANGED
-		tmos.Exit(fmt.Sprintf(`LoadValidators: Data has been corrupted or its spec has changed:
-                %v\n`, err))
+		return nil, corruptedDataError{err}
 	}
 	// TODO: ensure that buf is completely read.
 
@@ -620,6 +799,16 @@ func (store dbStore) LoadConsensusParams(height int64) (types.ConsensusParams, e
 	return types.ConsensusParamsFromProto(paramsInfo.ConsensusParams), nil
 }
 
+// LoadConsensusParamsChangeHeight loads the height at which the consensus
+// params for the given height last changed.
+func (store dbStore) LoadConsensusParamsChangeHeight(height int64) (int64, error) {
+	paramsInfo, err := store.loadConsensusParamsInfo(height)
+	if err != nil {
+		return 0, fmt.Errorf("could not find consensus params for height #%d: %w", height, err)
+	}
+	return paramsInfo.LastHeightChanged, nil
+}
+
 func (store dbStore) loadConsensusParamsInfo(height int64) (*tmstate.ConsensusParamsInfo, error) {
 	buf, err := store.db.Get(consensusParamsKey(height))
 	if err != nil {
@@ -1,6 +1,7 @@
 package state_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"testing"
@@ -13,6 +14,7 @@ import (
 	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/internal/libs/compress"
 	sm "github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/internal/test/factory"
 	tmrand "github.com/tendermint/tendermint/libs/rand"
@@ -286,6 +288,52 @@ func TestPruneStates(t *testing.T) {
 	}
 }
 
+func TestABCIResponsesCompression(t *testing.T) {
+	ctx := context.Background()
+	db := dbm.NewMemDB()
+	compressor, err := compress.NewCompressor("gzip", 0)
+	require.NoError(t, err)
+
+	makeResponses := func(height int64) *tmstate.ABCIResponses {
+		return &tmstate.ABCIResponses{
+			BeginBlock: &abci.ResponseBeginBlock{},
+			DeliverTxs: []*abci.ResponseDeliverTx{
+				{Code: 0, Data: []byte(fmt.Sprintf("height %d", height)), Log: "ok"},
+			},
+			EndBlock: &abci.ResponseEndBlock{},
+		}
+	}
+
+	// heights 1-5 are saved uncompressed, heights 6-10 compressed
+	for h := int64(1); h <= 10; h++ {
+		stateStore := sm.NewStore(db)
+		if h > 5 {
+			stateStore = sm.NewStore(db, sm.StoreWithCompressor(compressor))
+		}
+		require.NoError(t, stateStore.SaveABCIResponses(h, makeResponses(h)))
+	}
+
+	checkResponses := func() {
+		stateStore := sm.NewStore(db)
+		for h := int64(1); h <= 10; h++ {
+			responses, err := stateStore.LoadABCIResponses(h)
+			require.NoError(t, err)
+			require.Equal(t, makeResponses(h), responses)
+		}
+	}
+	checkResponses()
+
+	rewritten, err := sm.RecompressABCIResponses(ctx, db, compressor)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, rewritten)
+	checkResponses()
+
+	rewritten, err = sm.RecompressABCIResponses(ctx, db, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 10, rewritten)
+	checkResponses()
+}
+
 func TestABCIResponsesResultsHash(t *testing.T) {
 	responses := &tmstate.ABCIResponses{
 		BeginBlock: &abci.ResponseBeginBlock{},
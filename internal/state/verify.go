@@ -0,0 +1,201 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gogo/protobuf/proto"
+	dbm "github.com/tendermint/tm-db"
+
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// StoreError describes an integrity problem found at a height of the block or
+// state store by VerifyStore.
+type StoreError struct {
+	Height int64
+	Err    error
+}
+
+func (e StoreError) Error() string {
+	return fmt.Sprintf("height %d: %v", e.Height, e.Err)
+}
+
+func (e StoreError) Unwrap() error {
+	return e.Err
+}
+
+// VerifyStore walks the block store from its base to its height and checks
+// that:
+//
+//   - each block's parts reassemble to the block hash in its BlockMeta,
+//   - each block's LastBlockID links to the previous block,
+//   - each block's commit verifies against the validator set loaded from the
+//     state store, and
+//   - the stored ABCI responses of each block hash to the LastResultsHash of
+//     the next block's header.
+//
+// Every problem found is passed to report, and verification continues with
+// the next check or height. An error is only returned if verification could
+// not be carried out at all, e.g. because the context was canceled. The state
+// is read directly from stateDB, so that data which can't be decoded is
+// reported rather than exiting the process as the state Store does.
+//
+// Blocks at the base of the store for which no parts are stored, as written
+// by state sync, are treated as header-only and their parts and ABCI responses
+// are not checked.
+func VerifyStore(ctx context.Context, bs BlockStore, stateDB dbm.DB, report func(StoreError)) error {
+	ss := dbStore{db: stateDB}
+	base, height := bs.Base(), bs.Height()
+	if base == 0 || height == 0 {
+		return errors.New("block store is empty")
+	}
+
+	var prevMeta *types.BlockMeta
+	for h := base; h <= height; h++ {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("verification terminated at height %d: %w", h, err)
+		}
+		prevMeta = verifyHeight(bs, ss, h, base, height, prevMeta, func(err error) {
+			report(StoreError{Height: h, Err: err})
+		})
+	}
+	return nil
+}
+
+// verifyHeight verifies the block at the given height, reporting all problems
+// found. It returns the block's meta, or nil if it could not be loaded.
+func verifyHeight(
+	bs BlockStore,
+	ss dbStore,
+	height, base, last int64,
+	prevMeta *types.BlockMeta,
+	report func(error),
+) (meta *types.BlockMeta) {
+	// The block store panics when encountering data it can't decode, which
+	// is exactly what we're looking for here.
+	defer func() {
+		if r := recover(); r != nil {
+			report(fmt.Errorf("failed to load data: %v", r))
+		}
+	}()
+
+	meta = bs.LoadBlockMeta(height)
+	if meta == nil {
+		report(errors.New("block meta not found"))
+		return nil
+	}
+	if meta.Header.Height != height {
+		report(fmt.Errorf("block meta has height %d", meta.Header.Height))
+	}
+	if hash := meta.Header.Hash(); !bytes.Equal(hash, meta.BlockID.Hash) {
+		report(fmt.Errorf("header hash %X does not match block ID hash %X", hash, meta.BlockID.Hash))
+	}
+
+	headerOnly := height == base && bs.LoadBlockPart(height, 0) == nil
+	if !headerOnly {
+		if err := verifyBlockParts(bs, meta); err != nil {
+			report(err)
+		}
+	}
+
+	if prevMeta != nil && !meta.Header.LastBlockID.Equals(prevMeta.BlockID) {
+		report(fmt.Errorf("last block ID %v does not match block ID %v of height %d",
+			meta.Header.LastBlockID, prevMeta.BlockID, height-1))
+	}
+
+	if err := verifyCommit(bs, ss, meta, height == last); err != nil {
+		report(err)
+	}
+
+	if height < last && !headerOnly {
+		if err := verifyABCIResponses(bs, ss, height); err != nil {
+			report(err)
+		}
+	}
+	return meta
+}
+
+// verifyBlockParts checks that the stored parts of a block reassemble to the
+// block referenced by the block meta.
+func verifyBlockParts(bs BlockStore, meta *types.BlockMeta) error {
+	height := meta.Header.Height
+	partSet := types.NewPartSetFromHeader(meta.BlockID.PartSetHeader)
+	for i := 0; i < int(meta.BlockID.PartSetHeader.Total); i++ {
+		part := bs.LoadBlockPart(height, i)
+		if part == nil {
+			return fmt.Errorf("block part %d not found", i)
+		}
+		if _, err := partSet.AddPart(part); err != nil {
+			return fmt.Errorf("invalid block part %d: %w", i, err)
+		}
+	}
+	if !partSet.IsComplete() {
+		return errors.New("block parts are incomplete")
+	}
+
+	bz, err := io.ReadAll(partSet.GetReader())
+	if err != nil {
+		return fmt.Errorf("failed to read block parts: %w", err)
+	}
+	pbb := new(tmproto.Block)
+	if err := proto.Unmarshal(bz, pbb); err != nil {
+		return fmt.Errorf("failed to decode block: %w", err)
+	}
+	block, err := types.BlockFromProto(pbb)
+	if err != nil {
+		return fmt.Errorf("invalid block: %w", err)
+	}
+	if hash := block.Hash(); !bytes.Equal(hash, meta.BlockID.Hash) {
+		return fmt.Errorf("block hash %X does not match block ID hash %X", hash, meta.BlockID.Hash)
+	}
+	return nil
+}
+
+// verifyCommit checks that the commit for the block verifies against the
+// validator set at its height. For the last block the seen commit is used,
+// since the canonical commit is only stored with the next block.
+func verifyCommit(bs BlockStore, ss dbStore, meta *types.BlockMeta, last bool) error {
+	height := meta.Header.Height
+	commit := bs.LoadBlockCommit(height)
+	if commit == nil && last {
+		if seenCommit := bs.LoadSeenCommit(); seenCommit != nil && seenCommit.Height == height {
+			commit = seenCommit
+		}
+	}
+	if commit == nil {
+		return errors.New("commit not found")
+	}
+
+	vals, err := ss.loadValidators(height)
+	if err != nil {
+		return fmt.Errorf("failed to load validators: %w", err)
+	}
+	if err := vals.VerifyCommit(meta.Header.ChainID, meta.BlockID, height, commit); err != nil {
+		return fmt.Errorf("invalid commit: %w", err)
+	}
+	return nil
+}
+
+// verifyABCIResponses checks that the stored ABCI responses for the given
+// height hash to the LastResultsHash of the next block.
+func verifyABCIResponses(bs BlockStore, ss dbStore, height int64) error {
+	nextMeta := bs.LoadBlockMeta(height + 1)
+	if nextMeta == nil {
+		// reported when verifying the next height
+		return nil
+	}
+	abciResponses, err := ss.loadABCIResponses(height)
+	if err != nil {
+		return fmt.Errorf("failed to load ABCI responses: %w", err)
+	}
+	if hash := ABCIResponsesResultsHash(abciResponses); !bytes.Equal(hash, nextMeta.Header.LastResultsHash) {
+		return fmt.Errorf("ABCI responses hash %X does not match last results hash %X of height %d",
+			hash, nextMeta.Header.LastResultsHash, height+1)
+	}
+	return nil
+}
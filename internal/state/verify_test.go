@@ -0,0 +1,114 @@
+package state_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	memmock "github.com/tendermint/tendermint/internal/mempool/mock"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/store"
+	"github.com/tendermint/tendermint/internal/test/factory"
+	"github.com/tendermint/tendermint/libs/log"
+	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+// corruptBlockStore is a block store returning corrupted parts and commits
+// at the given heights.
+type corruptBlockStore struct {
+	*store.BlockStore
+	partHeight   int64
+	commitHeight int64
+}
+
+func (bs corruptBlockStore) LoadBlockPart(height int64, index int) *types.Part {
+	part := bs.BlockStore.LoadBlockPart(height, index)
+	if part != nil && height == bs.partHeight {
+		part.Bytes = append([]byte{0x01}, part.Bytes...)
+	}
+	return part
+}
+
+func (bs corruptBlockStore) LoadBlockCommit(height int64) *types.Commit {
+	commit := bs.BlockStore.LoadBlockCommit(height)
+	if commit != nil && height == bs.commitHeight {
+		commit.Signatures[0].Signature = make([]byte, 64)
+	}
+	return commit
+}
+
+func TestVerifyStore(t *testing.T) {
+	const height = 5
+
+	proxyApp := newTestApp()
+	require.NoError(t, proxyApp.Start())
+	defer proxyApp.Stop() //nolint:errcheck // ignore for tests
+
+	state, stateDB, privVals := makeState(3, 1)
+	stateStore := sm.NewStore(stateDB)
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+	blockExec := sm.NewBlockExecutor(
+		stateStore,
+		log.TestingLogger(),
+		proxyApp.Consensus(),
+		memmock.Mempool{},
+		sm.EmptyEvidencePool{},
+		blockStore,
+	)
+
+	lastCommit := types.NewCommit(0, 0, types.BlockID{}, nil)
+	for h := int64(1); h <= height; h++ {
+		proposerAddr := state.Validators.GetProposer().Address
+		block, partSet := state.MakeBlock(h, factory.MakeTenTxs(h), lastCommit, nil, proposerAddr)
+		blockID := types.BlockID{Hash: block.Hash(), PartSetHeader: partSet.Header()}
+
+		var err error
+		state, err = blockExec.ApplyBlock(state, blockID, block)
+		require.NoError(t, err)
+
+		lastCommit, err = makeValidCommit(h, blockID, state.LastValidators, privVals)
+		require.NoError(t, err)
+		blockStore.SaveBlock(block, partSet, lastCommit)
+	}
+
+	verify := func(bs sm.BlockStore) map[int64]int {
+		problems := map[int64]int{}
+		err := sm.VerifyStore(context.Background(), bs, stateDB, func(err sm.StoreError) {
+			problems[err.Height]++
+		})
+		require.NoError(t, err)
+		return problems
+	}
+
+	require.Empty(t, verify(blockStore))
+
+	// Corrupt the parts of block 2 and the commit for block 3.
+	require.Equal(t, map[int64]int{2: 1, 3: 1}, verify(corruptBlockStore{
+		BlockStore:   blockStore,
+		partHeight:   2,
+		commitHeight: 3,
+	}))
+
+	// Store ABCI responses for height 4 which don't match block 5.
+	require.NoError(t, stateStore.SaveABCIResponses(4, &tmstate.ABCIResponses{
+		DeliverTxs: []*abci.ResponseDeliverTx{{Code: 1}},
+		EndBlock:   &abci.ResponseEndBlock{},
+		BeginBlock: &abci.ResponseBeginBlock{},
+	}))
+	require.Equal(t, map[int64]int{4: 1}, verify(blockStore))
+
+	// Data which can't be decoded is reported rather than exiting.
+	require.NoError(t, stateDB.Set(sm.ABCIResponsesKey(2), []byte{0xff, 0xff, 0xff}))
+	require.NoError(t, stateDB.Set(sm.ValidatorsKey(3), []byte{0xff, 0xff, 0xff}))
+	require.Equal(t, map[int64]int{2: 1, 3: 1, 4: 1}, verify(blockStore))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Error(t, sm.VerifyStore(ctx, blockStore, stateDB, func(sm.StoreError) {}))
+	require.Error(t, sm.VerifyStore(context.Background(), store.NewBlockStore(dbm.NewMemDB()), stateDB,
+		func(sm.StoreError) {}))
+}
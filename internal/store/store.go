@@ -2,6 +2,7 @@ package store
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strconv"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/google/orderedcode"
 	dbm "github.com/tendermint/tm-db"
 
+	"github.com/tendermint/tendermint/internal/libs/compress"
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 	"github.com/tendermint/tendermint/types"
 )
@@ -27,17 +29,35 @@ the Commit data outside the Block. (TODO)
 
 The store can be assumed to contain all contiguous blocks between base and height (inclusive).
 
+Block parts can optionally be compressed, see WithCompressor. Compressed and
+uncompressed parts can be read regardless of the compressor in use.
+
 // NOTE: BlockStore methods will panic if they encounter errors
 // deserializing loaded data, indicating probable corruption on disk.
 */
 type BlockStore struct {
-	db dbm.DB
+	db         dbm.DB
+	compressor *compress.Compressor
+}
+
+// BlockStoreOption sets an optional parameter on the BlockStore.
+type BlockStoreOption func(*BlockStore)
+
+// WithCompressor sets the compressor used for new block parts.
+func WithCompressor(compressor *compress.Compressor) BlockStoreOption {
+	return func(bs *BlockStore) {
+		bs.compressor = compressor
+	}
 }
 
 // NewBlockStore returns a new BlockStore with the given DB,
 // initialized to the last height that was committed to the DB.
-func NewBlockStore(db dbm.DB) *BlockStore {
-	return &BlockStore{db}
+func NewBlockStore(db dbm.DB, options ...BlockStoreOption) *BlockStore {
+	bs := &BlockStore{db: db}
+	for _, option := range options {
+		option(bs)
+	}
+	return bs
 }
 
 // Base returns the first known contiguous block height, or 0 for empty block stores.
@@ -195,6 +215,10 @@ func (bs *BlockStore) LoadBlockPart(height int64, index int) *types.Part {
 		return nil
 	}
 
+	bz, err = compress.Decompress(bz)
+	if err != nil {
+		panic(fmt.Errorf("decompressing block part failed: %w", err))
+	}
 	err = proto.Unmarshal(bz, pbpart)
 	if err != nil {
 		panic(fmt.Errorf("unmarshal to tmproto.Part failed: %w", err))
@@ -293,28 +317,6 @@ func (bs *BlockStore) PruneBlocks(height int64) (uint64, error) {
 		return 0, fmt.Errorf("height must be equal to or less than the latest height %d", bs.Height())
 	}
 
-	// when removing the block meta, use the hash to remove the hash key at the same time
-	removeBlockHash := func(key, value []byte, batch dbm.Batch) error {
-		// unmarshal block meta
-		var pbbm = new(tmproto.BlockMeta)
-		err := proto.Unmarshal(value, pbbm)
-		if err != nil {
-			return fmt.Errorf("unmarshal to tmproto.BlockMeta: %w", err)
-		}
-
-		blockMeta, err := types.BlockMetaFromProto(pbbm)
-		if err != nil {
-			return fmt.Errorf("error from proto blockMeta: %w", err)
-		}
-
-		// delete the hash key corresponding to the block meta's hash
-		if err := batch.Delete(blockHashKey(blockMeta.BlockID.Hash)); err != nil {
-			return fmt.Errorf("failed to delete hash key: %X: %w", blockHashKey(blockMeta.BlockID.Hash), err)
-		}
-
-		return nil
-	}
-
 	// remove block meta first as this is used to indicate whether the block exists.
 	// For this reason, we also use ony block meta as a measure of the amount of blocks pruned
 	pruned, err := bs.pruneRange(blockMetaKey(0), blockMetaKey(height), removeBlockHash)
@@ -333,6 +335,76 @@ func (bs *BlockStore) PruneBlocks(height int64) (uint64, error) {
 	return pruned, nil
 }
 
+// DeleteBlocksFrom removes all blocks at and above the given height, and
+// returns the number of blocks deleted. The commit for the block below the
+// height becomes the seen commit, so that the block store is consistent with
+// a state rolled back to height - 1.
+func (bs *BlockStore) DeleteBlocksFrom(height int64) (uint64, error) {
+	if base := bs.Base(); height <= base {
+		return 0, fmt.Errorf("height must be greater than the base height %d", base)
+	}
+
+	if height > bs.Height() {
+		return 0, fmt.Errorf("height must be equal to or less than the latest height %d", bs.Height())
+	}
+
+	// the commit for the block below is stored with the block at height, so it
+	// must be saved as the seen commit before removing the block.
+	commit := bs.LoadBlockCommit(height - 1)
+	if commit == nil {
+		return 0, fmt.Errorf("commit for height %d not found", height-1)
+	}
+	if err := bs.SaveSeenCommit(height-1, commit); err != nil {
+		return 0, err
+	}
+
+	deleted, err := bs.pruneRange(blockMetaKey(height), blockMetaKey(maxHeight), removeBlockHash)
+	if err != nil {
+		return deleted, err
+	}
+
+	if _, err := bs.pruneRange(blockPartKey(height, 0), blockPartKey(maxHeight, 0), nil); err != nil {
+		return deleted, err
+	}
+
+	if _, err := bs.pruneRange(blockCommitKey(height), blockCommitKey(maxHeight), nil); err != nil {
+		return deleted, err
+	}
+
+	return deleted, nil
+}
+
+// removeBlockHash is a pre-deletion hook for block meta keys, which uses the
+// hash in the block meta to remove the hash key at the same time.
+func removeBlockHash(key, value []byte, batch dbm.Batch) error {
+	// unmarshal block meta
+	var pbbm = new(tmproto.BlockMeta)
+	err := proto.Unmarshal(value, pbbm)
+	if err != nil {
+		return fmt.Errorf("unmarshal to tmproto.BlockMeta: %w", err)
+	}
+
+	blockMeta, err := types.BlockMetaFromProto(pbbm)
+	if err != nil {
+		return fmt.Errorf("error from proto blockMeta: %w", err)
+	}
+
+	// delete the hash key corresponding to the block meta's hash
+	if err := batch.Delete(blockHashKey(blockMeta.BlockID.Hash)); err != nil {
+		return fmt.Errorf("failed to delete hash key: %X: %w", blockHashKey(blockMeta.BlockID.Hash), err)
+	}
+
+	return nil
+}
+
+// RecompressBlockParts rewrites all stored block parts with the block store's
+// compressor, skipping parts that are already stored with its algorithm, and
+// returns the number of parts rewritten. It can be interrupted by cancelling
+// the context and resumed by calling it again.
+func (bs *BlockStore) RecompressBlockParts(ctx context.Context) (uint64, error) {
+	return compress.Recompress(ctx, bs.db, blockPartKey(0, 0), blockPartKey(maxHeight, 0), bs.compressor)
+}
+
 // pruneRange is a generic function for deleting a range of values based on the lowest
 // height up to but excluding retainHeight. For each key/value pair, an optional hook can be
 // executed before the deletion itself is made. pruneRange will use batch delete to delete
@@ -494,7 +566,10 @@ func (bs *BlockStore) saveBlockPart(height int64, index int, part *types.Part, b
 	if err != nil {
 		panic(fmt.Errorf("unable to make part into proto: %w", err))
 	}
-	partBytes := mustEncode(pbp)
+	partBytes, err := bs.compressor.Compress(mustEncode(pbp))
+	if err != nil {
+		panic(fmt.Errorf("unable to compress part: %w", err))
+	}
 	if err := batch.Set(blockPartKey(height, index), partBytes); err != nil {
 		panic(err)
 	}
@@ -564,6 +639,9 @@ const (
 	prefixBlockHash   = int64(4)
 )
 
+// maxHeight is the upper bound used for key ranges spanning all heights.
+const maxHeight = 1<<63 - 1
+
 func blockMetaKey(height int64) []byte {
 	key, err := orderedcode.Append(nil, prefixBlockMeta, height)
 	if err != nil {
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"runtime/debug"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/internal/libs/compress"
 	sm "github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/internal/state/test/factory"
 	"github.com/tendermint/tendermint/libs/log"
@@ -433,6 +435,111 @@ func TestPruneBlocks(t *testing.T) {
 	assert.Nil(t, bs.LoadBlock(1501))
 }
 
+func TestDeleteBlocksFrom(t *testing.T) {
+	cfg, err := config.ResetTestRoot("blockchain_reactor_test")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(cfg.RootDir)
+	state, err := sm.MakeGenesisStateFromFile(cfg.GenesisFile())
+	require.NoError(t, err)
+	bs := NewBlockStore(dbm.NewMemDB())
+
+	// make more than 1000 blocks, to test batch deletions
+	for h := int64(1); h <= 1500; h++ {
+		block := factory.MakeBlock(state, h, makeTestCommit(h-1, tmtime.Now()))
+		partSet := block.MakePartSet(2)
+		seenCommit := makeTestCommit(h, tmtime.Now())
+		bs.SaveBlock(block, partSet, seenCommit)
+	}
+	_, err = bs.PruneBlocks(100)
+	require.NoError(t, err)
+
+	// Deleting at or below the base, or above the height, should error
+	_, err = bs.DeleteBlocksFrom(100)
+	require.Error(t, err)
+	_, err = bs.DeleteBlocksFrom(1501)
+	require.Error(t, err)
+
+	deletedBlock := bs.LoadBlock(200)
+	commit := bs.LoadBlockCommit(199)
+
+	deleted, err := bs.DeleteBlocksFrom(200)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1301, deleted)
+	assert.EqualValues(t, 100, bs.Base())
+	assert.EqualValues(t, 199, bs.Height())
+
+	require.NotNil(t, bs.LoadBlock(199))
+	require.Nil(t, bs.LoadBlock(200))
+	require.Nil(t, bs.LoadBlockByHash(deletedBlock.Hash()))
+	require.Nil(t, bs.LoadBlockCommit(200))
+	require.Nil(t, bs.LoadBlockMeta(200))
+	require.Nil(t, bs.LoadBlockPart(200, 1))
+	require.Equal(t, commit, bs.LoadSeenCommit())
+
+	// Blocks can be saved again at the deleted heights
+	block := factory.MakeBlock(state, 200, commit)
+	bs.SaveBlock(block, block.MakePartSet(2), makeTestCommit(200, tmtime.Now()))
+	assert.EqualValues(t, 200, bs.Height())
+	require.Equal(t, block.Hash(), bs.LoadBlockByHash(block.Hash()).Hash())
+}
+
+func TestBlockStoreCompression(t *testing.T) {
+	ctx := context.Background()
+	db := dbm.NewMemDB()
+	snappyCompressor, err := compress.NewCompressor("snappy", 0)
+	require.NoError(t, err)
+	gzipCompressor, err := compress.NewCompressor("gzip", 9)
+	require.NoError(t, err)
+
+	// save blocks uncompressed, then compressed, so that the store contains both
+	blocks := make([]*types.Block, 0, 20)
+	var parts uint64
+	for h := int64(1); h <= 20; h++ {
+		bs := NewBlockStore(db)
+		if h > 10 {
+			bs = NewBlockStore(db, WithCompressor(snappyCompressor))
+		}
+		block := factory.MakeBlock(state, h, makeTestCommit(h-1, tmtime.Now()))
+		partSet := block.MakePartSet(2)
+		bs.SaveBlock(block, partSet, makeTestCommit(h, tmtime.Now()))
+		blocks = append(blocks, block)
+		parts += uint64(partSet.Total())
+	}
+
+	checkBlocks := func(bs *BlockStore, algorithm func(h int64) compress.Algorithm) {
+		for _, block := range blocks {
+			require.Equal(t, block.Hash(), bs.LoadBlock(block.Height).Hash())
+			bz, err := db.Get(blockPartKey(block.Height, 0))
+			require.NoError(t, err)
+			require.Equal(t, algorithm(block.Height), compress.AlgorithmOf(bz))
+		}
+	}
+
+	// all blocks are readable regardless of the compressor
+	checkBlocks(NewBlockStore(db), func(h int64) compress.Algorithm {
+		if h > 10 {
+			return compress.Snappy
+		}
+		return compress.None
+	})
+
+	bs := NewBlockStore(db, WithCompressor(gzipCompressor))
+	rewritten, err := bs.RecompressBlockParts(ctx)
+	require.NoError(t, err)
+	require.Equal(t, parts, rewritten)
+	checkBlocks(bs, func(int64) compress.Algorithm { return compress.Gzip })
+
+	rewritten, err = bs.RecompressBlockParts(ctx)
+	require.NoError(t, err)
+	require.Zero(t, rewritten)
+
+	bs = NewBlockStore(db)
+	_, err = bs.RecompressBlockParts(ctx)
+	require.NoError(t, err)
+	checkBlocks(bs, func(int64) compress.Algorithm { return compress.None })
+}
+
 func TestLoadBlockMeta(t *testing.T) {
 	bs, db := freshBlockStore()
 	height := int64(10)
@@ -99,6 +99,30 @@ func TestPublishDoesNotBlock(t *testing.T) {
 	}
 }
 
+func TestPublishBurst(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+
+	// A subscriber isn't evicted by a burst of messages published before it
+	// receives any, as long as they fit in its queue.
+	const limit = 10
+	sub := newTestSub(t).must(s.SubscribeWithArgs(ctx, pubsub.SubscribeArgs{
+		ClientID: clientID,
+		Query:    query.Empty{},
+		Limit:    limit,
+	}))
+	for i := 0; i < limit; i++ {
+		require.NoError(t, s.Publish(ctx, i))
+	}
+	for i := 0; i < limit; i++ {
+		sub.mustReceive(ctx, i)
+	}
+	require.Equal(t, 1, s.NumClientSubscriptions(clientID))
+
+	require.NoError(t, s.Publish(ctx, limit))
+	sub.mustReceive(ctx, limit)
+}
+
 func TestSubscribeErrors(t *testing.T) {
 	s := newTestServer(t)
 	ctx := context.Background()
@@ -84,6 +84,48 @@ func TestParser(t *testing.T) {
 
 		{"hash='136E18F7E4C348B780CF873A0BF43922E5BAFA63'", true},
 		{"hash=136E18F7E4C348B780CF873A0BF43922E5BAFA63", false},
+
+		{"tm.events.type='NewBlock' OR tm.events.type='Tx'", true},
+		{"tm.events.type='NewBlock' or tm.events.type='Tx'", true},
+		{"tm.events.type='NewBlock' OR", false},
+		{"OR tm.events.type='NewBlock'", false},
+		{"tm.events.type='NewBlock' AND OR tm.events.type='Tx'", false},
+		{"account.balance=100 AND slashing.amount EXISTS OR slashing EXISTS", true},
+
+		{"NOT tm.events.type='NewBlock'", true},
+		{"NOT NOT tm.events.type='NewBlock'", true},
+		{"NOT slashing EXISTS AND account.balance=100", true},
+		{"NOTtm.events.type='NewBlock'", true}, // the tag "NOTtm.events.type"
+		{"NOT", false},
+		{"tm.events.type='NewBlock' NOT", false},
+		{"note.text='NewBlock'", true},
+
+		{"(tm.events.type='NewBlock')", true},
+		{"( tm.events.type='NewBlock' OR tm.events.type='Tx' ) AND tx.height > 5", true},
+		{"NOT (tm.events.type='NewBlock' OR (tx.height < 5 AND tx.gas > 7))", true},
+		{"NOT(tm.events.type='NewBlock')", false},
+		{"(tm.events.type='NewBlock'", false},
+		{"tm.events.type='NewBlock')", false},
+		{"()", false},
+
+		{"tx.height IN (1)", true},
+		{"tx.height IN (1, 2, 3)", true},
+		{"tx.height in(1,2,3)", true},
+		{"transfer.recipient IN ('Igor', 'Ivan') AND tx.height IN (DATE 2013-05-03, TIME 2013-05-03T14:45:00Z)", true},
+		{"tx.height IN ()", false},
+		{"tx.height IN (1,)", false},
+		{"tx.height IN 1", false},
+		{"tx.height IN (1 2)", false},
+		{"tx.height IN (Igor)", false},
+
+		{"transfer.recipient STARTS WITH 'cosmos1'", true},
+		{"transfer.recipient starts  with'cosmos1'", true},
+		{"transfer.recipient STARTSWITH 'cosmos1'", false},
+		{"transfer.recipient STARTS WITH 1", false},
+		{"message.action MATCHES '*send*'", true},
+		{"message.action matches'send?'", true},
+		{"message.action MATCHES 'send\\'", false},
+		{"message.action MATCHES DATE 2013-05-03", false},
 	}
 
 	for _, c := range cases {
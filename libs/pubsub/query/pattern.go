@@ -0,0 +1,93 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Pattern is the operand of the MATCHES operator: a glob pattern which must
+// match the whole value. In a pattern, '*' matches any sequence of characters,
+// '?' matches any single character, and '\' matches the character following it
+// literally; any other character matches itself.
+//
+// Patterns are deliberately restricted to globs, rather than arbitrary regular
+// expressions, so that they can be evaluated in linear time and translated
+// for databases, and so that a pattern with a literal prefix can be evaluated
+// with a range scan of an ordered index.
+type Pattern struct {
+	str    string
+	prefix string
+	re     *regexp.Regexp
+}
+
+// NewPattern parses the given glob pattern.
+func NewPattern(s string) (*Pattern, error) {
+	var (
+		expr    strings.Builder
+		literal strings.Builder
+		prefix  string
+		wild    bool
+	)
+	expr.WriteString(`(?s)^`)
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '*', '?':
+			if !wild {
+				prefix, wild = literal.String(), true
+			}
+			expr.WriteString(regexp.QuoteMeta(literal.String()))
+			literal.Reset()
+			if r == '*' {
+				expr.WriteString(`.*`)
+			} else {
+				expr.WriteString(`.`)
+			}
+
+		case '\\':
+			i++
+			if i == len(runes) {
+				return nil, fmt.Errorf("pattern %q ends with an unescaped backslash", s)
+			}
+			literal.WriteRune(runes[i])
+
+		default:
+			literal.WriteRune(r)
+		}
+	}
+	if !wild {
+		prefix = literal.String()
+	}
+	expr.WriteString(regexp.QuoteMeta(literal.String()))
+	expr.WriteString(`$`)
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("compiling pattern %q: %w", s, err)
+	}
+	return &Pattern{str: s, prefix: prefix, re: re}, nil
+}
+
+// String returns the original pattern.
+func (p *Pattern) String() string {
+	return p.str
+}
+
+// Match reports whether the value matches the pattern.
+func (p *Pattern) Match(value string) bool {
+	return p.re.MatchString(value)
+}
+
+// Prefix returns the literal prefix of the pattern, which all the values
+// matching the pattern start with.
+func (p *Pattern) Prefix() string {
+	return p.prefix
+}
+
+// Regexp returns a regular expression equivalent to the pattern. It uses only
+// the syntax shared by RE2 and the advanced regular expressions of PostgreSQL,
+// so it can be evaluated by databases.
+func (p *Pattern) Regexp() string {
+	return p.re.String()
+}
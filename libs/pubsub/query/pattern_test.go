@@ -0,0 +1,52 @@
+package query_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/pubsub/query"
+)
+
+func TestPattern(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		prefix  string
+		matches map[string]bool
+	}{
+		{"send", "send", map[string]bool{"send": true, "sends": false, "multisend": false, "": false}},
+		{"send*", "send", map[string]bool{"send": true, "sends": true, "multisend": false}},
+		{"*send*", "", map[string]bool{"send": true, "multisend_tokens": true, "sen": false}},
+		{"s?nd", "s", map[string]bool{"send": true, "sand": true, "snd": false, "seend": false}},
+		{"a.b*", "a.b", map[string]bool{"a.bc": true, "axbc": false}},
+		{`\*\?\\*`, `*?\`, map[string]bool{`*?\`: true, `*?\x`: true, `a?\`: false}},
+		{"*", "", map[string]bool{"": true, "line\nbreak": true}},
+		{"é?", "é", map[string]bool{"éa": true, "éé": true, "é": false}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.pattern, func(t *testing.T) {
+			p, err := query.NewPattern(tc.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, tc.pattern, p.String())
+			assert.Equal(t, tc.prefix, p.Prefix())
+			re := regexp.MustCompile(p.Regexp())
+			for value, expected := range tc.matches {
+				assert.Equal(t, expected, p.Match(value), value)
+				assert.Equal(t, expected, re.MatchString(value), value)
+			}
+		})
+	}
+
+	_, err := query.NewPattern(`send\`)
+	assert.Error(t, err)
+}
+
+func mustPattern(t *testing.T, s string) *query.Pattern {
+	t.Helper()
+	p, err := query.NewPattern(s)
+	require.NoError(t, err)
+	return p
+}
@@ -2,6 +2,21 @@
 //
 //		abci.invoice.number=22 AND abci.invoice.owner=Ivan
 //
+// Conditions can be combined with AND, OR and NOT, and grouped with
+// parentheses. AND binds more tightly than OR, so
+//
+//		transfer.sender='Ivan' AND transfer.recipient='Igor' OR transfer.recipient='Pavel'
+//
+// matches transfers from Ivan to Igor, and all transfers to Pavel. IN matches
+// any of a list of operands:
+//
+//		transfer.recipient IN ('Igor', 'Pavel') AND NOT tx.height < 5
+//
+// STARTS WITH matches values with a given prefix, and MATCHES matches values
+// against a glob pattern (see Pattern):
+//
+//		transfer.recipient STARTS WITH 'cosmos1' AND message.action MATCHES '*send*'
+//
 // See query.peg for the grammar, which is a https://en.wikipedia.org/wiki/Parsing_expression_grammar.
 // More: https://github.com/PhilippeSigaud/Pegged/wiki/PEG-Basics
 //
@@ -23,20 +38,45 @@ var (
 	numRegex = regexp.MustCompile(`([0-9\.]+)`)
 )
 
-// Query holds the query string and the query parser.
+// Query holds the query string and its expression tree.
 type Query struct {
-	str    string
-	parser *QueryParser
+	str  string
+	expr *Expr
 }
 
 // Condition represents a single condition within a query and consists of composite key
-// (e.g. "tx.gas"), operator (e.g. "=") and operand (e.g. "7").
+// (e.g. "tx.gas"), operator (e.g. "=") and operand (e.g. "7"). The operand of
+// the IN operator is the list of its operands, as a []interface{}, and the
+// operand of the MATCHES operator is a *Pattern.
 type Condition struct {
 	CompositeKey string
 	Op           Operator
 	Operand      interface{}
 }
 
+// ExprType is the type of a node of the expression tree of a query.
+type ExprType uint8
+
+const (
+	// ExprCondition is a single condition.
+	ExprCondition ExprType = iota
+	// ExprAnd holds if all of its arguments hold.
+	ExprAnd
+	// ExprOr holds if any of its arguments holds.
+	ExprOr
+	// ExprNot holds if its only argument does not hold.
+	ExprNot
+)
+
+// Expr is a node of the expression tree of a query: either a condition, or a
+// logical operator applied to its arguments. The arguments of an AND node are
+// never AND nodes themselves, and likewise for OR nodes.
+type Expr struct {
+	Type      ExprType
+	Condition Condition // for ExprCondition
+	Args      []*Expr   // for ExprAnd, ExprOr and ExprNot
+}
+
 // New parses the given string and returns a query or error if the string is
 // invalid.
 func New(s string) (*Query, error) {
@@ -45,7 +85,12 @@ func New(s string) (*Query, error) {
 	if err := p.Parse(); err != nil {
 		return nil, err
 	}
-	return &Query{str: s, parser: p}, nil
+	c := &compiler{buffer: p.buffer}
+	expr, err := c.compile(p.AST())
+	if err != nil {
+		return nil, err
+	}
+	return &Query{str: s, expr: expr}, nil
 }
 
 // MustParse turns the given string into a query or panics; for tests or others
@@ -82,6 +127,13 @@ const (
 	OpContains
 	// "EXISTS"; used to check if a certain event attribute is present.
 	OpExists
+	// "IN"; used to check if an event attribute is equal to any of a list of
+	// operands.
+	OpIn
+	// "STARTS WITH"; used to check if a string starts with a certain prefix.
+	OpStartsWith
+	// "MATCHES"; used to check if a string matches a glob pattern.
+	OpMatches
 )
 
 const (
@@ -91,276 +143,299 @@ const (
 	TimeLayout = time.RFC3339
 )
 
-// Conditions returns a list of conditions. It returns an error if there is any
-// error with the provided grammar in the Query.
-func (q *Query) Conditions() ([]Condition, error) {
-	var (
-		eventAttr string
-		op        Operator
-	)
+// Expr returns the expression tree of the query.
+func (q *Query) Expr() *Expr {
+	return q.expr
+}
 
-	conditions := make([]Condition, 0)
-	buffer, begin, end := q.parser.Buffer, 0, 0
+// Conditions returns a list of conditions. It returns an error if the query
+// is not a conjunction of conditions, i.e. if it uses OR or NOT, in which case
+// the expression tree returned by Expr must be used instead.
+func (q *Query) Conditions() ([]Condition, error) {
+	switch q.expr.Type {
+	case ExprCondition:
+		return []Condition{q.expr.Condition}, nil
+
+	case ExprAnd:
+		conditions := make([]Condition, 0, len(q.expr.Args))
+		for _, arg := range q.expr.Args {
+			if arg.Type != ExprCondition {
+				return nil, fmt.Errorf("query %q is not a conjunction of conditions", q.str)
+			}
+			conditions = append(conditions, arg.Condition)
+		}
+		return conditions, nil
 
-	// tokens must be in the following order: tag ("tx.gas") -> operator ("=") -> operand ("7")
-	for token := range q.parser.Tokens() {
-		switch token.pegRule {
-		case rulePegText:
-			begin, end = int(token.begin), int(token.end)
+	default:
+		return nil, fmt.Errorf("query %q is not a conjunction of conditions", q.str)
+	}
+}
 
-		case ruletag:
-			eventAttr = buffer[begin:end]
+// Matches returns true if the query matches against any event in the given set
+// of events, false otherwise. For each event, a match exists if the query is
+// matched against *any* value in a slice of values. An error is returned if
+// any attempted event match returns an error.
+//
+// For example, query "name=John" matches events = {"name": ["John", "Eric"]}.
+// More examples could be found in parser_test.go and query_test.go.
+func (q *Query) Matches(rawEvents []types.Event) (bool, error) {
+	if len(rawEvents) == 0 {
+		return false, nil
+	}
 
-		case rulele:
-			op = OpLessEqual
+	return q.expr.matches(flattenEvents(rawEvents))
+}
 
-		case rulege:
-			op = OpGreaterEqual
+// matches returns true if the expression holds for the given events.
+func (e *Expr) matches(events map[string][]string) (bool, error) {
+	switch e.Type {
+	case ExprAnd:
+		for _, arg := range e.Args {
+			match, err := arg.matches(events)
+			if err != nil || !match {
+				return false, err
+			}
+		}
+		return true, nil
 
-		case rulel:
-			op = OpLess
+	case ExprOr:
+		for _, arg := range e.Args {
+			match, err := arg.matches(events)
+			if err != nil || match {
+				return match, err
+			}
+		}
+		return false, nil
 
-		case ruleg:
-			op = OpGreater
+	case ExprNot:
+		match, err := e.Args[0].matches(events)
+		if err != nil {
+			return false, err
+		}
+		return !match, nil
 
-		case ruleequal:
-			op = OpEqual
+	default:
+		return e.Condition.matches(events)
+	}
+}
 
-		case rulecontains:
-			op = OpContains
+// matches returns true if the condition holds for the given events.
+func (c Condition) matches(events map[string][]string) (bool, error) {
+	switch c.Op {
+	case OpExists:
+		if strings.Contains(c.CompositeKey, ".") {
+			// Searching for a full "type.attribute" event.
+			_, ok := events[c.CompositeKey]
+			return ok, nil
+		}
 
-		case ruleexists:
-			op = OpExists
-			conditions = append(conditions, Condition{eventAttr, op, nil})
-
-		case rulevalue:
-			// strip single quotes from value (i.e. "'NewBlock'" -> "NewBlock")
-			valueWithoutSingleQuotes := buffer[begin+1 : end-1]
-			conditions = append(conditions, Condition{eventAttr, op, valueWithoutSingleQuotes})
-
-		case rulenumber:
-			number := buffer[begin:end]
-			if strings.ContainsAny(number, ".") { // if it looks like a floating-point number
-				value, err := strconv.ParseFloat(number, 64)
-				if err != nil {
-					err = fmt.Errorf(
-						"got %v while trying to parse %s as float64 (should never happen if the grammar is correct)",
-						err, number,
-					)
-					return nil, err
-				}
+		for compositeKey := range events {
+			if strings.Index(compositeKey, c.CompositeKey) == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
 
-				conditions = append(conditions, Condition{eventAttr, op, value})
-			} else {
-				value, err := strconv.ParseInt(number, 10, 64)
-				if err != nil {
-					err = fmt.Errorf(
-						"got %v while trying to parse %s as int64 (should never happen if the grammar is correct)",
-						err, number,
-					)
-					return nil, err
-				}
+	case OpIn:
+		for _, operand := range c.Operand.([]interface{}) {
+			match, err := match(c.CompositeKey, OpEqual, reflect.ValueOf(operand), events)
+			if err != nil || match {
+				return match, err
+			}
+		}
+		return false, nil
 
-				conditions = append(conditions, Condition{eventAttr, op, value})
+	case OpMatches:
+		pattern := c.Operand.(*Pattern)
+		for _, value := range events[c.CompositeKey] {
+			if pattern.Match(value) {
+				return true, nil
 			}
+		}
+		return false, nil
+
+	default:
+		// see if the triplet (event attribute, operator, operand) matches any event
+		// "tx.gas", "=", "7", { "tx.gas": 7, "tx.ID": "4AE393495334" }
+		return match(c.CompositeKey, c.Op, reflect.ValueOf(c.Operand), events)
+	}
+}
+
+// compiler builds the expression tree of a query from its syntax tree.
+type compiler struct {
+	buffer []rune
+}
+
+// text returns the text matched by the node.
+func (c *compiler) text(node *node32) string {
+	return string(c.buffer[node.begin:node.end])
+}
 
-		case ruletime:
-			value, err := time.Parse(TimeLayout, buffer[begin:end])
+// compile returns the expression of an e, expr, term or factor node.
+func (c *compiler) compile(node *node32) (*Expr, error) {
+	switch node.pegRule {
+	case rulee:
+		return c.compile(node.up)
+
+	case ruleexpr, ruleterm:
+		typ, sep := ExprOr, ruleor
+		if node.pegRule == ruleterm {
+			typ, sep = ExprAnd, ruleand
+		}
+
+		var args []*Expr
+		for child := node.up; child != nil; child = child.next {
+			if child.pegRule == sep {
+				continue
+			}
+			arg, err := c.compile(child)
 			if err != nil {
-				err = fmt.Errorf(
-					"got %v while trying to parse %s as time.Time / RFC3339 (should never happen if the grammar is correct)",
-					err, buffer[begin:end],
-				)
 				return nil, err
 			}
+			if arg.Type == typ {
+				// flatten "a AND (b AND c)" to "a AND b AND c"
+				args = append(args, arg.Args...)
+			} else {
+				args = append(args, arg)
+			}
+		}
+		if len(args) == 1 {
+			return args[0], nil
+		}
+		return &Expr{Type: typ, Args: args}, nil
 
-			conditions = append(conditions, Condition{eventAttr, op, value})
+	case rulefactor:
+		switch child := node.up; child.pegRule {
+		case rulenot:
+			arg, err := c.compile(child.next)
+			if err != nil {
+				return nil, err
+			}
+			return &Expr{Type: ExprNot, Args: []*Expr{arg}}, nil
 
-		case ruledate:
-			value, err := time.Parse("2006-01-02", buffer[begin:end])
+		case rulecondition:
+			condition, err := c.condition(child)
 			if err != nil {
-				err = fmt.Errorf(
-					"got %v while trying to parse %s as time.Time / '2006-01-02' (should never happen if the grammar is correct)",
-					err, buffer[begin:end],
-				)
 				return nil, err
 			}
+			return &Expr{Type: ExprCondition, Condition: condition}, nil
 
-			conditions = append(conditions, Condition{eventAttr, op, value})
+		default: // a parenthesized expr
+			return c.compile(child)
 		}
 	}
 
-	return conditions, nil
+	return nil, fmt.Errorf("unexpected %s in query (should never happen if the grammar is correct)",
+		rul3s[node.pegRule])
 }
 
-// Matches returns true if the query matches against any event in the given set
-// of events, false otherwise. For each event, a match exists if the query is
-// matched against *any* value in a slice of values. An error is returned if
-// any attempted event match returns an error.
-//
-// For example, query "name=John" matches events = {"name": ["John", "Eric"]}.
-// More examples could be found in parser_test.go and query_test.go.
-func (q *Query) Matches(rawEvents []types.Event) (bool, error) {
-	if len(rawEvents) == 0 {
-		return false, nil
-	}
-
-	events := flattenEvents(rawEvents)
-
-	var (
-		eventAttr string
-		op        Operator
-	)
-
-	buffer, begin, end := q.parser.Buffer, 0, 0
-
-	// tokens must be in the following order:
-
-	// tag ("tx.gas") -> operator ("=") -> operand ("7")
-	for token := range q.parser.Tokens() {
-		switch token.pegRule {
-		case rulePegText:
-			begin, end = int(token.begin), int(token.end)
-
+// condition returns the condition of a condition node. Its children must be
+// in the following order: tag ("tx.gas") -> operator ("=") -> operand ("7").
+func (c *compiler) condition(node *node32) (Condition, error) {
+	var condition Condition
+	for child := node.up; child != nil; child = child.next {
+		switch child.pegRule {
 		case ruletag:
-			eventAttr = buffer[begin:end]
+			condition.CompositeKey = c.text(child)
 
 		case rulele:
-			op = OpLessEqual
+			condition.Op = OpLessEqual
 
 		case rulege:
-			op = OpGreaterEqual
+			condition.Op = OpGreaterEqual
 
 		case rulel:
-			op = OpLess
+			condition.Op = OpLess
 
 		case ruleg:
-			op = OpGreater
+			condition.Op = OpGreater
 
 		case ruleequal:
-			op = OpEqual
+			condition.Op = OpEqual
 
 		case rulecontains:
-			op = OpContains
-		case ruleexists:
-			op = OpExists
-			if strings.Contains(eventAttr, ".") {
-				// Searching for a full "type.attribute" event.
-				_, ok := events[eventAttr]
-				if !ok {
-					return false, nil
-				}
-			} else {
-				foundEvent := false
-
-			loop:
-				for compositeKey := range events {
-					if strings.Index(compositeKey, eventAttr) == 0 {
-						foundEvent = true
-						break loop
-					}
-				}
-				if !foundEvent {
-					return false, nil
-				}
-			}
-
-		case rulevalue:
-			// strip single quotes from value (i.e. "'NewBlock'" -> "NewBlock")
-			valueWithoutSingleQuotes := buffer[begin+1 : end-1]
-
-			// see if the triplet (event attribute, operator, operand) matches any event
-			// "tx.gas", "=", "7", { "tx.gas": 7, "tx.ID": "4AE393495334" }
-			match, err := match(eventAttr, op, reflect.ValueOf(valueWithoutSingleQuotes), events)
-			if err != nil {
-				return false, err
-			}
+			condition.Op = OpContains
 
-			if !match {
-				return false, nil
-			}
+		case ruleexists:
+			condition.Op = OpExists
 
-		case rulenumber:
-			number := buffer[begin:end]
-			if strings.ContainsAny(number, ".") { // if it looks like a floating-point number
-				value, err := strconv.ParseFloat(number, 64)
-				if err != nil {
-					err = fmt.Errorf(
-						"got %v while trying to parse %s as float64 (should never happen if the grammar is correct)",
-						err, number,
-					)
-					return false, err
-				}
+		case rulein:
+			condition.Op = OpIn
+			condition.Operand = []interface{}{}
 
-				match, err := match(eventAttr, op, reflect.ValueOf(value), events)
-				if err != nil {
-					return false, err
-				}
+		case rulestartswith:
+			condition.Op = OpStartsWith
 
-				if !match {
-					return false, nil
-				}
-			} else {
-				value, err := strconv.ParseInt(number, 10, 64)
-				if err != nil {
-					err = fmt.Errorf(
-						"got %v while trying to parse %s as int64 (should never happen if the grammar is correct)",
-						err, number,
-					)
-					return false, err
-				}
-
-				match, err := match(eventAttr, op, reflect.ValueOf(value), events)
-				if err != nil {
-					return false, err
-				}
+		case rulematches:
+			condition.Op = OpMatches
 
-				if !match {
-					return false, nil
-				}
-			}
-
-		case ruletime:
-			value, err := time.Parse(TimeLayout, buffer[begin:end])
+		case ruleoperand:
+			value, err := c.operand(child.up)
 			if err != nil {
-				err = fmt.Errorf(
-					"got %v while trying to parse %s as time.Time / RFC3339 (should never happen if the grammar is correct)",
-					err, buffer[begin:end],
-				)
-				return false, err
+				return condition, err
 			}
+			condition.Operand = append(condition.Operand.([]interface{}), value)
 
-			match, err := match(eventAttr, op, reflect.ValueOf(value), events)
+		default:
+			value, err := c.operand(child)
 			if err != nil {
-				return false, err
+				return condition, err
 			}
-
-			if !match {
-				return false, nil
+			if condition.Op == OpMatches {
+				// the grammar only allows a string operand
+				value, err = NewPattern(value.(string))
+				if err != nil {
+					return condition, err
+				}
 			}
+			condition.Operand = value
+		}
+	}
+	return condition, nil
+}
 
-		case ruledate:
-			value, err := time.Parse("2006-01-02", buffer[begin:end])
+// operand returns the value of a value, number, time or date node.
+func (c *compiler) operand(node *node32) (interface{}, error) {
+	switch node.pegRule {
+	case rulevalue:
+		// strip single quotes from value (i.e. "'NewBlock'" -> "NewBlock")
+		value := c.text(node)
+		return value[1 : len(value)-1], nil
+
+	case rulenumber:
+		number := c.text(node)
+		if strings.ContainsAny(number, ".") { // if it looks like a floating-point number
+			value, err := strconv.ParseFloat(number, 64)
 			if err != nil {
-				err = fmt.Errorf(
-					"got %v while trying to parse %s as time.Time / '2006-01-02' (should never happen if the grammar is correct)",
-					err, buffer[begin:end],
-				)
-				return false, err
+				return nil, fmt.Errorf("got %v while trying to parse %s as float64", err, number)
 			}
+			return value, nil
+		}
 
-			match, err := match(eventAttr, op, reflect.ValueOf(value), events)
-			if err != nil {
-				return false, err
-			}
+		value, err := strconv.ParseInt(number, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("got %v while trying to parse %s as int64", err, number)
+		}
+		return value, nil
 
-			if !match {
-				return false, nil
-			}
+	case ruletime:
+		// the text of the time excludes the "TIME " prefix
+		value, err := time.Parse(TimeLayout, c.text(node.up))
+		if err != nil {
+			return nil, fmt.Errorf("got %v while trying to parse %s as time.Time / RFC3339", err, c.text(node.up))
+		}
+		return value, nil
+
+	case ruledate:
+		value, err := time.Parse(DateLayout, c.text(node.up))
+		if err != nil {
+			return nil, fmt.Errorf("got %v while trying to parse %s as time.Time / '2006-01-02'", err, c.text(node.up))
 		}
+		return value, nil
 	}
 
-	return true, nil
+	return nil, fmt.Errorf("unexpected %s in query (should never happen if the grammar is correct)",
+		rul3s[node.pegRule])
 }
 
 // match returns true if the given triplet (attribute, operator, operand) matches
@@ -496,6 +571,8 @@ func matchValue(value string, op Operator, operand reflect.Value) (bool, error)
 			return value == operand.String(), nil
 		case OpContains:
 			return strings.Contains(value, operand.String()), nil
+		case OpStartsWith:
+			return strings.HasPrefix(value, operand.String()), nil
 		}
 
 	default:
@@ -16,7 +16,11 @@ type pegRule uint8
 const (
 	ruleUnknown pegRule = iota
 	rulee
+	ruleexpr
+	ruleterm
+	rulefactor
 	rulecondition
+	ruleoperand
 	ruletag
 	rulevalue
 	rulenumber
@@ -27,9 +31,14 @@ const (
 	rulemonth
 	ruleday
 	ruleand
+	ruleor
+	rulenot
 	ruleequal
 	rulecontains
 	ruleexists
+	rulein
+	rulestartswith
+	rulematches
 	rulele
 	rulege
 	rulel
@@ -44,7 +53,11 @@ const (
 var rul3s = [...]string{
 	"Unknown",
 	"e",
+	"expr",
+	"term",
+	"factor",
 	"condition",
+	"operand",
 	"tag",
 	"value",
 	"number",
@@ -55,9 +68,14 @@ var rul3s = [...]string{
 	"month",
 	"day",
 	"and",
+	"or",
+	"not",
 	"equal",
 	"contains",
 	"exists",
+	"in",
+	"startswith",
+	"matches",
 	"le",
 	"ge",
 	"l",
@@ -356,7 +374,7 @@ func (t *tokens32) Expand(index int) {
 type QueryParser struct {
 	Buffer string
 	buffer []rune
-	rules  [21]func() bool
+	rules  [30]func() bool
 	Parse  func(rule ...int) error
 	Reset  func()
 	Pretty bool
@@ -494,132 +512,371 @@ func (p *QueryParser) Init() {
 
 	_rules = [...]func() bool{
 		nil,
-		/* 0 e <- <('"' condition (' '+ and ' '+ condition)* '"' !.)> */
+		/* 0 e <- <('"' expr '"' !.)> */
 		func() bool {
-			position0, tokenIndex0, depth0 := position, tokenIndex, depth
+			position318, tokenIndex318, depth318 := position, tokenIndex, depth
 			{
-				position1 := position
+				position319 := position
 				depth++
 				if buffer[position] != rune('"') {
-					goto l0
+					goto l318
 				}
 				position++
-				if !_rules[rulecondition]() {
-					goto l0
+				if !_rules[ruleexpr]() {
+					goto l318
 				}
-			l2:
+				if buffer[position] != rune('"') {
+					goto l318
+				}
+				position++
+				{
+					position320, tokenIndex320, depth320 := position, tokenIndex, depth
+					if !matchDot() {
+						goto l320
+					}
+					goto l318
+				l320:
+					position, tokenIndex, depth = position320, tokenIndex320, depth320
+				}
+				depth--
+				add(rulee, position319)
+			}
+			return true
+		l318:
+			position, tokenIndex, depth = position318, tokenIndex318, depth318
+			return false
+		},
+		/* 1 expr <- <(term (' '+ or ' '+ term)*)> */
+		func() bool {
+			position321, tokenIndex321, depth321 := position, tokenIndex, depth
+			{
+				position322 := position
+				depth++
+				if !_rules[ruleterm]() {
+					goto l321
+				}
+			l323:
 				{
-					position3, tokenIndex3, depth3 := position, tokenIndex, depth
+					position324, tokenIndex324, depth324 := position, tokenIndex, depth
 					if buffer[position] != rune(' ') {
-						goto l3
+						goto l324
 					}
 					position++
-				l4:
+				l325:
 					{
-						position5, tokenIndex5, depth5 := position, tokenIndex, depth
+						position326, tokenIndex326, depth326 := position, tokenIndex, depth
 						if buffer[position] != rune(' ') {
-							goto l5
+							goto l326
 						}
 						position++
-						goto l4
-					l5:
-						position, tokenIndex, depth = position5, tokenIndex5, depth5
+						goto l325
+					l326:
+						position, tokenIndex, depth = position326, tokenIndex326, depth326
 					}
 					{
-						position6 := position
+						position327 := position
 						depth++
 						{
-							position7, tokenIndex7, depth7 := position, tokenIndex, depth
+							position328, tokenIndex328, depth328 := position, tokenIndex, depth
+							if buffer[position] != rune('o') {
+								goto l329
+							}
+							position++
+							goto l328
+						l329:
+							position, tokenIndex, depth = position328, tokenIndex328, depth328
+							if buffer[position] != rune('O') {
+								goto l324
+							}
+							position++
+						}
+					l328:
+						{
+							position330, tokenIndex330, depth330 := position, tokenIndex, depth
+							if buffer[position] != rune('r') {
+								goto l331
+							}
+							position++
+							goto l330
+						l331:
+							position, tokenIndex, depth = position330, tokenIndex330, depth330
+							if buffer[position] != rune('R') {
+								goto l324
+							}
+							position++
+						}
+					l330:
+						depth--
+						add(ruleor, position327)
+					}
+					if buffer[position] != rune(' ') {
+						goto l324
+					}
+					position++
+				l332:
+					{
+						position333, tokenIndex333, depth333 := position, tokenIndex, depth
+						if buffer[position] != rune(' ') {
+							goto l333
+						}
+						position++
+						goto l332
+					l333:
+						position, tokenIndex, depth = position333, tokenIndex333, depth333
+					}
+					if !_rules[ruleterm]() {
+						goto l324
+					}
+					goto l323
+				l324:
+					position, tokenIndex, depth = position324, tokenIndex324, depth324
+				}
+				depth--
+				add(ruleexpr, position322)
+			}
+			return true
+		l321:
+			position, tokenIndex, depth = position321, tokenIndex321, depth321
+			return false
+		},
+		/* 2 term <- <(factor (' '+ and ' '+ factor)*)> */
+		func() bool {
+			position334, tokenIndex334, depth334 := position, tokenIndex, depth
+			{
+				position335 := position
+				depth++
+				if !_rules[rulefactor]() {
+					goto l334
+				}
+			l336:
+				{
+					position337, tokenIndex337, depth337 := position, tokenIndex, depth
+					if buffer[position] != rune(' ') {
+						goto l337
+					}
+					position++
+				l338:
+					{
+						position339, tokenIndex339, depth339 := position, tokenIndex, depth
+						if buffer[position] != rune(' ') {
+							goto l339
+						}
+						position++
+						goto l338
+					l339:
+						position, tokenIndex, depth = position339, tokenIndex339, depth339
+					}
+					{
+						position340 := position
+						depth++
+						{
+							position341, tokenIndex341, depth341 := position, tokenIndex, depth
 							if buffer[position] != rune('a') {
-								goto l8
+								goto l342
 							}
 							position++
-							goto l7
-						l8:
-							position, tokenIndex, depth = position7, tokenIndex7, depth7
+							goto l341
+						l342:
+							position, tokenIndex, depth = position341, tokenIndex341, depth341
 							if buffer[position] != rune('A') {
-								goto l3
+								goto l337
 							}
 							position++
 						}
-					l7:
+					l341:
 						{
-							position9, tokenIndex9, depth9 := position, tokenIndex, depth
+							position343, tokenIndex343, depth343 := position, tokenIndex, depth
 							if buffer[position] != rune('n') {
-								goto l10
+								goto l344
 							}
 							position++
-							goto l9
-						l10:
-							position, tokenIndex, depth = position9, tokenIndex9, depth9
+							goto l343
+						l344:
+							position, tokenIndex, depth = position343, tokenIndex343, depth343
 							if buffer[position] != rune('N') {
-								goto l3
+								goto l337
 							}
 							position++
 						}
-					l9:
+					l343:
 						{
-							position11, tokenIndex11, depth11 := position, tokenIndex, depth
+							position345, tokenIndex345, depth345 := position, tokenIndex, depth
 							if buffer[position] != rune('d') {
-								goto l12
+								goto l346
 							}
 							position++
-							goto l11
-						l12:
-							position, tokenIndex, depth = position11, tokenIndex11, depth11
+							goto l345
+						l346:
+							position, tokenIndex, depth = position345, tokenIndex345, depth345
 							if buffer[position] != rune('D') {
-								goto l3
+								goto l337
 							}
 							position++
 						}
-					l11:
+					l345:
 						depth--
-						add(ruleand, position6)
+						add(ruleand, position340)
 					}
 					if buffer[position] != rune(' ') {
-						goto l3
+						goto l337
 					}
 					position++
-				l13:
+				l347:
 					{
-						position14, tokenIndex14, depth14 := position, tokenIndex, depth
+						position348, tokenIndex348, depth348 := position, tokenIndex, depth
 						if buffer[position] != rune(' ') {
-							goto l14
+							goto l348
 						}
 						position++
-						goto l13
-					l14:
-						position, tokenIndex, depth = position14, tokenIndex14, depth14
+						goto l347
+					l348:
+						position, tokenIndex, depth = position348, tokenIndex348, depth348
 					}
-					if !_rules[rulecondition]() {
-						goto l3
+					if !_rules[rulefactor]() {
+						goto l337
 					}
-					goto l2
-				l3:
-					position, tokenIndex, depth = position3, tokenIndex3, depth3
-				}
-				if buffer[position] != rune('"') {
-					goto l0
+					goto l336
+				l337:
+					position, tokenIndex, depth = position337, tokenIndex337, depth337
 				}
-				position++
+				depth--
+				add(ruleterm, position335)
+			}
+			return true
+		l334:
+			position, tokenIndex, depth = position334, tokenIndex334, depth334
+			return false
+		},
+		/* 3 factor <- <((not ' '+ factor) / ('(' ' '* expr ' '* ')') / condition)> */
+		func() bool {
+			position349, tokenIndex349, depth349 := position, tokenIndex, depth
+			{
+				position350 := position
+				depth++
 				{
-					position15, tokenIndex15, depth15 := position, tokenIndex, depth
-					if !matchDot() {
-						goto l15
+					position351, tokenIndex351, depth351 := position, tokenIndex, depth
+					{
+						position353 := position
+						depth++
+						{
+							position354, tokenIndex354, depth354 := position, tokenIndex, depth
+							if buffer[position] != rune('n') {
+								goto l355
+							}
+							position++
+							goto l354
+						l355:
+							position, tokenIndex, depth = position354, tokenIndex354, depth354
+							if buffer[position] != rune('N') {
+								goto l352
+							}
+							position++
+						}
+					l354:
+						{
+							position356, tokenIndex356, depth356 := position, tokenIndex, depth
+							if buffer[position] != rune('o') {
+								goto l357
+							}
+							position++
+							goto l356
+						l357:
+							position, tokenIndex, depth = position356, tokenIndex356, depth356
+							if buffer[position] != rune('O') {
+								goto l352
+							}
+							position++
+						}
+					l356:
+						{
+							position358, tokenIndex358, depth358 := position, tokenIndex, depth
+							if buffer[position] != rune('t') {
+								goto l359
+							}
+							position++
+							goto l358
+						l359:
+							position, tokenIndex, depth = position358, tokenIndex358, depth358
+							if buffer[position] != rune('T') {
+								goto l352
+							}
+							position++
+						}
+					l358:
+						depth--
+						add(rulenot, position353)
+					}
+					if buffer[position] != rune(' ') {
+						goto l352
+					}
+					position++
+				l360:
+					{
+						position361, tokenIndex361, depth361 := position, tokenIndex, depth
+						if buffer[position] != rune(' ') {
+							goto l361
+						}
+						position++
+						goto l360
+					l361:
+						position, tokenIndex, depth = position361, tokenIndex361, depth361
+					}
+					if !_rules[rulefactor]() {
+						goto l352
+					}
+					goto l351
+				l352:
+					position, tokenIndex, depth = position351, tokenIndex351, depth351
+					if buffer[position] != rune('(') {
+						goto l362
+					}
+					position++
+				l363:
+					{
+						position364, tokenIndex364, depth364 := position, tokenIndex, depth
+						if buffer[position] != rune(' ') {
+							goto l364
+						}
+						position++
+						goto l363
+					l364:
+						position, tokenIndex, depth = position364, tokenIndex364, depth364
+					}
+					if !_rules[ruleexpr]() {
+						goto l362
+					}
+				l365:
+					{
+						position366, tokenIndex366, depth366 := position, tokenIndex, depth
+						if buffer[position] != rune(' ') {
+							goto l366
+						}
+						position++
+						goto l365
+					l366:
+						position, tokenIndex, depth = position366, tokenIndex366, depth366
+					}
+					if buffer[position] != rune(')') {
+						goto l362
+					}
+					position++
+					goto l351
+				l362:
+					position, tokenIndex, depth = position351, tokenIndex351, depth351
+					if !_rules[rulecondition]() {
+						goto l349
 					}
-					goto l0
-				l15:
-					position, tokenIndex, depth = position15, tokenIndex15, depth15
 				}
+			l351:
 				depth--
-				add(rulee, position1)
+				add(rulefactor, position350)
 			}
 			return true
-		l0:
-			position, tokenIndex, depth = position0, tokenIndex0, depth0
+		l349:
+			position, tokenIndex, depth = position349, tokenIndex349, depth349
 			return false
 		},
-		/* 1 condition <- <(tag ' '* ((le ' '* ((&('D' | 'd') date) | (&('T' | 't') time) | (&('0' | '1' | '2' | '3' | '4' | '5' | '6' | '7' | '8' | '9') number))) / (ge ' '* ((&('D' | 'd') date) | (&('T' | 't') time) | (&('0' | '1' | '2' | '3' | '4' | '5' | '6' | '7' | '8' | '9') number))) / ((&('E' | 'e') exists) | (&('=') (equal ' '* ((&('\'') value) | (&('D' | 'd') date) | (&('T' | 't') time) | (&('0' | '1' | '2' | '3' | '4' | '5' | '6' | '7' | '8' | '9') number)))) | (&('>') (g ' '* ((&('D' | 'd') date) | (&('T' | 't') time) | (&('0' | '1' | '2' | '3' | '4' | '5' | '6' | '7' | '8' | '9') number)))) | (&('<') (l ' '* ((&('D' | 'd') date) | (&('T' | 't') time) | (&('0' | '1' | '2' | '3' | '4' | '5' | '6' | '7' | '8' | '9') number)))) | (&('C' | 'c') (contains ' '* value)))))> */
+		/* 4 condition <- <(tag ' '* ((le ' '* ((&('D' | 'd') date) | (&('T' | 't') time) | (&('0' | '1' | '2' | '3' | '4' | '5' | '6' | '7' | '8' | '9') number))) / (ge ' '* ((&('D' | 'd') date) | (&('T' | 't') time) | (&('0' | '1' | '2' | '3' | '4' | '5' | '6' | '7' | '8' | '9') number))) / ((&('E' | 'e') exists) | (&('=') (equal ' '* ((&('\'') value) | (&('D' | 'd') date) | (&('T' | 't') time) | (&('0' | '1' | '2' | '3' | '4' | '5' | '6' | '7' | '8' | '9') number)))) | (&('>') (g ' '* ((&('D' | 'd') date) | (&('T' | 't') time) | (&('0' | '1' | '2' | '3' | '4' | '5' | '6' | '7' | '8' | '9') number)))) | (&('<') (l ' '* ((&('D' | 'd') date) | (&('T' | 't') time) | (&('0' | '1' | '2' | '3' | '4' | '5' | '6' | '7' | '8' | '9') number)))) | (&('I' | 'i') (in ' '* '(' ' '* operand (' '* ',' ' '* operand)* ' '* ')')) | (&('S' | 's') (startswith ' '* value)) | (&('M' | 'm') (matches ' '* value)) | (&('C' | 'c') (contains ' '* value)))))> */
 		func() bool {
 			position16, tokenIndex16, depth16 := position, tokenIndex, depth
 			{
@@ -1158,6 +1415,438 @@ func (p *QueryParser) Init() {
 								}
 							}
 
+							break
+						case 'I', 'i':
+							{
+								position301 := position
+								depth++
+								{
+									position302, tokenIndex302, depth302 := position, tokenIndex, depth
+									if buffer[position] != rune('i') {
+										goto l303
+									}
+									position++
+									goto l302
+								l303:
+									position, tokenIndex, depth = position302, tokenIndex302, depth302
+									if buffer[position] != rune('I') {
+										goto l16
+									}
+									position++
+								}
+							l302:
+								{
+									position304, tokenIndex304, depth304 := position, tokenIndex, depth
+									if buffer[position] != rune('n') {
+										goto l305
+									}
+									position++
+									goto l304
+								l305:
+									position, tokenIndex, depth = position304, tokenIndex304, depth304
+									if buffer[position] != rune('N') {
+										goto l16
+									}
+									position++
+								}
+							l304:
+								depth--
+								add(rulein, position301)
+							}
+						l306:
+							{
+								position307, tokenIndex307, depth307 := position, tokenIndex, depth
+								if buffer[position] != rune(' ') {
+									goto l307
+								}
+								position++
+								goto l306
+							l307:
+								position, tokenIndex, depth = position307, tokenIndex307, depth307
+							}
+							if buffer[position] != rune('(') {
+								goto l16
+							}
+							position++
+						l308:
+							{
+								position309, tokenIndex309, depth309 := position, tokenIndex, depth
+								if buffer[position] != rune(' ') {
+									goto l309
+								}
+								position++
+								goto l308
+							l309:
+								position, tokenIndex, depth = position309, tokenIndex309, depth309
+							}
+							if !_rules[ruleoperand]() {
+								goto l16
+							}
+						l310:
+							{
+								position311, tokenIndex311, depth311 := position, tokenIndex, depth
+							l312:
+								{
+									position313, tokenIndex313, depth313 := position, tokenIndex, depth
+									if buffer[position] != rune(' ') {
+										goto l313
+									}
+									position++
+									goto l312
+								l313:
+									position, tokenIndex, depth = position313, tokenIndex313, depth313
+								}
+								if buffer[position] != rune(',') {
+									goto l311
+								}
+								position++
+							l314:
+								{
+									position315, tokenIndex315, depth315 := position, tokenIndex, depth
+									if buffer[position] != rune(' ') {
+										goto l315
+									}
+									position++
+									goto l314
+								l315:
+									position, tokenIndex, depth = position315, tokenIndex315, depth315
+								}
+								if !_rules[ruleoperand]() {
+									goto l311
+								}
+								goto l310
+							l311:
+								position, tokenIndex, depth = position311, tokenIndex311, depth311
+							}
+						l316:
+							{
+								position317, tokenIndex317, depth317 := position, tokenIndex, depth
+								if buffer[position] != rune(' ') {
+									goto l317
+								}
+								position++
+								goto l316
+							l317:
+								position, tokenIndex, depth = position317, tokenIndex317, depth317
+							}
+							if buffer[position] != rune(')') {
+								goto l16
+							}
+							position++
+							break
+						case 'S', 's':
+							{
+								position401 := position
+								depth++
+								{
+									position402, tokenIndex402, depth402 := position, tokenIndex, depth
+									if buffer[position] != rune('s') {
+										goto l403
+									}
+									position++
+									goto l402
+								l403:
+									position, tokenIndex, depth = position402, tokenIndex402, depth402
+									if buffer[position] != rune('S') {
+										goto l16
+									}
+									position++
+								}
+							l402:
+								{
+									position404, tokenIndex404, depth404 := position, tokenIndex, depth
+									if buffer[position] != rune('t') {
+										goto l405
+									}
+									position++
+									goto l404
+								l405:
+									position, tokenIndex, depth = position404, tokenIndex404, depth404
+									if buffer[position] != rune('T') {
+										goto l16
+									}
+									position++
+								}
+							l404:
+								{
+									position406, tokenIndex406, depth406 := position, tokenIndex, depth
+									if buffer[position] != rune('a') {
+										goto l407
+									}
+									position++
+									goto l406
+								l407:
+									position, tokenIndex, depth = position406, tokenIndex406, depth406
+									if buffer[position] != rune('A') {
+										goto l16
+									}
+									position++
+								}
+							l406:
+								{
+									position408, tokenIndex408, depth408 := position, tokenIndex, depth
+									if buffer[position] != rune('r') {
+										goto l409
+									}
+									position++
+									goto l408
+								l409:
+									position, tokenIndex, depth = position408, tokenIndex408, depth408
+									if buffer[position] != rune('R') {
+										goto l16
+									}
+									position++
+								}
+							l408:
+								{
+									position410, tokenIndex410, depth410 := position, tokenIndex, depth
+									if buffer[position] != rune('t') {
+										goto l411
+									}
+									position++
+									goto l410
+								l411:
+									position, tokenIndex, depth = position410, tokenIndex410, depth410
+									if buffer[position] != rune('T') {
+										goto l16
+									}
+									position++
+								}
+							l410:
+								{
+									position412, tokenIndex412, depth412 := position, tokenIndex, depth
+									if buffer[position] != rune('s') {
+										goto l413
+									}
+									position++
+									goto l412
+								l413:
+									position, tokenIndex, depth = position412, tokenIndex412, depth412
+									if buffer[position] != rune('S') {
+										goto l16
+									}
+									position++
+								}
+							l412:
+								if buffer[position] != rune(' ') {
+									goto l16
+								}
+								position++
+							l414:
+								{
+									position415, tokenIndex415, depth415 := position, tokenIndex, depth
+									if buffer[position] != rune(' ') {
+										goto l415
+									}
+									position++
+									goto l414
+								l415:
+									position, tokenIndex, depth = position415, tokenIndex415, depth415
+								}
+								{
+									position416, tokenIndex416, depth416 := position, tokenIndex, depth
+									if buffer[position] != rune('w') {
+										goto l417
+									}
+									position++
+									goto l416
+								l417:
+									position, tokenIndex, depth = position416, tokenIndex416, depth416
+									if buffer[position] != rune('W') {
+										goto l16
+									}
+									position++
+								}
+							l416:
+								{
+									position418, tokenIndex418, depth418 := position, tokenIndex, depth
+									if buffer[position] != rune('i') {
+										goto l419
+									}
+									position++
+									goto l418
+								l419:
+									position, tokenIndex, depth = position418, tokenIndex418, depth418
+									if buffer[position] != rune('I') {
+										goto l16
+									}
+									position++
+								}
+							l418:
+								{
+									position420, tokenIndex420, depth420 := position, tokenIndex, depth
+									if buffer[position] != rune('t') {
+										goto l421
+									}
+									position++
+									goto l420
+								l421:
+									position, tokenIndex, depth = position420, tokenIndex420, depth420
+									if buffer[position] != rune('T') {
+										goto l16
+									}
+									position++
+								}
+							l420:
+								{
+									position422, tokenIndex422, depth422 := position, tokenIndex, depth
+									if buffer[position] != rune('h') {
+										goto l423
+									}
+									position++
+									goto l422
+								l423:
+									position, tokenIndex, depth = position422, tokenIndex422, depth422
+									if buffer[position] != rune('H') {
+										goto l16
+									}
+									position++
+								}
+							l422:
+								depth--
+								add(rulestartswith, position401)
+							}
+						l424:
+							{
+								position425, tokenIndex425, depth425 := position, tokenIndex, depth
+								if buffer[position] != rune(' ') {
+									goto l425
+								}
+								position++
+								goto l424
+							l425:
+								position, tokenIndex, depth = position425, tokenIndex425, depth425
+							}
+							if !_rules[rulevalue]() {
+								goto l16
+							}
+							break
+						case 'M', 'm':
+							{
+								position426 := position
+								depth++
+								{
+									position427, tokenIndex427, depth427 := position, tokenIndex, depth
+									if buffer[position] != rune('m') {
+										goto l428
+									}
+									position++
+									goto l427
+								l428:
+									position, tokenIndex, depth = position427, tokenIndex427, depth427
+									if buffer[position] != rune('M') {
+										goto l16
+									}
+									position++
+								}
+							l427:
+								{
+									position429, tokenIndex429, depth429 := position, tokenIndex, depth
+									if buffer[position] != rune('a') {
+										goto l430
+									}
+									position++
+									goto l429
+								l430:
+									position, tokenIndex, depth = position429, tokenIndex429, depth429
+									if buffer[position] != rune('A') {
+										goto l16
+									}
+									position++
+								}
+							l429:
+								{
+									position431, tokenIndex431, depth431 := position, tokenIndex, depth
+									if buffer[position] != rune('t') {
+										goto l432
+									}
+									position++
+									goto l431
+								l432:
+									position, tokenIndex, depth = position431, tokenIndex431, depth431
+									if buffer[position] != rune('T') {
+										goto l16
+									}
+									position++
+								}
+							l431:
+								{
+									position433, tokenIndex433, depth433 := position, tokenIndex, depth
+									if buffer[position] != rune('c') {
+										goto l434
+									}
+									position++
+									goto l433
+								l434:
+									position, tokenIndex, depth = position433, tokenIndex433, depth433
+									if buffer[position] != rune('C') {
+										goto l16
+									}
+									position++
+								}
+							l433:
+								{
+									position435, tokenIndex435, depth435 := position, tokenIndex, depth
+									if buffer[position] != rune('h') {
+										goto l436
+									}
+									position++
+									goto l435
+								l436:
+									position, tokenIndex, depth = position435, tokenIndex435, depth435
+									if buffer[position] != rune('H') {
+										goto l16
+									}
+									position++
+								}
+							l435:
+								{
+									position437, tokenIndex437, depth437 := position, tokenIndex, depth
+									if buffer[position] != rune('e') {
+										goto l438
+									}
+									position++
+									goto l437
+								l438:
+									position, tokenIndex, depth = position437, tokenIndex437, depth437
+									if buffer[position] != rune('E') {
+										goto l16
+									}
+									position++
+								}
+							l437:
+								{
+									position439, tokenIndex439, depth439 := position, tokenIndex, depth
+									if buffer[position] != rune('s') {
+										goto l440
+									}
+									position++
+									goto l439
+								l440:
+									position, tokenIndex, depth = position439, tokenIndex439, depth439
+									if buffer[position] != rune('S') {
+										goto l16
+									}
+									position++
+								}
+							l439:
+								depth--
+								add(rulematches, position426)
+							}
+						l441:
+							{
+								position442, tokenIndex442, depth442 := position, tokenIndex, depth
+								if buffer[position] != rune(' ') {
+									goto l442
+								}
+								position++
+								goto l441
+							l442:
+								position, tokenIndex, depth = position442, tokenIndex442, depth442
+							}
+							if !_rules[rulevalue]() {
+								goto l16
+							}
 							break
 						default:
 							{
@@ -1314,9 +2003,48 @@ func (p *QueryParser) Init() {
 			position, tokenIndex, depth = position16, tokenIndex16, depth16
 			return false
 		},
-		/* 2 tag <- <<(!((&('<') '<') | (&('>') '>') | (&('=') '=') | (&('\'') '\'') | (&('"') '"') | (&(')') ')') | (&('(') '(') | (&('\\') '\\') | (&('\r') '\r') | (&('\n') '\n') | (&('\t') '\t') | (&(' ') ' ')) .)+>> */
+		/* 5 operand <- <(number / time / date / value)> */
+		func() bool {
+			position367, tokenIndex367, depth367 := position, tokenIndex, depth
+			{
+				position368 := position
+				depth++
+				{
+					position369, tokenIndex369, depth369 := position, tokenIndex, depth
+					if !_rules[rulenumber]() {
+						goto l370
+					}
+					goto l369
+				l370:
+					position, tokenIndex, depth = position369, tokenIndex369, depth369
+					if !_rules[ruletime]() {
+						goto l371
+					}
+					goto l369
+				l371:
+					position, tokenIndex, depth = position369, tokenIndex369, depth369
+					if !_rules[ruledate]() {
+						goto l372
+					}
+					goto l369
+				l372:
+					position, tokenIndex, depth = position369, tokenIndex369, depth369
+					if !_rules[rulevalue]() {
+						goto l367
+					}
+				}
+			l369:
+				depth--
+				add(ruleoperand, position368)
+			}
+			return true
+		l367:
+			position, tokenIndex, depth = position367, tokenIndex367, depth367
+			return false
+		},
+		/* 6 tag <- <<(!((&('<') '<') | (&('>') '>') | (&('=') '=') | (&('\'') '\'') | (&('"') '"') | (&(')') ')') | (&('(') '(') | (&('\\') '\\') | (&('\r') '\r') | (&('\n') '\n') | (&('\t') '\t') | (&(' ') ' ')) .)+>> */
 		nil,
-		/* 3 value <- <<('\'' (!('"' / '\'') .)* '\'')>> */
+		/* 7 value <- <<('\'' (!('"' / '\'') .)* '\'')>> */
 		func() bool {
 			position85, tokenIndex85, depth85 := position, tokenIndex, depth
 			{
@@ -1375,7 +2103,7 @@ func (p *QueryParser) Init() {
 			position, tokenIndex, depth = position85, tokenIndex85, depth85
 			return false
 		},
-		/* 4 number <- <<('0' / ([1-9] digit* ('.' digit*)?))>> */
+		/* 8 number <- <<('0' / ([1-9] digit* ('.' digit*)?))>> */
 		func() bool {
 			position93, tokenIndex93, depth93 := position, tokenIndex, depth
 			{
@@ -1441,7 +2169,7 @@ func (p *QueryParser) Init() {
 			position, tokenIndex, depth = position93, tokenIndex93, depth93
 			return false
 		},
-		/* 5 digit <- <[0-9]> */
+		/* 9 digit <- <[0-9]> */
 		func() bool {
 			position104, tokenIndex104, depth104 := position, tokenIndex, depth
 			{
@@ -1459,7 +2187,7 @@ func (p *QueryParser) Init() {
 			position, tokenIndex, depth = position104, tokenIndex104, depth104
 			return false
 		},
-		/* 6 time <- <(('t' / 'T') ('i' / 'I') ('m' / 'M') ('e' / 'E') ' ' <(year '-' month '-' day 'T' digit digit ':' digit digit ':' digit digit ((('-' / '+') digit digit ':' digit digit) / 'Z'))>)> */
+		/* 10 time <- <(('t' / 'T') ('i' / 'I') ('m' / 'M') ('e' / 'E') ' ' <(year '-' month '-' day 'T' digit digit ':' digit digit ':' digit digit ((('-' / '+') digit digit ':' digit digit) / 'Z'))>)> */
 		func() bool {
 			position106, tokenIndex106, depth106 := position, tokenIndex, depth
 			{
@@ -1632,7 +2360,7 @@ func (p *QueryParser) Init() {
 			position, tokenIndex, depth = position106, tokenIndex106, depth106
 			return false
 		},
-		/* 7 date <- <(('d' / 'D') ('a' / 'A') ('t' / 'T') ('e' / 'E') ' ' <(year '-' month '-' day)>)> */
+		/* 11 date <- <(('d' / 'D') ('a' / 'A') ('t' / 'T') ('e' / 'E') ' ' <(year '-' month '-' day)>)> */
 		func() bool {
 			position121, tokenIndex121, depth121 := position, tokenIndex, depth
 			{
@@ -1733,7 +2461,7 @@ func (p *QueryParser) Init() {
 			position, tokenIndex, depth = position121, tokenIndex121, depth121
 			return false
 		},
-		/* 8 year <- <(('1' / '2') digit digit digit)> */
+		/* 12 year <- <(('1' / '2') digit digit digit)> */
 		func() bool {
 			position132, tokenIndex132, depth132 := position, tokenIndex, depth
 			{
@@ -1771,7 +2499,7 @@ func (p *QueryParser) Init() {
 			position, tokenIndex, depth = position132, tokenIndex132, depth132
 			return false
 		},
-		/* 9 month <- <(('0' / '1') digit)> */
+		/* 13 month <- <(('0' / '1') digit)> */
 		func() bool {
 			position136, tokenIndex136, depth136 := position, tokenIndex, depth
 			{
@@ -1803,7 +2531,7 @@ func (p *QueryParser) Init() {
 			position, tokenIndex, depth = position136, tokenIndex136, depth136
 			return false
 		},
-		/* 10 day <- <(((&('3') '3') | (&('2') '2') | (&('1') '1') | (&('0') '0')) digit)> */
+		/* 14 day <- <(((&('3') '3') | (&('2') '2') | (&('1') '1') | (&('0') '0')) digit)> */
 		func() bool {
 			position140, tokenIndex140, depth140 := position, tokenIndex, depth
 			{
@@ -1849,21 +2577,31 @@ func (p *QueryParser) Init() {
 			position, tokenIndex, depth = position140, tokenIndex140, depth140
 			return false
 		},
-		/* 11 and <- <(('a' / 'A') ('n' / 'N') ('d' / 'D'))> */
+		/* 15 and <- <(('a' / 'A') ('n' / 'N') ('d' / 'D'))> */
+		nil,
+		/* 16 or <- <(('o' / 'O') ('r' / 'R'))> */
+		nil,
+		/* 17 not <- <(('n' / 'N') ('o' / 'O') ('t' / 'T'))> */
+		nil,
+		/* 18 equal <- <'='> */
+		nil,
+		/* 19 contains <- <(('c' / 'C') ('o' / 'O') ('n' / 'N') ('t' / 'T') ('a' / 'A') ('i' / 'I') ('n' / 'N') ('s' / 'S'))> */
+		nil,
+		/* 20 exists <- <(('e' / 'E') ('x' / 'X') ('i' / 'I') ('s' / 'S') ('t' / 'T') ('s' / 'S'))> */
 		nil,
-		/* 12 equal <- <'='> */
+		/* 21 in <- <(('i' / 'I') ('n' / 'N'))> */
 		nil,
-		/* 13 contains <- <(('c' / 'C') ('o' / 'O') ('n' / 'N') ('t' / 'T') ('a' / 'A') ('i' / 'I') ('n' / 'N') ('s' / 'S'))> */
+		/* 22 startswith <- <(('s' / 'S') ('t' / 'T') ('a' / 'A') ('r' / 'R') ('t' / 'T') ('s' / 'S') ' '+ ('w' / 'W') ('i' / 'I') ('t' / 'T') ('h' / 'H'))> */
 		nil,
-		/* 14 exists <- <(('e' / 'E') ('x' / 'X') ('i' / 'I') ('s' / 'S') ('t' / 'T') ('s' / 'S'))> */
+		/* 23 matches <- <(('m' / 'M') ('a' / 'A') ('t' / 'T') ('c' / 'C') ('h' / 'H') ('e' / 'E') ('s' / 'S'))> */
 		nil,
-		/* 15 le <- <('<' '=')> */
+		/* 24 le <- <('<' '=')> */
 		nil,
-		/* 16 ge <- <('>' '=')> */
+		/* 25 ge <- <('>' '=')> */
 		nil,
-		/* 17 l <- <'<'> */
+		/* 26 l <- <'<'> */
 		nil,
-		/* 18 g <- <'>'> */
+		/* 27 g <- <'>'> */
 		nil,
 		nil,
 	}
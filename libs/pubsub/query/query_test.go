@@ -173,6 +173,31 @@ func TestMatches(t *testing.T) {
 			false,
 			false,
 		},
+		{"transfer.recipient = 'Igor' OR transfer.recipient = 'Pavel'",
+			map[string][]string{"transfer.recipient": {"Pavel"}}, false, true, false},
+		{"transfer.recipient = 'Igor' OR transfer.recipient = 'Pavel'",
+			map[string][]string{"transfer.recipient": {"Ivan"}}, false, false, false},
+		{"transfer.sender = 'Ivan' AND transfer.recipient = 'Igor' OR transfer.recipient = 'Pavel'",
+			map[string][]string{"transfer.sender": {"Igor"}, "transfer.recipient": {"Pavel"}}, false, true, false},
+		{"transfer.sender = 'Ivan' AND (transfer.recipient = 'Igor' OR transfer.recipient = 'Pavel')",
+			map[string][]string{"transfer.sender": {"Igor"}, "transfer.recipient": {"Pavel"}}, false, false, false},
+		{"NOT transfer.recipient = 'Igor'", map[string][]string{"transfer.recipient": {"Pavel"}}, false, true, false},
+		{"NOT transfer.recipient = 'Igor'", map[string][]string{"transfer.recipient": {"Igor", "Pavel"}}, false, false, false},
+		{"NOT slash EXISTS", map[string][]string{"transfer.recipient": {"Igor"}}, false, true, false},
+		{"NOT (tx.gas > 7 AND tx.gas < 9)", map[string][]string{"tx.gas": {"10"}}, false, true, false},
+		{"transfer.recipient IN ('Igor', 'Pavel')", map[string][]string{"transfer.recipient": {"Pavel"}}, false, true, false},
+		{"transfer.recipient IN ('Igor', 'Pavel')", map[string][]string{"transfer.recipient": {"Ivan"}}, false, false, false},
+		{"tx.gas IN (7, 8.5)", map[string][]string{"tx.gas": {"8.5"}}, false, true, false},
+		{"tx.gas IN (7, 8)", map[string][]string{"tx.gas": {"gas"}}, false, false, true},
+		{"tx.gas > 7 OR tx.gas < 3", map[string][]string{"tx.gas": {"gas"}}, false, false, true},
+		{"transfer.recipient STARTS WITH 'cosmos1'", map[string][]string{"transfer.recipient": {"cosmos1abc"}}, false, true, false},
+		{"transfer.recipient STARTS WITH 'cosmos1'", map[string][]string{"transfer.recipient": {"acosmos1"}}, false, false, false},
+		{"message.action MATCHES '*send*'", map[string][]string{"message.action": {"multisend_tokens"}}, false, true, false},
+		{"message.action MATCHES 'send?'", map[string][]string{"message.action": {"sends", "send"}}, false, true, false},
+		{"message.action MATCHES 'send?'", map[string][]string{"message.action": {"send"}}, false, false, false},
+		{"message.action MATCHES 'send'", map[string][]string{"message.action": {"multisend"}}, false, false, false},
+		{"message.action MATCHES '\\*.send'", map[string][]string{"message.action": {"*.send"}}, false, true, false},
+		{"message.action MATCHES '\\*.send'", map[string][]string{"message.action": {"a.send"}}, false, false, false},
 	}
 
 	for _, tc := range testCases {
@@ -234,6 +259,21 @@ func TestConditions(t *testing.T) {
 				{CompositeKey: "slashing", Op: query.OpExists},
 			},
 		},
+		{
+			s: "transfer.recipient STARTS WITH 'cosmos1' AND message.action MATCHES 'send*'",
+			conditions: []query.Condition{
+				{CompositeKey: "transfer.recipient", Op: query.OpStartsWith, Operand: "cosmos1"},
+				{CompositeKey: "message.action", Op: query.OpMatches, Operand: mustPattern(t, "send*")},
+			},
+		},
+		{
+			s: "tx.gas > 7 AND (tx.gas < 9 AND tx.height IN (1, 'one'))",
+			conditions: []query.Condition{
+				{CompositeKey: "tx.gas", Op: query.OpGreater, Operand: int64(7)},
+				{CompositeKey: "tx.gas", Op: query.OpLess, Operand: int64(9)},
+				{CompositeKey: "tx.height", Op: query.OpIn, Operand: []interface{}{int64(1), "one"}},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -244,4 +284,50 @@ func TestConditions(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, tc.conditions, c)
 	}
+
+	for _, s := range []string{"tx.gas > 7 OR tx.gas < 9", "tx.gas > 7 AND NOT tx.gas < 9"} {
+		_, err := query.MustParse(s).Conditions()
+		require.Error(t, err, s)
+	}
+}
+
+func TestExpr(t *testing.T) {
+	cond := func(key string, value int64) *query.Expr {
+		return &query.Expr{
+			Type:      query.ExprCondition,
+			Condition: query.Condition{CompositeKey: key, Op: query.OpEqual, Operand: value},
+		}
+	}
+
+	testCases := []struct {
+		s    string
+		expr *query.Expr
+	}{
+		{"a.b = 1", cond("a.b", 1)},
+		{"(a.b = 1)", cond("a.b", 1)},
+		{"a.b = 1 AND a.c = 2 OR a.d = 3", &query.Expr{Type: query.ExprOr, Args: []*query.Expr{
+			{Type: query.ExprAnd, Args: []*query.Expr{cond("a.b", 1), cond("a.c", 2)}},
+			cond("a.d", 3),
+		}}},
+		{"a.b = 1 AND (a.c = 2 OR a.d = 3)", &query.Expr{Type: query.ExprAnd, Args: []*query.Expr{
+			cond("a.b", 1),
+			{Type: query.ExprOr, Args: []*query.Expr{cond("a.c", 2), cond("a.d", 3)}},
+		}}},
+		{"a.b = 1 OR (a.c = 2 OR a.d = 3)", &query.Expr{Type: query.ExprOr, Args: []*query.Expr{
+			cond("a.b", 1), cond("a.c", 2), cond("a.d", 3),
+		}}},
+		{"NOT a.b = 1 AND a.c = 2", &query.Expr{Type: query.ExprAnd, Args: []*query.Expr{
+			{Type: query.ExprNot, Args: []*query.Expr{cond("a.b", 1)}},
+			cond("a.c", 2),
+		}}},
+		{"NOT (a.b = 1 AND a.c = 2)", &query.Expr{Type: query.ExprNot, Args: []*query.Expr{
+			{Type: query.ExprAnd, Args: []*query.Expr{cond("a.b", 1), cond("a.c", 2)}},
+		}}},
+	}
+
+	for _, tc := range testCases {
+		q, err := query.New(tc.s)
+		require.NoError(t, err)
+		require.Equal(t, tc.expr, q.Expr(), tc.s)
+	}
 }
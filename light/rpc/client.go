@@ -489,6 +489,17 @@ func (c *Client) TxSearch(
 	return c.next.TxSearch(ctx, query, prove, page, perPage, orderBy)
 }
 
+func (c *Client) TxSearchWithCursor(
+	ctx context.Context,
+	query string,
+	prove bool,
+	perPage *int,
+	orderBy string,
+	cursor string,
+) (*coretypes.ResultTxSearch, error) {
+	return c.next.TxSearchWithCursor(ctx, query, prove, perPage, orderBy, cursor)
+}
+
 func (c *Client) BlockSearch(
 	ctx context.Context,
 	query string,
@@ -498,6 +509,16 @@ func (c *Client) BlockSearch(
 	return c.next.BlockSearch(ctx, query, page, perPage, orderBy)
 }
 
+func (c *Client) BlockSearchWithCursor(
+	ctx context.Context,
+	query string,
+	perPage *int,
+	orderBy string,
+	cursor string,
+) (*coretypes.ResultBlockSearch, error) {
+	return c.next.BlockSearchWithCursor(ctx, query, perPage, orderBy, cursor)
+}
+
 // Validators fetches and verifies validators.
 func (c *Client) Validators(
 	ctx context.Context,
@@ -2,6 +2,7 @@ package node
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
@@ -18,10 +19,12 @@ import (
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/internal/consensus"
 	"github.com/tendermint/tendermint/internal/eventbus"
+	"github.com/tendermint/tendermint/internal/libs/compress"
 	"github.com/tendermint/tendermint/internal/mempool"
 	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/internal/proxy"
 	rpccore "github.com/tendermint/tendermint/internal/rpc/core"
+	rpcgrpc "github.com/tendermint/tendermint/internal/rpc/grpc"
 	sm "github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/internal/state/indexer"
 	"github.com/tendermint/tendermint/internal/statesync"
@@ -34,8 +37,11 @@ import (
 	tmtime "github.com/tendermint/tendermint/libs/time"
 	"github.com/tendermint/tendermint/privval"
 	tmgrpc "github.com/tendermint/tendermint/privval/grpc"
+	rpcproto "github.com/tendermint/tendermint/proto/tendermint/rpc"
 	rpcserver "github.com/tendermint/tendermint/rpc/jsonrpc/server"
 	"github.com/tendermint/tendermint/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	_ "net/http/pprof" // nolint: gosec // securely exposed on separate, optional port
 
@@ -73,9 +79,12 @@ type nodeImpl struct {
 	pexReactor       service.Service    // for exchanging peer addresses
 	evidenceReactor  service.Service
 	rpcListeners     []net.Listener // rpc servers
+	grpcServer       *grpc.Server   // nil unless the gRPC server is enabled
 	shutdownOps      closer
 	indexerService   service.Service
+	pruner           *sm.Pruner // nil unless a pruning policy is configured or events are indexed
 	rpcEnv           *rpccore.Environment
+	rpcMetrics       *rpcserver.Metrics
 	prometheusSrv    *http.Server
 }
 
@@ -129,13 +138,18 @@ func makeNode(cfg *config.Config,
 ) (service.Service, error) {
 	closers := []closer{}
 
-	blockStore, stateDB, dbCloser, err := initDBs(cfg, dbProvider)
+	compressor, err := compress.NewCompressor(cfg.Storage.Compression, cfg.Storage.CompressionLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	blockStore, stateDB, dbCloser, err := initDBs(cfg, dbProvider, compressor)
 	if err != nil {
 		return nil, combineCloseError(err, dbCloser)
 	}
 	closers = append(closers, dbCloser)
 
-	stateStore := sm.NewStore(stateDB)
+	stateStore := sm.NewStore(stateDB, sm.StoreWithCompressor(compressor))
 
 	genDoc, err := genesisDocProvider()
 	if err != nil {
@@ -166,13 +180,17 @@ func makeNode(cfg *config.Config,
 	// we might need to index the txs of the replayed block as this might not have happened
 	// when the node stopped last time (i.e. the node stopped after it saved the block
 	// but before it indexed the txs, or, endblocker panicked)
-	eventBus, err := createAndStartEventBus(logger)
+	eventLog, err := createEventLog(cfg.RPC)
+	if err != nil {
+		return nil, combineCloseError(err, makeCloser(closers))
+	}
+	eventBus, err := createAndStartEventBus(logger, eventLog)
 	if err != nil {
 		return nil, combineCloseError(err, makeCloser(closers))
 	}
 
 	indexerService, eventSinks, err := createAndStartIndexerService(cfg, dbProvider, eventBus,
-		logger, genDoc.ChainID, nodeMetrics.indexer)
+		logger, genDoc.ChainID, nodeMetrics.indexer, blockStore, stateStore)
 	if err != nil {
 		return nil, combineCloseError(err, makeCloser(closers))
 	}
@@ -283,6 +301,31 @@ func makeNode(cfg *config.Config,
 		return nil, combineCloseError(err, makeCloser(closers))
 	}
 
+	blockExecOptions := []sm.BlockExecutorOption{sm.BlockExecutorWithMetrics(nodeMetrics.state)}
+
+	// prune according to the operator's pruning policy, if any. Indexed events
+	// are only pruned by the pruner, in the background, so it also prunes at
+	// the application's request if events are indexed.
+	var pruner *sm.Pruner
+	if cfg.Pruning.Enabled() || indexer.IndexingEnabled(eventSinks) {
+		pruner, err = sm.NewPruner(sm.PrunerArgs{
+			StateStore: stateStore,
+			BlockStore: blockStore,
+			EventSinks: eventSinks,
+			Policy: sm.PruningPolicy{
+				Archive:    cfg.Pruning.Archive,
+				KeepBlocks: int64(cfg.Pruning.KeepBlocks),
+				KeepTime:   cfg.Pruning.KeepTime,
+			},
+			Interval: cfg.Pruning.Interval,
+			Logger:   logger.With("module", "pruner"),
+		})
+		if err != nil {
+			return nil, combineCloseError(err, makeCloser(closers))
+		}
+		blockExecOptions = append(blockExecOptions, sm.BlockExecutorWithPruner(pruner))
+	}
+
 	// make block executor for consensus and blockchain reactors to execute blocks
 	blockExec := sm.NewBlockExecutor(
 		stateStore,
@@ -291,7 +334,7 @@ func makeNode(cfg *config.Config,
 		mp,
 		evPool,
 		blockStore,
-		sm.BlockExecutorWithMetrics(nodeMetrics.state),
+		blockExecOptions...,
 	)
 
 	csReactor, csState, err := createConsensusReactor(
@@ -359,7 +402,7 @@ func makeNode(cfg *config.Config,
 
 	var pexReactor service.Service
 	if cfg.P2P.PexReactor {
-		pexReactor, err = createPEXReactor(logger, peerManager, router)
+		pexReactor, err = createPEXReactor(cfg, logger, peerManager, router)
 		if err != nil {
 			return nil, combineCloseError(err, makeCloser(closers))
 		}
@@ -386,11 +429,13 @@ func makeNode(cfg *config.Config,
 		pexReactor:       pexReactor,
 		evidenceReactor:  evReactor,
 		indexerService:   indexerService,
+		pruner:           pruner,
 		eventBus:         eventBus,
 		eventSinks:       eventSinks,
 
 		shutdownOps: makeCloser(closers),
 
+		rpcMetrics: nodeMetrics.rpc,
 		rpcEnv: &rpccore.Environment{
 			ProxyAppQuery:   proxyApp.Query(),
 			ProxyAppMempool: proxyApp.Mempool(),
@@ -404,13 +449,16 @@ func makeNode(cfg *config.Config,
 			BlockSyncReactor: bcReactor.(consensus.BlockSyncReactor),
 
 			PeerManager: peerManager,
-
-			GenDoc:     genDoc,
-			EventSinks: eventSinks,
-			EventBus:   eventBus,
-			Mempool:    mp,
-			Logger:     logger.With("module", "rpc"),
-			Config:     *cfg.RPC,
+			Router:      router,
+
+			GenDoc:         genDoc,
+			EventSinks:     eventSinks,
+			IndexerService: indexerService,
+			EventBus:       eventBus,
+			EventLog:       eventLog,
+			Mempool:        mp,
+			Logger:         logger.With("module", "rpc"),
+			Config:         *cfg.RPC,
 		},
 	}
 
@@ -465,7 +513,7 @@ func makeSeedNode(cfg *config.Config,
 			closer)
 	}
 
-	pexReactor, err := createPEXReactor(logger, peerManager, router)
+	pexReactor, err := createPEXReactor(cfg, logger, peerManager, router)
 	if err != nil {
 		return nil, combineCloseError(err, closer)
 	}
@@ -509,12 +557,20 @@ func (n *nodeImpl) OnStart() error {
 
 	// Start the RPC server before the P2P server
 	// so we can eg. receive txs for the first block
-	if n.config.RPC.ListenAddress != "" && n.config.Mode != config.ModeSeed {
+	if (n.config.RPC.ListenAddress != "" || n.config.RPC.GRPCListenAddress != "") &&
+		n.config.Mode != config.ModeSeed {
 		listeners, err := n.startRPC()
 		if err != nil {
 			return err
 		}
 		n.rpcListeners = listeners
+
+		if n.config.RPC.GRPCListenAddress != "" {
+			n.grpcServer, err = n.startGRPCServer()
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	if n.config.Instrumentation.Prometheus &&
@@ -552,6 +608,12 @@ func (n *nodeImpl) OnStart() error {
 		if err := n.evidenceReactor.Start(); err != nil {
 			return err
 		}
+
+		if n.pruner != nil {
+			if err := n.pruner.Start(); err != nil {
+				return err
+			}
+		}
 	}
 
 	if n.config.P2P.PexReactor {
@@ -650,6 +712,12 @@ func (n *nodeImpl) OnStop() {
 		}
 	}
 
+	if n.pruner != nil && n.pruner.IsRunning() {
+		if err := n.pruner.Stop(); err != nil {
+			n.Logger.Error("failed to stop the pruner", "err", err)
+		}
+	}
+
 	for _, es := range n.eventSinks {
 		if err := es.Stop(); err != nil {
 			n.Logger.Error("failed to stop event sink", "err", err)
@@ -702,6 +770,11 @@ func (n *nodeImpl) OnStop() {
 		}
 	}
 
+	if n.grpcServer != nil {
+		n.Logger.Info("Stopping gRPC server")
+		n.grpcServer.Stop()
+	}
+
 	if pvsc, ok := n.privValidator.(service.Service); ok {
 		if err := pvsc.Stop(); err != nil {
 			n.Logger.Error("Error closing private validator", "err", err)
@@ -743,6 +816,16 @@ func (n *nodeImpl) startRPC() ([]net.Listener, error) {
 	cfg.MaxBodyBytes = n.config.RPC.MaxBodyBytes
 	cfg.MaxHeaderBytes = n.config.RPC.MaxHeaderBytes
 	cfg.MaxOpenConnections = n.config.RPC.MaxOpenConnections
+	cfg.MaxBatchSize = n.config.RPC.MaxBatchSize
+	cfg.Metrics = n.rpcMetrics
+	cfg.RateLimit = n.rpcRateLimitConfig()
+	if n.config.RPC.IsAuthEnabled() {
+		auth, err := n.rpcAuthConfig()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Auth = auth
+	}
 	// If necessary adjust global WriteTimeout to ensure it's greater than
 	// TimeoutBroadcastTxCommit.
 	// See https://github.com/tendermint/tendermint/issues/3435
@@ -817,6 +900,95 @@ func (n *nodeImpl) startRPC() ([]net.Listener, error) {
 	return listeners, nil
 }
 
+// startGRPCServer starts a gRPC server exposing the RPC environment, listening
+// on the gRPC listen address. It uses TLS if the RPC server does.
+func (n *nodeImpl) startGRPCServer() (*grpc.Server, error) {
+	listener, err := rpcserver.Listen(
+		n.config.RPC.GRPCListenAddress,
+		n.config.RPC.GRPCMaxOpenConnections,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		opts []grpc.ServerOption
+		auth *rpcserver.AuthConfig
+	)
+	if n.config.RPC.IsAuthEnabled() {
+		auth, err = n.rpcAuthConfig()
+		if err != nil {
+			_ = listener.Close()
+			return nil, err
+		}
+		opts = append(opts, rpcgrpc.AuthServerOptions(auth)...)
+	}
+	// The rate limit follows the authentication, to identify the clients by
+	// their name.
+	if rateLimit := n.rpcRateLimitConfig(); rateLimit != nil {
+		opts = append(opts, rpcgrpc.RateLimitServerOptions(rpcserver.NewRateLimiter(rateLimit, n.rpcMetrics))...)
+	}
+	if n.config.RPC.IsTLSEnabled() {
+		cert, err := tls.LoadX509KeyPair(n.config.RPC.CertFile(), n.config.RPC.KeyFile())
+		if err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("loading TLS credentials of the gRPC server: %w", err)
+		}
+		tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+		if auth != nil && auth.ClientCAs != nil {
+			tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+			tlsConfig.ClientCAs = auth.ClientCAs
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	logger := n.Logger.With("module", "grpc-server")
+	srv := grpc.NewServer(opts...)
+	rpcproto.RegisterRPCServiceServer(srv, rpcgrpc.NewServer(n.rpcEnv, logger))
+	go func() {
+		if err := srv.Serve(listener); err != nil {
+			logger.Error("Error serving gRPC server", "err", err)
+		}
+	}()
+	return srv, nil
+}
+
+// rpcRateLimitConfig returns the configuration limiting the rate of the
+// requests of the clients of the RPC and gRPC servers, or nil if the rate is
+// unlimited.
+func (n *nodeImpl) rpcRateLimitConfig() *rpcserver.RateLimitConfig {
+	if !n.config.RPC.IsRateLimitEnabled() {
+		return nil
+	}
+	return &rpcserver.RateLimitConfig{
+		Rate:      n.config.RPC.RateLimit,
+		Burst:     n.config.RPC.RateLimitBurst,
+		Costs:     n.config.RPC.RateLimitCosts,
+		BatchCost: n.config.RPC.RateLimitBatchCost,
+	}
+}
+
+// rpcAuthConfig loads the configuration authenticating the clients of the RPC
+// and gRPC servers.
+func (n *nodeImpl) rpcAuthConfig() (*rpcserver.AuthConfig, error) {
+	auth := new(rpcserver.AuthConfig)
+	if n.config.RPC.AuthFile != "" {
+		var err error
+		auth, err = rpcserver.LoadAuthFile(n.config.RPC.AuthFilePath())
+		if err != nil {
+			return nil, err
+		}
+	}
+	if n.config.RPC.TLSClientCAFile != "" {
+		pool, err := rpcserver.LoadClientCAs(n.config.RPC.ClientCAFile())
+		if err != nil {
+			return nil, fmt.Errorf("loading RPC client CAs: %w", err)
+		}
+		auth.ClientCAs = pool
+	}
+	return auth, nil
+}
+
 // startPrometheusServer starts a Prometheus HTTP server, listening for metrics
 // collectors on addr.
 func (n *nodeImpl) startPrometheusServer(addr string) *http.Server {
@@ -905,11 +1077,12 @@ type nodeMetrics struct {
 	mempool   *mempool.Metrics
 	p2p       *p2p.Metrics
 	proxy     *proxy.Metrics
+	rpc       *rpcserver.Metrics
 	state     *sm.Metrics
 	statesync *statesync.Metrics
 }
 
-// metricsProvider returns consensus, p2p, mempool, state, statesync Metrics.
+// metricsProvider returns consensus, p2p, mempool, rpc, state, statesync Metrics.
 type metricsProvider func(chainID string) *nodeMetrics
 
 // defaultMetricsProvider returns Metrics build using Prometheus client library
@@ -923,6 +1096,7 @@ func defaultMetricsProvider(cfg *config.InstrumentationConfig) metricsProvider {
 				mempool:   mempool.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
 				p2p:       p2p.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
 				proxy:     proxy.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
+				rpc:       rpcserver.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
 				state:     sm.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
 				statesync: statesync.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
 			}
@@ -933,6 +1107,7 @@ func defaultMetricsProvider(cfg *config.InstrumentationConfig) metricsProvider {
 			mempool:   mempool.NopMetrics(),
 			p2p:       p2p.NopMetrics(),
 			proxy:     proxy.NopMetrics(),
+			rpc:       rpcserver.NopMetrics(),
 			state:     sm.NopMetrics(),
 			statesync: statesync.NopMetrics(),
 		}
@@ -1026,6 +1201,15 @@ func getRouterConfig(conf *config.Config, proxyApp proxy.AppConns) p2p.RouterOpt
 		QueueType: conf.P2P.QueueType,
 	}
 
+	if path := conf.P2P.CaptureFilePath(); path != "" {
+		// the filters have already been validated by ValidateBasic
+		peers, channels, _ := conf.P2P.CaptureFilters()
+		opts.Capture = &p2p.CaptureOptions{Path: path, Peers: peers}
+		for _, chID := range channels {
+			opts.Capture.Channels = append(opts.Capture.Channels, p2p.ChannelID(chID))
+		}
+	}
+
 	if conf.FilterPeers && proxyApp != nil {
 		opts.FilterPeerByID = func(ctx context.Context, id types.NodeID) error {
 			res, err := proxyApp.Query().QuerySync(context.Background(), abci.RequestQuery{
@@ -551,7 +551,7 @@ func TestNodeSetEventSink(t *testing.T) {
 
 	logger := log.TestingLogger()
 	setupTest := func(t *testing.T, conf *config.Config) []indexer.EventSink {
-		eventBus, err := createAndStartEventBus(logger)
+		eventBus, err := createAndStartEventBus(logger, nil)
 		require.NoError(t, err)
 		t.Cleanup(func() { require.NoError(t, eventBus.Stop()) })
 		genDoc, err := types.GenesisDocFromFile(cfg.GenesisFile())
@@ -559,7 +559,7 @@ func TestNodeSetEventSink(t *testing.T) {
 
 		indexService, eventSinks, err := createAndStartIndexerService(cfg,
 			config.DefaultDBProvider, eventBus, logger, genDoc.ChainID,
-			indexer.NopMetrics())
+			indexer.NopMetrics(), store.NewBlockStore(dbm.NewMemDB()), sm.NewStore(dbm.NewMemDB()))
 		require.NoError(t, err)
 		t.Cleanup(func() { require.NoError(t, indexService.Stop()) })
 		return eventSinks
@@ -15,7 +15,9 @@ import (
 	"github.com/tendermint/tendermint/internal/blocksync"
 	"github.com/tendermint/tendermint/internal/consensus"
 	"github.com/tendermint/tendermint/internal/eventbus"
+	"github.com/tendermint/tendermint/internal/eventlog"
 	"github.com/tendermint/tendermint/internal/evidence"
+	"github.com/tendermint/tendermint/internal/libs/compress"
 	"github.com/tendermint/tendermint/internal/mempool"
 	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/internal/p2p/conn"
@@ -68,6 +70,7 @@ func combineCloseError(err error, cl closer) error {
 func initDBs(
 	cfg *config.Config,
 	dbProvider config.DBProvider,
+	compressor *compress.Compressor,
 ) (*store.BlockStore, dbm.DB, closer, error) {
 
 	blockStoreDB, err := dbProvider(&config.DBContext{ID: "blockstore", Config: cfg})
@@ -75,7 +78,7 @@ func initDBs(
 		return nil, nil, func() error { return nil }, err
 	}
 	closers := []closer{}
-	blockStore := store.NewBlockStore(blockStoreDB)
+	blockStore := store.NewBlockStore(blockStoreDB, store.WithCompressor(compressor))
 	closers = append(closers, blockStoreDB.Close)
 
 	stateDB, err := dbProvider(&config.DBContext{ID: "state", Config: cfg})
@@ -98,9 +101,24 @@ func createAndStartProxyAppConns(clientCreator abciclient.Creator, logger log.Lo
 	return proxyApp, nil
 }
 
-func createAndStartEventBus(logger log.Logger) (*eventbus.EventBus, error) {
+// createEventLog returns the log of the events served by the events RPC
+// method, or nil if it is disabled.
+func createEventLog(cfg *config.RPCConfig) (*eventlog.Log, error) {
+	if cfg.EventLogWindowSize == 0 {
+		return nil, nil
+	}
+	return eventlog.New(eventlog.Options{
+		WindowSize: cfg.EventLogWindowSize,
+		MaxItems:   cfg.EventLogMaxItems,
+	})
+}
+
+func createAndStartEventBus(logger log.Logger, eventLog *eventlog.Log) (*eventbus.EventBus, error) {
 	eventBus := eventbus.NewDefault()
 	eventBus.SetLogger(logger.With("module", "events"))
+	if eventLog != nil {
+		eventBus.SetEventLog(eventLog)
+	}
 	if err := eventBus.Start(); err != nil {
 		return nil, err
 	}
@@ -114,18 +132,31 @@ func createAndStartIndexerService(
 	logger log.Logger,
 	chainID string,
 	metrics *indexer.Metrics,
+	blockStore *store.BlockStore,
+	stateStore sm.Store,
 ) (*indexer.Service, []indexer.EventSink, error) {
 	eventSinks, err := sink.EventSinksFromConfig(cfg, dbProvider, chainID)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	indexerService := indexer.NewService(indexer.ServiceArgs{
+	args := indexer.ServiceArgs{
 		Sinks:    eventSinks,
 		EventBus: eventBus,
 		Logger:   logger.With("module", "txindex"),
 		Metrics:  metrics,
-	})
+	}
+	if indexer.IndexingEnabled(eventSinks) {
+		// The indexed heights of the sinks are tracked to backfill them.
+		db, err := dbProvider(&config.DBContext{ID: "indexer", Config: cfg})
+		if err != nil {
+			return nil, nil, err
+		}
+		args.BlockStore = blockStore
+		args.StateStore = stateStore
+		args.DB = db
+	}
+	indexerService := indexer.NewService(args)
 
 	if err := indexerService.Start(); err != nil {
 		return nil, nil, err
@@ -488,6 +519,7 @@ func createRouter(
 }
 
 func createPEXReactor(
+	cfg *config.Config,
 	logger log.Logger,
 	peerManager *p2p.PeerManager,
 	router *p2p.Router,
@@ -498,7 +530,12 @@ func createPEXReactor(
 		return nil, err
 	}
 
-	return pex.NewReactor(logger, peerManager, channel, peerManager.Subscribe()), nil
+	var options []pex.ReactorOption
+	if cfg.Mode == config.ModeSeed {
+		options = append(options, pex.WithSeedMode(router, pex.DefaultSeedOptions()))
+	}
+
+	return pex.NewReactor(logger, peerManager, channel, peerManager.Subscribe(), options...), nil
 }
 
 func makeNodeInfo(
@@ -0,0 +1,1121 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: tendermint/rpc/service.proto
+
+package rpc
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/gogo/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+func init() { proto.RegisterFile("tendermint/rpc/service.proto", fileDescriptor_d170ca344f015d69) }
+
+var fileDescriptor_d170ca344f015d69 = []byte{
+	// 662 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x96, 0x4f, 0x6f, 0xd3, 0x3c,
+	0x1c, 0xc7, 0xd7, 0x47, 0x7a, 0x0a, 0x18, 0xb4, 0x31, 0x4b, 0x1c, 0xa8, 0xb6, 0x8c, 0x6d, 0xfc,
+	0x1b, 0x87, 0x16, 0x81, 0x38, 0x71, 0x61, 0xcd, 0xa6, 0x6d, 0x48, 0x8c, 0xd2, 0x86, 0x49, 0x8c,
+	0x03, 0x4a, 0x1d, 0x8f, 0x44, 0x6d, 0x9c, 0x10, 0x3b, 0x55, 0xfa, 0x2e, 0x78, 0x09, 0xbc, 0x1c,
+	0x8e, 0x3b, 0x72, 0x44, 0xed, 0x1b, 0x41, 0x4d, 0xec, 0x3a, 0x89, 0xe3, 0xb6, 0x12, 0xdc, 0xb6,
+	0xdf, 0xf7, 0x93, 0xcf, 0xb7, 0x69, 0x6c, 0x37, 0x60, 0x8b, 0x61, 0xe2, 0xe0, 0xc8, 0xf7, 0x08,
+	0x6b, 0x45, 0x21, 0x6a, 0x51, 0x1c, 0x8d, 0x3c, 0x84, 0x9b, 0x61, 0x14, 0xb0, 0x00, 0xae, 0xcb,
+	0xb4, 0x19, 0x85, 0xa8, 0xd1, 0x28, 0xd1, 0x6c, 0x1c, 0x62, 0x9a, 0xb1, 0x2f, 0x7e, 0x40, 0x00,
+	0xba, 0x1d, 0xb3, 0x97, 0x09, 0xe0, 0x09, 0xa8, 0x9f, 0x62, 0x7b, 0xc8, 0x5c, 0xb8, 0xdd, 0x2c,
+	0x5a, 0x9a, 0xd9, 0xbc, 0x8b, 0xbf, 0xc5, 0x98, 0xb2, 0x86, 0xa1, 0x8b, 0x69, 0x18, 0x10, 0x9a,
+	0x8a, 0x7a, 0xcc, 0x66, 0x31, 0x55, 0x45, 0xd9, 0x5c, 0x2b, 0x12, 0x31, 0x17, 0xbd, 0x05, 0x37,
+	0xce, 0x31, 0x3b, 0x23, 0x57, 0x01, 0x54, 0x50, 0x1e, 0x08, 0xd5, 0x8e, 0x36, 0xe7, 0xae, 0x01,
+	0x80, 0x47, 0xb1, 0x1f, 0x9a, 0xb3, 0x7f, 0x08, 0x8d, 0xe9, 0xac, 0x0a, 0xc3, 0x83, 0xf2, 0x65,
+	0x2a, 0x23, 0x1a, 0x9e, 0xad, 0x82, 0xf2, 0xb2, 0x2f, 0x60, 0xbd, 0x54, 0xf4, 0xa8, 0x7c, 0x75,
+	0x75, 0xc9, 0xe3, 0x65, 0x98, 0x2c, 0x68, 0x0f, 0x03, 0x34, 0x40, 0xae, 0xed, 0x91, 0xf4, 0x0b,
+	0x52, 0x0a, 0x8a, 0xb9, 0xb6, 0xa0, 0x8c, 0xc9, 0x82, 0x13, 0x4c, 0x30, 0xf5, 0xa8, 0xe9, 0xc6,
+	0x64, 0x80, 0x1d, 0xb5, 0xa0, 0x98, 0x6b, 0x0b, 0xca, 0x18, 0x2f, 0x38, 0x02, 0xff, 0xa7, 0xd5,
+	0x70, 0xab, 0xf2, 0x13, 0x09, 0xdd, 0xb6, 0x26, 0xe5, 0x96, 0x0e, 0xb8, 0x9d, 0x0e, 0xda, 0xe3,
+	0x53, 0x9b, 0xba, 0x70, 0xaf, 0x92, 0xce, 0xc2, 0x15, 0x8d, 0x9f, 0xc0, 0x1d, 0x31, 0x88, 0x87,
+	0x8c, 0xc2, 0x7d, 0x1d, 0x3e, 0x4b, 0x85, 0xf3, 0xe1, 0x62, 0x48, 0xee, 0x0b, 0x33, 0xf0, 0x7d,
+	0x8f, 0xa9, 0xfb, 0x22, 0x9b, 0x6b, 0xf7, 0x85, 0x88, 0xb9, 0xa8, 0x07, 0xc0, 0x85, 0x3d, 0xf4,
+	0x1c, 0x9b, 0x05, 0x11, 0x85, 0xbb, 0x65, 0x5a, 0x66, 0x42, 0xb8, 0xb7, 0x08, 0xe1, 0xd2, 0x3e,
+	0xd8, 0x98, 0x2f, 0xb6, 0x8e, 0x1d, 0xd9, 0x3e, 0x85, 0xfa, 0xd5, 0x98, 0x01, 0x42, 0xff, 0x64,
+	0x29, 0xc7, 0x3b, 0x5e, 0x83, 0xff, 0xac, 0x04, 0xde, 0x2f, 0xe3, 0x56, 0x22, 0x4c, 0x8d, 0xaa,
+	0x88, 0x5f, 0xfc, 0x0e, 0xdc, 0xb4, 0x92, 0x1e, 0xb6, 0x23, 0xe4, 0xc2, 0x1d, 0x95, 0xcb, 0x12,
+	0x21, 0x7a, 0xa0, 0x07, 0xb8, 0xee, 0x82, 0x2f, 0x1d, 0x6e, 0xac, 0x5e, 0x3a, 0x45, 0xe9, 0xfe,
+	0x42, 0x46, 0x1e, 0x5a, 0xa6, 0x8b, 0xd1, 0xc0, 0x4a, 0xd4, 0x43, 0x8b, 0x07, 0xda, 0x43, 0x6b,
+	0x9e, 0xcb, 0x5b, 0xee, 0x62, 0x3f, 0x18, 0x61, 0x2b, 0x51, 0x6f, 0x59, 0x24, 0xda, 0x5b, 0x96,
+	0x80, 0xdc, 0xd4, 0x1f, 0x09, 0x0a, 0xc8, 0x95, 0x17, 0xf9, 0xd8, 0xb1, 0x12, 0xaa, 0x6e, 0xea,
+	0x62, 0xae, 0xdd, 0xd4, 0x65, 0x8c, 0x17, 0x60, 0xb0, 0x79, 0x1e, 0xfb, 0xa5, 0x8e, 0xa7, 0xca,
+	0xd1, 0x1c, 0xfb, 0x7f, 0x57, 0xf3, 0x19, 0xdc, 0x6d, 0x47, 0x81, 0xed, 0x20, 0x9b, 0x32, 0x2b,
+	0x39, 0xa4, 0x63, 0x82, 0x2a, 0x9e, 0x9f, 0x24, 0xf4, 0xcf, 0x2f, 0xcf, 0x70, 0xf9, 0x25, 0xd8,
+	0xc8, 0x8d, 0x7b, 0xff, 0xd4, 0xdd, 0x07, 0x9b, 0xb9, 0x31, 0x3f, 0x0c, 0x56, 0xb1, 0x1f, 0x2c,
+	0x60, 0x4a, 0x87, 0x83, 0x9b, 0xeb, 0x38, 0x1e, 0x79, 0x0e, 0x26, 0x08, 0xab, 0xcf, 0x40, 0x41,
+	0x96, 0x37, 0x49, 0x52, 0xae, 0xce, 0xc3, 0xb6, 0x79, 0x96, 0xfe, 0xfc, 0x28, 0xab, 0x53, 0x24,
+	0xda, 0xd5, 0x29, 0x81, 0xf9, 0x59, 0x7e, 0x6b, 0x36, 0xfb, 0x10, 0xe3, 0x68, 0x0c, 0x2b, 0xf1,
+	0x34, 0x12, 0xc2, 0xdd, 0x05, 0x04, 0x37, 0xbe, 0x01, 0xf5, 0xe3, 0x11, 0x26, 0xac, 0xe2, 0x45,
+	0x24, 0x9b, 0x0b, 0xd7, 0xbd, 0xca, 0xf8, 0x79, 0xad, 0xfd, 0xfe, 0xe7, 0xc4, 0xa8, 0x5d, 0x4f,
+	0x8c, 0xda, 0xef, 0x89, 0x51, 0xfb, 0x3e, 0x35, 0xd6, 0xae, 0xa7, 0xc6, 0xda, 0xaf, 0xa9, 0xb1,
+	0x76, 0xf9, 0xea, 0xab, 0xc7, 0xdc, 0xb8, 0xdf, 0x44, 0x81, 0xdf, 0xca, 0xbd, 0x63, 0xe5, 0xfe,
+	0x4c, 0x5f, 0xb2, 0x5a, 0xc5, 0xf7, 0xaf, 0x7e, 0x3d, 0x9d, 0xbe, 0xfc, 0x33, 0x00, 0x9e, 0x60,
+	0x04, 0x87, 0xc6, 0x09, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// RPCServiceClient is the client API for RPCService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type RPCServiceClient interface {
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	NetInfo(ctx context.Context, in *NetInfoRequest, opts ...grpc.CallOption) (*NetInfoResponse, error)
+	DumpConsensusState(ctx context.Context, in *DumpConsensusStateRequest, opts ...grpc.CallOption) (*DumpConsensusStateResponse, error)
+	ConsensusState(ctx context.Context, in *ConsensusStateRequest, opts ...grpc.CallOption) (*ConsensusStateResponse, error)
+	BlockchainInfo(ctx context.Context, in *BlockchainInfoRequest, opts ...grpc.CallOption) (*BlockchainInfoResponse, error)
+	GenesisChunked(ctx context.Context, in *GenesisChunkedRequest, opts ...grpc.CallOption) (*GenesisChunkedResponse, error)
+	Block(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*BlockResponse, error)
+	BlockByHash(ctx context.Context, in *BlockByHashRequest, opts ...grpc.CallOption) (*BlockResponse, error)
+	BlockResults(ctx context.Context, in *BlockResultsRequest, opts ...grpc.CallOption) (*BlockResultsResponse, error)
+	Commit(ctx context.Context, in *CommitRequest, opts ...grpc.CallOption) (*CommitResponse, error)
+	Validators(ctx context.Context, in *ValidatorsRequest, opts ...grpc.CallOption) (*ValidatorsResponse, error)
+	ConsensusParams(ctx context.Context, in *ConsensusParamsRequest, opts ...grpc.CallOption) (*ConsensusParamsResponse, error)
+	Tx(ctx context.Context, in *TxRequest, opts ...grpc.CallOption) (*TxResponse, error)
+	TxSearch(ctx context.Context, in *TxSearchRequest, opts ...grpc.CallOption) (*TxSearchResponse, error)
+	BlockSearch(ctx context.Context, in *BlockSearchRequest, opts ...grpc.CallOption) (*BlockSearchResponse, error)
+	CheckTx(ctx context.Context, in *CheckTxRequest, opts ...grpc.CallOption) (*CheckTxResponse, error)
+	RemoveTx(ctx context.Context, in *RemoveTxRequest, opts ...grpc.CallOption) (*RemoveTxResponse, error)
+	UnconfirmedTxs(ctx context.Context, in *UnconfirmedTxsRequest, opts ...grpc.CallOption) (*UnconfirmedTxsResponse, error)
+	NumUnconfirmedTxs(ctx context.Context, in *NumUnconfirmedTxsRequest, opts ...grpc.CallOption) (*UnconfirmedTxsResponse, error)
+	BroadcastTxAsync(ctx context.Context, in *BroadcastTxRequest, opts ...grpc.CallOption) (*BroadcastTxResponse, error)
+	BroadcastTxSync(ctx context.Context, in *BroadcastTxRequest, opts ...grpc.CallOption) (*BroadcastTxResponse, error)
+	BroadcastTxCommit(ctx context.Context, in *BroadcastTxRequest, opts ...grpc.CallOption) (*BroadcastTxCommitResponse, error)
+	BroadcastEvidence(ctx context.Context, in *BroadcastEvidenceRequest, opts ...grpc.CallOption) (*BroadcastEvidenceResponse, error)
+	ABCIInfo(ctx context.Context, in *ABCIInfoRequest, opts ...grpc.CallOption) (*ABCIInfoResponse, error)
+	ABCIQuery(ctx context.Context, in *ABCIQueryRequest, opts ...grpc.CallOption) (*ABCIQueryResponse, error)
+	// Events streams the events matching a query until the call is canceled.
+	// Events may be dropped by the node if they are not read fast enough.
+	Events(ctx context.Context, in *EventsRequest, opts ...grpc.CallOption) (RPCService_EventsClient, error)
+}
+
+type rPCServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewRPCServiceClient(cc *grpc.ClientConn) RPCServiceClient {
+	return &rPCServiceClient{cc}
+}
+
+func (c *rPCServiceClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/Health", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/Status", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) NetInfo(ctx context.Context, in *NetInfoRequest, opts ...grpc.CallOption) (*NetInfoResponse, error) {
+	out := new(NetInfoResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/NetInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) DumpConsensusState(ctx context.Context, in *DumpConsensusStateRequest, opts ...grpc.CallOption) (*DumpConsensusStateResponse, error) {
+	out := new(DumpConsensusStateResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/DumpConsensusState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) ConsensusState(ctx context.Context, in *ConsensusStateRequest, opts ...grpc.CallOption) (*ConsensusStateResponse, error) {
+	out := new(ConsensusStateResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/ConsensusState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) BlockchainInfo(ctx context.Context, in *BlockchainInfoRequest, opts ...grpc.CallOption) (*BlockchainInfoResponse, error) {
+	out := new(BlockchainInfoResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/BlockchainInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) GenesisChunked(ctx context.Context, in *GenesisChunkedRequest, opts ...grpc.CallOption) (*GenesisChunkedResponse, error) {
+	out := new(GenesisChunkedResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/GenesisChunked", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) Block(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*BlockResponse, error) {
+	out := new(BlockResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/Block", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) BlockByHash(ctx context.Context, in *BlockByHashRequest, opts ...grpc.CallOption) (*BlockResponse, error) {
+	out := new(BlockResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/BlockByHash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) BlockResults(ctx context.Context, in *BlockResultsRequest, opts ...grpc.CallOption) (*BlockResultsResponse, error) {
+	out := new(BlockResultsResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/BlockResults", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) Commit(ctx context.Context, in *CommitRequest, opts ...grpc.CallOption) (*CommitResponse, error) {
+	out := new(CommitResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/Commit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) Validators(ctx context.Context, in *ValidatorsRequest, opts ...grpc.CallOption) (*ValidatorsResponse, error) {
+	out := new(ValidatorsResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/Validators", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) ConsensusParams(ctx context.Context, in *ConsensusParamsRequest, opts ...grpc.CallOption) (*ConsensusParamsResponse, error) {
+	out := new(ConsensusParamsResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/ConsensusParams", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) Tx(ctx context.Context, in *TxRequest, opts ...grpc.CallOption) (*TxResponse, error) {
+	out := new(TxResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/Tx", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) TxSearch(ctx context.Context, in *TxSearchRequest, opts ...grpc.CallOption) (*TxSearchResponse, error) {
+	out := new(TxSearchResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/TxSearch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) BlockSearch(ctx context.Context, in *BlockSearchRequest, opts ...grpc.CallOption) (*BlockSearchResponse, error) {
+	out := new(BlockSearchResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/BlockSearch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) CheckTx(ctx context.Context, in *CheckTxRequest, opts ...grpc.CallOption) (*CheckTxResponse, error) {
+	out := new(CheckTxResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/CheckTx", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) RemoveTx(ctx context.Context, in *RemoveTxRequest, opts ...grpc.CallOption) (*RemoveTxResponse, error) {
+	out := new(RemoveTxResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/RemoveTx", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) UnconfirmedTxs(ctx context.Context, in *UnconfirmedTxsRequest, opts ...grpc.CallOption) (*UnconfirmedTxsResponse, error) {
+	out := new(UnconfirmedTxsResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/UnconfirmedTxs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) NumUnconfirmedTxs(ctx context.Context, in *NumUnconfirmedTxsRequest, opts ...grpc.CallOption) (*UnconfirmedTxsResponse, error) {
+	out := new(UnconfirmedTxsResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/NumUnconfirmedTxs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) BroadcastTxAsync(ctx context.Context, in *BroadcastTxRequest, opts ...grpc.CallOption) (*BroadcastTxResponse, error) {
+	out := new(BroadcastTxResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/BroadcastTxAsync", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) BroadcastTxSync(ctx context.Context, in *BroadcastTxRequest, opts ...grpc.CallOption) (*BroadcastTxResponse, error) {
+	out := new(BroadcastTxResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/BroadcastTxSync", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) BroadcastTxCommit(ctx context.Context, in *BroadcastTxRequest, opts ...grpc.CallOption) (*BroadcastTxCommitResponse, error) {
+	out := new(BroadcastTxCommitResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/BroadcastTxCommit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) BroadcastEvidence(ctx context.Context, in *BroadcastEvidenceRequest, opts ...grpc.CallOption) (*BroadcastEvidenceResponse, error) {
+	out := new(BroadcastEvidenceResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/BroadcastEvidence", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) ABCIInfo(ctx context.Context, in *ABCIInfoRequest, opts ...grpc.CallOption) (*ABCIInfoResponse, error) {
+	out := new(ABCIInfoResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/ABCIInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) ABCIQuery(ctx context.Context, in *ABCIQueryRequest, opts ...grpc.CallOption) (*ABCIQueryResponse, error) {
+	out := new(ABCIQueryResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.RPCService/ABCIQuery", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rPCServiceClient) Events(ctx context.Context, in *EventsRequest, opts ...grpc.CallOption) (RPCService_EventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_RPCService_serviceDesc.Streams[0], "/tendermint.rpc.RPCService/Events", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &rPCServiceEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type RPCService_EventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type rPCServiceEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *rPCServiceEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RPCServiceServer is the server API for RPCService service.
+type RPCServiceServer interface {
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+	NetInfo(context.Context, *NetInfoRequest) (*NetInfoResponse, error)
+	DumpConsensusState(context.Context, *DumpConsensusStateRequest) (*DumpConsensusStateResponse, error)
+	ConsensusState(context.Context, *ConsensusStateRequest) (*ConsensusStateResponse, error)
+	BlockchainInfo(context.Context, *BlockchainInfoRequest) (*BlockchainInfoResponse, error)
+	GenesisChunked(context.Context, *GenesisChunkedRequest) (*GenesisChunkedResponse, error)
+	Block(context.Context, *BlockRequest) (*BlockResponse, error)
+	BlockByHash(context.Context, *BlockByHashRequest) (*BlockResponse, error)
+	BlockResults(context.Context, *BlockResultsRequest) (*BlockResultsResponse, error)
+	Commit(context.Context, *CommitRequest) (*CommitResponse, error)
+	Validators(context.Context, *ValidatorsRequest) (*ValidatorsResponse, error)
+	ConsensusParams(context.Context, *ConsensusParamsRequest) (*ConsensusParamsResponse, error)
+	Tx(context.Context, *TxRequest) (*TxResponse, error)
+	TxSearch(context.Context, *TxSearchRequest) (*TxSearchResponse, error)
+	BlockSearch(context.Context, *BlockSearchRequest) (*BlockSearchResponse, error)
+	CheckTx(context.Context, *CheckTxRequest) (*CheckTxResponse, error)
+	RemoveTx(context.Context, *RemoveTxRequest) (*RemoveTxResponse, error)
+	UnconfirmedTxs(context.Context, *UnconfirmedTxsRequest) (*UnconfirmedTxsResponse, error)
+	NumUnconfirmedTxs(context.Context, *NumUnconfirmedTxsRequest) (*UnconfirmedTxsResponse, error)
+	BroadcastTxAsync(context.Context, *BroadcastTxRequest) (*BroadcastTxResponse, error)
+	BroadcastTxSync(context.Context, *BroadcastTxRequest) (*BroadcastTxResponse, error)
+	BroadcastTxCommit(context.Context, *BroadcastTxRequest) (*BroadcastTxCommitResponse, error)
+	BroadcastEvidence(context.Context, *BroadcastEvidenceRequest) (*BroadcastEvidenceResponse, error)
+	ABCIInfo(context.Context, *ABCIInfoRequest) (*ABCIInfoResponse, error)
+	ABCIQuery(context.Context, *ABCIQueryRequest) (*ABCIQueryResponse, error)
+	// Events streams the events matching a query until the call is canceled.
+	// Events may be dropped by the node if they are not read fast enough.
+	Events(*EventsRequest, RPCService_EventsServer) error
+}
+
+// UnimplementedRPCServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedRPCServiceServer struct {
+}
+
+func (*UnimplementedRPCServiceServer) Health(ctx context.Context, req *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
+func (*UnimplementedRPCServiceServer) Status(ctx context.Context, req *StatusRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (*UnimplementedRPCServiceServer) NetInfo(ctx context.Context, req *NetInfoRequest) (*NetInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NetInfo not implemented")
+}
+func (*UnimplementedRPCServiceServer) DumpConsensusState(ctx context.Context, req *DumpConsensusStateRequest) (*DumpConsensusStateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DumpConsensusState not implemented")
+}
+func (*UnimplementedRPCServiceServer) ConsensusState(ctx context.Context, req *ConsensusStateRequest) (*ConsensusStateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConsensusState not implemented")
+}
+func (*UnimplementedRPCServiceServer) BlockchainInfo(ctx context.Context, req *BlockchainInfoRequest) (*BlockchainInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BlockchainInfo not implemented")
+}
+func (*UnimplementedRPCServiceServer) GenesisChunked(ctx context.Context, req *GenesisChunkedRequest) (*GenesisChunkedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenesisChunked not implemented")
+}
+func (*UnimplementedRPCServiceServer) Block(ctx context.Context, req *BlockRequest) (*BlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Block not implemented")
+}
+func (*UnimplementedRPCServiceServer) BlockByHash(ctx context.Context, req *BlockByHashRequest) (*BlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BlockByHash not implemented")
+}
+func (*UnimplementedRPCServiceServer) BlockResults(ctx context.Context, req *BlockResultsRequest) (*BlockResultsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BlockResults not implemented")
+}
+func (*UnimplementedRPCServiceServer) Commit(ctx context.Context, req *CommitRequest) (*CommitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Commit not implemented")
+}
+func (*UnimplementedRPCServiceServer) Validators(ctx context.Context, req *ValidatorsRequest) (*ValidatorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Validators not implemented")
+}
+func (*UnimplementedRPCServiceServer) ConsensusParams(ctx context.Context, req *ConsensusParamsRequest) (*ConsensusParamsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConsensusParams not implemented")
+}
+func (*UnimplementedRPCServiceServer) Tx(ctx context.Context, req *TxRequest) (*TxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Tx not implemented")
+}
+func (*UnimplementedRPCServiceServer) TxSearch(ctx context.Context, req *TxSearchRequest) (*TxSearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxSearch not implemented")
+}
+func (*UnimplementedRPCServiceServer) BlockSearch(ctx context.Context, req *BlockSearchRequest) (*BlockSearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BlockSearch not implemented")
+}
+func (*UnimplementedRPCServiceServer) CheckTx(ctx context.Context, req *CheckTxRequest) (*CheckTxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckTx not implemented")
+}
+func (*UnimplementedRPCServiceServer) RemoveTx(ctx context.Context, req *RemoveTxRequest) (*RemoveTxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveTx not implemented")
+}
+func (*UnimplementedRPCServiceServer) UnconfirmedTxs(ctx context.Context, req *UnconfirmedTxsRequest) (*UnconfirmedTxsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnconfirmedTxs not implemented")
+}
+func (*UnimplementedRPCServiceServer) NumUnconfirmedTxs(ctx context.Context, req *NumUnconfirmedTxsRequest) (*UnconfirmedTxsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NumUnconfirmedTxs not implemented")
+}
+func (*UnimplementedRPCServiceServer) BroadcastTxAsync(ctx context.Context, req *BroadcastTxRequest) (*BroadcastTxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BroadcastTxAsync not implemented")
+}
+func (*UnimplementedRPCServiceServer) BroadcastTxSync(ctx context.Context, req *BroadcastTxRequest) (*BroadcastTxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BroadcastTxSync not implemented")
+}
+func (*UnimplementedRPCServiceServer) BroadcastTxCommit(ctx context.Context, req *BroadcastTxRequest) (*BroadcastTxCommitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BroadcastTxCommit not implemented")
+}
+func (*UnimplementedRPCServiceServer) BroadcastEvidence(ctx context.Context, req *BroadcastEvidenceRequest) (*BroadcastEvidenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BroadcastEvidence not implemented")
+}
+func (*UnimplementedRPCServiceServer) ABCIInfo(ctx context.Context, req *ABCIInfoRequest) (*ABCIInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ABCIInfo not implemented")
+}
+func (*UnimplementedRPCServiceServer) ABCIQuery(ctx context.Context, req *ABCIQueryRequest) (*ABCIQueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ABCIQuery not implemented")
+}
+func (*UnimplementedRPCServiceServer) Events(req *EventsRequest, srv RPCService_EventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method Events not implemented")
+}
+
+func RegisterRPCServiceServer(s *grpc.Server, srv RPCServiceServer) {
+	s.RegisterService(&_RPCService_serviceDesc, srv)
+}
+
+func _RPCService_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/Health",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_NetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NetInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).NetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/NetInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).NetInfo(ctx, req.(*NetInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_DumpConsensusState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DumpConsensusStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).DumpConsensusState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/DumpConsensusState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).DumpConsensusState(ctx, req.(*DumpConsensusStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_ConsensusState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConsensusStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).ConsensusState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/ConsensusState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).ConsensusState(ctx, req.(*ConsensusStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_BlockchainInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockchainInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).BlockchainInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/BlockchainInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).BlockchainInfo(ctx, req.(*BlockchainInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_GenesisChunked_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenesisChunkedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).GenesisChunked(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/GenesisChunked",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).GenesisChunked(ctx, req.(*GenesisChunkedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_Block_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).Block(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/Block",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).Block(ctx, req.(*BlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_BlockByHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockByHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).BlockByHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/BlockByHash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).BlockByHash(ctx, req.(*BlockByHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_BlockResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockResultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).BlockResults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/BlockResults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).BlockResults(ctx, req.(*BlockResultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_Commit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).Commit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/Commit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).Commit(ctx, req.(*CommitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_Validators_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidatorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).Validators(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/Validators",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).Validators(ctx, req.(*ValidatorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_ConsensusParams_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConsensusParamsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).ConsensusParams(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/ConsensusParams",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).ConsensusParams(ctx, req.(*ConsensusParamsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_Tx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).Tx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/Tx",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).Tx(ctx, req.(*TxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_TxSearch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxSearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).TxSearch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/TxSearch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).TxSearch(ctx, req.(*TxSearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_BlockSearch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockSearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).BlockSearch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/BlockSearch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).BlockSearch(ctx, req.(*BlockSearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_CheckTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).CheckTx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/CheckTx",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).CheckTx(ctx, req.(*CheckTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_RemoveTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).RemoveTx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/RemoveTx",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).RemoveTx(ctx, req.(*RemoveTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_UnconfirmedTxs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnconfirmedTxsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).UnconfirmedTxs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/UnconfirmedTxs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).UnconfirmedTxs(ctx, req.(*UnconfirmedTxsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_NumUnconfirmedTxs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NumUnconfirmedTxsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).NumUnconfirmedTxs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/NumUnconfirmedTxs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).NumUnconfirmedTxs(ctx, req.(*NumUnconfirmedTxsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_BroadcastTxAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BroadcastTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).BroadcastTxAsync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/BroadcastTxAsync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).BroadcastTxAsync(ctx, req.(*BroadcastTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_BroadcastTxSync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BroadcastTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).BroadcastTxSync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/BroadcastTxSync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).BroadcastTxSync(ctx, req.(*BroadcastTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_BroadcastTxCommit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BroadcastTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).BroadcastTxCommit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/BroadcastTxCommit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).BroadcastTxCommit(ctx, req.(*BroadcastTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_BroadcastEvidence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BroadcastEvidenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).BroadcastEvidence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/BroadcastEvidence",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).BroadcastEvidence(ctx, req.(*BroadcastEvidenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_ABCIInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ABCIInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).ABCIInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/ABCIInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).ABCIInfo(ctx, req.(*ABCIInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_ABCIQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ABCIQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RPCServiceServer).ABCIQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.RPCService/ABCIQuery",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RPCServiceServer).ABCIQuery(ctx, req.(*ABCIQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RPCService_Events_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(EventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RPCServiceServer).Events(m, &rPCServiceEventsServer{stream})
+}
+
+type RPCService_EventsServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type rPCServiceEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *rPCServiceEventsServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _RPCService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "tendermint.rpc.RPCService",
+	HandlerType: (*RPCServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Health",
+			Handler:    _RPCService_Health_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _RPCService_Status_Handler,
+		},
+		{
+			MethodName: "NetInfo",
+			Handler:    _RPCService_NetInfo_Handler,
+		},
+		{
+			MethodName: "DumpConsensusState",
+			Handler:    _RPCService_DumpConsensusState_Handler,
+		},
+		{
+			MethodName: "ConsensusState",
+			Handler:    _RPCService_ConsensusState_Handler,
+		},
+		{
+			MethodName: "BlockchainInfo",
+			Handler:    _RPCService_BlockchainInfo_Handler,
+		},
+		{
+			MethodName: "GenesisChunked",
+			Handler:    _RPCService_GenesisChunked_Handler,
+		},
+		{
+			MethodName: "Block",
+			Handler:    _RPCService_Block_Handler,
+		},
+		{
+			MethodName: "BlockByHash",
+			Handler:    _RPCService_BlockByHash_Handler,
+		},
+		{
+			MethodName: "BlockResults",
+			Handler:    _RPCService_BlockResults_Handler,
+		},
+		{
+			MethodName: "Commit",
+			Handler:    _RPCService_Commit_Handler,
+		},
+		{
+			MethodName: "Validators",
+			Handler:    _RPCService_Validators_Handler,
+		},
+		{
+			MethodName: "ConsensusParams",
+			Handler:    _RPCService_ConsensusParams_Handler,
+		},
+		{
+			MethodName: "Tx",
+			Handler:    _RPCService_Tx_Handler,
+		},
+		{
+			MethodName: "TxSearch",
+			Handler:    _RPCService_TxSearch_Handler,
+		},
+		{
+			MethodName: "BlockSearch",
+			Handler:    _RPCService_BlockSearch_Handler,
+		},
+		{
+			MethodName: "CheckTx",
+			Handler:    _RPCService_CheckTx_Handler,
+		},
+		{
+			MethodName: "RemoveTx",
+			Handler:    _RPCService_RemoveTx_Handler,
+		},
+		{
+			MethodName: "UnconfirmedTxs",
+			Handler:    _RPCService_UnconfirmedTxs_Handler,
+		},
+		{
+			MethodName: "NumUnconfirmedTxs",
+			Handler:    _RPCService_NumUnconfirmedTxs_Handler,
+		},
+		{
+			MethodName: "BroadcastTxAsync",
+			Handler:    _RPCService_BroadcastTxAsync_Handler,
+		},
+		{
+			MethodName: "BroadcastTxSync",
+			Handler:    _RPCService_BroadcastTxSync_Handler,
+		},
+		{
+			MethodName: "BroadcastTxCommit",
+			Handler:    _RPCService_BroadcastTxCommit_Handler,
+		},
+		{
+			MethodName: "BroadcastEvidence",
+			Handler:    _RPCService_BroadcastEvidence_Handler,
+		},
+		{
+			MethodName: "ABCIInfo",
+			Handler:    _RPCService_ABCIInfo_Handler,
+		},
+		{
+			MethodName: "ABCIQuery",
+			Handler:    _RPCService_ABCIQuery_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Events",
+			Handler:       _RPCService_Events_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "tendermint/rpc/service.proto",
+}
@@ -3,6 +3,7 @@ package client_test
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -63,3 +64,43 @@ func testTxEventsSent(ctx context.Context, t *testing.T, broadcastMethod string,
 	require.True(t, txe.Result.IsOK())
 	<-done
 }
+
+func TestEventLog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n, conf := NodeSuite(t)
+	for _, c := range GetClients(t, n, conf) {
+		c := c
+		t.Run(reflect.TypeOf(c).String(), func(t *testing.T) {
+			elc, ok := c.(client.EventLogClient)
+			require.True(t, ok)
+
+			one := 1
+			query := types.QueryForEvent(types.EventNewBlockValue).String()
+			res, err := elc.Events(ctx, query, &one, "", waitForEventTimeout)
+			require.NoError(t, err)
+			require.Len(t, res.Items, 1)
+			block, ok := res.Items[0].Data.(types.EventDataNewBlock)
+			require.True(t, ok)
+
+			// The next block follows the cursor.
+			res, err = elc.Events(ctx, query, &one, res.NextCursor, 5*time.Second)
+			require.NoError(t, err)
+			require.Len(t, res.Items, 1)
+			next, ok := res.Items[0].Data.(types.EventDataNewBlock)
+			require.True(t, ok)
+			assert.Equal(t, block.Block.Height+1, next.Block.Height)
+
+			// Canceling the context ends a request waiting for events.
+			waitCtx, waitCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+			defer waitCancel()
+			start := time.Now()
+			res, err = elc.Events(waitCtx, "tm.event = 'Unknown'", &one, res.NextCursor, 5*time.Second)
+			if err == nil {
+				assert.Empty(t, res.Items)
+			}
+			assert.Less(t, int64(time.Since(start)), int64(2*time.Second))
+		})
+	}
+}
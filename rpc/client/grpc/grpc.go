@@ -0,0 +1,689 @@
+package grpc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
+	"github.com/tendermint/tendermint/libs/bytes"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+	tmnet "github.com/tendermint/tendermint/libs/net"
+	rpcproto "github.com/tendermint/tendermint/proto/tendermint/rpc"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	"github.com/tendermint/tendermint/types"
+)
+
+// resubscribeInterval is the interval between attempts to resubscribe after a
+// subscription has ended.
+const resubscribeInterval = time.Second
+
+/*
+GRPC is a Client implementation that communicates with a Tendermint node over
+its gRPC interface, which is enabled with the rpc.grpc-laddr option of the node.
+
+The unsafe methods of the JSON RPC interface are not available over gRPC.
+
+You can subscribe for any event published by Tendermint using Subscribe method.
+Each subscription is a stream, which lasts until the client unsubscribes or is
+stopped. Note delivery is best-effort. If you don't read events fast enough or
+network is slow, Tendermint might end the stream. The client will attempt to
+resubscribe (you don't need to do anything). It will keep trying every second
+indefinitely until successful.
+
+Example:
+
+	c, err := New("tcp://192.168.1.10:26670", grpc.WithInsecure())
+	if err != nil {
+		// handle error
+	}
+	defer c.Close()
+
+	// call Start/Stop if you're subscribing to events
+	err = c.Start()
+	if err != nil {
+		// handle error
+	}
+	defer c.Stop()
+
+	res, err := c.Status(ctx)
+	if err != nil {
+		// handle error
+	}
+
+	// handle result
+*/
+type GRPC struct {
+	*rpcclient.RunState
+
+	remote string
+	conn   *grpc.ClientConn
+	client rpcproto.RPCServiceClient
+
+	mtx tmsync.Mutex
+	// subscriptions maps the query of each subscription to a function ending
+	// it.
+	subscriptions map[string]context.CancelFunc
+}
+
+var _ rpcclient.RemoteClient = (*GRPC)(nil)
+
+// New creates a client for the gRPC server at the remote address, in the form
+// <protocol>://<host>:<port>, where the protocol is tcp or unix. The dial
+// options must set up the transport security, e.g. with grpc.WithInsecure or
+// grpc.WithTransportCredentials. The connection is established in the
+// background, so New doesn't fail if the server is unavailable.
+func New(remote string, opts ...grpc.DialOption) (*GRPC, error) {
+	protocol, address := tmnet.ProtocolAndAddress(remote)
+	target := address
+	if protocol == "unix" {
+		target = "unix://" + address
+	}
+
+	// Blocks and search results may be larger than the default limit.
+	opts = append([]grpc.DialOption{
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(math.MaxInt32)),
+	}, opts...)
+	conn, err := grpc.Dial(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithConn(remote, conn), nil
+}
+
+// WithBearerToken returns a dial option authenticating the calls to a node
+// requiring authentication with the bearer token of the client.
+func WithBearerToken(token string) grpc.DialOption {
+	return grpc.WithPerRPCCredentials(bearerToken(token))
+}
+
+type bearerToken string
+
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+// RequireTransportSecurity implements credentials.PerRPCCredentials. The token
+// may be sent over an insecure connection, e.g. to a local node.
+func (bearerToken) RequireTransportSecurity() bool {
+	return false
+}
+
+// NewWithConn creates a client using an existing connection to the gRPC server
+// of a node. Close closes the connection.
+func NewWithConn(remote string, conn *grpc.ClientConn) *GRPC {
+	return &GRPC{
+		RunState:      rpcclient.NewRunState("grpc", nil),
+		remote:        remote,
+		conn:          conn,
+		client:        rpcproto.NewRPCServiceClient(conn),
+		subscriptions: make(map[string]context.CancelFunc),
+	}
+}
+
+// Remote returns the remote network address in a string form.
+func (c *GRPC) Remote() string {
+	return c.remote
+}
+
+// Stop ends the subscriptions and stops the client.
+func (c *GRPC) Stop() error {
+	if err := c.RunState.Stop(); err != nil {
+		return err
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for query, cancel := range c.subscriptions {
+		cancel()
+		delete(c.subscriptions, query)
+	}
+	return nil
+}
+
+// Close closes the connection to the server.
+func (c *GRPC) Close() error {
+	return c.conn.Close()
+}
+
+//-----------------------------------------------------------------------------
+// Methods
+
+// heightParam returns the height of a request, where zero selects the latest
+// height.
+func heightParam(height *int64) int64 {
+	if height == nil {
+		return 0
+	}
+	return *height
+}
+
+// intParam returns a page or limit of a request, where zero selects the
+// default value.
+func intParam(n *int) int32 {
+	if n == nil {
+		return 0
+	}
+	return int32(*n)
+}
+
+// cursorParam returns the cursor of a search request.
+func cursorParam(cursor *string) *rpcproto.Cursor {
+	if cursor == nil {
+		return nil
+	}
+	return &rpcproto.Cursor{Position: *cursor}
+}
+
+func (c *GRPC) Status(ctx context.Context) (*coretypes.ResultStatus, error) {
+	res, err := c.client.Status(ctx, &rpcproto.StatusRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return coretypes.ResultStatusFromProto(res)
+}
+
+func (c *GRPC) ABCIInfo(ctx context.Context) (*coretypes.ResultABCIInfo, error) {
+	res, err := c.client.ABCIInfo(ctx, &rpcproto.ABCIInfoRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultABCIInfo{Response: res.Response}, nil
+}
+
+func (c *GRPC) ABCIQuery(ctx context.Context, path string, data bytes.HexBytes) (*coretypes.ResultABCIQuery, error) {
+	return c.ABCIQueryWithOptions(ctx, path, data, rpcclient.DefaultABCIQueryOptions)
+}
+
+func (c *GRPC) ABCIQueryWithOptions(
+	ctx context.Context,
+	path string,
+	data bytes.HexBytes,
+	opts rpcclient.ABCIQueryOptions) (*coretypes.ResultABCIQuery, error) {
+	res, err := c.client.ABCIQuery(ctx, &rpcproto.ABCIQueryRequest{
+		Path:   path,
+		Data:   data,
+		Height: opts.Height,
+		Prove:  opts.Prove,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultABCIQuery{Response: res.Response}, nil
+}
+
+func (c *GRPC) BroadcastTxCommit(ctx context.Context, tx types.Tx) (*coretypes.ResultBroadcastTxCommit, error) {
+	res, err := c.client.BroadcastTxCommit(ctx, &rpcproto.BroadcastTxRequest{Tx: tx})
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultBroadcastTxCommit{
+		CheckTx:   res.CheckTx,
+		DeliverTx: res.DeliverTx,
+		Hash:      res.Hash,
+		Height:    res.Height,
+	}, nil
+}
+
+func (c *GRPC) BroadcastTxAsync(ctx context.Context, tx types.Tx) (*coretypes.ResultBroadcastTx, error) {
+	res, err := c.client.BroadcastTxAsync(ctx, &rpcproto.BroadcastTxRequest{Tx: tx})
+	if err != nil {
+		return nil, err
+	}
+	return broadcastTxFromProto(res), nil
+}
+
+func (c *GRPC) BroadcastTxSync(ctx context.Context, tx types.Tx) (*coretypes.ResultBroadcastTx, error) {
+	res, err := c.client.BroadcastTxSync(ctx, &rpcproto.BroadcastTxRequest{Tx: tx})
+	if err != nil {
+		return nil, err
+	}
+	return broadcastTxFromProto(res), nil
+}
+
+func broadcastTxFromProto(res *rpcproto.BroadcastTxResponse) *coretypes.ResultBroadcastTx {
+	return &coretypes.ResultBroadcastTx{
+		Code:         res.Code,
+		Data:         res.Data,
+		Log:          res.Log,
+		Codespace:    res.Codespace,
+		MempoolError: res.MempoolError,
+		Hash:         res.Hash,
+	}
+}
+
+func (c *GRPC) UnconfirmedTxs(ctx context.Context, limit *int) (*coretypes.ResultUnconfirmedTxs, error) {
+	res, err := c.client.UnconfirmedTxs(ctx, &rpcproto.UnconfirmedTxsRequest{Limit: intParam(limit)})
+	if err != nil {
+		return nil, err
+	}
+	return coretypes.ResultUnconfirmedTxsFromProto(res), nil
+}
+
+func (c *GRPC) NumUnconfirmedTxs(ctx context.Context) (*coretypes.ResultUnconfirmedTxs, error) {
+	res, err := c.client.NumUnconfirmedTxs(ctx, &rpcproto.NumUnconfirmedTxsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return coretypes.ResultUnconfirmedTxsFromProto(res), nil
+}
+
+func (c *GRPC) CheckTx(ctx context.Context, tx types.Tx) (*coretypes.ResultCheckTx, error) {
+	res, err := c.client.CheckTx(ctx, &rpcproto.CheckTxRequest{Tx: tx})
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultCheckTx{ResponseCheckTx: res.CheckTx}, nil
+}
+
+func (c *GRPC) RemoveTx(ctx context.Context, txKey types.TxKey) error {
+	_, err := c.client.RemoveTx(ctx, &rpcproto.RemoveTxRequest{TxKey: txKey[:]})
+	return err
+}
+
+func (c *GRPC) NetInfo(ctx context.Context) (*coretypes.ResultNetInfo, error) {
+	res, err := c.client.NetInfo(ctx, &rpcproto.NetInfoRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return coretypes.ResultNetInfoFromProto(res), nil
+}
+
+func (c *GRPC) DumpConsensusState(ctx context.Context) (*coretypes.ResultDumpConsensusState, error) {
+	res, err := c.client.DumpConsensusState(ctx, &rpcproto.DumpConsensusStateRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return coretypes.ResultDumpConsensusStateFromProto(res), nil
+}
+
+func (c *GRPC) ConsensusState(ctx context.Context) (*coretypes.ResultConsensusState, error) {
+	res, err := c.client.ConsensusState(ctx, &rpcproto.ConsensusStateRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultConsensusState{RoundState: res.RoundState}, nil
+}
+
+func (c *GRPC) ConsensusParams(ctx context.Context, height *int64) (*coretypes.ResultConsensusParams, error) {
+	res, err := c.client.ConsensusParams(ctx, &rpcproto.ConsensusParamsRequest{Height: heightParam(height)})
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultConsensusParams{
+		BlockHeight:     res.BlockHeight,
+		ConsensusParams: types.ConsensusParamsFromProto(res.ConsensusParams),
+	}, nil
+}
+
+func (c *GRPC) Health(ctx context.Context) (*coretypes.ResultHealth, error) {
+	if _, err := c.client.Health(ctx, &rpcproto.HealthRequest{}); err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultHealth{}, nil
+}
+
+func (c *GRPC) BlockchainInfo(ctx context.Context, minHeight, maxHeight int64) (*coretypes.ResultBlockchainInfo, error) {
+	res, err := c.client.BlockchainInfo(ctx, &rpcproto.BlockchainInfoRequest{
+		MinHeight: minHeight,
+		MaxHeight: maxHeight,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return coretypes.ResultBlockchainInfoFromProto(res)
+}
+
+// Genesis fetches the genesis document in chunks, so unlike the genesis
+// method of the JSON RPC interface it also works for large documents.
+func (c *GRPC) Genesis(ctx context.Context) (*coretypes.ResultGenesis, error) {
+	var data []byte
+	for chunk, total := 0, 1; chunk < total; chunk++ {
+		res, err := c.GenesisChunked(ctx, uint(chunk))
+		if err != nil {
+			return nil, err
+		}
+		bz, err := base64.StdEncoding.DecodeString(res.Data)
+		if err != nil {
+			return nil, fmt.Errorf("decoding genesis chunk %d: %w", chunk, err)
+		}
+		data = append(data, bz...)
+		total = res.TotalChunks
+	}
+
+	genDoc := new(types.GenesisDoc)
+	if err := tmjson.Unmarshal(data, genDoc); err != nil {
+		return nil, fmt.Errorf("decoding genesis document: %w", err)
+	}
+	return &coretypes.ResultGenesis{Genesis: genDoc}, nil
+}
+
+func (c *GRPC) GenesisChunked(ctx context.Context, id uint) (*coretypes.ResultGenesisChunk, error) {
+	res, err := c.client.GenesisChunked(ctx, &rpcproto.GenesisChunkedRequest{Chunk: uint32(id)})
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultGenesisChunk{
+		ChunkNumber: int(res.Chunk),
+		TotalChunks: int(res.Total),
+		Data:        res.Data,
+	}, nil
+}
+
+func (c *GRPC) Block(ctx context.Context, height *int64) (*coretypes.ResultBlock, error) {
+	res, err := c.client.Block(ctx, &rpcproto.BlockRequest{Height: heightParam(height)})
+	if err != nil {
+		return nil, err
+	}
+	return coretypes.ResultBlockFromProto(res)
+}
+
+func (c *GRPC) BlockByHash(ctx context.Context, hash bytes.HexBytes) (*coretypes.ResultBlock, error) {
+	res, err := c.client.BlockByHash(ctx, &rpcproto.BlockByHashRequest{Hash: hash})
+	if err != nil {
+		return nil, err
+	}
+	return coretypes.ResultBlockFromProto(res)
+}
+
+func (c *GRPC) BlockResults(ctx context.Context, height *int64) (*coretypes.ResultBlockResults, error) {
+	res, err := c.client.BlockResults(ctx, &rpcproto.BlockResultsRequest{Height: heightParam(height)})
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultBlockResults{
+		Height:                res.Height,
+		TxsResults:            res.TxsResults,
+		TotalGasUsed:          res.TotalGasUsed,
+		BeginBlockEvents:      res.BeginBlockEvents,
+		EndBlockEvents:        res.EndBlockEvents,
+		ValidatorUpdates:      res.ValidatorUpdates,
+		ConsensusParamUpdates: res.ConsensusParamUpdates,
+	}, nil
+}
+
+func (c *GRPC) Commit(ctx context.Context, height *int64) (*coretypes.ResultCommit, error) {
+	res, err := c.client.Commit(ctx, &rpcproto.CommitRequest{Height: heightParam(height)})
+	if err != nil {
+		return nil, err
+	}
+	return coretypes.ResultCommitFromProto(res)
+}
+
+func (c *GRPC) Validators(ctx context.Context, height *int64, page, perPage *int) (*coretypes.ResultValidators, error) {
+	res, err := c.client.Validators(ctx, &rpcproto.ValidatorsRequest{
+		Height:  heightParam(height),
+		Page:    intParam(page),
+		PerPage: intParam(perPage),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return coretypes.ResultValidatorsFromProto(res)
+}
+
+func (c *GRPC) Tx(ctx context.Context, hash bytes.HexBytes, prove bool) (*coretypes.ResultTx, error) {
+	res, err := c.client.Tx(ctx, &rpcproto.TxRequest{Hash: hash, Prove: prove})
+	if err != nil {
+		return nil, err
+	}
+	return coretypes.ResultTxFromProto(res)
+}
+
+func (c *GRPC) TxSearch(
+	ctx context.Context,
+	query string,
+	prove bool,
+	page,
+	perPage *int,
+	orderBy string,
+) (*coretypes.ResultTxSearch, error) {
+	return c.txSearch(ctx, query, prove, page, perPage, orderBy, nil)
+}
+
+func (c *GRPC) TxSearchWithCursor(
+	ctx context.Context,
+	query string,
+	prove bool,
+	perPage *int,
+	orderBy string,
+	cursor string,
+) (*coretypes.ResultTxSearch, error) {
+	return c.txSearch(ctx, query, prove, nil, perPage, orderBy, &cursor)
+}
+
+func (c *GRPC) txSearch(
+	ctx context.Context,
+	query string,
+	prove bool,
+	page,
+	perPage *int,
+	orderBy string,
+	cursor *string,
+) (*coretypes.ResultTxSearch, error) {
+	res, err := c.client.TxSearch(ctx, &rpcproto.TxSearchRequest{
+		Query:   query,
+		Prove:   prove,
+		Page:    intParam(page),
+		PerPage: intParam(perPage),
+		OrderBy: orderBy,
+		Cursor:  cursorParam(cursor),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return coretypes.ResultTxSearchFromProto(res)
+}
+
+func (c *GRPC) BlockSearch(
+	ctx context.Context,
+	query string,
+	page, perPage *int,
+	orderBy string,
+) (*coretypes.ResultBlockSearch, error) {
+	return c.blockSearch(ctx, query, page, perPage, orderBy, nil)
+}
+
+func (c *GRPC) BlockSearchWithCursor(
+	ctx context.Context,
+	query string,
+	perPage *int,
+	orderBy string,
+	cursor string,
+) (*coretypes.ResultBlockSearch, error) {
+	return c.blockSearch(ctx, query, nil, perPage, orderBy, &cursor)
+}
+
+func (c *GRPC) blockSearch(
+	ctx context.Context,
+	query string,
+	page, perPage *int,
+	orderBy string,
+	cursor *string,
+) (*coretypes.ResultBlockSearch, error) {
+	res, err := c.client.BlockSearch(ctx, &rpcproto.BlockSearchRequest{
+		Query:   query,
+		Page:    intParam(page),
+		PerPage: intParam(perPage),
+		OrderBy: orderBy,
+		Cursor:  cursorParam(cursor),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return coretypes.ResultBlockSearchFromProto(res)
+}
+
+func (c *GRPC) BroadcastEvidence(ctx context.Context, ev types.Evidence) (*coretypes.ResultBroadcastEvidence, error) {
+	pbEv, err := types.EvidenceToProto(ev)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.client.BroadcastEvidence(ctx, &rpcproto.BroadcastEvidenceRequest{Evidence: pbEv})
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultBroadcastEvidence{Hash: res.Hash}, nil
+}
+
+//-----------------------------------------------------------------------------
+// Events
+
+// Subscribe implements EventsClient by opening a stream of the events matching
+// the query. By default, it returns a channel with cap=1. Error is returned if
+// it fails to subscribe.
+//
+// The subscriber param is ignored because Tendermint will override it with the
+// remote address anyway.
+//
+// The channel is never closed to prevent clients from seeing an erroneous
+// event.
+//
+// It returns an error if the client is not running.
+func (c *GRPC) Subscribe(ctx context.Context, subscriber, query string,
+	outCapacity ...int) (out <-chan coretypes.ResultEvent, err error) {
+
+	if !c.IsRunning() {
+		return nil, rpcclient.ErrClientNotRunning
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if _, ok := c.subscriptions[query]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "already subscribed to %q", query)
+	}
+
+	// The stream outlives the context of the call.
+	subCtx, cancel := context.WithCancel(context.Background())
+	stream, err := c.subscribe(ctx, subCtx, query)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	outCap := 1
+	if len(outCapacity) > 0 {
+		outCap = outCapacity[0]
+	}
+	outc := make(chan coretypes.ResultEvent, outCap)
+	c.subscriptions[query] = cancel
+	go c.eventsRoutine(subCtx, query, stream, outc)
+
+	return outc, nil
+}
+
+// subscribe opens a stream of the events matching the query, which lasts until
+// subCtx is done, and waits until the server has subscribed or ctx is done.
+func (c *GRPC) subscribe(ctx, subCtx context.Context, query string) (rpcproto.RPCService_EventsClient, error) {
+	stream, err := c.client.Events(subCtx, &rpcproto.EventsRequest{Query: query})
+	if err != nil {
+		return nil, err
+	}
+
+	// The server sends the headers once subscribed.
+	done := make(chan error, 1)
+	go func() {
+		_, err := stream.Header()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return stream, err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// eventsRoutine delivers the events of a stream, and resubscribes when the
+// stream ends until the subscription is canceled.
+func (c *GRPC) eventsRoutine(
+	ctx context.Context,
+	query string,
+	stream rpcproto.RPCService_EventsClient,
+	outc chan<- coretypes.ResultEvent,
+) {
+	for {
+		pb, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return // client unsubscribed
+			}
+			c.Logger.Error("subscription was canceled, resubscribing", "err", err, "query", query)
+			if stream = c.resubscribe(ctx, query); stream == nil {
+				return
+			}
+			continue
+		}
+
+		event, err := coretypes.ResultEventFromProto(pb)
+		if err != nil {
+			c.Logger.Error("failed to decode event", "err", err, "query", query)
+			continue
+		}
+		select {
+		case outc <- *event:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// resubscribe tries to resubscribe to the query every second until it
+// succeeds, or returns nil once the subscription is canceled.
+func (c *GRPC) resubscribe(ctx context.Context, query string) rpcproto.RPCService_EventsClient {
+	for {
+		select {
+		case <-time.After(resubscribeInterval):
+		case <-ctx.Done():
+			return nil
+		}
+		stream, err := c.subscribe(ctx, ctx, query)
+		if err == nil {
+			return stream
+		}
+		c.Logger.Error("failed to resubscribe", "err", err, "query", query)
+	}
+}
+
+// Unsubscribe implements EventsClient by ending the stream of the query.
+//
+// It returns an error if the client is not running.
+func (c *GRPC) Unsubscribe(ctx context.Context, subscriber, query string) error {
+	if !c.IsRunning() {
+		return rpcclient.ErrClientNotRunning
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	cancel, ok := c.subscriptions[query]
+	if !ok {
+		return errors.New("subscription not found")
+	}
+	cancel()
+	delete(c.subscriptions, query)
+	return nil
+}
+
+// UnsubscribeAll implements EventsClient by ending all the streams.
+//
+// It returns an error if the client is not running.
+func (c *GRPC) UnsubscribeAll(ctx context.Context, subscriber string) error {
+	if !c.IsRunning() {
+		return rpcclient.ErrClientNotRunning
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for query, cancel := range c.subscriptions {
+		cancel()
+		delete(c.subscriptions, query)
+	}
+	return nil
+}
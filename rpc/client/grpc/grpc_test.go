@@ -0,0 +1,204 @@
+package grpc_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/tendermint/tendermint/abci/example/kvstore"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/libs/log"
+	tmnet "github.com/tendermint/tendermint/libs/net"
+	tmrand "github.com/tendermint/tendermint/libs/rand"
+	"github.com/tendermint/tendermint/libs/service"
+	"github.com/tendermint/tendermint/rpc/client"
+	rpcgrpc "github.com/tendermint/tendermint/rpc/client/grpc"
+	rpclocal "github.com/tendermint/tendermint/rpc/client/local"
+	rpctest "github.com/tendermint/tendermint/rpc/test"
+	"github.com/tendermint/tendermint/types"
+)
+
+func nodeSuite(t *testing.T) (service.Service, *config.Config) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	conf, err := rpctest.CreateConfig(t.Name())
+	require.NoError(t, err)
+	port, err := tmnet.GetFreePort()
+	require.NoError(t, err)
+	conf.RPC.GRPCListenAddress = fmt.Sprintf("tcp://127.0.0.1:%d", port)
+
+	// start a tendermint node in the background to test against
+	dir, err := os.MkdirTemp("/tmp", fmt.Sprint("rpc-grpc-test-", t.Name()))
+	require.NoError(t, err)
+
+	app := kvstore.NewPersistentKVStoreApplication(dir)
+
+	node, closer, err := rpctest.StartTendermint(ctx, conf, app, rpctest.SuppressStdout)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, node.Stop())
+		assert.NoError(t, closer(ctx))
+		assert.NoError(t, app.Close())
+		node.Wait()
+		_ = os.RemoveAll(dir)
+	})
+	return node, conf
+}
+
+func getGRPCClient(t *testing.T, conf *config.Config) *rpcgrpc.GRPC {
+	t.Helper()
+
+	c, err := rpcgrpc.New(conf.RPC.GRPCListenAddress, grpc.WithInsecure())
+	require.NoError(t, err)
+
+	c.SetLogger(log.TestingLogger())
+	t.Cleanup(func() {
+		if c.IsRunning() {
+			require.NoError(t, c.Stop())
+		}
+		require.NoError(t, c.Close())
+	})
+
+	return c
+}
+
+func makeTx() types.Tx {
+	return types.Tx(fmt.Sprintf("%s=%s", tmrand.Str(8), tmrand.Str(8)))
+}
+
+func TestGRPCClient(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n, conf := nodeSuite(t)
+	node, ok := n.(rpclocal.NodeService)
+	require.True(t, ok)
+	local, err := rpclocal.New(node)
+	require.NoError(t, err)
+
+	c := getGRPCClient(t, conf)
+
+	t.Run("Status", func(t *testing.T) {
+		status, err := c.Status(ctx)
+		require.NoError(t, err)
+		expected, err := local.Status(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, expected.NodeInfo, status.NodeInfo)
+		assert.Equal(t, expected.ValidatorInfo, status.ValidatorInfo)
+		assert.Equal(t, conf.Moniker, status.NodeInfo.Moniker)
+	})
+	t.Run("Health", func(t *testing.T) {
+		_, err := c.Health(ctx)
+		require.NoError(t, err)
+	})
+	t.Run("Genesis", func(t *testing.T) {
+		genesis, err := c.Genesis(ctx)
+		require.NoError(t, err)
+		expected, err := local.Genesis(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, expected.Genesis.ChainID, genesis.Genesis.ChainID)
+		assert.Equal(t, expected.Genesis.Validators, genesis.Genesis.Validators)
+	})
+	t.Run("Validators", func(t *testing.T) {
+		h := int64(1)
+		vals, err := c.Validators(ctx, &h, nil, nil)
+		require.NoError(t, err)
+		expected, err := local.Validators(ctx, &h, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, expected, vals)
+	})
+	t.Run("ABCIInfo", func(t *testing.T) {
+		info, err := c.ABCIInfo(ctx)
+		require.NoError(t, err)
+		assert.True(t, len(info.Response.Data) > 0)
+	})
+	t.Run("BroadcastTxCommit", func(t *testing.T) {
+		tx := makeTx()
+		bres, err := c.BroadcastTxCommit(ctx, tx)
+		require.NoError(t, err)
+		require.True(t, bres.CheckTx.IsOK())
+		require.True(t, bres.DeliverTx.IsOK())
+
+		block, err := c.Block(ctx, &bres.Height)
+		require.NoError(t, err)
+		expected, err := local.Block(ctx, &bres.Height)
+		require.NoError(t, err)
+		assert.Equal(t, expected.BlockID, block.BlockID)
+		assert.Equal(t, expected.Block.Hash(), block.Block.Hash())
+
+		byHash, err := c.BlockByHash(ctx, block.BlockID.Hash)
+		require.NoError(t, err)
+		assert.Equal(t, block.BlockID, byHash.BlockID)
+
+		results, err := c.BlockResults(ctx, &bres.Height)
+		require.NoError(t, err)
+		require.Len(t, results.TxsResults, 1)
+		assert.EqualValues(t, abci.CodeTypeOK, results.TxsResults[0].Code)
+
+		txres, err := c.Tx(ctx, bres.Hash, true)
+		require.NoError(t, err)
+		assert.Equal(t, bres.Height, txres.Height)
+		assert.EqualValues(t, tx, txres.Tx)
+		assert.NoError(t, txres.Proof.Validate(block.Block.DataHash))
+
+		search, err := c.TxSearch(ctx, fmt.Sprintf("tx.hash='%X'", bres.Hash), true, nil, nil, "asc")
+		require.NoError(t, err)
+		require.Len(t, search.Txs, 1)
+		assert.Equal(t, txres, search.Txs[0])
+
+		search, err = c.TxSearchWithCursor(ctx, fmt.Sprintf("tx.hash='%X'", bres.Hash), true, nil, "asc", "")
+		require.NoError(t, err)
+		require.Len(t, search.Txs, 1)
+		assert.Equal(t, txres, search.Txs[0])
+
+		blocks, err := c.BlockSearchWithCursor(ctx, fmt.Sprintf("block.height=%d", bres.Height), nil, "asc", "")
+		require.NoError(t, err)
+		require.Len(t, blocks.Blocks, 1)
+		assert.Equal(t, bres.Height, blocks.Blocks[0].Block.Height)
+
+		query, err := c.ABCIQuery(ctx, "/key", []byte(tx)[:8])
+		require.NoError(t, err)
+		assert.EqualValues(t, []byte(tx)[9:], query.Response.Value)
+	})
+	t.Run("Errors", func(t *testing.T) {
+		h := int64(1 << 40)
+		_, err := c.Block(ctx, &h)
+		assert.Error(t, err)
+		_, err = c.TxSearch(ctx, "tx.height >", false, nil, nil, "asc")
+		assert.Error(t, err)
+	})
+	t.Run("Subscribe", func(t *testing.T) {
+		require.NoError(t, c.Start())
+		defer func() { require.NoError(t, c.Stop()) }()
+
+		evt, err := client.WaitForOneEvent(c, types.EventNewBlockValue, 10*time.Second)
+		require.NoError(t, err)
+		_, ok := evt.(types.EventDataNewBlock)
+		require.True(t, ok, "got %T", evt)
+
+		tx := makeTx()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			time.Sleep(100 * time.Millisecond)
+			_, err := c.BroadcastTxSync(ctx, tx)
+			assert.NoError(t, err)
+		}()
+		evt, err = client.WaitForOneEvent(c, types.EventTxValue, 10*time.Second)
+		require.NoError(t, err)
+		txe, ok := evt.(types.EventDataTx)
+		require.True(t, ok, "got %T", evt)
+		assert.EqualValues(t, tx, txe.Tx)
+		<-done
+	})
+}
@@ -156,7 +156,10 @@ func NewWithClientAndWSOptions(remote string, c *http.Client, wso WSOptions) (*H
 	return httpClient, nil
 }
 
-var _ rpcclient.Client = (*HTTP)(nil)
+var (
+	_ rpcclient.Client         = (*HTTP)(nil)
+	_ rpcclient.EventLogClient = (*HTTP)(nil)
+)
 
 // Remote returns the remote network address in a string form.
 func (c *HTTP) Remote() string {
@@ -484,6 +487,29 @@ func (c *baseRPCClient) TxSearch(
 	perPage *int,
 	orderBy string,
 ) (*coretypes.ResultTxSearch, error) {
+	return c.txSearch(ctx, query, prove, page, perPage, orderBy, nil)
+}
+
+func (c *baseRPCClient) TxSearchWithCursor(
+	ctx context.Context,
+	query string,
+	prove bool,
+	perPage *int,
+	orderBy string,
+	cursor string,
+) (*coretypes.ResultTxSearch, error) {
+	return c.txSearch(ctx, query, prove, nil, perPage, orderBy, &cursor)
+}
+
+func (c *baseRPCClient) txSearch(
+	ctx context.Context,
+	query string,
+	prove bool,
+	page,
+	perPage *int,
+	orderBy string,
+	cursor *string,
+) (*coretypes.ResultTxSearch, error) {
 
 	result := new(coretypes.ResultTxSearch)
 	params := map[string]interface{}{
@@ -498,6 +524,9 @@ func (c *baseRPCClient) TxSearch(
 	if perPage != nil {
 		params["per_page"] = perPage
 	}
+	if cursor != nil {
+		params["cursor"] = cursor
+	}
 
 	_, err := c.caller.Call(ctx, "tx_search", params, result)
 	if err != nil {
@@ -507,12 +536,61 @@ func (c *baseRPCClient) TxSearch(
 	return result, nil
 }
 
+func (c *baseRPCClient) Events(
+	ctx context.Context,
+	query string,
+	maxItems *int,
+	after string,
+	waitTime time.Duration,
+) (*coretypes.ResultEvents, error) {
+
+	result := new(coretypes.ResultEvents)
+	params := map[string]interface{}{
+		"query": query,
+		"after": after,
+	}
+
+	if maxItems != nil {
+		params["max_items"] = maxItems
+	}
+	if waitTime > 0 {
+		params["wait_time"] = waitTime.String()
+	}
+
+	_, err := c.caller.Call(ctx, "events", params, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 func (c *baseRPCClient) BlockSearch(
 	ctx context.Context,
 	query string,
 	page, perPage *int,
 	orderBy string,
 ) (*coretypes.ResultBlockSearch, error) {
+	return c.blockSearch(ctx, query, page, perPage, orderBy, nil)
+}
+
+func (c *baseRPCClient) BlockSearchWithCursor(
+	ctx context.Context,
+	query string,
+	perPage *int,
+	orderBy string,
+	cursor string,
+) (*coretypes.ResultBlockSearch, error) {
+	return c.blockSearch(ctx, query, nil, perPage, orderBy, &cursor)
+}
+
+func (c *baseRPCClient) blockSearch(
+	ctx context.Context,
+	query string,
+	page, perPage *int,
+	orderBy string,
+	cursor *string,
+) (*coretypes.ResultBlockSearch, error) {
 
 	result := new(coretypes.ResultBlockSearch)
 	params := map[string]interface{}{
@@ -526,6 +604,9 @@ func (c *baseRPCClient) BlockSearch(
 	if perPage != nil {
 		params["per_page"] = perPage
 	}
+	if cursor != nil {
+		params["cursor"] = cursor
+	}
 
 	_, err := c.caller.Call(ctx, "block_search", params, result)
 	if err != nil {
@@ -22,6 +22,7 @@ implementation.
 
 import (
 	"context"
+	"time"
 
 	"github.com/tendermint/tendermint/libs/bytes"
 	"github.com/tendermint/tendermint/rpc/coretypes"
@@ -92,6 +93,18 @@ type SignClient interface {
 		orderBy string,
 	) (*coretypes.ResultTxSearch, error)
 
+	// TxSearchWithCursor searches for transactions like TxSearch, but returns
+	// the page following the cursor, where an empty cursor selects the first
+	// page. The result holds the cursor of the next page.
+	TxSearchWithCursor(
+		ctx context.Context,
+		query string,
+		prove bool,
+		perPage *int,
+		orderBy string,
+		cursor string,
+	) (*coretypes.ResultTxSearch, error)
+
 	// BlockSearch defines a method to search for a paginated set of blocks by
 	// BeginBlock and EndBlock event search criteria.
 	BlockSearch(
@@ -100,6 +113,17 @@ type SignClient interface {
 		page, perPage *int,
 		orderBy string,
 	) (*coretypes.ResultBlockSearch, error)
+
+	// BlockSearchWithCursor searches for blocks like BlockSearch, but returns
+	// the page following the cursor, where an empty cursor selects the first
+	// page. The result holds the cursor of the next page.
+	BlockSearchWithCursor(
+		ctx context.Context,
+		query string,
+		perPage *int,
+		orderBy string,
+		cursor string,
+	) (*coretypes.ResultBlockSearch, error)
 }
 
 // HistoryClient provides access to data from genesis to now in large chunks.
@@ -141,6 +165,17 @@ type EventsClient interface {
 	UnsubscribeAll(ctx context.Context, subscriber string) error
 }
 
+// EventLogClient reads the event log of the node, which lets clients read the
+// events following a cursor without missing events between requests.
+type EventLogClient interface {
+	// Events returns up to maxItems of the events following the cursor after
+	// (or the oldest events, if empty) which match the query (or all the
+	// events, if empty). If no events match, it waits up to waitTime for
+	// events to be published.
+	Events(ctx context.Context, query string, maxItems *int, after string,
+		waitTime time.Duration) (*coretypes.ResultEvents, error)
+}
+
 // MempoolClient shows us data about current mempool state.
 type MempoolClient interface {
 	UnconfirmedTxs(ctx context.Context, limit *int) (*coretypes.ResultUnconfirmedTxs, error)
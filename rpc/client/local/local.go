@@ -66,7 +66,10 @@ func New(node NodeService) (*Local, error) {
 	}, nil
 }
 
-var _ rpcclient.Client = (*Local)(nil)
+var (
+	_ rpcclient.Client         = (*Local)(nil)
+	_ rpcclient.EventLogClient = (*Local)(nil)
+)
 
 // SetLogger allows to set a logger on the client.
 func (c *Local) SetLogger(l log.Logger) {
@@ -185,7 +188,33 @@ func (c *Local) TxSearch(
 	perPage *int,
 	orderBy string,
 ) (*coretypes.ResultTxSearch, error) {
-	return c.env.TxSearch(c.ctx, queryString, prove, page, perPage, orderBy)
+	return c.env.TxSearch(c.ctx, queryString, prove, page, perPage, orderBy, nil)
+}
+
+func (c *Local) TxSearchWithCursor(
+	_ context.Context,
+	queryString string,
+	prove bool,
+	perPage *int,
+	orderBy string,
+	cursor string,
+) (*coretypes.ResultTxSearch, error) {
+	return c.env.TxSearch(c.ctx, queryString, prove, nil, perPage, orderBy, &cursor)
+}
+
+func (c *Local) Events(
+	ctx context.Context,
+	queryString string,
+	maxItems *int,
+	after string,
+	waitTime time.Duration,
+) (*coretypes.ResultEvents, error) {
+	var wait string
+	if waitTime > 0 {
+		wait = waitTime.String()
+	}
+	// The request waits for events until ctx is canceled.
+	return c.env.Events(c.ctx.WithContext(ctx), queryString, maxItems, after, wait)
 }
 
 func (c *Local) BlockSearch(
@@ -194,7 +223,17 @@ func (c *Local) BlockSearch(
 	page, perPage *int,
 	orderBy string,
 ) (*coretypes.ResultBlockSearch, error) {
-	return c.env.BlockSearch(c.ctx, queryString, page, perPage, orderBy)
+	return c.env.BlockSearch(c.ctx, queryString, page, perPage, orderBy, nil)
+}
+
+func (c *Local) BlockSearchWithCursor(
+	_ context.Context,
+	queryString string,
+	perPage *int,
+	orderBy string,
+	cursor string,
+) (*coretypes.ResultBlockSearch, error) {
+	return c.env.BlockSearch(c.ctx, queryString, nil, perPage, orderBy, &cursor)
 }
 
 func (c *Local) BroadcastEvidence(ctx context.Context, ev types.Evidence) (*coretypes.ResultBroadcastEvidence, error) {
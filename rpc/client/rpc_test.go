@@ -317,8 +317,10 @@ func TestClientMethodCalls(t *testing.T) {
 				// sh is start height or status height
 				sh := s.SyncInfo.LatestBlockHeight
 
-				// look for the future
-				h := sh + 20
+				// look for the future, far enough ahead that the node can't get
+				// there before the request is served, however fast it commits
+				// empty blocks
+				h := sh + 1000
 				_, err = c.Block(ctx, &h)
 				require.Error(t, err) // no block yet
 
@@ -830,6 +832,29 @@ func TestClientMethodCallsAdvanced(t *testing.T) {
 					}
 				}
 				require.Len(t, seen, txCount)
+
+				// check pagination with a cursor
+				var (
+					cursor string
+					count  int
+				)
+				maxHeight = 0
+				for {
+					result, err := c.TxSearchWithCursor(ctx, "tx.height >= 1", false, &perPage, "asc", cursor)
+					require.NoError(t, err)
+					require.LessOrEqual(t, len(result.Txs), perPage)
+					require.Equal(t, -1, result.TotalCount)
+					for _, tx := range result.Txs {
+						require.Greater(t, tx.Height, maxHeight)
+						maxHeight = tx.Height
+					}
+					count += len(result.Txs)
+					if result.NextCursor == "" {
+						break
+					}
+					cursor = result.NextCursor
+				}
+				require.Equal(t, txCount, count)
 			})
 		}
 	})
@@ -0,0 +1,634 @@
+package coretypes
+
+import (
+	"fmt"
+
+	"github.com/tendermint/tendermint/crypto/encoding"
+	rpcproto "github.com/tendermint/tendermint/proto/tendermint/rpc"
+	"github.com/tendermint/tendermint/types"
+)
+
+// This file converts the results to and from the protobuf messages of the gRPC
+// interface. The results without a conversion map field by field to their
+// message, or are embedded in it.
+
+// ToProto converts the status to protobuf.
+func (s *ResultStatus) ToProto() (*rpcproto.StatusResponse, error) {
+	pb := &rpcproto.StatusResponse{
+		NodeInfo: *s.NodeInfo.ToProto(),
+		SyncInfo: rpcproto.SyncInfo{
+			LatestBlockHash:     s.SyncInfo.LatestBlockHash,
+			LatestAppHash:       s.SyncInfo.LatestAppHash,
+			LatestBlockHeight:   s.SyncInfo.LatestBlockHeight,
+			LatestBlockTime:     s.SyncInfo.LatestBlockTime,
+			EarliestBlockHash:   s.SyncInfo.EarliestBlockHash,
+			EarliestAppHash:     s.SyncInfo.EarliestAppHash,
+			EarliestBlockHeight: s.SyncInfo.EarliestBlockHeight,
+			EarliestBlockTime:   s.SyncInfo.EarliestBlockTime,
+			MaxPeerBlockHeight:  s.SyncInfo.MaxPeerBlockHeight,
+			CatchingUp:          s.SyncInfo.CatchingUp,
+			TotalSyncedTime:     s.SyncInfo.TotalSyncedTime,
+			RemainingTime:       s.SyncInfo.RemainingTime,
+			TotalSnapshots:      s.SyncInfo.TotalSnapshots,
+			ChunkProcessAvgTime: s.SyncInfo.ChunkProcessAvgTime,
+			SnapshotHeight:      s.SyncInfo.SnapshotHeight,
+			SnapshotChunksCount: s.SyncInfo.SnapshotChunksCount,
+			SnapshotChunksTotal: s.SyncInfo.SnapshotChunksTotal,
+			BackfilledBlocks:    s.SyncInfo.BackFilledBlocks,
+			BackfillBlocksTotal: s.SyncInfo.BackFillBlocksTotal,
+		},
+		ValidatorInfo: rpcproto.ValidatorInfo{
+			Address:     s.ValidatorInfo.Address,
+			VotingPower: s.ValidatorInfo.VotingPower,
+		},
+	}
+	if s.ValidatorInfo.PubKey != nil {
+		pk, err := encoding.PubKeyToProto(s.ValidatorInfo.PubKey)
+		if err != nil {
+			return nil, err
+		}
+		pb.ValidatorInfo.PubKey = &pk
+	}
+	for _, info := range s.IndexerInfo {
+		pb.IndexerInfo = append(pb.IndexerInfo, rpcproto.IndexerInfo{
+			Sink:          info.Sink,
+			IndexedHeight: info.IndexedHeight,
+			Lag:           info.Lag,
+			Backfilling:   info.Backfilling,
+		})
+	}
+	return pb, nil
+}
+
+// ResultStatusFromProto converts a status from protobuf.
+func ResultStatusFromProto(pb *rpcproto.StatusResponse) (*ResultStatus, error) {
+	nodeInfo, err := types.NodeInfoFromProto(&pb.NodeInfo)
+	if err != nil {
+		return nil, err
+	}
+	s := &ResultStatus{
+		NodeInfo: nodeInfo,
+		SyncInfo: SyncInfo{
+			LatestBlockHash:     pb.SyncInfo.LatestBlockHash,
+			LatestAppHash:       pb.SyncInfo.LatestAppHash,
+			LatestBlockHeight:   pb.SyncInfo.LatestBlockHeight,
+			LatestBlockTime:     pb.SyncInfo.LatestBlockTime,
+			EarliestBlockHash:   pb.SyncInfo.EarliestBlockHash,
+			EarliestAppHash:     pb.SyncInfo.EarliestAppHash,
+			EarliestBlockHeight: pb.SyncInfo.EarliestBlockHeight,
+			EarliestBlockTime:   pb.SyncInfo.EarliestBlockTime,
+			MaxPeerBlockHeight:  pb.SyncInfo.MaxPeerBlockHeight,
+			CatchingUp:          pb.SyncInfo.CatchingUp,
+			TotalSyncedTime:     pb.SyncInfo.TotalSyncedTime,
+			RemainingTime:       pb.SyncInfo.RemainingTime,
+			TotalSnapshots:      pb.SyncInfo.TotalSnapshots,
+			ChunkProcessAvgTime: pb.SyncInfo.ChunkProcessAvgTime,
+			SnapshotHeight:      pb.SyncInfo.SnapshotHeight,
+			SnapshotChunksCount: pb.SyncInfo.SnapshotChunksCount,
+			SnapshotChunksTotal: pb.SyncInfo.SnapshotChunksTotal,
+			BackFilledBlocks:    pb.SyncInfo.BackfilledBlocks,
+			BackFillBlocksTotal: pb.SyncInfo.BackfillBlocksTotal,
+		},
+		ValidatorInfo: ValidatorInfo{
+			Address:     pb.ValidatorInfo.Address,
+			VotingPower: pb.ValidatorInfo.VotingPower,
+		},
+	}
+	if pb.ValidatorInfo.PubKey != nil {
+		s.ValidatorInfo.PubKey, err = encoding.PubKeyFromProto(*pb.ValidatorInfo.PubKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, info := range pb.IndexerInfo {
+		s.IndexerInfo = append(s.IndexerInfo, IndexerInfo{
+			Sink:          info.Sink,
+			IndexedHeight: info.IndexedHeight,
+			Lag:           info.Lag,
+			Backfilling:   info.Backfilling,
+		})
+	}
+	return s, nil
+}
+
+// ToProto converts the network info to protobuf.
+func (r *ResultNetInfo) ToProto() *rpcproto.NetInfoResponse {
+	pb := &rpcproto.NetInfoResponse{
+		Listening: r.Listening,
+		Listeners: r.Listeners,
+		NPeers:    int64(r.NPeers),
+	}
+	for _, peer := range r.Peers {
+		pbPeer := rpcproto.Peer{NodeID: string(peer.ID), URL: peer.URL}
+		if cs := peer.ConnectionStatus; cs != nil {
+			pbPeer.ConnectionStatus = &rpcproto.PeerConnectionStatus{
+				Protocol:      cs.Protocol,
+				ConnectedAt:   cs.ConnectedAt,
+				Duration:      cs.Duration,
+				Latency:       cs.Latency,
+				SendQueueSize: int64(cs.SendQueueSize),
+			}
+			for _, ch := range cs.Channels {
+				pbPeer.ConnectionStatus.Channels = append(pbPeer.ConnectionStatus.Channels, rpcproto.PeerChannelStatus{
+					ID:               uint32(ch.ID),
+					MessagesSent:     ch.MessagesSent,
+					BytesSent:        ch.BytesSent,
+					MessagesReceived: ch.MessagesReceived,
+					BytesReceived:    ch.BytesReceived,
+					MessagesDropped:  ch.MessagesDropped,
+				})
+			}
+		}
+		pb.Peers = append(pb.Peers, pbPeer)
+	}
+	return pb
+}
+
+// ResultNetInfoFromProto converts network info from protobuf.
+func ResultNetInfoFromProto(pb *rpcproto.NetInfoResponse) *ResultNetInfo {
+	r := &ResultNetInfo{
+		Listening: pb.Listening,
+		Listeners: pb.Listeners,
+		NPeers:    int(pb.NPeers),
+	}
+	for _, pbPeer := range pb.Peers {
+		peer := Peer{ID: types.NodeID(pbPeer.NodeID), URL: pbPeer.URL}
+		if cs := pbPeer.ConnectionStatus; cs != nil {
+			peer.ConnectionStatus = &PeerConnectionStatus{
+				Protocol:      cs.Protocol,
+				ConnectedAt:   cs.ConnectedAt,
+				Duration:      cs.Duration,
+				Latency:       cs.Latency,
+				SendQueueSize: int(cs.SendQueueSize),
+			}
+			for _, ch := range cs.Channels {
+				peer.ConnectionStatus.Channels = append(peer.ConnectionStatus.Channels, PeerChannelStatus{
+					ID:               uint16(ch.ID),
+					MessagesSent:     ch.MessagesSent,
+					BytesSent:        ch.BytesSent,
+					MessagesReceived: ch.MessagesReceived,
+					BytesReceived:    ch.BytesReceived,
+					MessagesDropped:  ch.MessagesDropped,
+				})
+			}
+		}
+		r.Peers = append(r.Peers, peer)
+	}
+	return r
+}
+
+// ToProto converts the consensus state to protobuf.
+func (r *ResultDumpConsensusState) ToProto() *rpcproto.DumpConsensusStateResponse {
+	pb := &rpcproto.DumpConsensusStateResponse{RoundState: r.RoundState}
+	for _, peer := range r.Peers {
+		pb.Peers = append(pb.Peers, rpcproto.PeerStateInfo{
+			NodeAddress: peer.NodeAddress,
+			PeerState:   peer.PeerState,
+		})
+	}
+	return pb
+}
+
+// ResultDumpConsensusStateFromProto converts a consensus state from protobuf.
+func ResultDumpConsensusStateFromProto(pb *rpcproto.DumpConsensusStateResponse) *ResultDumpConsensusState {
+	r := &ResultDumpConsensusState{RoundState: pb.RoundState}
+	for _, peer := range pb.Peers {
+		r.Peers = append(r.Peers, PeerStateInfo{
+			NodeAddress: peer.NodeAddress,
+			PeerState:   peer.PeerState,
+		})
+	}
+	return r
+}
+
+// ToProto converts the block metas to protobuf.
+func (r *ResultBlockchainInfo) ToProto() *rpcproto.BlockchainInfoResponse {
+	pb := &rpcproto.BlockchainInfoResponse{LastHeight: r.LastHeight}
+	for _, meta := range r.BlockMetas {
+		pb.BlockMetas = append(pb.BlockMetas, meta.ToProto())
+	}
+	return pb
+}
+
+// ResultBlockchainInfoFromProto converts block metas from protobuf.
+func ResultBlockchainInfoFromProto(pb *rpcproto.BlockchainInfoResponse) (*ResultBlockchainInfo, error) {
+	r := &ResultBlockchainInfo{LastHeight: pb.LastHeight}
+	for _, pbMeta := range pb.BlockMetas {
+		meta, err := types.BlockMetaFromProto(pbMeta)
+		if err != nil {
+			return nil, err
+		}
+		r.BlockMetas = append(r.BlockMetas, meta)
+	}
+	return r, nil
+}
+
+// ToProto converts the block to protobuf. The block is nil if it wasn't
+// found.
+func (r *ResultBlock) ToProto() (*rpcproto.BlockResponse, error) {
+	pb := &rpcproto.BlockResponse{BlockID: r.BlockID.ToProto()}
+	if r.Block != nil {
+		var err error
+		if pb.Block, err = r.Block.ToProto(); err != nil {
+			return nil, err
+		}
+	}
+	return pb, nil
+}
+
+// ResultBlockFromProto converts a block from protobuf.
+func ResultBlockFromProto(pb *rpcproto.BlockResponse) (*ResultBlock, error) {
+	blockID, err := types.BlockIDFromProto(&pb.BlockID)
+	if err != nil {
+		return nil, err
+	}
+	r := &ResultBlock{BlockID: *blockID}
+	if pb.Block != nil {
+		if r.Block, err = types.BlockFromProto(pb.Block); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// ToProto converts the signed header to protobuf.
+func (r *ResultCommit) ToProto() *rpcproto.CommitResponse {
+	return &rpcproto.CommitResponse{
+		SignedHeader: r.SignedHeader.ToProto(),
+		Canonical:    r.CanonicalCommit,
+	}
+}
+
+// ResultCommitFromProto converts a signed header from protobuf.
+func ResultCommitFromProto(pb *rpcproto.CommitResponse) (*ResultCommit, error) {
+	sh, err := types.SignedHeaderFromProto(pb.SignedHeader)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultCommit{SignedHeader: *sh, CanonicalCommit: pb.Canonical}, nil
+}
+
+// ToProto converts the transaction to protobuf.
+func (r *ResultTx) ToProto() *rpcproto.TxResponse {
+	pb := &rpcproto.TxResponse{
+		Hash:     r.Hash,
+		Height:   r.Height,
+		Index:    r.Index,
+		TxResult: r.TxResult,
+		Tx:       r.Tx,
+	}
+	if r.Proof.RootHash != nil {
+		proof := r.Proof.ToProto()
+		pb.Proof = &proof
+	}
+	return pb
+}
+
+// ResultTxFromProto converts a transaction from protobuf.
+func ResultTxFromProto(pb *rpcproto.TxResponse) (*ResultTx, error) {
+	r := &ResultTx{
+		Hash:     pb.Hash,
+		Height:   pb.Height,
+		Index:    pb.Index,
+		TxResult: pb.TxResult,
+		Tx:       pb.Tx,
+	}
+	if pb.Proof != nil {
+		var err error
+		if r.Proof, err = types.TxProofFromProto(*pb.Proof); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// ToProto converts the search results to protobuf.
+func (r *ResultTxSearch) ToProto() *rpcproto.TxSearchResponse {
+	pb := &rpcproto.TxSearchResponse{
+		TotalCount: int64(r.TotalCount),
+		NextCursor: r.NextCursor,
+	}
+	for _, tx := range r.Txs {
+		pb.Txs = append(pb.Txs, tx.ToProto())
+	}
+	return pb
+}
+
+// ResultTxSearchFromProto converts search results from protobuf.
+func ResultTxSearchFromProto(pb *rpcproto.TxSearchResponse) (*ResultTxSearch, error) {
+	r := &ResultTxSearch{
+		TotalCount: int(pb.TotalCount),
+		NextCursor: pb.NextCursor,
+	}
+	for _, pbTx := range pb.Txs {
+		tx, err := ResultTxFromProto(pbTx)
+		if err != nil {
+			return nil, err
+		}
+		r.Txs = append(r.Txs, tx)
+	}
+	return r, nil
+}
+
+// ToProto converts the search results to protobuf.
+func (r *ResultBlockSearch) ToProto() (*rpcproto.BlockSearchResponse, error) {
+	pb := &rpcproto.BlockSearchResponse{
+		TotalCount: int64(r.TotalCount),
+		NextCursor: r.NextCursor,
+	}
+	for _, block := range r.Blocks {
+		pbBlock, err := block.ToProto()
+		if err != nil {
+			return nil, err
+		}
+		pb.Blocks = append(pb.Blocks, pbBlock)
+	}
+	return pb, nil
+}
+
+// ResultBlockSearchFromProto converts search results from protobuf.
+func ResultBlockSearchFromProto(pb *rpcproto.BlockSearchResponse) (*ResultBlockSearch, error) {
+	r := &ResultBlockSearch{
+		TotalCount: int(pb.TotalCount),
+		NextCursor: pb.NextCursor,
+	}
+	for _, pbBlock := range pb.Blocks {
+		block, err := ResultBlockFromProto(pbBlock)
+		if err != nil {
+			return nil, err
+		}
+		r.Blocks = append(r.Blocks, block)
+	}
+	return r, nil
+}
+
+// ToProto converts the validators to protobuf.
+func (r *ResultValidators) ToProto() (*rpcproto.ValidatorsResponse, error) {
+	pb := &rpcproto.ValidatorsResponse{
+		BlockHeight: r.BlockHeight,
+		Count:       int64(r.Count),
+		Total:       int64(r.Total),
+	}
+	for _, val := range r.Validators {
+		pbVal, err := val.ToProto()
+		if err != nil {
+			return nil, err
+		}
+		pb.Validators = append(pb.Validators, pbVal)
+	}
+	return pb, nil
+}
+
+// ResultValidatorsFromProto converts validators from protobuf.
+func ResultValidatorsFromProto(pb *rpcproto.ValidatorsResponse) (*ResultValidators, error) {
+	r := &ResultValidators{
+		BlockHeight: pb.BlockHeight,
+		Count:       int(pb.Count),
+		Total:       int(pb.Total),
+	}
+	for _, pbVal := range pb.Validators {
+		val, err := types.ValidatorFromProto(pbVal)
+		if err != nil {
+			return nil, err
+		}
+		r.Validators = append(r.Validators, val)
+	}
+	return r, nil
+}
+
+// ToProto converts the unconfirmed transactions to protobuf.
+func (r *ResultUnconfirmedTxs) ToProto() *rpcproto.UnconfirmedTxsResponse {
+	pb := &rpcproto.UnconfirmedTxsResponse{
+		Count:      int64(r.Count),
+		Total:      int64(r.Total),
+		TotalBytes: r.TotalBytes,
+	}
+	for _, tx := range r.Txs {
+		pb.Txs = append(pb.Txs, tx)
+	}
+	return pb
+}
+
+// ResultUnconfirmedTxsFromProto converts unconfirmed transactions from
+// protobuf.
+func ResultUnconfirmedTxsFromProto(pb *rpcproto.UnconfirmedTxsResponse) *ResultUnconfirmedTxs {
+	r := &ResultUnconfirmedTxs{
+		Count:      int(pb.Count),
+		Total:      int(pb.Total),
+		TotalBytes: pb.TotalBytes,
+	}
+	for _, tx := range pb.Txs {
+		r.Txs = append(r.Txs, tx)
+	}
+	return r
+}
+
+// ToProto converts the event to protobuf.
+func (r *ResultEvent) ToProto() (*rpcproto.Event, error) {
+	data, err := EventDataToProto(r.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &rpcproto.Event{
+		SubscriptionID: r.SubscriptionID,
+		Query:          r.Query,
+		Data:           *data,
+		Events:         r.Events,
+	}, nil
+}
+
+// ResultEventFromProto converts an event from protobuf.
+func ResultEventFromProto(pb *rpcproto.Event) (*ResultEvent, error) {
+	data, err := EventDataFromProto(&pb.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultEvent{
+		SubscriptionID: pb.SubscriptionID,
+		Query:          pb.Query,
+		Data:           data,
+		Events:         pb.Events,
+	}, nil
+}
+
+// EventDataToProto converts the data of an event to protobuf.
+func EventDataToProto(data types.TMEventData) (*rpcproto.EventData, error) {
+	pb := new(rpcproto.EventData)
+	switch data := data.(type) {
+	case types.EventDataNewBlock:
+		block, err := data.Block.ToProto()
+		if err != nil {
+			return nil, err
+		}
+		pb.Sum = &rpcproto.EventData_NewBlock{NewBlock: &rpcproto.EventDataNewBlock{
+			Block:            block,
+			BlockID:          data.BlockID.ToProto(),
+			ResultBeginBlock: data.ResultBeginBlock,
+			ResultEndBlock:   data.ResultEndBlock,
+		}}
+	case types.EventDataNewBlockHeader:
+		pb.Sum = &rpcproto.EventData_NewBlockHeader{NewBlockHeader: &rpcproto.EventDataNewBlockHeader{
+			Header:           *data.Header.ToProto(),
+			NumTxs:           data.NumTxs,
+			ResultBeginBlock: data.ResultBeginBlock,
+			ResultEndBlock:   data.ResultEndBlock,
+		}}
+	case types.EventDataNewEvidence:
+		ev, err := types.EvidenceToProto(data.Evidence)
+		if err != nil {
+			return nil, err
+		}
+		pb.Sum = &rpcproto.EventData_NewEvidence{NewEvidence: &rpcproto.EventDataNewEvidence{
+			Evidence: ev,
+			Height:   data.Height,
+		}}
+	case types.EventDataTx:
+		txResult := data.TxResult
+		pb.Sum = &rpcproto.EventData_Tx{Tx: &txResult}
+	case types.EventDataRoundState:
+		pb.Sum = &rpcproto.EventData_RoundState{RoundState: &rpcproto.EventDataRoundState{
+			Height: data.Height,
+			Round:  data.Round,
+			Step:   data.Step,
+		}}
+	case types.EventDataNewRound:
+		pb.Sum = &rpcproto.EventData_NewRound{NewRound: &rpcproto.EventDataNewRound{
+			Height:          data.Height,
+			Round:           data.Round,
+			Step:            data.Step,
+			ProposerAddress: data.Proposer.Address,
+			ProposerIndex:   data.Proposer.Index,
+		}}
+	case types.EventDataCompleteProposal:
+		pb.Sum = &rpcproto.EventData_CompleteProposal{CompleteProposal: &rpcproto.EventDataCompleteProposal{
+			Height:  data.Height,
+			Round:   data.Round,
+			Step:    data.Step,
+			BlockID: data.BlockID.ToProto(),
+		}}
+	case types.EventDataVote:
+		pb.Sum = &rpcproto.EventData_Vote{Vote: data.Vote.ToProto()}
+	case types.EventDataValidatorSetUpdates:
+		updates := new(rpcproto.EventDataValidatorSetUpdates)
+		for _, val := range data.ValidatorUpdates {
+			pbVal, err := val.ToProto()
+			if err != nil {
+				return nil, err
+			}
+			updates.ValidatorUpdates = append(updates.ValidatorUpdates, pbVal)
+		}
+		pb.Sum = &rpcproto.EventData_ValidatorSetUpdates{ValidatorSetUpdates: updates}
+	case types.EventDataString:
+		pb.Sum = &rpcproto.EventData_Text{Text: string(data)}
+	case types.EventDataBlockSyncStatus:
+		pb.Sum = &rpcproto.EventData_BlockSyncStatus{BlockSyncStatus: &rpcproto.EventDataSyncStatus{
+			Complete: data.Complete,
+			Height:   data.Height,
+		}}
+	case types.EventDataStateSyncStatus:
+		pb.Sum = &rpcproto.EventData_StateSyncStatus{StateSyncStatus: &rpcproto.EventDataSyncStatus{
+			Complete: data.Complete,
+			Height:   data.Height,
+		}}
+	default:
+		return nil, fmt.Errorf("unknown event data type %T", data)
+	}
+	return pb, nil
+}
+
+// EventDataFromProto converts the data of an event from protobuf.
+func EventDataFromProto(pb *rpcproto.EventData) (types.TMEventData, error) {
+	switch sum := pb.Sum.(type) {
+	case *rpcproto.EventData_NewBlock:
+		block, err := types.BlockFromProto(sum.NewBlock.Block)
+		if err != nil {
+			return nil, err
+		}
+		blockID, err := types.BlockIDFromProto(&sum.NewBlock.BlockID)
+		if err != nil {
+			return nil, err
+		}
+		return types.EventDataNewBlock{
+			Block:            block,
+			BlockID:          *blockID,
+			ResultBeginBlock: sum.NewBlock.ResultBeginBlock,
+			ResultEndBlock:   sum.NewBlock.ResultEndBlock,
+		}, nil
+	case *rpcproto.EventData_NewBlockHeader:
+		header, err := types.HeaderFromProto(&sum.NewBlockHeader.Header)
+		if err != nil {
+			return nil, err
+		}
+		return types.EventDataNewBlockHeader{
+			Header:           header,
+			NumTxs:           sum.NewBlockHeader.NumTxs,
+			ResultBeginBlock: sum.NewBlockHeader.ResultBeginBlock,
+			ResultEndBlock:   sum.NewBlockHeader.ResultEndBlock,
+		}, nil
+	case *rpcproto.EventData_NewEvidence:
+		ev, err := types.EvidenceFromProto(sum.NewEvidence.Evidence)
+		if err != nil {
+			return nil, err
+		}
+		return types.EventDataNewEvidence{Evidence: ev, Height: sum.NewEvidence.Height}, nil
+	case *rpcproto.EventData_Tx:
+		return types.EventDataTx{TxResult: *sum.Tx}, nil
+	case *rpcproto.EventData_RoundState:
+		return types.EventDataRoundState{
+			Height: sum.RoundState.Height,
+			Round:  sum.RoundState.Round,
+			Step:   sum.RoundState.Step,
+		}, nil
+	case *rpcproto.EventData_NewRound:
+		return types.EventDataNewRound{
+			Height: sum.NewRound.Height,
+			Round:  sum.NewRound.Round,
+			Step:   sum.NewRound.Step,
+			Proposer: types.ValidatorInfo{
+				Address: sum.NewRound.ProposerAddress,
+				Index:   sum.NewRound.ProposerIndex,
+			},
+		}, nil
+	case *rpcproto.EventData_CompleteProposal:
+		blockID, err := types.BlockIDFromProto(&sum.CompleteProposal.BlockID)
+		if err != nil {
+			return nil, err
+		}
+		return types.EventDataCompleteProposal{
+			Height:  sum.CompleteProposal.Height,
+			Round:   sum.CompleteProposal.Round,
+			Step:    sum.CompleteProposal.Step,
+			BlockID: *blockID,
+		}, nil
+	case *rpcproto.EventData_Vote:
+		vote, err := types.VoteFromProto(sum.Vote)
+		if err != nil {
+			return nil, err
+		}
+		return types.EventDataVote{Vote: vote}, nil
+	case *rpcproto.EventData_ValidatorSetUpdates:
+		var updates types.EventDataValidatorSetUpdates
+		for _, pbVal := range sum.ValidatorSetUpdates.ValidatorUpdates {
+			val, err := types.ValidatorFromProto(pbVal)
+			if err != nil {
+				return nil, err
+			}
+			updates.ValidatorUpdates = append(updates.ValidatorUpdates, val)
+		}
+		return updates, nil
+	case *rpcproto.EventData_Text:
+		return types.EventDataString(sum.Text), nil
+	case *rpcproto.EventData_BlockSyncStatus:
+		return types.EventDataBlockSyncStatus{
+			Complete: sum.BlockSyncStatus.Complete,
+			Height:   sum.BlockSyncStatus.Height,
+		}, nil
+	case *rpcproto.EventData_StateSyncStatus:
+		return types.EventDataStateSyncStatus{
+			Complete: sum.StateSyncStatus.Complete,
+			Height:   sum.StateSyncStatus.Height,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown event data type %T", sum)
+	}
+}
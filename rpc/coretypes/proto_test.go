@@ -0,0 +1,99 @@
+package coretypes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestStatusProto(t *testing.T) {
+	pubKey := ed25519.GenPrivKey().PubKey()
+	status := &ResultStatus{
+		NodeInfo: types.NodeInfo{
+			NodeID:     types.NodeIDFromPubKey(pubKey),
+			ListenAddr: "tcp://127.0.0.1:26656",
+			Network:    "test-chain",
+			Version:    "1.0.0",
+			Channels:   []byte{0x20, 0x21},
+			Moniker:    "node",
+			Other:      types.NodeInfoOther{TxIndex: "on", RPCAddress: "tcp://127.0.0.1:26657"},
+		},
+		SyncInfo: SyncInfo{
+			LatestBlockHash:   []byte{1, 2, 3},
+			LatestBlockHeight: 10,
+			LatestBlockTime:   time.Unix(1000, 0).UTC(),
+			CatchingUp:        true,
+			TotalSyncedTime:   time.Minute,
+		},
+		ValidatorInfo: ValidatorInfo{
+			Address:     pubKey.Address(),
+			PubKey:      pubKey,
+			VotingPower: 10,
+		},
+		IndexerInfo: []IndexerInfo{{Sink: "kv", IndexedHeight: 9, Lag: 1}},
+	}
+
+	pb, err := status.ToProto()
+	require.NoError(t, err)
+	decoded, err := ResultStatusFromProto(pb)
+	require.NoError(t, err)
+	assert.Equal(t, status, decoded)
+}
+
+func TestEventDataProto(t *testing.T) {
+	val := types.NewValidator(ed25519.GenPrivKey().PubKey(), 10)
+
+	testCases := []struct {
+		name string
+		data types.TMEventData
+	}{
+		{"Tx", types.EventDataTx{TxResult: abci.TxResult{
+			Height: 1,
+			Tx:     []byte("tx"),
+			Result: abci.ResponseDeliverTx{Code: 1, Log: "log"},
+		}}},
+		{"RoundState", types.EventDataRoundState{Height: 1, Round: 2, Step: "step"}},
+		{"NewRound", types.EventDataNewRound{
+			Height:   1,
+			Round:    2,
+			Step:     "step",
+			Proposer: types.ValidatorInfo{Address: val.Address, Index: 3},
+		}},
+		{"CompleteProposal", types.EventDataCompleteProposal{Height: 1, Round: 2, Step: "step"}},
+		{"ValidatorSetUpdates", types.EventDataValidatorSetUpdates{
+			ValidatorUpdates: []*types.Validator{val},
+		}},
+		{"String", types.EventDataString("text")},
+		{"BlockSyncStatus", types.EventDataBlockSyncStatus{Complete: true, Height: 5}},
+		{"StateSyncStatus", types.EventDataStateSyncStatus{Height: 5}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			event := &ResultEvent{
+				SubscriptionID: "sub",
+				Query:          "tm.event = 'Test'",
+				Data:           tc.data,
+				Events: []abci.Event{{
+					Type:       "tm",
+					Attributes: []abci.EventAttribute{{Key: "event", Value: "Test"}},
+				}},
+			}
+			pb, err := event.ToProto()
+			require.NoError(t, err)
+			decoded, err := ResultEventFromProto(pb)
+			require.NoError(t, err)
+			assert.Equal(t, event, decoded)
+		})
+	}
+
+	_, err := EventDataToProto(nil)
+	assert.Error(t, err)
+}
@@ -22,6 +22,9 @@ var (
 	// ErrInvalidRequest is used as a wrapper to cover more specific cases where the user has
 	// made an invalid request
 	ErrInvalidRequest = errors.New("invalid request")
+	// ErrTooManySubscriptions is used as a wrapper when a subscription exceeds
+	// the limits on subscriptions
+	ErrTooManySubscriptions = errors.New("too many subscriptions")
 )
 
 // List of blocks
@@ -122,6 +125,18 @@ type ResultStatus struct {
 	NodeInfo      types.NodeInfo `json:"node_info"`
 	SyncInfo      SyncInfo       `json:"sync_info"`
 	ValidatorInfo ValidatorInfo  `json:"validator_info"`
+	IndexerInfo   []IndexerInfo  `json:"indexer_info,omitempty"`
+}
+
+// Info about the indexing progress of an event sink
+type IndexerInfo struct {
+	Sink string `json:"sink"`
+	// The height up to which all blocks have been indexed.
+	IndexedHeight int64 `json:"indexed_height"`
+	// The number of blocks between the indexed height and the latest block.
+	Lag int64 `json:"lag"`
+	// Whether the missing blocks are being indexed in the background.
+	Backfilling bool `json:"backfilling"`
 }
 
 // Is TxIndexing enabled
@@ -154,6 +169,33 @@ type ResultDialPeers struct {
 type Peer struct {
 	ID  types.NodeID `json:"node_id"`
 	URL string       `json:"url"`
+
+	// ConnectionStatus is only set for peers with an active connection.
+	ConnectionStatus *PeerConnectionStatus `json:"connection_status,omitempty"`
+}
+
+// PeerConnectionStatus contains connection and traffic statistics for a
+// connected peer.
+type PeerConnectionStatus struct {
+	Protocol    string        `json:"protocol"`
+	ConnectedAt time.Time     `json:"connected_at"`
+	Duration    time.Duration `json:"duration"`
+	// Estimated round-trip time, or 0 if unknown.
+	Latency time.Duration `json:"latency"`
+	// Number of outbound messages waiting to be sent.
+	SendQueueSize int                 `json:"send_queue_size"`
+	Channels      []PeerChannelStatus `json:"channels"`
+}
+
+// PeerChannelStatus contains traffic statistics for a single channel of a
+// connected peer.
+type PeerChannelStatus struct {
+	ID               uint16 `json:"id"`
+	MessagesSent     uint64 `json:"messages_sent"`
+	BytesSent        uint64 `json:"bytes_sent"`
+	MessagesReceived uint64 `json:"messages_received"`
+	BytesReceived    uint64 `json:"bytes_received"`
+	MessagesDropped  uint64 `json:"messages_dropped"`
 }
 
 // Validators for a height.
@@ -224,16 +266,21 @@ type ResultTx struct {
 	Proof    types.TxProof          `json:"proof,omitempty"`
 }
 
-// Result of searching for txs
+// Result of searching for txs. TotalCount is -1 when searching with a cursor,
+// and NextCursor is empty on the last page.
 type ResultTxSearch struct {
 	Txs        []*ResultTx `json:"txs"`
 	TotalCount int         `json:"total_count"`
+	NextCursor string      `json:"next_cursor,omitempty"`
 }
 
 // ResultBlockSearch defines the RPC response type for a block search by events.
+// TotalCount is -1 when searching with a cursor, and NextCursor is empty on the
+// last page.
 type ResultBlockSearch struct {
 	Blocks     []*ResultBlock `json:"blocks"`
 	TotalCount int            `json:"total_count"`
+	NextCursor string         `json:"next_cursor,omitempty"`
 }
 
 // List of mempool txs
@@ -275,3 +322,24 @@ type ResultEvent struct {
 	Data           types.TMEventData `json:"data"`
 	Events         []abci.Event      `json:"events"`
 }
+
+// An event of the event log of the node, at a cursor.
+type EventItem struct {
+	Cursor string            `json:"cursor"`
+	Event  string            `json:"event"`
+	Data   types.TMEventData `json:"data"`
+	Events []abci.Event      `json:"events"`
+}
+
+// Result of reading the event log. More is true if the items were truncated
+// to max_items, and NextCursor is the cursor to read the following items
+// from. Oldest and Newest are the cursors of the oldest and newest items of
+// the log: events following the cursor of a request may have been missed if
+// it precedes Oldest.
+type ResultEvents struct {
+	Items      []*EventItem `json:"items"`
+	More       bool         `json:"more"`
+	NextCursor string       `json:"next_cursor"`
+	Oldest     string       `json:"oldest"`
+	Newest     string       `json:"newest"`
+}
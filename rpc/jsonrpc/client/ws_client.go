@@ -24,6 +24,7 @@ type WSOptions struct {
 	ReadWait             time.Duration // deadline for any read op
 	WriteWait            time.Duration // deadline for any write op
 	PingPeriod           time.Duration // frequency with which pings are sent
+	Header               http.Header   // headers of the handshake, e.g. Authorization
 }
 
 // DefaultWSOptions returns default WS options.
@@ -67,6 +68,9 @@ type WSClient struct { // nolint: maligned
 	// Support both ws and wss protocols
 	protocol string
 
+	// Headers sent with the handshake.
+	header http.Header
+
 	wg sync.WaitGroup
 
 	mtx            tmsync.RWMutex
@@ -124,6 +128,7 @@ func NewWSWithOptions(remoteAddr, endpoint string, opts WSOptions) (*WSClient, e
 		writeWait:            opts.WriteWait,
 		pingPeriod:           opts.PingPeriod,
 		protocol:             parsedURL.Scheme,
+		header:               opts.Header,
 
 		// sentIDs: make(map[types.JSONRPCIntID]bool),
 	}
@@ -242,7 +247,7 @@ func (c *WSClient) dial() error {
 		NetDial: c.Dialer,
 		Proxy:   http.ProxyFromEnvironment,
 	}
-	rHeader := http.Header{}
+	rHeader := c.header.Clone()
 	conn, _, err := dialer.Dial(c.protocol+"://"+c.Address+c.Endpoint, rHeader) // nolint:bodyclose
 	if err != nil {
 		return err
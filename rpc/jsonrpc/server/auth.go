@@ -0,0 +1,279 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/tendermint/tendermint/libs/log"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+)
+
+// AnyRoute is the key of the allow list in AuthConfig.Routes that applies to
+// the routes without an allow list of their own, except the unsafe routes.
+const AnyRoute = "*"
+
+// CertClientPrefix prefixes the common name of a client certificate to form
+// the name of the client, so that a certificate can't take the name of a key.
+const CertClientPrefix = "cert:"
+
+// unsafeRoutePrefixes are the prefixes of the unsafe routes, which only the
+// clients in their own allow list may call.
+var unsafeRoutePrefixes = []string{"unsafe_", "dial_"}
+
+// AuthConfig configures the authentication of RPC clients, and the routes
+// each client may call.
+//
+// Clients authenticate with a bearer token in the Authorization header of
+// their requests ("Authorization: Bearer <token>"), or with a client
+// certificate verified by ClientCAs when the server is run with ServeTLS. The
+// name of a client is the name of its token, or the common name of its
+// certificate prefixed with CertClientPrefix, e.g. "cert:ops".
+//
+// When authentication is enabled, the requests of clients which did not
+// authenticate are rejected, including websocket upgrades.
+type AuthConfig struct {
+	// Keys maps the name of each client to its bearer token.
+	Keys map[string]string `json:"keys"`
+
+	// Routes maps routes to the names of the clients allowed to call them.
+	// The AnyRoute list applies to the routes without a list of their own,
+	// and routes without any list may be called by all authenticated clients.
+	// The unsafe routes (unsafe_* and dial_*) may only be called by the
+	// clients in their own list.
+	Routes map[string][]string `json:"routes"`
+
+	// ClientCAs verifies the certificates of TLS clients. If nil, clients
+	// can't authenticate with a certificate.
+	ClientCAs *x509.CertPool `json:"-"`
+}
+
+// LoadAuthFile loads the keys and the allow lists of an AuthConfig from a JSON
+// file. For example:
+//
+//	{
+//	  "keys": {"ops": "<token>", "wallet": "<token>"},
+//	  "routes": {
+//	    "unsafe_flush_mempool": ["ops"],
+//	    "broadcast_tx_commit": ["ops", "wallet"]
+//	  }
+//	}
+func LoadAuthFile(path string) (*AuthConfig, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := new(AuthConfig)
+	if err := json.Unmarshal(bz, config); err != nil {
+		return nil, fmt.Errorf("decoding auth file %s: %w", path, err)
+	}
+	if err := config.ValidateBasic(); err != nil {
+		return nil, fmt.Errorf("invalid auth file %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// LoadClientCAs loads the PEM encoded certificates of the CAs verifying the
+// certificates of TLS clients.
+func LoadClientCAs(path string) (*x509.CertPool, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bz) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// ValidateBasic performs basic validation (checking the names and tokens are
+// not empty, the tokens are distinct and the names of the keys can't be taken
+// by certificates) and returns an error if any check fails.
+func (c *AuthConfig) ValidateBasic() error {
+	tokens := make(map[string]string, len(c.Keys))
+	for name, token := range c.Keys {
+		if name == "" {
+			return errors.New("empty key name")
+		}
+		if strings.HasPrefix(name, CertClientPrefix) {
+			return fmt.Errorf("key name %q has the prefix %q of certificate clients", name, CertClientPrefix)
+		}
+		if token == "" {
+			return fmt.Errorf("empty token for key %q", name)
+		}
+		if other, ok := tokens[token]; ok {
+			return fmt.Errorf("keys %q and %q have the same token", other, name)
+		}
+		tokens[token] = name
+	}
+	for route, names := range c.Routes {
+		if route == "" {
+			return errors.New("empty route")
+		}
+		for _, name := range names {
+			if name == "" {
+				return fmt.Errorf("empty client name for route %q", route)
+			}
+		}
+	}
+	return nil
+}
+
+// Authenticate returns the name of the client sending the authorization, the
+// value of an Authorization header, over a connection with the TLS state.
+// Either may be empty. It returns an error if the client did not
+// authenticate.
+func (c *AuthConfig) Authenticate(authorization string, state *tls.ConnectionState) (string, error) {
+	if authorization != "" {
+		const scheme = "bearer "
+		if len(authorization) <= len(scheme) || !strings.EqualFold(authorization[:len(scheme)], scheme) {
+			return "", errors.New("unsupported authorization scheme")
+		}
+		token := []byte(authorization[len(scheme):])
+		// Check all the keys, so the time taken doesn't depend on the token.
+		var client string
+		for name, key := range c.Keys {
+			if subtle.ConstantTimeCompare(token, []byte(key)) == 1 {
+				client = name
+			}
+		}
+		if client == "" {
+			return "", errors.New("invalid token")
+		}
+		return client, nil
+	}
+
+	// The certificate chains are only verified against ClientCAs.
+	if c.ClientCAs != nil && state != nil && len(state.VerifiedChains) > 0 {
+		if name := state.VerifiedChains[0][0].Subject.CommonName; name != "" {
+			return CertClientPrefix + name, nil
+		}
+		return "", errors.New("client certificate without a common name")
+	}
+	return "", errors.New("missing credentials")
+}
+
+// Allowed reports whether the client may call the route.
+func (c *AuthConfig) Allowed(client, route string) bool {
+	names, ok := c.Routes[route]
+	if !ok {
+		if isUnsafeRoute(route) {
+			return false
+		}
+		names, ok = c.Routes[AnyRoute]
+	}
+	if !ok {
+		return true
+	}
+	for _, name := range names {
+		if name == client {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnsafeRoute reports whether the route is one of the unsafe routes.
+func isUnsafeRoute(route string) bool {
+	for _, prefix := range unsafeRoutePrefixes {
+		if strings.HasPrefix(route, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// tlsConfig returns the TLS configuration of a server verifying the
+// certificates of its clients, or nil if clients don't use certificates.
+func (c *AuthConfig) tlsConfig() *tls.Config {
+	if c.ClientCAs == nil {
+		return nil
+	}
+	return &tls.Config{
+		ClientAuth: tls.VerifyClientCertIfGiven,
+		ClientCAs:  c.ClientCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+}
+
+//-----------------------------------------------------------------------------
+
+type authContextKey struct{}
+
+// authInfo identifies the authenticated client of a request. The client is
+// empty if the request was not authenticated.
+type authInfo struct {
+	config *AuthConfig
+	client string
+}
+
+// allowed reports whether the client may call the route. All routes are
+// allowed if the server doesn't authenticate clients, and none if the request
+// was not authenticated.
+func (ai *authInfo) allowed(route string) bool {
+	if ai == nil {
+		return true
+	}
+	return ai.client != "" && ai.config.Allowed(ai.client, route)
+}
+
+// authInfoFromContext returns the authenticated client of a request, or nil if
+// the server doesn't authenticate clients.
+func authInfoFromContext(ctx context.Context) *authInfo {
+	ai, _ := ctx.Value(authContextKey{}).(*authInfo)
+	return ai
+}
+
+// authHandler rejects the requests of clients which did not authenticate,
+// and records the client of the others in their context.
+type authHandler struct {
+	h      http.Handler
+	config *AuthConfig
+	logger log.Logger
+}
+
+func (h authHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	client, err := h.config.Authenticate(r.Header.Get("Authorization"), r.TLS)
+	// Browsers don't send credentials with CORS preflight requests, which are
+	// passed to the CORS handler without a client, so no route allows them.
+	if err != nil && !isPreflight(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="tendermint"`)
+		res := rpctypes.RPCUnauthorizedError(nil, err)
+		if wErr := WriteRPCResponseHTTPError(w, res); wErr != nil {
+			h.logger.Error("failed to write response", "res", res, "err", wErr)
+		}
+		return
+	}
+	ctx := context.WithValue(r.Context(), authContextKey{}, &authInfo{config: h.config, client: client})
+	h.h.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// isPreflight reports whether the request is a CORS preflight request.
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions &&
+		r.Header.Get("Origin") != "" &&
+		r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// authorizeRoute wraps the handler of a route, responding with an error if the
+// client may not call it.
+func authorizeRoute(route string, next http.HandlerFunc, logger log.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authInfoFromContext(r.Context()).allowed(route) {
+			res := rpctypes.RPCForbiddenError(rpctypes.JSONRPCIntID(-1))
+			if wErr := WriteRPCResponseHTTPError(w, res); wErr != nil {
+				logger.Error("failed to write response", "res", res, "err", wErr)
+			}
+			return
+		}
+		next(w, r)
+	}
+}
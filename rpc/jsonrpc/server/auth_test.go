@@ -0,0 +1,307 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+)
+
+const (
+	testOpsToken  = "ops-token"
+	testUserToken = "user-token"
+)
+
+func testAuthConfig() *AuthConfig {
+	return &AuthConfig{
+		Keys: map[string]string{"ops": testOpsToken, "user": testUserToken},
+		Routes: map[string][]string{
+			"unsafe": {"ops"},
+		},
+	}
+}
+
+// startAuthServer starts a server with the auth config, serving the "status"
+// and "unsafe" routes over HTTP and websocket.
+func startAuthServer(t *testing.T, auth *AuthConfig, tlsFiles ...string) string {
+	t.Helper()
+
+	funcMap := map[string]*RPCFunc{
+		"status": NewRPCFunc(func(ctx *rpctypes.Context) (string, error) { return "status", nil }, "", false),
+		"unsafe": NewRPCFunc(func(ctx *rpctypes.Context) (string, error) { return "unsafe", nil }, "", false),
+	}
+	logger := log.TestingLogger()
+	mux := http.NewServeMux()
+	RegisterRPCFuncs(mux, funcMap, logger)
+	wm := NewWebsocketManager(funcMap)
+	wm.SetLogger(logger)
+	mux.HandleFunc("/websocket", wm.WebsocketHandler)
+
+	l, err := Listen("tcp://127.0.0.1:0", 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	config := DefaultConfig()
+	config.Auth = auth
+	if len(tlsFiles) == 2 {
+		go ServeTLS(l, mux, tlsFiles[0], tlsFiles[1], logger, config) //nolint:errcheck // ignore for tests
+		return "https://" + l.Addr().String()
+	}
+	go Serve(l, mux, logger, config) //nolint:errcheck // ignore for tests
+	return "http://" + l.Addr().String()
+}
+
+func doRequest(t *testing.T, c *http.Client, method, url, token, body string) (int, rpctypes.RPCResponse) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := c.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	bz, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	var resp rpctypes.RPCResponse
+	require.NoError(t, json.Unmarshal(bz, &resp), string(bz))
+	return res.StatusCode, resp
+}
+
+func TestAuthHTTP(t *testing.T) {
+	url := startAuthServer(t, testAuthConfig())
+	c := &http.Client{Timeout: 5 * time.Second}
+
+	testCases := []struct {
+		name   string
+		token  string
+		route  string
+		status int
+		code   int
+	}{
+		{"no token", "", "status", http.StatusUnauthorized, -32001},
+		{"invalid token", "other-token", "status", http.StatusUnauthorized, -32001},
+		{"user status", testUserToken, "status", http.StatusOK, 0},
+		{"user unsafe", testUserToken, "unsafe", http.StatusForbidden, -32002},
+		{"ops unsafe", testOpsToken, "unsafe", http.StatusOK, 0},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// URI
+			status, resp := doRequest(t, c, "GET", url+"/"+tc.route, tc.token, "")
+			assert.Equal(t, tc.status, status)
+			if tc.code == 0 {
+				assert.Nil(t, resp.Error)
+			} else if assert.NotNil(t, resp.Error) {
+				assert.Equal(t, tc.code, resp.Error.Code)
+			}
+
+			// JSON
+			body := `{"jsonrpc":"2.0","id":1,"method":"` + tc.route + `"}`
+			_, resp = doRequest(t, c, "POST", url, tc.token, body)
+			if tc.code == 0 {
+				assert.Nil(t, resp.Error)
+			} else if assert.NotNil(t, resp.Error) {
+				assert.Equal(t, tc.code, resp.Error.Code)
+			}
+		})
+	}
+}
+
+func TestAuthPreflight(t *testing.T) {
+	url := startAuthServer(t, testAuthConfig())
+	c := &http.Client{Timeout: 5 * time.Second}
+
+	// Without a CORS handler, the preflight request reaches the route, which
+	// denies it.
+	req, err := http.NewRequest(http.MethodOptions, url+"/status", nil)
+	require.NoError(t, err)
+	req.Header.Set("Origin", "https://example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	res, err := c.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+}
+
+func TestAuthWebsocket(t *testing.T) {
+	url := startAuthServer(t, testAuthConfig())
+	wsURL := "ws" + strings.TrimPrefix(url, "http") + "/websocket"
+
+	d := websocket.Dialer{}
+	_, dialResp, err := d.Dial(wsURL, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, dialResp.StatusCode)
+	dialResp.Body.Close()
+
+	call := func(token, route string) rpctypes.RPCResponse {
+		header := http.Header{"Authorization": []string{"Bearer " + token}}
+		conn, dialResp, err := d.Dial(wsURL, header)
+		require.NoError(t, err)
+		defer conn.Close()
+		defer dialResp.Body.Close()
+
+		req := rpctypes.RPCRequest{JSONRPC: "2.0", ID: rpctypes.JSONRPCIntID(1), Method: route}
+		require.NoError(t, conn.WriteJSON(req))
+		var resp rpctypes.RPCResponse
+		require.NoError(t, conn.ReadJSON(&resp))
+		return resp
+	}
+
+	resp := call(testUserToken, "status")
+	assert.Nil(t, resp.Error)
+	resp = call(testUserToken, "unsafe")
+	if assert.NotNil(t, resp.Error) {
+		assert.Equal(t, -32002, resp.Error.Code)
+	}
+	resp = call(testOpsToken, "unsafe")
+	assert.Nil(t, resp.Error)
+}
+
+func TestAuthClientCertificate(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	clientTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "ops"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientDER, err := x509.CreateCertificate(rand.Reader, clientTmpl, caCert, &clientKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	auth := testAuthConfig()
+	auth.ClientCAs = x509.NewCertPool()
+	auth.ClientCAs.AddCert(caCert)
+	auth.Routes["status"] = []string{CertClientPrefix + "ops"}
+	url := startAuthServer(t, auth, "test.crt", "test.key")
+
+	newClient := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{
+			Timeout: 5 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true, // nolint: gosec // test certificate
+					Certificates:       certs,
+				},
+			},
+		}
+	}
+
+	status, _ := doRequest(t, newClient(), "GET", url+"/unsafe", "", "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	cert := tls.Certificate{Certificate: [][]byte{clientDER}, PrivateKey: clientKey}
+	status, resp := doRequest(t, newClient(cert), "GET", url+"/status", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Nil(t, resp.Error)
+
+	// The certificate doesn't take the name of the "ops" key.
+	status, _ = doRequest(t, newClient(cert), "GET", url+"/unsafe", "", "")
+	assert.Equal(t, http.StatusForbidden, status)
+
+	// A token takes precedence over the certificate.
+	status, _ = doRequest(t, newClient(cert), "GET", url+"/status", testUserToken, "")
+	assert.Equal(t, http.StatusForbidden, status)
+}
+
+func TestAuthConfig(t *testing.T) {
+	auth := &AuthConfig{
+		Keys: map[string]string{"ops": "a", "user": "b"},
+		Routes: map[string][]string{
+			"unsafe": {"ops"},
+			"status": {"ops", "user"},
+			AnyRoute: {"ops"},
+		},
+	}
+	require.NoError(t, auth.ValidateBasic())
+	assert.True(t, auth.Allowed("ops", "unsafe"))
+	assert.False(t, auth.Allowed("user", "unsafe"))
+	assert.True(t, auth.Allowed("user", "status"))
+	assert.False(t, auth.Allowed("user", "block"))
+
+	// The unsafe routes may only be called by the clients in their own list.
+	assert.False(t, auth.Allowed("ops", "unsafe_flush_mempool"))
+	assert.False(t, auth.Allowed("ops", "dial_peers"))
+	delete(auth.Routes, AnyRoute)
+	assert.True(t, auth.Allowed("user", "block"))
+	assert.False(t, auth.Allowed("ops", "unsafe_flush_mempool"))
+	assert.False(t, auth.Allowed("ops", "dial_seeds"))
+	auth.Routes["dial_seeds"] = []string{"ops"}
+	assert.True(t, auth.Allowed("ops", "dial_seeds"))
+	assert.False(t, auth.Allowed("user", "dial_seeds"))
+
+	client, err := auth.Authenticate("bearer b", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "user", client)
+	_, err = auth.Authenticate("Basic b", nil)
+	assert.Error(t, err)
+	_, err = auth.Authenticate("", nil)
+	assert.Error(t, err)
+
+	auth.Keys["other"] = "a"
+	assert.Error(t, auth.ValidateBasic())
+	delete(auth.Keys, "other")
+	auth.Keys["empty"] = ""
+	assert.Error(t, auth.ValidateBasic())
+	delete(auth.Keys, "empty")
+	auth.Keys[CertClientPrefix+"ops"] = "c"
+	assert.Error(t, auth.ValidateBasic())
+}
+
+func TestLoadAuthFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"keys": {"ops": "secret"},
+		"routes": {"unsafe_flush_mempool": ["ops"]}
+	}`), 0600))
+
+	auth, err := LoadAuthFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"ops": "secret"}, auth.Keys)
+	assert.Equal(t, map[string][]string{"unsafe_flush_mempool": {"ops"}}, auth.Routes)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"keys": {"ops": ""}}`), 0600))
+	_, err = LoadAuthFile(path)
+	assert.Error(t, err)
+}
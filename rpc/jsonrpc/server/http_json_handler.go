@@ -43,8 +43,10 @@ func makeJSONRPCHandler(funcMap map[string]*RPCFunc, logger log.Logger) http.Han
 		var (
 			requests  []rpctypes.RPCRequest
 			responses []rpctypes.RPCResponse
+			batch     = true
 		)
 		if err := json.Unmarshal(b, &requests); err != nil {
+			batch = false
 			// next, try to unmarshal as a single request
 			var request rpctypes.RPCRequest
 			if err := json.Unmarshal(b, &request); err != nil {
@@ -57,6 +59,36 @@ func makeJSONRPCHandler(funcMap map[string]*RPCFunc, logger log.Logger) http.Han
 			requests = []rpctypes.RPCRequest{request}
 		}
 
+		limiter := limiterFromContext(r.Context())
+		if batch {
+			if err := limiter.allowBatchSize(len(requests)); err != nil {
+				res := rpctypes.RPCInvalidRequestError(nil, err)
+				if wErr := WriteRPCResponseHTTPError(w, res); wErr != nil {
+					logger.Error("failed to write response", "res", res, "err", wErr)
+				}
+				return
+			}
+		}
+		routes := make([]string, 0, len(requests))
+		for _, request := range requests {
+			if request.ID != nil {
+				routes = append(routes, request.Method)
+			}
+		}
+		if batch {
+			if err := limiter.allowBatchCost(routes...); err != nil {
+				res := rpctypes.RPCInvalidRequestError(nil, err)
+				if wErr := WriteRPCResponseHTTPError(w, res); wErr != nil {
+					logger.Error("failed to write response", "res", res, "err", wErr)
+				}
+				return
+			}
+		}
+		if wait, err := limiter.allowRoutes(batch, routes...); err != nil {
+			writeTooManyRequests(w, rpctypes.RPCTooManyRequestsError(nil, err), wait, logger)
+			return
+		}
+
 		// Set the default response cache to true unless
 		// 1. Any RPC request rrror.
 		// 2. Any RPC request doesn't allow to be cached.
@@ -88,6 +120,11 @@ func makeJSONRPCHandler(funcMap map[string]*RPCFunc, logger log.Logger) http.Han
 				c = false
 				continue
 			}
+			if !authInfoFromContext(r.Context()).allowed(request.Method) {
+				responses = append(responses, rpctypes.RPCForbiddenError(request.ID))
+				c = false
+				continue
+			}
 			ctx := &rpctypes.Context{JSONReq: &request, HTTPReq: r}
 			args := []reflect.Value{reflect.ValueOf(ctx)}
 			if len(request.Params) > 0 {
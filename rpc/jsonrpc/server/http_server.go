@@ -32,6 +32,17 @@ type Config struct {
 	MaxBodyBytes int64
 	// mirrors http.Server#MaxHeaderBytes
 	MaxHeaderBytes int
+	// Auth, if set, authenticates the clients and restricts the routes each
+	// client may call. The routes are authorized by the handlers registered
+	// with RegisterRPCFuncs and the WebsocketManager.
+	Auth *AuthConfig
+	// RateLimit, if set, limits the rate of the requests of each client.
+	RateLimit *RateLimitConfig
+	// MaxBatchSize is the maximum number of requests in a JSON-RPC batch
+	// request (0 - unlimited).
+	MaxBatchSize int
+	// Metrics, if set, counts the requests rejected by the server.
+	Metrics *Metrics
 }
 
 // DefaultConfig returns a default configuration.
@@ -46,14 +57,15 @@ func DefaultConfig() *Config {
 }
 
 // Serve creates a http.Server and calls Serve with the given listener. It
-// wraps handler with RecoverAndLogHandler and a handler, which limits the max
-// body size to config.MaxBodyBytes.
+// wraps handler with RecoverAndLogHandler, a handler which limits the max body
+// size to config.MaxBodyBytes, and a handler authenticating the clients if
+// config.Auth is set.
 //
 // NOTE: This function blocks - you may want to call it in a go-routine.
 func Serve(listener net.Listener, handler http.Handler, logger log.Logger, config *Config) error {
 	logger.Info(fmt.Sprintf("Starting RPC HTTP server on %s", listener.Addr()))
 	s := &http.Server{
-		Handler:        RecoverAndLogHandler(maxBytesHandler{h: handler, n: config.MaxBodyBytes}, logger),
+		Handler:        makeHandler(handler, logger, config),
 		ReadTimeout:    config.ReadTimeout,
 		WriteTimeout:   config.WriteTimeout,
 		MaxHeaderBytes: config.MaxHeaderBytes,
@@ -63,9 +75,9 @@ func Serve(listener net.Listener, handler http.Handler, logger log.Logger, confi
 	return err
 }
 
-// Serve creates a http.Server and calls ServeTLS with the given listener,
-// certFile and keyFile. It wraps handler with RecoverAndLogHandler and a
-// handler, which limits the max body size to config.MaxBodyBytes.
+// ServeTLS creates a http.Server and calls ServeTLS with the given listener,
+// certFile and keyFile. It wraps handler like Serve, and verifies the
+// certificates of the clients if config.Auth.ClientCAs is set.
 //
 // NOTE: This function blocks - you may want to call it in a go-routine.
 func ServeTLS(
@@ -78,11 +90,14 @@ func ServeTLS(
 	logger.Info(fmt.Sprintf("Starting RPC HTTPS server on %s (cert: %q, key: %q)",
 		listener.Addr(), certFile, keyFile))
 	s := &http.Server{
-		Handler:        RecoverAndLogHandler(maxBytesHandler{h: handler, n: config.MaxBodyBytes}, logger),
+		Handler:        makeHandler(handler, logger, config),
 		ReadTimeout:    config.ReadTimeout,
 		WriteTimeout:   config.WriteTimeout,
 		MaxHeaderBytes: config.MaxHeaderBytes,
 	}
+	if config.Auth != nil {
+		s.TLSConfig = config.Auth.tlsConfig()
+	}
 	err := s.ServeTLS(listener, certFile, keyFile)
 
 	logger.Error("RPC HTTPS server stopped", "err", err)
@@ -100,6 +115,9 @@ func ServeTLS(
 // 404	-32601	Method not found.
 // 500	-32602	Invalid params.
 // 500	-32603	Internal error.
+// 401	-32001	Unauthorized.
+// 403	-32002	Forbidden.
+// 429	-32003	Too many requests.
 // 500	-32099..-32000	Server error.
 //
 // source: https://www.jsonrpc.org/historical/json-rpc-over-http.html
@@ -122,6 +140,12 @@ func WriteRPCResponseHTTPError(
 		httpCode = http.StatusBadRequest
 	case -32601:
 		httpCode = http.StatusNotFound
+	case -32001:
+		httpCode = http.StatusUnauthorized
+	case -32002:
+		httpCode = http.StatusForbidden
+	case -32003:
+		httpCode = http.StatusTooManyRequests
 	default:
 		httpCode = http.StatusInternalServerError
 	}
@@ -249,6 +273,17 @@ func (w *responseWriterWrapper) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	return w.ResponseWriter.(http.Hijacker).Hijack()
 }
 
+// makeHandler wraps the handler of a server with the handlers of the config.
+func makeHandler(handler http.Handler, logger log.Logger, config *Config) http.Handler {
+	if config.RateLimit != nil || config.MaxBatchSize > 0 || config.Metrics != nil {
+		handler = rateLimitHandler{h: handler, limiter: newRateLimiter(config)}
+	}
+	if config.Auth != nil {
+		handler = authHandler{h: handler, config: config.Auth, logger: logger}
+	}
+	return RecoverAndLogHandler(maxBytesHandler{h: handler, n: config.MaxBodyBytes}, logger)
+}
+
 type maxBytesHandler struct {
 	h http.Handler
 	n int64
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+
+	prometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsSubsystem is a the subsystem label for the RPC server.
+const MetricsSubsystem = "rpc"
+
+// The reasons requests are rejected for, used as the label of
+// Metrics.RejectedRequests.
+const (
+	rejectedRateLimit     = "rate_limit"
+	rejectedBatchSize     = "batch_size"
+	rejectedBatchCost     = "batch_cost"
+	rejectedSubscriptions = "subscriptions"
+)
+
+// Metrics contains metrics exposed by the RPC server.
+type Metrics struct {
+	// Number of requests rejected by the server, by reason: rate_limit,
+	// batch_size, batch_cost or subscriptions.
+	RejectedRequests metrics.Counter
+}
+
+// PrometheusMetrics returns Metrics build using Prometheus client library.
+// Optionally, labels can be provided along with their values ("foo",
+// "fooValue").
+func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+	return &Metrics{
+		RejectedRequests: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "rejected_requests",
+			Help:      "Number of requests rejected by the server, by reason.",
+		}, append(labels, "reason")).With(labelsAndValues...),
+	}
+}
+
+// NopMetrics returns no-op Metrics.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		RejectedRequests: discard.NewCounter(),
+	}
+}
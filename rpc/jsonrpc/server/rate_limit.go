@@ -0,0 +1,306 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/libs/log"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+)
+
+const (
+	// DefaultRouteCost is the cost of the routes without a cost in
+	// RateLimitConfig.Costs.
+	DefaultRouteCost = 1
+
+	// sweepInterval is the interval between removals of the buckets of idle
+	// clients.
+	sweepInterval = time.Minute
+)
+
+// RateLimitConfig configures the rate limits of the clients of a server.
+//
+// Each request costs a number of units, depending on its route, and each
+// client may spend Rate units per second, and up to Burst units at once.
+// Clients are identified by their name if they authenticated (see AuthConfig),
+// and by their IP address otherwise.
+type RateLimitConfig struct {
+	// Number of units each client may spend per second.
+	Rate float64
+	// Maximum number of units a client may spend at once.
+	Burst int
+	// Costs maps routes to the cost of calling them. The other routes cost
+	// DefaultRouteCost.
+	Costs map[string]int
+	// Additional cost of a JSON-RPC batch request, on top of the costs of its
+	// requests.
+	BatchCost int
+}
+
+// ValidateBasic performs basic validation (checking the rate, burst and costs
+// are positive, and no route costs more than the burst) and returns an error
+// if any check fails.
+func (c *RateLimitConfig) ValidateBasic() error {
+	if c.Rate <= 0 {
+		return errors.New("rate must be positive")
+	}
+	if c.Burst <= 0 {
+		return errors.New("burst must be positive")
+	}
+	for route, cost := range c.Costs {
+		if cost <= 0 {
+			return fmt.Errorf("cost of %s must be positive", route)
+		}
+		if cost > c.Burst {
+			return fmt.Errorf("cost of %s (%d) exceeds the burst (%d)", route, cost, c.Burst)
+		}
+	}
+	if c.BatchCost < 0 {
+		return errors.New("batch cost can't be negative")
+	}
+	return nil
+}
+
+// cost returns the cost of calling the route.
+func (c *RateLimitConfig) cost(route string) int {
+	if cost, ok := c.Costs[route]; ok {
+		return cost
+	}
+	return DefaultRouteCost
+}
+
+// routesCost returns the cost of calling the routes, plus the cost of a batch
+// if batch is true.
+func (c *RateLimitConfig) routesCost(batch bool, routes []string) int {
+	cost := 0
+	for _, route := range routes {
+		cost += c.cost(route)
+	}
+	if batch {
+		cost += c.BatchCost
+	}
+	return cost
+}
+
+// bucket is the token bucket of a client.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter limits the rate of the requests of the clients of a server, and
+// the size of their batch requests.
+type rateLimiter struct {
+	config       *RateLimitConfig // nil if the rate is unlimited
+	maxBatchSize int              // 0 if unlimited
+	metrics      *Metrics
+	now          func() time.Time
+
+	mtx       sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func newRateLimiter(config *Config) *rateLimiter {
+	metrics := config.Metrics
+	if metrics == nil {
+		metrics = NopMetrics()
+	}
+	return &rateLimiter{
+		config:       config.RateLimit,
+		maxBatchSize: config.MaxBatchSize,
+		metrics:      metrics,
+		now:          time.Now,
+		buckets:      make(map[string]*bucket),
+		lastSweep:    time.Now(),
+	}
+}
+
+// RateLimiter limits the rate of the requests of the clients of a server
+// other than the JSON-RPC server, e.g. the gRPC server, with the same route
+// costs.
+type RateLimiter struct {
+	limiter *rateLimiter
+}
+
+// NewRateLimiter returns a limiter of the clients' requests as configured.
+// The requests it rejects are counted by the metrics, if not nil.
+func NewRateLimiter(config *RateLimitConfig, metrics *Metrics) *RateLimiter {
+	return &RateLimiter{limiter: newRateLimiter(&Config{RateLimit: config, Metrics: metrics})}
+}
+
+// Allow takes the cost of calling the route from the bucket of a client,
+// identified by its name if it authenticated and by its IP address otherwise.
+// If the client exceeded its rate limit, it returns an error and the time
+// after which it may retry.
+func (l *RateLimiter) Allow(name, ip, route string) (time.Duration, error) {
+	cl := &clientLimiter{limiter: l.limiter, client: clientID(name, ip)}
+	return cl.allowRoutes(false, route)
+}
+
+// clientID returns the identifier of the bucket of a client: its name if it
+// authenticated, and its IP address otherwise.
+func clientID(name, ip string) string {
+	if name != "" {
+		return "key:" + name
+	}
+	return "ip:" + ip
+}
+
+// take takes cost units from the bucket of the client, if it holds enough.
+// Otherwise, it returns the time after which the bucket will hold enough, and
+// false.
+func (l *rateLimiter) take(client string, cost int) (time.Duration, bool) {
+	if l.config == nil {
+		return 0, true
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: float64(l.config.Burst), last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = math.Min(float64(l.config.Burst), b.tokens+now.Sub(b.last).Seconds()*l.config.Rate)
+	b.last = now
+
+	if b.tokens < float64(cost) {
+		if float64(cost) > float64(l.config.Burst) {
+			return 0, false // never
+		}
+		wait := (float64(cost) - b.tokens) / l.config.Rate
+		return time.Duration(wait * float64(time.Second)), false
+	}
+	b.tokens -= float64(cost)
+	return 0, true
+}
+
+// sweep removes the buckets which have refilled, so idle clients don't use
+// memory.
+func (l *rateLimiter) sweep(now time.Time) {
+	for client, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.config.Rate >= float64(l.config.Burst) {
+			delete(l.buckets, client)
+		}
+	}
+	l.lastSweep = now
+}
+
+type rateLimitContextKey struct{}
+
+// clientLimiter limits the requests of a client.
+type clientLimiter struct {
+	limiter *rateLimiter
+	client  string
+}
+
+// limiterFromContext returns the limiter of the client of a request, or nil
+// if the server doesn't limit clients.
+func limiterFromContext(ctx context.Context) *clientLimiter {
+	cl, _ := ctx.Value(rateLimitContextKey{}).(*clientLimiter)
+	return cl
+}
+
+// allowRoutes takes the cost of calling the routes, plus the cost of a batch
+// if batch is true, from the bucket of the client. If the client exceeded its
+// rate limit, it returns an error and the time after which it may retry.
+func (cl *clientLimiter) allowRoutes(batch bool, routes ...string) (time.Duration, error) {
+	if cl == nil || cl.limiter.config == nil {
+		return 0, nil
+	}
+	wait, ok := cl.limiter.take(cl.client, cl.limiter.config.routesCost(batch, routes))
+	if !ok {
+		cl.rejected(rejectedRateLimit)
+		return wait, fmt.Errorf("rate limit of %s exceeded", cl.client)
+	}
+	return 0, nil
+}
+
+// allowBatchSize returns an error if a batch request has too many requests.
+func (cl *clientLimiter) allowBatchSize(size int) error {
+	if cl == nil || cl.limiter.maxBatchSize == 0 || size <= cl.limiter.maxBatchSize {
+		return nil
+	}
+	cl.rejected(rejectedBatchSize)
+	return fmt.Errorf("batch request of %d requests exceeds the limit of %d", size, cl.limiter.maxBatchSize)
+}
+
+// allowBatchCost returns an error if a batch request of the routes costs more
+// than the burst, which the client could never afford however long it waits.
+func (cl *clientLimiter) allowBatchCost(routes ...string) error {
+	if cl == nil || cl.limiter.config == nil {
+		return nil
+	}
+	config := cl.limiter.config
+	cost := config.routesCost(true, routes)
+	if cost <= config.Burst {
+		return nil
+	}
+	cl.rejected(rejectedBatchCost)
+	return fmt.Errorf("batch request too expensive: it costs %d units, over the burst of %d", cost, config.Burst)
+}
+
+// rejected counts a request rejected for the reason.
+func (cl *clientLimiter) rejected(reason string) {
+	if cl != nil {
+		cl.limiter.metrics.RejectedRequests.With("reason", reason).Add(1)
+	}
+}
+
+// rateLimitHandler records the limiter of the client of each request in its
+// context. It must be wrapped by the authHandler, if any, to identify
+// clients by their name.
+type rateLimitHandler struct {
+	h       http.Handler
+	limiter *rateLimiter
+}
+
+func (h rateLimitHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var name string
+	if ai := authInfoFromContext(r.Context()); ai != nil {
+		name = ai.client
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr // e.g. a unix socket
+	}
+	client := &clientLimiter{limiter: h.limiter, client: clientID(name, host)}
+	ctx := context.WithValue(r.Context(), rateLimitContextKey{}, client)
+	h.h.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// writeTooManyRequests responds to a request rejected by the rate limit.
+func writeTooManyRequests(w http.ResponseWriter, res rpctypes.RPCResponse, wait time.Duration, logger log.Logger) {
+	if wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	}
+	if wErr := WriteRPCResponseHTTPError(w, res); wErr != nil {
+		logger.Error("failed to write response", "res", res, "err", wErr)
+	}
+}
+
+// limitRoute wraps the handler of a route, responding with an error if the
+// client exceeded its rate limit.
+func limitRoute(route string, next http.HandlerFunc, logger log.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if wait, err := limiterFromContext(r.Context()).allowRoutes(false, route); err != nil {
+			writeTooManyRequests(w, rpctypes.RPCTooManyRequestsError(rpctypes.JSONRPCIntID(-1), err), wait, logger)
+			return
+		}
+		next(w, r)
+	}
+}
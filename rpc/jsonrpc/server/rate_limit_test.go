@@ -0,0 +1,251 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+)
+
+// rejectedCounter counts the rejected requests by reason.
+type rejectedCounter struct {
+	mtx    *sync.Mutex
+	counts map[string]float64
+	reason string
+}
+
+func newRejectedCounter() *rejectedCounter {
+	return &rejectedCounter{mtx: new(sync.Mutex), counts: make(map[string]float64)}
+}
+
+func (c *rejectedCounter) With(labelsAndValues ...string) metrics.Counter {
+	cc := *c
+	cc.reason = labelsAndValues[len(labelsAndValues)-1]
+	return &cc
+}
+
+func (c *rejectedCounter) Add(delta float64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.counts[c.reason] += delta
+}
+
+func (c *rejectedCounter) count(reason string) float64 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.counts[reason]
+}
+
+// startLimitServer starts a server with the config, serving the "status" and
+// "search" routes over HTTP and websocket.
+func startLimitServer(t *testing.T, config *Config) string {
+	t.Helper()
+
+	funcMap := map[string]*RPCFunc{
+		"status": NewRPCFunc(func(ctx *rpctypes.Context) (string, error) { return "status", nil }, "", false),
+		"search": NewRPCFunc(func(ctx *rpctypes.Context) (string, error) { return "search", nil }, "", false),
+	}
+	logger := log.TestingLogger()
+	mux := http.NewServeMux()
+	RegisterRPCFuncs(mux, funcMap, logger)
+	wm := NewWebsocketManager(funcMap)
+	wm.SetLogger(logger)
+	mux.HandleFunc("/websocket", wm.WebsocketHandler)
+
+	l, err := Listen("tcp://127.0.0.1:0", 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	go Serve(l, mux, logger, config) //nolint:errcheck // ignore for tests
+	return "http://" + l.Addr().String()
+}
+
+// testLimitConfig returns a config allowing each client 5 units, and no
+// more for the duration of a test.
+func testLimitConfig() (*Config, *rejectedCounter) {
+	counter := newRejectedCounter()
+	config := DefaultConfig()
+	config.RateLimit = &RateLimitConfig{
+		Rate:      0.001,
+		Burst:     5,
+		Costs:     map[string]int{"search": 4},
+		BatchCost: 1,
+	}
+	config.MaxBatchSize = 2
+	config.Metrics = &Metrics{RejectedRequests: counter}
+	return config, counter
+}
+
+func TestRateLimiterTake(t *testing.T) {
+	now := time.Now()
+	config := DefaultConfig()
+	config.RateLimit = &RateLimitConfig{Rate: 2, Burst: 10}
+	l := newRateLimiter(config)
+	l.now = func() time.Time { return now }
+
+	_, ok := l.take("a", 10)
+	assert.True(t, ok)
+	wait, ok := l.take("a", 3)
+	assert.False(t, ok)
+	assert.Equal(t, 1500*time.Millisecond, wait)
+
+	// Other clients have their own bucket.
+	_, ok = l.take("b", 3)
+	assert.True(t, ok)
+
+	// The bucket refills at the rate, up to the burst.
+	now = now.Add(2 * time.Second)
+	_, ok = l.take("a", 5)
+	assert.False(t, ok)
+	_, ok = l.take("a", 4)
+	assert.True(t, ok)
+	now = now.Add(time.Hour)
+	_, ok = l.take("a", 11)
+	assert.False(t, ok)
+	_, ok = l.take("a", 10)
+	assert.True(t, ok)
+
+	// The buckets of idle clients are removed.
+	now = now.Add(time.Hour)
+	_, ok = l.take("c", 1)
+	assert.True(t, ok)
+	assert.Len(t, l.buckets, 1)
+}
+
+func TestRateLimitConfig(t *testing.T) {
+	config := &RateLimitConfig{Rate: 1, Burst: 10, Costs: map[string]int{"search": 10}}
+	assert.NoError(t, config.ValidateBasic())
+
+	config.Costs["search"] = 11
+	assert.Error(t, config.ValidateBasic())
+	config.Costs["search"] = 0
+	assert.Error(t, config.ValidateBasic())
+	config.Costs["search"] = 1
+	config.Rate = 0
+	assert.Error(t, config.ValidateBasic())
+}
+
+func TestRateLimitHTTP(t *testing.T) {
+	config, counter := testLimitConfig()
+	url := startLimitServer(t, config)
+	c := &http.Client{Timeout: 5 * time.Second}
+
+	status, resp := doRequest(t, c, "GET", url+"/search", "", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Nil(t, resp.Error)
+
+	req, err := http.NewRequest("GET", url+"/search", nil)
+	require.NoError(t, err)
+	res, err := c.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	assert.NotEmpty(t, res.Header.Get("Retry-After"))
+
+	_, resp = doRequest(t, c, "POST", url, "", `{"jsonrpc":"2.0","id":1,"method":"search"}`)
+	if assert.NotNil(t, resp.Error) {
+		assert.Equal(t, -32003, resp.Error.Code)
+	}
+
+	// The last unit is enough for a cheap route.
+	status, _ = doRequest(t, c, "GET", url+"/status", "", "")
+	assert.Equal(t, http.StatusOK, status)
+
+	assert.Equal(t, 2.0, counter.count(rejectedRateLimit))
+}
+
+func TestRateLimitBatch(t *testing.T) {
+	config, counter := testLimitConfig()
+	url := startLimitServer(t, config)
+	c := &http.Client{Timeout: 5 * time.Second}
+
+	batch := func(methods ...string) string {
+		reqs := make([]string, len(methods))
+		for i, method := range methods {
+			reqs[i] = `{"jsonrpc":"2.0","id":1,"method":"` + method + `"}`
+		}
+		return "[" + strings.Join(reqs, ",") + "]"
+	}
+
+	// Too many requests.
+	status, resp := doRequest(t, c, "POST", url, "", batch("status", "status", "status"))
+	assert.Equal(t, http.StatusBadRequest, status)
+	if assert.NotNil(t, resp.Error) {
+		assert.Equal(t, -32600, resp.Error.Code)
+	}
+	assert.Equal(t, 1.0, counter.count(rejectedBatchSize))
+
+	// The batch costs 1 unit on top of its requests: 4 + 1 + 1 > 5, more
+	// than the burst, so it is never allowed.
+	status, resp = doRequest(t, c, "POST", url, "", batch("search", "status"))
+	assert.Equal(t, http.StatusBadRequest, status)
+	if assert.NotNil(t, resp.Error) {
+		assert.Equal(t, -32600, resp.Error.Code)
+	}
+	assert.Equal(t, 1.0, counter.count(rejectedBatchCost))
+	assert.Equal(t, 0.0, counter.count(rejectedRateLimit))
+
+	// 1 + 1 + 1 <= 5
+	req, err := http.NewRequest("POST", url, strings.NewReader(batch("status", "status")))
+	require.NoError(t, err)
+	res, err := c.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	// 1 + 1 + 1 > 5 - 3
+	status, _ = doRequest(t, c, "POST", url, "", batch("status", "status"))
+	assert.Equal(t, http.StatusTooManyRequests, status)
+	assert.Equal(t, 1.0, counter.count(rejectedRateLimit))
+}
+
+func TestRateLimitClients(t *testing.T) {
+	config, _ := testLimitConfig()
+	config.Auth = testAuthConfig()
+	url := startLimitServer(t, config)
+	c := &http.Client{Timeout: 5 * time.Second}
+
+	// Authenticated clients are limited by key, not by IP address.
+	status, _ := doRequest(t, c, "GET", url+"/search", testUserToken, "")
+	assert.Equal(t, http.StatusOK, status)
+	status, _ = doRequest(t, c, "GET", url+"/search", testUserToken, "")
+	assert.Equal(t, http.StatusTooManyRequests, status)
+	status, _ = doRequest(t, c, "GET", url+"/search", testOpsToken, "")
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestRateLimitWebsocket(t *testing.T) {
+	config, counter := testLimitConfig()
+	url := startLimitServer(t, config)
+	wsURL := "ws" + strings.TrimPrefix(url, "http") + "/websocket"
+
+	conn, dialResp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	defer dialResp.Body.Close()
+
+	call := func(route string) rpctypes.RPCResponse {
+		req := rpctypes.RPCRequest{JSONRPC: "2.0", ID: rpctypes.JSONRPCIntID(1), Method: route}
+		require.NoError(t, conn.WriteJSON(req))
+		var resp rpctypes.RPCResponse
+		require.NoError(t, conn.ReadJSON(&resp))
+		return resp
+	}
+
+	assert.Nil(t, call("search").Error)
+	resp := call("search")
+	if assert.NotNil(t, resp.Error) {
+		assert.Equal(t, -32003, resp.Error.Code)
+	}
+	assert.Nil(t, call("status").Error)
+	assert.Equal(t, 1.0, counter.count(rejectedRateLimit))
+}
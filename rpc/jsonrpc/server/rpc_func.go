@@ -15,7 +15,8 @@ import (
 func RegisterRPCFuncs(mux *http.ServeMux, funcMap map[string]*RPCFunc, logger log.Logger) {
 	// HTTP endpoints
 	for funcName, rpcFunc := range funcMap {
-		mux.HandleFunc("/"+funcName, makeHTTPHandler(rpcFunc, logger))
+		handler := limitRoute(funcName, makeHTTPHandler(rpcFunc, logger), logger)
+		mux.HandleFunc("/"+funcName, authorizeRoute(funcName, handler, logger))
 	}
 
 	// JSONRPC endpoints
@@ -88,6 +88,8 @@ func (wm *WebsocketManager) WebsocketHandler(w http.ResponseWriter, r *http.Requ
 	// register connection
 	logger := wm.logger.With("remote", wsConn.RemoteAddr())
 	con := newWSConnection(wsConn, wm.funcMap, logger, wm.wsConnOptions...)
+	con.auth = authInfoFromContext(r.Context())
+	con.limiter = limiterFromContext(r.Context())
 	wm.logger.Info("New websocket connection", "remote", con.remoteAddr)
 	err = con.Start() // BLOCKING
 	if err != nil {
@@ -119,6 +121,14 @@ type wsConnection struct {
 
 	funcMap map[string]*RPCFunc
 
+	// authenticated client of the connection; nil if the server doesn't
+	// authenticate clients
+	auth *authInfo
+
+	// rate limiter of the client of the connection; nil if the server doesn't
+	// limit clients
+	limiter *clientLimiter
+
 	// write channel capacity
 	writeChanCapacity int
 
@@ -368,6 +378,18 @@ func (wsc *wsConnection) readRoutine() {
 				}
 				continue
 			}
+			if !wsc.auth.allowed(request.Method) {
+				if err := wsc.WriteRPCResponse(writeCtx, rpctypes.RPCForbiddenError(request.ID)); err != nil {
+					wsc.Logger.Error("Error writing RPC response", "err", err)
+				}
+				continue
+			}
+			if _, err := wsc.limiter.allowRoutes(false, request.Method); err != nil {
+				if err := wsc.WriteRPCResponse(writeCtx, rpctypes.RPCTooManyRequestsError(request.ID, err)); err != nil {
+					wsc.Logger.Error("Error writing RPC response", "err", err)
+				}
+				continue
+			}
 
 			ctx := &rpctypes.Context{JSONReq: &request, WSConn: wsc}
 			args := []reflect.Value{reflect.ValueOf(ctx)}
@@ -407,6 +429,11 @@ func (wsc *wsConnection) readRoutine() {
 					coretypes.ErrPageOutOfRange, coretypes.ErrInvalidRequest:
 					resp = rpctypes.RPCInvalidRequestError(request.ID, err)
 
+				// check if the client has too many subscriptions
+				case coretypes.ErrTooManySubscriptions:
+					wsc.limiter.rejected(rejectedSubscriptions)
+					resp = rpctypes.RPCTooManyRequestsError(request.ID, err)
+
 				// lastly default all remaining errors as internal errors
 				default: // includes ctypes.ErrHeightNotAvailable and ctypes.ErrHeightExceedsChainHead
 					resp = rpctypes.RPCInternalError(request.ID, err)
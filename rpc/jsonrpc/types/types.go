@@ -244,6 +244,24 @@ func RPCServerError(id jsonrpcid, err error) RPCResponse {
 	return NewRPCErrorResponse(id, -32000, "Server error", err.Error())
 }
 
+// RPCUnauthorizedError is returned when the server requires authentication
+// and the client did not authenticate.
+func RPCUnauthorizedError(id jsonrpcid, err error) RPCResponse {
+	return NewRPCErrorResponse(id, -32001, "Unauthorized", err.Error())
+}
+
+// RPCForbiddenError is returned when the client is not allowed to call the
+// method.
+func RPCForbiddenError(id jsonrpcid) RPCResponse {
+	return NewRPCErrorResponse(id, -32002, "Forbidden", "")
+}
+
+// RPCTooManyRequestsError is returned when the client exceeded its rate
+// limit.
+func RPCTooManyRequestsError(id jsonrpcid, err error) RPCResponse {
+	return NewRPCErrorResponse(id, -32003, "Too many requests", err.Error())
+}
+
 //----------------------------------------
 
 // WSRPCConnection represents a websocket connection.
@@ -271,6 +289,18 @@ type Context struct {
 	WSConn WSRPCConnection
 	// http request
 	HTTPReq *http.Request
+
+	// context of a request made in process, see WithContext
+	ctx context.Context
+}
+
+// WithContext returns a copy of the request whose Context method returns the
+// given context, unless the request was made over HTTP or websocket. It lets
+// in-process callers cancel the requests they make.
+func (ctx *Context) WithContext(c context.Context) *Context {
+	cp := *ctx
+	cp.ctx = c
+	return &cp
 }
 
 // RemoteAddr returns the remote address (usually a string "IP:port").
@@ -295,11 +325,15 @@ func (ctx *Context) RemoteAddr() string {
 //		is canceled (with HTTP/2), or when the ServeHTTP method returns.
 // WS:
 //		The context is canceled when the client's connections closes.
+// In process:
+//		The context given to WithContext.
 func (ctx *Context) Context() context.Context {
 	if ctx.HTTPReq != nil {
 		return ctx.HTTPReq.Context()
 	} else if ctx.WSConn != nil {
 		return ctx.WSConn.Context()
+	} else if ctx.ctx != nil {
+		return ctx.ctx
 	}
 	return context.Background()
 }
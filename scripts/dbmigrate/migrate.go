@@ -0,0 +1,178 @@
+// Package dbmigrate copies databases between tm-db backends, e.g. to move a
+// node from goleveldb to another backend without resyncing it.
+//
+// Like keymigrate, the migration does not depend on any tendermint code: it
+// copies every key of a database in key order, so that an interrupted
+// migration can be resumed from the last copied key, and can verify the copy
+// by comparing key counts and checksums of both databases.
+package dbmigrate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	dbm "github.com/tendermint/tm-db"
+)
+
+// DefaultBatchSize is the default number of keys written per batch.
+const DefaultBatchSize = 1000
+
+// Progress records how far the migration of a database has progressed.
+type Progress struct {
+	// LastKey is the last key copied, or nil if no key has been copied yet.
+	LastKey []byte `json:"last_key"`
+
+	// Keys is the number of keys copied so far.
+	Keys uint64 `json:"keys"`
+
+	// Done is true once all keys have been copied.
+	Done bool `json:"done"`
+}
+
+// Migrate copies all keys from src to dst, in batches of batchSize keys,
+// starting after progress.LastKey. After each batch has been written, the
+// updated progress is passed to onBatch, which may persist it in order to
+// resume the migration later. The source database must not be modified while
+// the migration runs.
+func Migrate(
+	ctx context.Context,
+	src, dst dbm.DB,
+	progress Progress,
+	batchSize int,
+	onBatch func(Progress) error,
+) (Progress, error) {
+	if progress.Done {
+		return progress, nil
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	var start []byte
+	if progress.LastKey != nil {
+		// the smallest key greater than the last copied key
+		start = append(append([]byte{}, progress.LastKey...), 0x00)
+	}
+	iter, err := src.Iterator(start, nil)
+	if err != nil {
+		return progress, err
+	}
+	defer iter.Close()
+
+	var lastKey []byte
+	for iter.Valid() {
+		if err := ctx.Err(); err != nil {
+			return progress, err
+		}
+
+		batch := dst.NewBatch()
+		n := 0
+		for ; iter.Valid() && n < batchSize; iter.Next() {
+			if err := batch.Set(iter.Key(), iter.Value()); err != nil {
+				batch.Close()
+				return progress, err
+			}
+			lastKey = append(lastKey[:0], iter.Key()...)
+			n++
+		}
+		if err := iter.Error(); err != nil {
+			batch.Close()
+			return progress, err
+		}
+		err := batch.WriteSync()
+		if closeErr := batch.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return progress, err
+		}
+
+		progress.LastKey = append([]byte{}, lastKey...)
+		progress.Keys += uint64(n)
+		if onBatch != nil {
+			if err := onBatch(progress); err != nil {
+				return progress, err
+			}
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return progress, err
+	}
+
+	progress.Done = true
+	return progress, nil
+}
+
+// Checksum is a summary of the contents of a database.
+type Checksum struct {
+	// Keys is the number of keys in the database.
+	Keys uint64
+
+	// Hash is a SHA-256 hash over all length-prefixed keys and values of the
+	// database, in key order.
+	Hash []byte
+}
+
+// Equal returns true if both checksums are equal.
+func (c Checksum) Equal(o Checksum) bool {
+	return c.Keys == o.Keys && bytes.Equal(c.Hash, o.Hash)
+}
+
+func (c Checksum) String() string {
+	return fmt.Sprintf("%d keys, hash %X", c.Keys, c.Hash)
+}
+
+// ComputeChecksum computes the checksum of all contents of the database.
+func ComputeChecksum(ctx context.Context, db dbm.DB) (Checksum, error) {
+	iter, err := db.Iterator(nil, nil)
+	if err != nil {
+		return Checksum{}, err
+	}
+	defer iter.Close()
+
+	var (
+		checksum Checksum
+		hasher   = sha256.New()
+		length   [binary.MaxVarintLen64]byte
+	)
+	for ; iter.Valid(); iter.Next() {
+		if checksum.Keys%DefaultBatchSize == 0 {
+			if err := ctx.Err(); err != nil {
+				return Checksum{}, err
+			}
+		}
+		for _, bz := range [][]byte{iter.Key(), iter.Value()} {
+			n := binary.PutUvarint(length[:], uint64(len(bz)))
+			_, _ = hasher.Write(length[:n])
+			_, _ = hasher.Write(bz)
+		}
+		checksum.Keys++
+	}
+	if err := iter.Error(); err != nil {
+		return Checksum{}, err
+	}
+
+	checksum.Hash = hasher.Sum(nil)
+	return checksum, nil
+}
+
+// Verify checks that src and dst have the same contents, by comparing their
+// key counts and checksums.
+func Verify(ctx context.Context, src, dst dbm.DB) error {
+	srcChecksum, err := ComputeChecksum(ctx, src)
+	if err != nil {
+		return fmt.Errorf("computing source checksum: %w", err)
+	}
+	dstChecksum, err := ComputeChecksum(ctx, dst)
+	if err != nil {
+		return fmt.Errorf("computing target checksum: %w", err)
+	}
+	if !srcChecksum.Equal(dstChecksum) {
+		return fmt.Errorf("target database (%v) does not match source database (%v)",
+			dstChecksum, srcChecksum)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package dbmigrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+)
+
+func makeDB(t *testing.T, n int) dbm.DB {
+	t.Helper()
+	db := dbm.NewMemDB()
+	for i := 0; i < n; i++ {
+		require.NoError(t, db.Set([]byte(fmt.Sprintf("key%05d", i)), []byte(fmt.Sprintf("value%d", i))))
+	}
+	return db
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	src := makeDB(t, 2500)
+	dst := dbm.NewMemDB()
+
+	batches := 0
+	progress, err := Migrate(ctx, src, dst, Progress{}, 1000, func(p Progress) error {
+		batches++
+		require.False(t, p.Done)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, batches)
+	require.True(t, progress.Done)
+	require.EqualValues(t, 2500, progress.Keys)
+	require.Equal(t, []byte("key02499"), progress.LastKey)
+	require.NoError(t, Verify(ctx, src, dst))
+
+	// Migrating again is a no-op.
+	_, err = Migrate(ctx, src, dst, progress, 1000, func(Progress) error {
+		t.Fatal("unexpected batch")
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestMigrateResume(t *testing.T) {
+	ctx := context.Background()
+	src := makeDB(t, 2500)
+	dst := dbm.NewMemDB()
+
+	errInterrupted := errors.New("interrupted")
+	var saved Progress
+	_, err := Migrate(ctx, src, dst, Progress{}, 1000, func(p Progress) error {
+		saved = p
+		if p.Keys == 2000 {
+			return errInterrupted
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errInterrupted)
+	require.EqualValues(t, 2000, saved.Keys)
+	require.Error(t, Verify(ctx, src, dst))
+
+	progress, err := Migrate(ctx, src, dst, saved, 1000, nil)
+	require.NoError(t, err)
+	require.True(t, progress.Done)
+	require.EqualValues(t, 2500, progress.Keys)
+	require.NoError(t, Verify(ctx, src, dst))
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	src := makeDB(t, 10)
+	dst := makeDB(t, 10)
+	require.NoError(t, Verify(ctx, src, dst))
+
+	require.NoError(t, dst.Set([]byte("key00003"), []byte("changed")))
+	require.Error(t, Verify(ctx, src, dst))
+
+	dst = makeDB(t, 11)
+	require.Error(t, Verify(ctx, src, dst))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := Migrate(canceled, src, dbm.NewMemDB(), Progress{}, 0, nil)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Error(t, Verify(canceled, src, dst))
+}